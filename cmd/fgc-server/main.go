@@ -15,8 +15,15 @@ import (
 	"go.uber.org/zap"
 
 	"code.forgejo.org/forgejo/classroom/internal/api"
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/cache"
 	"code.forgejo.org/forgejo/classroom/internal/config"
 	"code.forgejo.org/forgejo/classroom/internal/database"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
 var (
@@ -79,16 +86,44 @@ func main() {
 		)
 	}
 
-	// TODO: Initialize cache
-	// TODO: Initialize Forgejo client
-	// TODO: Initialize services
+	// Collect Prometheus metrics, served on /metrics
+	var m *metrics.Metrics
+	if cfg.Server.Metrics.Enabled {
+		m = metrics.New(logger)
+		m.WatchDB(db.DB)
+	}
+
+	// Cache reads in Redis, falling back to memory if enabled
+	readCache := cache.New(&cfg.Redis, &cfg.Cache, logger)
+	defer readCache.Close()
+
+	// Initialize Forgejo client
+	forgejoClient, err := forgejo.New(&cfg.Forgejo, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize Forgejo client", zap.Error(err))
+	}
+	forgejoClient = forgejoClient.WithMetrics(m)
+
+	// Background jobs run on a Postgres-backed queue
+	jobQueue := queue.NewPostgresQueue(db.DB, &cfg.Queue)
+	workers := queue.NewPool(jobQueue, &cfg.Queue, logger)
+	workers.SetMetrics(m)
+	m.WatchQueue(jobQueue)
+
+	// Initialize services
+	services := service.New(repository.New(db), forgejoClient, jobQueue, cache.NewStore(readCache, &cfg.Cache, logger), logger)
+	services.RegisterJobs(workers)
+	m.WatchActivity(services.Classrooms)
+
+	// Validate API tokens against Forgejo
+	tokens := auth.NewTokenValidator(forgejoClient, &cfg.Auth)
 
 	// Initialize Gin router
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := api.NewRouter(cfg, logger)
+	router := api.NewRouter(cfg, services, tokens, m, logger)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -106,12 +141,19 @@ func main() {
 		}
 	}()
 
+	workers.Start()
+
+	// Schedule deadline enforcement, catching up on deadlines missed while down
+	schedCtx, stopScheduler := context.WithCancel(context.Background())
+	go services.Deadlines.Run(schedCtx)
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopScheduler()
 
 	// Create shutdown context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -122,6 +164,11 @@ func main() {
 		logger.Error("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Let running jobs finish; unfinished ones are retried after restart
+	if err := workers.Shutdown(ctx); err != nil {
+		logger.Error("Job workers forced to shutdown", zap.Error(err))
+	}
+
 	logger.Info("Server exited")
 }
 
@@ -131,6 +178,8 @@ func initConfig() error {
 	viper.SetDefault("server.mode", "debug")
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
+	viper.SetDefault("server.rate_limit.enabled", true)
+	viper.SetDefault("server.metrics.enabled", true)
 
 	// Environment variables
 	viper.SetEnvPrefix("FGC")
@@ -2,8 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 )
 
 // NewAssignmentCommand creates the assignment command and its subcommands
@@ -20,6 +25,7 @@ func NewAssignmentCommand() *cobra.Command {
 	cmd.AddCommand(newAssignmentUpdateCommand())
 	cmd.AddCommand(newAssignmentDeleteCommand())
 	cmd.AddCommand(newAssignmentStatsCommand())
+	cmd.AddCommand(newAssignmentInvitationCommand())
 
 	return cmd
 }
@@ -31,15 +37,42 @@ func newAssignmentCreateCommand() *cobra.Command {
 		Long:  "Create a new assignment from a template repository",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment creation
-			fmt.Printf("Creating assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			classroom, _ := cmd.Flags().GetString("classroom")
+			classroomID, err := parseID(classroom, "classroom ID")
+			if err != nil {
+				return err
+			}
+			template, _ := cmd.Flags().GetString("template")
+			deadline, _ := cmd.Flags().GetString("deadline")
+			description, _ := cmd.Flags().GetString("description")
+			maxTeamSize, _ := cmd.Flags().GetInt("max-teams")
+			autoAccept, _ := cmd.Flags().GetBool("auto-accept")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			assignment, err := api.CreateAssignment(cmd.Context(), &model.CreateAssignmentRequest{
+				ClassroomID:        classroomID,
+				Name:               args[0],
+				Description:        description,
+				TemplateRepository: template,
+				Deadline:           deadline,
+				MaxTeamSize:        maxTeamSize,
+				AutoAccept:         autoAccept,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Created assignment %d (%s)\n", assignment.ID, assignment.Slug)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringP("classroom", "c", "", "Classroom ID (required)")
-	cmd.Flags().StringP("template", "t", "", "Template repository URL (required)")
+	cmd.Flags().StringP("template", "t", "", "Template repository as owner/name or URL (required)")
 	cmd.Flags().StringP("deadline", "d", "", "Assignment deadline (RFC3339 format)")
 	cmd.Flags().StringP("description", "D", "", "Assignment description")
 	cmd.Flags().IntP("max-teams", "m", 1, "Maximum team size (1 for individual)")
@@ -56,17 +89,48 @@ func newAssignmentListCommand() *cobra.Command {
 		Short: "List assignments",
 		Long:  "List assignments in a classroom or across all classrooms",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment listing
-			fmt.Println("Listing assignments...")
-			fmt.Println("Not yet implemented")
-			return nil
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			req := &model.AssignmentListRequest{}
+			if classroom, _ := cmd.Flags().GetString("classroom"); classroom != "" {
+				id, err := parseID(classroom, "classroom ID")
+				if err != nil {
+					return err
+				}
+				req.ClassroomID = id
+			}
+			if active, _ := cmd.Flags().GetBool("active"); active {
+				req.Status = "active"
+			}
+			if past, _ := cmd.Flags().GetBool("past"); past {
+				req.Status = "past"
+			}
+			req.Page, _ = cmd.Flags().GetInt("page")
+			req.PerPage, _ = cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListAssignments(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, assignmentTable, list.Assignments, list.Page, list.TotalPages, list.Total, "assignments")
 		},
 	}
 
 	cmd.Flags().StringP("classroom", "c", "", "Filter by classroom ID")
 	cmd.Flags().BoolP("active", "a", false, "Show only active assignments")
 	cmd.Flags().BoolP("past", "p", false, "Show only past assignments")
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
+	cmd.Flags().Int("page", 1, "Page number")
+	cmd.Flags().Int("per-page", 20, "Assignments per page")
+	cmd.MarkFlagsMutuallyExclusive("active", "past")
 
 	return cmd
 }
@@ -78,14 +142,30 @@ func newAssignmentViewCommand() *cobra.Command {
 		Long:  "Display detailed information about a specific assignment",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment viewing
-			fmt.Printf("Viewing assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			assignment, err := api.GetAssignment(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			return output.RenderOne(os.Stdout, opts, assignmentTable, *assignment)
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
 
 	return cmd
 }
@@ -97,9 +177,48 @@ func newAssignmentUpdateCommand() *cobra.Command {
 		Long:  "Update the settings of an existing assignment",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment updating
-			fmt.Printf("Updating assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+
+			var req model.UpdateAssignmentRequest
+			flags := cmd.Flags()
+			if flags.Changed("name") {
+				name, _ := flags.GetString("name")
+				req.Name = &name
+			}
+			if flags.Changed("deadline") {
+				deadline, _ := flags.GetString("deadline")
+				req.Deadline = &deadline
+			}
+			if flags.Changed("description") {
+				description, _ := flags.GetString("description")
+				req.Description = &description
+			}
+			if flags.Changed("max-teams") {
+				maxTeamSize, _ := flags.GetInt("max-teams")
+				req.MaxTeamSize = &maxTeamSize
+			}
+			if flags.Changed("auto-accept") || flags.Changed("no-auto-accept") {
+				autoAccept := flags.Changed("auto-accept")
+				req.AutoAccept = &autoAccept
+			}
+			if req == (model.UpdateAssignmentRequest{}) {
+				return fmt.Errorf("nothing to update: set at least one flag")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			assignment, err := api.UpdateAssignment(cmd.Context(), id, &req)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Updated assignment %d\n", assignment.ID)
 			return nil
 		},
 	}
@@ -110,6 +229,7 @@ func newAssignmentUpdateCommand() *cobra.Command {
 	cmd.Flags().IntP("max-teams", "m", 0, "New maximum team size")
 	cmd.Flags().Bool("auto-accept", false, "Enable auto-accept submissions")
 	cmd.Flags().Bool("no-auto-accept", false, "Disable auto-accept submissions")
+	cmd.MarkFlagsMutuallyExclusive("auto-accept", "no-auto-accept")
 
 	return cmd
 }
@@ -121,9 +241,33 @@ func newAssignmentDeleteCommand() *cobra.Command {
 		Long:  "Permanently delete an assignment and all its submissions",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment deletion
-			fmt.Printf("Deleting assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			force, _ := cmd.Flags().GetBool("force")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				assignment, err := api.GetAssignment(cmd.Context(), id)
+				if err != nil {
+					return err
+				}
+				if !confirm(fmt.Sprintf("Delete assignment %q with all its submissions?", assignment.Name)) {
+					fmt.Println("Aborted")
+					return nil
+				}
+			}
+
+			if err := api.DeleteAssignment(cmd.Context(), id); err != nil {
+				return err
+			}
+
+			fmt.Printf("Deleted assignment %d\n", id)
 			return nil
 		},
 	}
@@ -137,18 +281,120 @@ func newAssignmentStatsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "stats [id]",
 		Short: "View assignment statistics",
-		Long:  "Display statistics for assignment submissions and progress",
-		Args:  cobra.ExactArgs(1),
+		Long: `Display statistics for assignment submissions and progress. With
+--detailed, also show how many commits students made, when they accepted
+and who has not accepted yet.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment statistics
-			fmt.Printf("Viewing stats for assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			detailed, _ := cmd.Flags().GetBool("detailed")
+			if detailed && opts.Format == output.FormatCSV {
+				return fmt.Errorf("--detailed cannot be used with csv output: use json or yaml")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			stats, err := api.GetAssignmentStats(cmd.Context(), id, &model.AssignmentStatsRequest{Detailed: detailed})
+			if err != nil {
+				return err
+			}
+
+			if err := output.RenderOne(os.Stdout, opts, assignmentStatsTable, *stats); err != nil {
+				return err
+			}
+			if stats.Details == nil || opts.Format != output.FormatTable {
+				return nil
+			}
+			return renderStatsDetails(opts, stats.Details)
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
 	cmd.Flags().Bool("detailed", false, "Show detailed statistics")
 
 	return cmd
 }
+
+// renderStatsDetails renders the distributions of detailed statistics as
+// tables below the summary
+func renderStatsDetails(opts *output.Options, details *model.AssignmentStatsDetails) error {
+	// --columns only selects the summary fields
+	opts = &output.Options{Format: output.FormatTable, NoHeaders: opts.NoHeaders}
+
+	fmt.Println("\nCommits per student:")
+	if err := output.Render(os.Stdout, opts, statsBucketTable, details.CommitDistribution); err != nil {
+		return err
+	}
+	fmt.Println("\nAcceptances per day:")
+	if err := output.Render(os.Stdout, opts, dateCountTable, details.AcceptanceHistogram); err != nil {
+		return err
+	}
+	fmt.Println("\nNot accepted:")
+	return output.Render(os.Stdout, opts, rosterTable, details.NotAccepted)
+}
+
+// assignmentTable lists the columns of assignments
+var assignmentTable = &output.Table[model.Assignment]{
+	Columns: []output.Column[model.Assignment]{
+		{Name: "id", Header: "ID", Value: func(a model.Assignment) string { return strconv.FormatInt(a.ID, 10) }},
+		{Name: "classroom_id", Header: "Classroom", Value: func(a model.Assignment) string { return strconv.FormatInt(a.ClassroomID, 10) }},
+		{Name: "name", Header: "Name", Value: func(a model.Assignment) string { return a.Name }},
+		{Name: "slug", Header: "Slug", Value: func(a model.Assignment) string { return a.Slug }},
+		{Name: "description", Header: "Description", Value: func(a model.Assignment) string { return a.Description }},
+		{Name: "template_repository", Header: "Template", Value: func(a model.Assignment) string { return a.TemplateRepository }},
+		{Name: "deadline", Header: "Deadline", Value: func(a model.Assignment) string { return formatTime(a.Deadline) }},
+		{Name: "max_team_size", Header: "Team size", Value: func(a model.Assignment) string { return strconv.Itoa(a.MaxTeamSize) }},
+		{Name: "auto_accept", Header: "Auto-accept", Value: func(a model.Assignment) string { return strconv.FormatBool(a.AutoAccept) }},
+		{Name: "created_at", Header: "Created", Value: func(a model.Assignment) string { return formatTime(&a.CreatedAt) }},
+	},
+	Default: []string{"id", "classroom_id", "name", "slug", "deadline", "max_team_size"},
+}
+
+// assignmentStatsTable lists the columns of assignment statistics
+var assignmentStatsTable = &output.Table[model.AssignmentStats]{
+	Columns: []output.Column[model.AssignmentStats]{
+		{Name: "total_students", Header: "Students", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.TotalStudents) }},
+		{Name: "accepted_count", Header: "Accepted", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.AcceptedCount) }},
+		{Name: "acceptance_rate", Header: "Acceptance rate", Value: func(s model.AssignmentStats) string { return formatPercent(s.AcceptanceRate) }},
+		{Name: "submission_count", Header: "Submitted", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.SubmissionCount) }},
+		{Name: "submission_rate", Header: "Submission rate", Value: func(s model.AssignmentStats) string { return formatPercent(s.SubmissionRate) }},
+		{Name: "team_count", Header: "Teams", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.TeamCount) }},
+		{Name: "average_commits", Header: "Average commits", Value: func(s model.AssignmentStats) string { return strconv.FormatFloat(s.AverageCommits, 'f', 1, 64) }},
+		{Name: "on_time_submissions", Header: "On time", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.OnTimeSubmissions) }},
+		{Name: "late_submissions", Header: "Late", Value: func(s model.AssignmentStats) string { return strconv.Itoa(s.LateSubmissions) }},
+	},
+}
+
+// statsBucketTable lists the columns of distribution buckets
+var statsBucketTable = &output.Table[model.StatsBucket]{
+	Columns: []output.Column[model.StatsBucket]{
+		{Name: "range", Header: "Commits", Value: func(b model.StatsBucket) string {
+			switch {
+			case b.Max == nil:
+				return strconv.Itoa(b.Min) + "+"
+			case *b.Max == b.Min:
+				return strconv.Itoa(b.Min)
+			}
+			return strconv.Itoa(b.Min) + "-" + strconv.Itoa(*b.Max)
+		}},
+		{Name: "count", Header: "Students", Value: func(b model.StatsBucket) string { return strconv.Itoa(b.Count) }},
+	},
+}
+
+// dateCountTable lists the columns of per-day counts
+var dateCountTable = &output.Table[model.DateCount]{
+	Columns: []output.Column[model.DateCount]{
+		{Name: "date", Header: "Date", Value: func(d model.DateCount) string { return d.Date }},
+		{Name: "count", Header: "Students", Value: func(d model.DateCount) string { return strconv.Itoa(d.Count) }},
+	},
+}
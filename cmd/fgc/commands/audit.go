@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// NewAuditCommand creates the audit command and its subcommands
+func NewAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log",
+		Long:  "Review who changed what in a classroom, and through which request",
+	}
+
+	cmd.AddCommand(newAuditListCommand())
+
+	return cmd
+}
+
+func newAuditListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [classroom-id]",
+		Short: "List audit events",
+		Long: `Display the audit events of a classroom, newest first. Changes made by
+the server itself, such as deadline tags, are listed with the actor "system".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			flags := cmd.Flags()
+			action, _ := flags.GetString("action")
+			targetType, _ := flags.GetString("target-type")
+			targetID, _ := flags.GetInt64("target-id")
+			actor, _ := flags.GetString("actor")
+			since, _ := flags.GetString("since")
+			until, _ := flags.GetString("until")
+			page, _ := flags.GetInt("page")
+			perPage, _ := flags.GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListAuditEvents(cmd.Context(), id, &model.AuditListRequest{
+				Action:     action,
+				TargetType: targetType,
+				TargetID:   targetID,
+				Actor:      actor,
+				Since:      since,
+				Until:      until,
+				Page:       page,
+				PerPage:    perPage,
+			})
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, auditTable, list.Events, list.Page, list.TotalPages, list.Total, "events")
+		},
+	}
+
+	output.AddFlags(cmd)
+	cmd.Flags().StringP("action", "a", "", "Action, e.g. roster.link")
+	cmd.Flags().String("target-type", "", "Target type, e.g. roster_entry")
+	cmd.Flags().Int64("target-id", 0, "Target ID")
+	cmd.Flags().String("actor", "", "Forgejo login of the actor")
+	cmd.Flags().String("since", "", "Events at or after this RFC3339 time")
+	cmd.Flags().String("until", "", "Events before this RFC3339 time")
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 50, "Events per page")
+
+	return cmd
+}
+
+// auditActor returns the login of an event's actor, or "system"
+func auditActor(e model.AuditEvent) string {
+	if e.ActorLogin == "" {
+		return "system"
+	}
+	return e.ActorLogin
+}
+
+// auditFields returns the names of the fields an event changed
+func auditFields(e model.AuditEvent) string {
+	fields := make([]string, 0, len(e.Changes))
+	for name := range e.Changes {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ", ")
+}
+
+// auditTable lists the columns of audit events
+var auditTable = &output.Table[model.AuditEvent]{
+	Columns: []output.Column[model.AuditEvent]{
+		{Name: "id", Header: "ID", Value: func(e model.AuditEvent) string { return strconv.FormatInt(e.ID, 10) }},
+		{Name: "created_at", Header: "Time", Value: func(e model.AuditEvent) string { return formatTime(&e.CreatedAt) }},
+		{Name: "actor", Header: "Actor", Value: auditActor},
+		{Name: "action", Header: "Action", Value: func(e model.AuditEvent) string { return e.Action }},
+		{Name: "target_type", Header: "Target", Value: func(e model.AuditEvent) string { return e.TargetType }},
+		{Name: "target_id", Header: "Target ID", Value: func(e model.AuditEvent) string { return strconv.FormatInt(e.TargetID, 10) }},
+		{Name: "changes", Header: "Changed", Value: auditFields},
+		{Name: "request_id", Header: "Request", Value: func(e model.AuditEvent) string { return e.RequestID }},
+		{Name: "ip", Header: "IP", Value: func(e model.AuditEvent) string { return e.IP }},
+	},
+	Default: []string{"id", "created_at", "actor", "action", "target_type", "target_id", "changes"},
+}
@@ -0,0 +1,344 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// newRosterJoinLinkCommand creates the roster join-link command and its
+// subcommands
+func newRosterJoinLinkCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "join-link",
+		Short: "Manage classroom join links",
+		Long: `View, rotate, enable and disable the join link of a classroom. Students
+claim their roster entry with the link, using "fgc student join".`,
+	}
+
+	cmd.AddCommand(newJoinLinkViewCommand())
+	cmd.AddCommand(newJoinLinkRotateCommand())
+	cmd.AddCommand(newJoinLinkUpdateCommand())
+
+	return cmd
+}
+
+func newJoinLinkViewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view [classroom-id]",
+		Short: "View the join link of a classroom",
+		Long:  "Display the join link token and URL of a classroom",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			link, err := api.GetJoinLink(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			return output.RenderOne(os.Stdout, opts, joinLinkTable, *link)
+		},
+	}
+
+	output.AddFlags(cmd)
+
+	return cmd
+}
+
+func newJoinLinkRotateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate [classroom-id]",
+		Short: "Issue a new join link token",
+		Long: `Issue a new join link token for a classroom. The previous link stops
+working; linked students and pending claims are not affected. The link
+keeps whether claims need approval; a classroom's first link requires it
+unless --no-approval is set.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			var req model.RotateJoinLinkRequest
+			flags := cmd.Flags()
+			if flags.Changed("require-approval") || flags.Changed("no-approval") {
+				approval := flags.Changed("require-approval")
+				req.RequiresApproval = &approval
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			link, err := api.RotateJoinLink(cmd.Context(), id, &req)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Join link token: %s\n", link.Token)
+			if link.URL != "" {
+				fmt.Printf("Join link URL: %s\n", link.URL)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("require-approval", false, "Claims wait for staff approval")
+	cmd.Flags().Bool("no-approval", false, "Claims link students right away")
+	cmd.MarkFlagsMutuallyExclusive("require-approval", "no-approval")
+
+	return cmd
+}
+
+func newJoinLinkUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [classroom-id]",
+		Short: "Enable or disable a join link",
+		Long:  "Enable or disable the join link of a classroom, or change whether claims need approval",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+
+			var req model.UpdateJoinLinkRequest
+			flags := cmd.Flags()
+			if flags.Changed("enable") || flags.Changed("disable") {
+				enabled := flags.Changed("enable")
+				req.Enabled = &enabled
+			}
+			if flags.Changed("require-approval") || flags.Changed("no-approval") {
+				approval := flags.Changed("require-approval")
+				req.RequiresApproval = &approval
+			}
+			if req == (model.UpdateJoinLinkRequest{}) {
+				return fmt.Errorf("nothing to update: set --enable, --disable, --require-approval or --no-approval")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			link, err := api.UpdateJoinLink(cmd.Context(), id, &req)
+			if err != nil {
+				return err
+			}
+
+			state := "disabled"
+			if link.Enabled {
+				state = "enabled"
+			}
+			if link.RequiresApproval {
+				state += ", claims need approval"
+			}
+			fmt.Printf("Join link of classroom %d is %s\n", link.ClassroomID, state)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("enable", false, "Enable the join link")
+	cmd.Flags().Bool("disable", false, "Disable the join link")
+	cmd.Flags().Bool("require-approval", false, "Claims wait for staff approval")
+	cmd.Flags().Bool("no-approval", false, "Claims link students right away")
+	cmd.MarkFlagsMutuallyExclusive("enable", "disable")
+	cmd.MarkFlagsMutuallyExclusive("require-approval", "no-approval")
+
+	return cmd
+}
+
+// newRosterClaimsCommand creates the roster claims command and its
+// subcommands
+func newRosterClaimsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claims",
+		Short: "Review roster claims",
+		Long:  "List, approve and reject the roster entries students claimed through the join link",
+	}
+
+	cmd.AddCommand(newClaimsListCommand())
+	cmd.AddCommand(newClaimsDecideCommand("approve", "Approve a pending claim and link the student"))
+	cmd.AddCommand(newClaimsDecideCommand("reject", "Reject a pending claim"))
+
+	return cmd
+}
+
+func newClaimsListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [classroom-id]",
+		Short: "List roster claims",
+		Long:  "Display the roster claims of a classroom, oldest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			status, _ := cmd.Flags().GetString("status")
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListClaims(cmd.Context(), id, &model.RosterClaimListRequest{
+				Status:  status,
+				Page:    page,
+				PerPage: perPage,
+			})
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, claimTable, list.Claims, list.Page, list.TotalPages, list.Total, "claims")
+		},
+	}
+
+	output.AddFlags(cmd)
+	cmd.Flags().StringP("status", "s", model.ClaimStatusPending, "Claim status (pending, approved, rejected; empty for all)")
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 100, "Claims per page")
+
+	return cmd
+}
+
+// newClaimsDecideCommand creates the approve or reject command
+func newClaimsDecideCommand(action, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   action + " [classroom-id] [claim-id]",
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			classroomID, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			claimID, err := parseID(args[1], "claim ID")
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			decide := api.ApproveClaim
+			if action == "reject" {
+				decide = api.RejectClaim
+			}
+			claim, err := decide(cmd.Context(), classroomID, claimID)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Claim %d of %s by %s %s\n", claim.ID, claim.StudentID, claim.ForgejoUsername, claim.Status)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newStudentJoinCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "join [join-link]",
+		Short: "Join a classroom",
+		Long: `Claim your entry in a classroom roster and link it to your Forgejo
+account. The classroom is given by the join link or token shared by the
+instructor; your entry is found by your student ID or email.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			studentID, _ := cmd.Flags().GetString("student-id")
+			email, _ := cmd.Flags().GetString("email")
+			if studentID == "" && email == "" {
+				return fmt.Errorf("set --student-id or --email")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			token := invitationToken(args[0])
+			details, err := api.ResolveJoinLink(cmd.Context(), token)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Joining %s (%s)\n", details.ClassroomName, details.OrganizationName)
+
+			claim, err := api.ClaimRosterEntry(cmd.Context(), token, &model.ClaimRosterRequest{
+				StudentEmail: email,
+				StudentID:    studentID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if claim.Status == model.ClaimStatusPending {
+				fmt.Printf("Claimed %s; waiting for approval by the classroom staff\n", claim.StudentName)
+				return nil
+			}
+			fmt.Printf("Linked %s to your Forgejo account %s\n", claim.StudentName, claim.ForgejoUsername)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("student-id", "", "Your student ID")
+	cmd.Flags().StringP("email", "e", "", "Your email address in the roster")
+
+	return cmd
+}
+
+// joinLinkTable lists the columns of join links
+var joinLinkTable = &output.Table[model.JoinLink]{
+	Columns: []output.Column[model.JoinLink]{
+		{Name: "classroom_id", Header: "Classroom", Value: func(l model.JoinLink) string { return strconv.FormatInt(l.ClassroomID, 10) }},
+		{Name: "token", Header: "Token", Value: func(l model.JoinLink) string { return l.Token }},
+		{Name: "url", Header: "URL", Value: func(l model.JoinLink) string { return l.URL }},
+		{Name: "enabled", Header: "Enabled", Value: func(l model.JoinLink) string { return strconv.FormatBool(l.Enabled) }},
+		{Name: "requires_approval", Header: "Approval", Value: func(l model.JoinLink) string { return strconv.FormatBool(l.RequiresApproval) }},
+		{Name: "created_at", Header: "Issued", Value: func(l model.JoinLink) string { return formatTime(&l.CreatedAt) }},
+	},
+}
+
+// claimTable lists the columns of roster claims
+var claimTable = &output.Table[model.RosterClaim]{
+	Columns: []output.Column[model.RosterClaim]{
+		{Name: "id", Header: "ID", Value: func(c model.RosterClaim) string { return strconv.FormatInt(c.ID, 10) }},
+		{Name: "student_id", Header: "Student ID", Value: func(c model.RosterClaim) string { return c.StudentID }},
+		{Name: "student_name", Header: "Name", Value: func(c model.RosterClaim) string { return c.StudentName }},
+		{Name: "forgejo_username", Header: "Forgejo user", Value: func(c model.RosterClaim) string { return c.ForgejoUsername }},
+		{Name: "status", Header: "Status", Value: func(c model.RosterClaim) string { return c.Status }},
+		{Name: "decided_by", Header: "Decided by", Value: func(c model.RosterClaim) string { return formatOptional(c.DecidedBy) }},
+		{Name: "decided_at", Header: "Decided", Value: func(c model.RosterClaim) string { return formatTime(c.DecidedAt) }},
+		{Name: "created_at", Header: "Claimed", Value: func(c model.RosterClaim) string { return formatTime(&c.CreatedAt) }},
+	},
+	Default: []string{"id", "student_id", "student_name", "forgejo_username", "status", "created_at"},
+}
@@ -2,8 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 )
 
 // NewClassroomCommand creates the classroom command and its subcommands
@@ -20,6 +25,7 @@ func NewClassroomCommand() *cobra.Command {
 	cmd.AddCommand(newClassroomUpdateCommand())
 	cmd.AddCommand(newClassroomDeleteCommand())
 	cmd.AddCommand(newClassroomArchiveCommand())
+	cmd.AddCommand(newClassroomStatsCommand())
 
 	return cmd
 }
@@ -31,9 +37,26 @@ func newClassroomCreateCommand() *cobra.Command {
 		Long:  "Create a new classroom with the specified name and organization",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom creation
-			fmt.Printf("Creating classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			org, _ := cmd.Flags().GetString("org")
+			description, _ := cmd.Flags().GetString("description")
+			public, _ := cmd.Flags().GetBool("public")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			classroom, err := api.CreateClassroom(cmd.Context(), &model.CreateClassroomRequest{
+				Name:             args[0],
+				Description:      description,
+				OrganizationName: org,
+				Public:           public,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Created classroom %d (%s)\n", classroom.ID, classroom.Slug)
 			return nil
 		},
 	}
@@ -52,16 +75,39 @@ func newClassroomListCommand() *cobra.Command {
 		Short: "List classrooms",
 		Long:  "List all classrooms accessible to the current user",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom listing
-			fmt.Println("Listing classrooms...")
-			fmt.Println("Not yet implemented")
-			return nil
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			org, _ := cmd.Flags().GetString("org")
+			archived, _ := cmd.Flags().GetBool("archived")
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListClassrooms(cmd.Context(), &model.ClassroomListRequest{
+				OrganizationName: org,
+				IncludeArchived:  archived,
+				Page:             page,
+				PerPage:          perPage,
+			})
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, classroomTable, list.Classrooms, list.Page, list.TotalPages, list.Total, "classrooms")
 		},
 	}
 
 	cmd.Flags().StringP("org", "o", "", "Filter by organization")
 	cmd.Flags().BoolP("archived", "a", false, "Include archived classrooms")
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 20, "Classrooms per page")
 
 	return cmd
 }
@@ -73,14 +119,30 @@ func newClassroomViewCommand() *cobra.Command {
 		Long:  "Display detailed information about a specific classroom",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom viewing
-			fmt.Printf("Viewing classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			classroom, err := api.GetClassroom(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			return output.RenderOne(os.Stdout, opts, classroomTable, *classroom)
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
 
 	return cmd
 }
@@ -92,9 +154,40 @@ func newClassroomUpdateCommand() *cobra.Command {
 		Long:  "Update the settings of an existing classroom",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom updating
-			fmt.Printf("Updating classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+
+			var req model.UpdateClassroomRequest
+			flags := cmd.Flags()
+			if flags.Changed("name") {
+				name, _ := flags.GetString("name")
+				req.Name = &name
+			}
+			if flags.Changed("description") {
+				description, _ := flags.GetString("description")
+				req.Description = &description
+			}
+			if flags.Changed("public") || flags.Changed("private") {
+				public := flags.Changed("public")
+				req.Public = &public
+			}
+			if req == (model.UpdateClassroomRequest{}) {
+				return fmt.Errorf("nothing to update: set --name, --description, --public or --private")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			classroom, err := api.UpdateClassroom(cmd.Context(), id, &req)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Updated classroom %d\n", classroom.ID)
 			return nil
 		},
 	}
@@ -103,6 +196,7 @@ func newClassroomUpdateCommand() *cobra.Command {
 	cmd.Flags().StringP("description", "d", "", "New classroom description")
 	cmd.Flags().Bool("public", false, "Make classroom public")
 	cmd.Flags().Bool("private", false, "Make classroom private")
+	cmd.MarkFlagsMutuallyExclusive("public", "private")
 
 	return cmd
 }
@@ -114,9 +208,33 @@ func newClassroomDeleteCommand() *cobra.Command {
 		Long:  "Permanently delete a classroom and all its data",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom deletion
-			fmt.Printf("Deleting classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			force, _ := cmd.Flags().GetBool("force")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				classroom, err := api.GetClassroom(cmd.Context(), id)
+				if err != nil {
+					return err
+				}
+				if !confirm(fmt.Sprintf("Delete classroom %q with its roster and assignments?", classroom.Name)) {
+					fmt.Println("Aborted")
+					return nil
+				}
+			}
+
+			if err := api.DeleteClassroom(cmd.Context(), id); err != nil {
+				return err
+			}
+
+			fmt.Printf("Deleted classroom %d\n", id)
 			return nil
 		},
 	}
@@ -133,12 +251,118 @@ func newClassroomArchiveCommand() *cobra.Command {
 		Long:  "Archive a classroom to make it read-only",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement classroom archiving
-			fmt.Printf("Archiving classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			classroom, err := api.ArchiveClassroom(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Archived classroom %d\n", classroom.ID)
 			return nil
 		},
 	}
 
 	return cmd
 }
+
+func newClassroomStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats [id]",
+		Short: "View classroom statistics",
+		Long: `Display an overview of a classroom: its roster, assignments and
+submissions, and how many students accepted and submitted each assignment.
+CSV output and --columns apply to the assignments.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			stats, err := api.GetClassroomStats(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			switch opts.Format {
+			case output.FormatJSON, output.FormatYAML:
+				return output.RenderOne(os.Stdout, opts, classroomStatsTable, *stats)
+			case output.FormatCSV:
+				return output.Render(os.Stdout, opts, assignmentProgressTable, stats.Assignments)
+			}
+
+			summary := &output.Options{Format: output.FormatTable, NoHeaders: opts.NoHeaders}
+			if err := output.RenderOne(os.Stdout, summary, classroomStatsTable, *stats); err != nil {
+				return err
+			}
+			fmt.Println()
+			return renderList(opts, assignmentProgressTable, stats.Assignments, 1, 1, len(stats.Assignments), "assignments")
+		},
+	}
+
+	output.AddFlags(cmd)
+
+	return cmd
+}
+
+// classroomTable lists the columns of classrooms
+var classroomTable = &output.Table[model.Classroom]{
+	Columns: []output.Column[model.Classroom]{
+		{Name: "id", Header: "ID", Value: func(c model.Classroom) string { return strconv.FormatInt(c.ID, 10) }},
+		{Name: "name", Header: "Name", Value: func(c model.Classroom) string { return c.Name }},
+		{Name: "slug", Header: "Slug", Value: func(c model.Classroom) string { return c.Slug }},
+		{Name: "description", Header: "Description", Value: func(c model.Classroom) string { return c.Description }},
+		{Name: "organization", Header: "Organization", Value: func(c model.Classroom) string { return c.OrganizationName }},
+		{Name: "instructor", Header: "Instructor", Value: func(c model.Classroom) string { return c.InstructorLogin }},
+		{Name: "public", Header: "Public", Value: func(c model.Classroom) string { return strconv.FormatBool(c.Public) }},
+		{Name: "archived", Header: "Archived", Value: func(c model.Classroom) string { return strconv.FormatBool(c.Archived) }},
+		{Name: "created_at", Header: "Created", Value: func(c model.Classroom) string { return formatTime(&c.CreatedAt) }},
+	},
+	Default: []string{"id", "name", "slug", "organization", "archived"},
+}
+
+// classroomStatsTable lists the columns of the classroom statistics summary
+var classroomStatsTable = &output.Table[model.ClassroomStats]{
+	Columns: []output.Column[model.ClassroomStats]{
+		{Name: "total_students", Header: "Students", Value: func(s model.ClassroomStats) string { return strconv.Itoa(s.TotalStudents) }},
+		{Name: "linked_students", Header: "Linked", Value: func(s model.ClassroomStats) string { return strconv.Itoa(s.LinkedStudents) }},
+		{Name: "total_assignments", Header: "Assignments", Value: func(s model.ClassroomStats) string { return strconv.Itoa(s.TotalAssignments) }},
+		{Name: "active_assignments", Header: "Active", Value: func(s model.ClassroomStats) string { return strconv.Itoa(s.ActiveAssignments) }},
+		{Name: "total_submissions", Header: "Submissions", Value: func(s model.ClassroomStats) string { return strconv.Itoa(s.TotalSubmissions) }},
+	},
+}
+
+// assignmentProgressTable lists the columns of the per-assignment rows of
+// classroom statistics
+var assignmentProgressTable = &output.Table[model.AssignmentProgress]{
+	Columns: []output.Column[model.AssignmentProgress]{
+		{Name: "assignment_id", Header: "ID", Value: func(p model.AssignmentProgress) string { return strconv.FormatInt(p.AssignmentID, 10) }},
+		{Name: "name", Header: "Assignment", Value: func(p model.AssignmentProgress) string { return p.Name }},
+		{Name: "slug", Header: "Slug", Value: func(p model.AssignmentProgress) string { return p.Slug }},
+		{Name: "deadline", Header: "Deadline", Value: func(p model.AssignmentProgress) string { return formatTime(p.Deadline) }},
+		{Name: "accepted_count", Header: "Accepted", Value: func(p model.AssignmentProgress) string { return strconv.Itoa(p.AcceptedCount) }},
+		{Name: "acceptance_rate", Header: "Accepted %", Value: func(p model.AssignmentProgress) string { return formatPercent(p.AcceptanceRate) }},
+		{Name: "submission_count", Header: "Submitted", Value: func(p model.AssignmentProgress) string { return strconv.Itoa(p.SubmissionCount) }},
+		{Name: "submission_rate", Header: "Submitted %", Value: func(p model.AssignmentProgress) string { return formatPercent(p.SubmissionRate) }},
+		{Name: "late_count", Header: "Late", Value: func(p model.AssignmentProgress) string { return strconv.Itoa(p.LateCount) }},
+	},
+	Default: []string{"assignment_id", "name", "deadline", "accepted_count", "acceptance_rate", "submission_count", "submission_rate", "late_count"},
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/pkg/client"
+)
+
+// newAPIClient creates an API client from the --server and --token settings
+func newAPIClient() (*client.Client, error) {
+	server := viper.GetString("server")
+	if server == "" {
+		return nil, fmt.Errorf("no server configured: use --server or set FGC_SERVER")
+	}
+	return client.New(server, viper.GetString("token"))
+}
+
+// parseID parses a numeric ID argument
+func parseID(arg, name string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive integer", name, arg)
+	}
+	return id, nil
+}
+
+// confirm asks the user to confirm a destructive action on stdin
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// renderList renders one page of a list. People are told when the list is
+// empty and which page they are looking at; scripts only get the items.
+func renderList[T any](opts *output.Options, t *output.Table[T], items []T, page, totalPages, total int, noun string) error {
+	if opts.Decorated() && total == 0 {
+		fmt.Printf("No %s\n", noun)
+		return nil
+	}
+	if err := output.Render(os.Stdout, opts, t, items); err != nil {
+		return err
+	}
+	if opts.Decorated() && totalPages > 1 {
+		fmt.Printf("Page %d of %d (%d %s)\n", page, totalPages, total, noun)
+	}
+	return nil
+}
+
+// formatTime formats an optional timestamp for display
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatOptional formats an optional string for display
+func formatOptional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatOptionalID formats an optional ID for display
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// formatPercent formats a rate between 0 and 1 as a percentage
+func formatPercent(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', 0, 64) + "%"
+}
+
+// PrintError prints a command error to w. API errors are printed with
+// their code, message and request ID, followed by the details that help
+// fix the request, such as the invalid fields.
+func PrintError(w io.Writer, err error) {
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "Error: %s: %s\n", apiErr.Code, apiErr.Message)
+	if fields, ok := apiErr.Details["fields"].([]interface{}); ok {
+		for _, f := range fields {
+			if field, ok := f.(map[string]interface{}); ok {
+				fmt.Fprintf(w, "  %v: %v\n", field["field"], field["message"])
+			}
+		}
+	}
+
+	var keys []string
+	for key := range apiErr.Details {
+		if key != "fields" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %v\n", key, apiErr.Details[key])
+	}
+
+	if apiErr.RequestID != "" {
+		fmt.Fprintf(w, "Request ID: %s\n", apiErr.RequestID)
+	}
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// newAssignmentInvitationCommand creates the assignment invitation command
+// and its subcommands
+func newAssignmentInvitationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invitation",
+		Short: "Manage assignment invitation links",
+		Long: `View, rotate, enable and disable the invitation link of an assignment.
+Students accept an assignment with its link, using "fgc student accept".`,
+	}
+
+	cmd.AddCommand(newInvitationViewCommand())
+	cmd.AddCommand(newInvitationRotateCommand())
+	cmd.AddCommand(newInvitationUpdateCommand())
+
+	return cmd
+}
+
+func newInvitationViewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view [assignment-id]",
+		Short: "View the invitation of an assignment",
+		Long:  "Display the invitation token and link of an assignment",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			inv, err := api.GetInvitation(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			return output.RenderOne(os.Stdout, opts, invitationTable, *inv)
+		},
+	}
+
+	output.AddFlags(cmd)
+
+	return cmd
+}
+
+func newInvitationRotateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate [assignment-id]",
+		Short: "Issue a new invitation token",
+		Long: `Issue a new invitation token for an assignment. The previous link stops
+working; students who already accepted are not affected.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			expires, _ := cmd.Flags().GetString("expires")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			inv, err := api.RotateInvitation(cmd.Context(), id, &model.RotateInvitationRequest{ExpiresAt: expires})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Invitation token: %s\n", inv.Token)
+			if inv.URL != "" {
+				fmt.Printf("Invitation URL: %s\n", inv.URL)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("expires", "e", "", "Expiry of the invitation (RFC3339 format)")
+
+	return cmd
+}
+
+func newInvitationUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [assignment-id]",
+		Short: "Enable or disable an invitation",
+		Long:  "Enable or disable the invitation of an assignment, or change its expiry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+
+			var req model.UpdateInvitationRequest
+			flags := cmd.Flags()
+			if flags.Changed("enable") || flags.Changed("disable") {
+				enabled := flags.Changed("enable")
+				req.Enabled = &enabled
+			}
+			if flags.Changed("expires") {
+				expires, _ := flags.GetString("expires")
+				req.ExpiresAt = &expires
+			}
+			if flags.Changed("no-expiry") {
+				none := ""
+				req.ExpiresAt = &none
+			}
+			if req == (model.UpdateInvitationRequest{}) {
+				return fmt.Errorf("nothing to update: set --enable, --disable, --expires or --no-expiry")
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			inv, err := api.UpdateInvitation(cmd.Context(), id, &req)
+			if err != nil {
+				return err
+			}
+
+			state := "disabled"
+			if inv.Enabled {
+				state = "enabled"
+			}
+			fmt.Printf("Invitation of assignment %d is %s\n", inv.AssignmentID, state)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("enable", false, "Enable the invitation")
+	cmd.Flags().Bool("disable", false, "Disable the invitation")
+	cmd.Flags().StringP("expires", "e", "", "New expiry (RFC3339 format)")
+	cmd.Flags().Bool("no-expiry", false, "Remove the expiry")
+	cmd.MarkFlagsMutuallyExclusive("enable", "disable")
+	cmd.MarkFlagsMutuallyExclusive("expires", "no-expiry")
+
+	return cmd
+}
+
+// invitationToken returns the token of an invitation given as a token or
+// as its URL
+func invitationToken(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if u, err := url.Parse(arg); err == nil && u.Scheme != "" && u.Host != "" {
+		return path.Base(strings.TrimSuffix(u.Path, "/"))
+	}
+	return arg
+}
+
+// invitationTable lists the columns of invitations
+var invitationTable = &output.Table[model.Invitation]{
+	Columns: []output.Column[model.Invitation]{
+		{Name: "assignment_id", Header: "Assignment", Value: func(i model.Invitation) string { return strconv.FormatInt(i.AssignmentID, 10) }},
+		{Name: "token", Header: "Token", Value: func(i model.Invitation) string { return i.Token }},
+		{Name: "url", Header: "URL", Value: func(i model.Invitation) string { return i.URL }},
+		{Name: "enabled", Header: "Enabled", Value: func(i model.Invitation) string { return strconv.FormatBool(i.Enabled) }},
+		{Name: "expires_at", Header: "Expires", Value: func(i model.Invitation) string { return formatTime(i.ExpiresAt) }},
+		{Name: "created_at", Header: "Issued", Value: func(i model.Invitation) string { return formatTime(&i.CreatedAt) }},
+	},
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// NewJobCommand creates the job command and its subcommands
+func NewJobCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Inspect background jobs",
+		Long:  "Check the status and errors of background jobs such as large roster imports",
+	}
+
+	cmd.AddCommand(newJobStatusCommand())
+	cmd.AddCommand(newJobErrorsCommand())
+
+	return cmd
+}
+
+func newJobStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status [job-id]",
+		Short: "Show the status of a job",
+		Long:  "Display the state, attempts and result of a background job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "job ID")
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			job, err := api.GetJob(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Job:      %d (%s)\n", job.ID, job.Type)
+			fmt.Printf("Status:   %s\n", job.Status)
+			fmt.Printf("Attempts: %d/%d\n", job.Attempts, job.MaxAttempts)
+			if job.LastError != nil {
+				fmt.Printf("Error:    %s\n", *job.LastError)
+			}
+			if len(job.Result) > 0 {
+				result, err := json.MarshalIndent(job.Result, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Result:\n%s\n", result)
+			}
+			if job.ErrorCount > 0 {
+				fmt.Printf("%d rows failed; list them with: fgc job errors %d\n", job.ErrorCount, job.ID)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newJobErrorsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "errors [job-id]",
+		Short: "List the failed rows of a job",
+		Long:  "Display the per-row failures reported by a background job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "job ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			result, err := api.ListJobErrors(cmd.Context(), id, page, perPage)
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, jobErrorTable, result.Errors, result.Page, result.TotalPages, result.Total, "errors")
+		},
+	}
+
+	output.AddFlags(cmd)
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 100, "Errors per page")
+
+	return cmd
+}
+
+// jobErrorTable lists the columns of the failed rows of a job
+var jobErrorTable = &output.Table[model.JobError]{
+	Columns: []output.Column[model.JobError]{
+		{Name: "row", Header: "Row", Value: func(e model.JobError) string { return strconv.Itoa(e.Row) }},
+		{Name: "field", Header: "Field", Value: func(e model.JobError) string { return e.Field }},
+		{Name: "code", Header: "Code", Value: func(e model.JobError) string { return e.Code }},
+		{Name: "message", Header: "Message", Value: func(e model.JobError) string { return e.Message }},
+	},
+	Default: []string{"row", "field", "message"},
+}
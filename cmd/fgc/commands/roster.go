@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/pkg/client"
 )
 
 // NewRosterCommand creates the roster command and its subcommands
@@ -18,6 +26,8 @@ func NewRosterCommand() *cobra.Command {
 	cmd.AddCommand(newRosterListCommand())
 	cmd.AddCommand(newRosterLinkCommand())
 	cmd.AddCommand(newRosterImportCommand())
+	cmd.AddCommand(newRosterJoinLinkCommand())
+	cmd.AddCommand(newRosterClaimsCommand())
 
 	return cmd
 }
@@ -26,12 +36,46 @@ func newRosterAddCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add [classroom-id] [student-identifier]",
 		Short: "Add a student to a classroom roster",
-		Long:  "Add a student to a classroom roster using their email or username",
-		Args:  cobra.ExactArgs(2),
+		Long: `Add a student to a classroom roster. The identifier becomes the student ID;
+if it is an email address, it is also used as the student's email.`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement roster addition
-			fmt.Printf("Adding student %s to classroom %s\n", args[1], args[0])
-			fmt.Println("Not yet implemented")
+			classroomID, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			name, _ := cmd.Flags().GetString("name")
+			email, _ := cmd.Flags().GetString("email")
+			role, _ := cmd.Flags().GetString("role")
+
+			identifier := args[1]
+			if email == "" && strings.Contains(identifier, "@") {
+				email = identifier
+			}
+			if email == "" {
+				return fmt.Errorf("--email is required when the identifier is not an email address")
+			}
+			if name == "" {
+				name = identifier
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			result, err := applyRosterOperation(cmd.Context(), api, classroomID, model.RosterOperation{
+				Action:       model.RosterActionAdd,
+				StudentID:    identifier,
+				StudentName:  name,
+				StudentEmail: email,
+				Role:         role,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Added %s to classroom %d (roster entry %d)\n", identifier, classroomID, *result.ID)
 			return nil
 		},
 	}
@@ -50,16 +94,44 @@ func newRosterListCommand() *cobra.Command {
 		Long:  "Display all students enrolled in the specified classroom",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement roster listing
-			fmt.Printf("Listing roster for classroom: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			classroomID, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			linkedOnly, _ := cmd.Flags().GetBool("linked-only")
+			unlinkedOnly, _ := cmd.Flags().GetBool("unlinked-only")
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListStudents(cmd.Context(), classroomID, &model.RosterListRequest{
+				LinkedOnly:   linkedOnly,
+				UnlinkedOnly: unlinkedOnly,
+				Page:         page,
+				PerPage:      perPage,
+			})
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, rosterTable, list.Students, list.Page, list.TotalPages, list.Total, "students")
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml, csv)")
+	output.AddFlags(cmd)
 	cmd.Flags().BoolP("linked-only", "l", false, "Show only students with linked accounts")
 	cmd.Flags().BoolP("unlinked-only", "u", false, "Show only students without linked accounts")
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 100, "Students per page")
+	cmd.MarkFlagsMutuallyExclusive("linked-only", "unlinked-only")
 
 	return cmd
 }
@@ -71,9 +143,27 @@ func newRosterLinkCommand() *cobra.Command {
 		Long:  "Associate a roster entry with a Forgejo username",
 		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement account linking
-			fmt.Printf("Linking student %s to Forgejo account %s in classroom %s\n", args[1], args[2], args[0])
-			fmt.Println("Not yet implemented")
+			classroomID, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			force, _ := cmd.Flags().GetBool("force")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			if _, err := applyRosterOperation(cmd.Context(), api, classroomID, model.RosterOperation{
+				Action:          model.RosterActionLink,
+				StudentID:       args[1],
+				ForgejoUsername: args[2],
+				Force:           force,
+			}); err != nil {
+				return err
+			}
+
+			fmt.Printf("Linked %s to Forgejo account %s\n", args[1], args[2])
 			return nil
 		},
 	}
@@ -90,10 +180,45 @@ func newRosterImportCommand() *cobra.Command {
 		Long:  "Bulk import students from a CSV file with columns: name, email, identifier",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement roster import
-			fmt.Printf("Importing roster from %s to classroom %s\n", args[1], args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			classroomID, err := parseID(args[0], "classroom ID")
+			if err != nil {
+				return err
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			update, _ := cmd.Flags().GetBool("update")
+			delimiter, _ := cmd.Flags().GetString("delimiter")
+
+			file, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			resp, err := api.ImportRoster(cmd.Context(), classroomID, args[1], file, &model.ImportRosterRequest{
+				DryRun:    dryRun,
+				Update:    update,
+				Delimiter: delimiter,
+			})
+			if err != nil {
+				return err
+			}
+
+			if resp.JobID != nil {
+				fmt.Printf("Import queued as job %d\n", *resp.JobID)
+				fmt.Printf("Check its progress with: fgc job status %d\n", *resp.JobID)
+				return nil
+			}
+
+			printRosterImportResult(resp.Result)
+			if len(resp.Result.Errors) == 0 {
+				return nil
+			}
+			return output.Render(os.Stdout, &output.Options{Format: output.FormatTable}, jobErrorTable, resp.Result.Errors)
 		},
 	}
 
@@ -103,3 +228,51 @@ func newRosterImportCommand() *cobra.Command {
 
 	return cmd
 }
+
+// applyRosterOperation applies a single roster operation. It goes through
+// the bulk endpoint, which identifies entries by student ID and can replace
+// existing links.
+func applyRosterOperation(ctx context.Context, api *client.Client, classroomID int64, op model.RosterOperation) (*model.RosterOperationResult, error) {
+	resp, err := api.BulkRoster(ctx, classroomID, &model.BulkRosterRequest{Operations: []model.RosterOperation{op}})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != 1 {
+		return nil, fmt.Errorf("unexpected response: %d results for 1 operation", len(resp.Results))
+	}
+
+	result := &resp.Results[0]
+	if result.Error != nil {
+		return nil, &client.Error{Code: result.Error.Code, Message: result.Error.Message, Details: result.Error.Details}
+	}
+	return result, nil
+}
+
+// printRosterImportResult prints the summary of a roster import
+func printRosterImportResult(r *model.RosterImportResult) {
+	if r.DryRun {
+		fmt.Println("Dry run: no changes were made")
+	}
+	fmt.Printf("Rows:    %d\n", r.Total)
+	fmt.Printf("Created: %d\n", r.Created)
+	fmt.Printf("Updated: %d\n", r.Updated)
+	fmt.Printf("Skipped: %d\n", r.Skipped)
+	fmt.Printf("Failed:  %d\n", r.Failed)
+}
+
+// rosterTable lists the columns of roster entries. The CSV headers of
+// student_name, student_email, student_id and role are understood by
+// roster import.
+var rosterTable = &output.Table[model.RosterEntry]{
+	Columns: []output.Column[model.RosterEntry]{
+		{Name: "id", Header: "ID", Value: func(e model.RosterEntry) string { return strconv.FormatInt(e.ID, 10) }},
+		{Name: "student_id", Header: "Student ID", Value: func(e model.RosterEntry) string { return e.StudentID }},
+		{Name: "student_name", Header: "Name", Value: func(e model.RosterEntry) string { return e.StudentName }},
+		{Name: "student_email", Header: "Email", Value: func(e model.RosterEntry) string { return e.StudentEmail }},
+		{Name: "forgejo_username", Header: "Forgejo user", Value: func(e model.RosterEntry) string { return formatOptional(e.ForgejoUsername) }},
+		{Name: "role", Header: "Role", Value: func(e model.RosterEntry) string { return e.Role }},
+		{Name: "linked_at", Header: "Linked", Value: func(e model.RosterEntry) string { return formatTime(e.LinkedAt) }},
+		{Name: "created_at", Header: "Added", Value: func(e model.RosterEntry) string { return formatTime(&e.CreatedAt) }},
+	},
+	Default: []string{"id", "student_id", "student_name", "student_email", "forgejo_username", "role"},
+}
@@ -1,9 +1,15 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/pkg/client"
 )
 
 // NewStudentCommand creates the student command and its subcommands
@@ -11,24 +17,52 @@ func NewStudentCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "student",
 		Short: "Student operations",
-		Long:  "Commands for students to interact with assignments",
+		Long:  "Commands for students to join classrooms and interact with assignments",
 	}
 
 	cmd.AddCommand(newStudentAcceptCommand())
+	cmd.AddCommand(newStudentJoinCommand())
 
 	return cmd
 }
 
 func newStudentAcceptCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "accept [assignment-id]",
+		Use:   "accept [invitation]",
 		Short: "Accept an assignment",
-		Long:  "Accept an assignment and create a repository for submission",
-		Args:  cobra.ExactArgs(1),
+		Long: `Accept an assignment and create a repository for submission. The
+assignment is given by the invitation link or token shared by the
+instructor, or by its numeric ID.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement assignment acceptance
-			fmt.Printf("Accepting assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			team, _ := cmd.Flags().GetString("team")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			var submission *model.Submission
+			if id, err := strconv.ParseInt(args[0], 10, 64); err == nil && id > 0 {
+				submission, err = api.AcceptAssignment(cmd.Context(), id, team)
+				if err != nil {
+					return acceptError(err)
+				}
+			} else {
+				token := invitationToken(args[0])
+				details, err := api.ResolveInvitation(cmd.Context(), token)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Accepting %q of %s\n", details.AssignmentName, details.ClassroomName)
+
+				submission, err = api.AcceptInvitation(cmd.Context(), token, team)
+				if err != nil {
+					return acceptError(err)
+				}
+			}
+
+			fmt.Printf("Assignment accepted: %s\n", submission.RepositoryURL)
 			return nil
 		},
 	}
@@ -37,3 +71,14 @@ func newStudentAcceptCommand() *cobra.Command {
 
 	return cmd
 }
+
+// acceptError reports an assignment that was already accepted as success,
+// with the existing repository
+func acceptError(err error) error {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Code == response.ErrBusinessAlreadyAccepted {
+		fmt.Printf("Assignment already accepted: %v\n", apiErr.Details["repository_url"])
+		return nil
+	}
+	return err
+}
@@ -2,8 +2,14 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 )
 
 // NewSubmissionCommand creates the submission command and its subcommands
@@ -28,17 +34,47 @@ func newSubmissionListCommand() *cobra.Command {
 		Long:  "Display all submissions for the specified assignment",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement submission listing
-			fmt.Printf("Listing submissions for assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			status, _ := cmd.Flags().GetString("status")
+			teamOnly, _ := cmd.Flags().GetBool("team-only")
+			individualOnly, _ := cmd.Flags().GetBool("individual-only")
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListSubmissions(cmd.Context(), assignmentID, &model.SubmissionListRequest{
+				Status:         status,
+				TeamOnly:       teamOnly,
+				IndividualOnly: individualOnly,
+				Page:           page,
+				PerPage:        perPage,
+			})
+			if err != nil {
+				return err
+			}
+
+			return renderList(opts, submissionTable, list.Submissions, list.Page, list.TotalPages, list.Total, "submissions")
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
 	cmd.Flags().StringP("status", "s", "", "Filter by status (pending, accepted, late)")
 	cmd.Flags().BoolP("team-only", "t", false, "Show only team submissions")
 	cmd.Flags().BoolP("individual-only", "i", false, "Show only individual submissions")
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 100, "Submissions per page")
+	cmd.MarkFlagsMutuallyExclusive("team-only", "individual-only")
 
 	return cmd
 }
@@ -50,15 +86,36 @@ func newSubmissionViewCommand() *cobra.Command {
 		Long:  "Display detailed information about a specific submission",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement submission viewing
-			fmt.Printf("Viewing submission: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			id, err := parseID(args[0], "submission ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			// The API only tracks the latest commit, so that is all the
+			// commit history there is to show
+			if showCommits, _ := cmd.Flags().GetBool("show-commits"); !showCommits && len(opts.Columns) == 0 {
+				opts.Columns = submissionViewColumns
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			submission, err := api.GetSubmission(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			return output.RenderOne(os.Stdout, opts, submissionTable, *submission)
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
-	cmd.Flags().Bool("show-commits", false, "Show commit history")
+	output.AddFlags(cmd)
+	cmd.Flags().Bool("show-commits", false, "Show the latest commit")
 
 	return cmd
 }
@@ -67,20 +124,107 @@ func newSubmissionDownloadCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "download [assignment-id]",
 		Short: "Download submissions for grading",
-		Long:  "Download all submissions for an assignment as zip archives",
-		Args:  cobra.ExactArgs(1),
+		Long: `Download all submissions for an assignment as one archive, with one folder
+per student or team. A manifest.csv inside the archive maps the folders to
+roster entries and the commits they were taken from.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement submission download
-			fmt.Printf("Downloading submissions for assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			dest, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			atDeadline, _ := cmd.Flags().GetBool("at-deadline")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			// Download next to the destination, so a failed download leaves
+			// no partial archive behind
+			dir, name := dest, ""
+			if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+				dir, name = filepath.Split(dest)
+				if dir == "" {
+					dir = "."
+				}
+			}
+			tmp, err := os.CreateTemp(dir, ".fgc-download-*")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name())
+			defer tmp.Close()
+
+			filename, err := api.DownloadSubmissions(cmd.Context(), assignmentID, &model.SubmissionDownloadRequest{
+				Format:     format,
+				AtDeadline: atDeadline,
+			}, tmp)
+			if err != nil {
+				return err
+			}
+			if err := tmp.Close(); err != nil {
+				return err
+			}
+
+			if name == "" {
+				name = filename
+			}
+			if name == "" {
+				name = fmt.Sprintf("assignment-%d-submissions.%s", assignmentID, format)
+			}
+			path := filepath.Join(dir, name)
+			if err := os.Rename(tmp.Name(), path); err != nil {
+				return err
+			}
+			fmt.Printf("Downloaded submissions to %s\n", path)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringP("output", "o", "./submissions", "Output directory")
+	cmd.Flags().StringP("output", "o", ".", "Output file, or directory to save the archive in")
 	cmd.Flags().StringP("format", "f", "zip", "Archive format (zip, tar, tar.gz)")
-	cmd.Flags().Bool("latest-only", false, "Download only the latest submission from each student/team")
-	cmd.Flags().StringP("at-deadline", "d", "", "Download submissions as they were at the deadline")
+	cmd.Flags().Bool("latest-only", false, "Download the latest commit of each student/team (default)")
+	cmd.Flags().BoolP("at-deadline", "d", false, "Download submissions as they were tagged at the deadline")
+	cmd.MarkFlagsMutuallyExclusive("latest-only", "at-deadline")
 
 	return cmd
 }
+
+// submissionTable lists the columns of submissions
+var submissionTable = &output.Table[model.Submission]{
+	Columns: []output.Column[model.Submission]{
+		{Name: "id", Header: "ID", Value: func(s model.Submission) string { return strconv.FormatInt(s.ID, 10) }},
+		{Name: "assignment_id", Header: "Assignment", Value: func(s model.Submission) string { return strconv.FormatInt(s.AssignmentID, 10) }},
+		{Name: "team_id", Header: "Team", Value: func(s model.Submission) string { return formatOptionalID(s.TeamID) }},
+		{Name: "repository_name", Header: "Repository", Value: func(s model.Submission) string { return s.RepositoryName }},
+		{Name: "repository_url", Header: "URL", Value: func(s model.Submission) string { return s.RepositoryURL }},
+		{Name: "status", Header: "Status", Value: func(s model.Submission) string { return s.Status }},
+		{Name: "accepted_at", Header: "Accepted", Value: func(s model.Submission) string { return formatTime(s.AcceptedAt) }},
+		{Name: "commit_count", Header: "Commits", Value: func(s model.Submission) string { return strconv.Itoa(s.CommitCount) }},
+		{Name: "last_commit_sha", Header: "Last commit", Value: func(s model.Submission) string { return shortSHA(s.LastCommitSHA) }},
+		{Name: "last_commit_message", Header: "Message", Value: func(s model.Submission) string { return formatOptional(s.LastCommitMessage) }},
+		{Name: "deadline_tag", Header: "Deadline tag", Value: func(s model.Submission) string { return formatOptional(s.DeadlineTag) }},
+		{Name: "deadline_sha", Header: "Deadline commit", Value: func(s model.Submission) string { return shortSHA(s.DeadlineSHA) }},
+	},
+	Default: []string{"id", "repository_name", "status", "commit_count", "last_commit_sha", "accepted_at"},
+}
+
+// submissionViewColumns are the columns of submission view without
+// --show-commits
+var submissionViewColumns = []string{
+	"id", "assignment_id", "team_id", "repository_url", "status", "accepted_at", "commit_count", "deadline_tag", "deadline_sha",
+}
+
+// shortSHA abbreviates an optional commit SHA for display
+func shortSHA(sha *string) string {
+	if sha == nil {
+		return ""
+	}
+	if len(*sha) > 10 {
+		return (*sha)[:10]
+	}
+	return *sha
+}
@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"code.forgejo.org/forgejo/classroom/cmd/fgc/output"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/pkg/client"
 )
 
 // NewTeamCommand creates the team command and its subcommands
@@ -29,9 +37,29 @@ func newTeamCreateCommand() *cobra.Command {
 		Long:  "Create a new team for a team-based assignment",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement team creation
-			fmt.Printf("Creating team %s for assignment %s\n", args[1], args[0])
-			fmt.Println("Not yet implemented")
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			description, _ := cmd.Flags().GetString("description")
+			members, _ := cmd.Flags().GetStringSlice("members")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			team, err := api.CreateTeam(cmd.Context(), &model.CreateTeamRequest{
+				AssignmentID: assignmentID,
+				Name:         args[1],
+				Description:  description,
+				Members:      members,
+			})
+			if err != nil {
+				return teamError(err)
+			}
+
+			fmt.Printf("Created team %d (%s)\n", team.ID, team.Slug)
 			return nil
 		},
 	}
@@ -49,15 +77,43 @@ func newTeamListCommand() *cobra.Command {
 		Long:  "Display all teams for the specified assignment",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement team listing
-			fmt.Printf("Listing teams for assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
-			return nil
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			opts, err := output.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			showMembers, _ := cmd.Flags().GetBool("show-members")
+			if showMembers && opts.Format == output.FormatTable && len(opts.Columns) == 0 {
+				opts.Columns = []string{"id", "name", "slug", "member_count", "members"}
+			}
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			list, err := api.ListTeams(cmd.Context(), assignmentID, &model.TeamListRequest{
+				ShowMembers: showMembers,
+				Page:        page,
+				PerPage:     perPage,
+			})
+			if err != nil {
+				return teamError(err)
+			}
+
+			return renderList(opts, teamTable, list.Teams, list.Page, list.TotalPages, list.Total, "teams")
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "table", "Output format (table, json, yaml)")
+	output.AddFlags(cmd)
 	cmd.Flags().Bool("show-members", false, "Show team member details")
+	cmd.Flags().IntP("page", "p", 1, "Page number")
+	cmd.Flags().Int("per-page", 100, "Teams per page")
 
 	return cmd
 }
@@ -69,9 +125,25 @@ func newTeamJoinCommand() *cobra.Command {
 		Long:  "Join an existing team for an assignment",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement team joining
-			fmt.Printf("Joining team %s for assignment %s\n", args[1], args[0])
-			fmt.Println("Not yet implemented")
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			team, err := findTeam(cmd.Context(), api, assignmentID, args[1])
+			if err != nil {
+				return teamError(err)
+			}
+			if _, err := api.JoinTeam(cmd.Context(), team.ID); err != nil {
+				return teamError(err)
+			}
+
+			fmt.Printf("Joined team %s\n", team.Name)
 			return nil
 		},
 	}
@@ -83,17 +155,100 @@ func newTeamLeaveCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "leave [assignment-id]",
 		Short: "Leave current team",
-		Long:  "Leave the current team for an assignment",
-		Args:  cobra.ExactArgs(1),
+		Long: `Leave the current team for an assignment. The team is found through your
+submission; staff must name it with --team.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// TODO: Implement team leaving
-			fmt.Printf("Leaving team for assignment: %s\n", args[0])
-			fmt.Println("Not yet implemented")
+			assignmentID, err := parseID(args[0], "assignment ID")
+			if err != nil {
+				return err
+			}
+			force, _ := cmd.Flags().GetBool("force")
+			name, _ := cmd.Flags().GetString("team")
+
+			api, err := newAPIClient()
+			if err != nil {
+				return err
+			}
+
+			var teamID int64
+			if name != "" {
+				team, err := findTeam(cmd.Context(), api, assignmentID, name)
+				if err != nil {
+					return teamError(err)
+				}
+				teamID = team.ID
+			} else {
+				// Students only see their own submissions
+				list, err := api.ListSubmissions(cmd.Context(), assignmentID, &model.SubmissionListRequest{TeamOnly: true})
+				if err != nil {
+					return err
+				}
+				if len(list.Submissions) != 1 || list.Submissions[0].TeamID == nil {
+					return fmt.Errorf("cannot tell which team to leave: use --team")
+				}
+				teamID = *list.Submissions[0].TeamID
+			}
+
+			if err := api.LeaveTeam(cmd.Context(), teamID, force); err != nil {
+				return teamError(err)
+			}
+
+			fmt.Printf("Left team %d\n", teamID)
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolP("force", "f", false, "Force leave even if you are the team leader")
+	cmd.Flags().StringP("team", "t", "", "Name of the team to leave")
 
 	return cmd
 }
+
+// teamError explains team calls refused by servers that do not manage
+// teams, where students form teams as they accept an assignment
+func teamError(err error) error {
+	if errors.Is(err, client.ErrNotSupported) {
+		return fmt.Errorf(`team management is not supported by the server: students form and join teams with "fgc student accept --team <name>"`)
+	}
+	return err
+}
+
+// findTeam looks up a team of an assignment by name or slug
+func findTeam(ctx context.Context, api *client.Client, assignmentID int64, name string) (*model.TeamWithMembers, error) {
+	for page := 1; ; page++ {
+		list, err := api.ListTeams(ctx, assignmentID, &model.TeamListRequest{Page: page, PerPage: 100})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Teams {
+			team := &list.Teams[i]
+			if strings.EqualFold(team.Name, name) || team.Slug == name {
+				return team, nil
+			}
+		}
+		if page >= list.TotalPages {
+			return nil, fmt.Errorf("assignment %d has no team %q", assignmentID, name)
+		}
+	}
+}
+
+// teamTable lists the columns of teams. Members are only listed by the
+// API with --show-members.
+var teamTable = &output.Table[model.TeamWithMembers]{
+	Columns: []output.Column[model.TeamWithMembers]{
+		{Name: "id", Header: "ID", Value: func(t model.TeamWithMembers) string { return strconv.FormatInt(t.ID, 10) }},
+		{Name: "name", Header: "Name", Value: func(t model.TeamWithMembers) string { return t.Name }},
+		{Name: "slug", Header: "Slug", Value: func(t model.TeamWithMembers) string { return t.Slug }},
+		{Name: "description", Header: "Description", Value: func(t model.TeamWithMembers) string { return t.Description }},
+		{Name: "member_count", Header: "Members", Value: func(t model.TeamWithMembers) string { return strconv.Itoa(t.MemberCount) }},
+		{Name: "members", Header: "Users", Value: func(t model.TeamWithMembers) string {
+			users := make([]string, len(t.Members))
+			for i, m := range t.Members {
+				users[i] = m.ForgejoUsername
+			}
+			return strings.Join(users, ", ")
+		}},
+	},
+	Default: []string{"id", "name", "slug", "member_count"},
+}
@@ -25,16 +25,20 @@ with Forgejo to provide GitHub Classroom-like functionality for self-hosted Git
 
 This CLI tool allows you to manage classrooms, assignments, rosters, submissions, and teams.`,
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
+		// Errors are printed below, with the details of API errors
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
 	// Global flags
 	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/.fgc.yaml)")
-	rootCmd.PersistentFlags().String("server", "", "Forgejo server URL")
+	rootCmd.PersistentFlags().String("server", "", "Forgejo Classroom server URL")
 	rootCmd.PersistentFlags().String("token", "", "Forgejo API token")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "show what would be done without executing")
 
 	// Bind flags to viper
+	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
 	viper.BindPFlag("server", rootCmd.PersistentFlags().Lookup("server"))
 	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
@@ -47,12 +51,14 @@ This CLI tool allows you to manage classrooms, assignments, rosters, submissions
 	rootCmd.AddCommand(commands.NewSubmissionCommand())
 	rootCmd.AddCommand(commands.NewTeamCommand())
 	rootCmd.AddCommand(commands.NewStudentCommand())
+	rootCmd.AddCommand(commands.NewJobCommand())
+	rootCmd.AddCommand(commands.NewAuditCommand())
 
 	// Initialize configuration
 	cobra.OnInitialize(initConfig)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		commands.PrintError(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,263 @@
+// Package output renders the results of fgc commands as aligned tables,
+// JSON, YAML or CSV.
+//
+// Each command describes the columns of its result type with a Table.
+// Tables and CSV show the selected columns; JSON and YAML show the whole
+// objects as returned by the API, with the same field names.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatTable, FormatJSON, FormatYAML, FormatCSV}
+
+// Column is one column of a Table
+type Column[T any] struct {
+	Name   string // used by --columns and as the CSV header
+	Header string // used in tables
+	Value  func(T) string
+}
+
+// Table describes how to render values of type T
+type Table[T any] struct {
+	Columns []Column[T]
+	Default []string // columns of lists without --columns; all when empty
+}
+
+// Options are the output settings of a command
+type Options struct {
+	Format    string
+	Columns   []string
+	NoHeaders bool
+}
+
+// AddFlags adds the --format, --columns and --no-headers flags to cmd
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("format", "f", FormatTable, "Output format ("+strings.Join(Formats, ", ")+")")
+	cmd.Flags().StringSlice("columns", nil, "Columns to show in table and csv output (comma-separated)")
+	cmd.Flags().Bool("no-headers", false, "Omit headers from table and csv output")
+}
+
+// FromFlags returns the options set with the flags added by AddFlags
+func FromFlags(cmd *cobra.Command) (*Options, error) {
+	opts := &Options{}
+	opts.Format, _ = cmd.Flags().GetString("format")
+	opts.Columns, _ = cmd.Flags().GetStringSlice("columns")
+	opts.NoHeaders, _ = cmd.Flags().GetBool("no-headers")
+
+	switch opts.Format {
+	case FormatTable, FormatCSV:
+	case FormatJSON, FormatYAML:
+		if len(opts.Columns) > 0 {
+			return nil, fmt.Errorf("--columns cannot be used with %s output", opts.Format)
+		}
+	default:
+		return nil, fmt.Errorf("invalid format %q: must be one of %s", opts.Format, strings.Join(Formats, ", "))
+	}
+	return opts, nil
+}
+
+// Decorated reports whether the output is meant for people, so that
+// commands may add messages such as page footers
+func (o *Options) Decorated() bool {
+	return o.Format == FormatTable && !o.NoHeaders
+}
+
+// Render writes a list of items to w
+func Render[T any](w io.Writer, opts *Options, t *Table[T], items []T) error {
+	if items == nil {
+		items = []T{} // JSON [] rather than null
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		return writeJSON(w, items)
+	case FormatYAML:
+		return writeYAML(w, items)
+	}
+
+	columns, err := t.selected(opts.Columns, t.Default)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = values(columns, item)
+	}
+
+	if opts.Format == FormatCSV {
+		return writeCSV(w, opts, columns, rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !opts.NoHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = strings.ToUpper(c.Header)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = cell(row[i])
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// RenderOne writes a single item to w. Tables show every column, one per
+// line.
+func RenderOne[T any](w io.Writer, opts *Options, t *Table[T], item T) error {
+	switch opts.Format {
+	case FormatJSON:
+		return writeJSON(w, item)
+	case FormatYAML:
+		return writeYAML(w, item)
+	}
+
+	columns, err := t.selected(opts.Columns, nil)
+	if err != nil {
+		return err
+	}
+	row := values(columns, item)
+
+	if opts.Format == FormatCSV {
+		return writeCSV(w, opts, columns, [][]string{row})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for i, c := range columns {
+		if opts.NoHeaders {
+			fmt.Fprintln(tw, cell(row[i]))
+		} else {
+			fmt.Fprintf(tw, "%s:\t%s\n", c.Header, cell(row[i]))
+		}
+	}
+	return tw.Flush()
+}
+
+// ColumnNames returns the names of all columns of t
+func (t *Table[T]) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// selected returns the columns named in names, or in defaults if names is
+// empty, or all columns if both are
+func (t *Table[T]) selected(names, defaults []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		names = defaults
+	}
+	if len(names) == 0 {
+		return t.Columns, nil
+	}
+
+	columns := make([]Column[T], 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range t.Columns {
+			if strings.EqualFold(c.Name, strings.TrimSpace(name)) {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q: must be one of %s", name, strings.Join(t.ColumnNames(), ", "))
+		}
+	}
+	return columns, nil
+}
+
+func values[T any](columns []Column[T], item T) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.Value(item)
+	}
+	return row
+}
+
+// cell formats a table cell, showing empty values as "-" and keeping
+// values on one line so that columns stay aligned
+func cell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ").Replace(value)
+}
+
+func writeCSV[T any](w io.Writer, opts *Options, columns []Column[T], rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if !opts.NoHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.Name
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML writes v as YAML with the field names and order of its JSON
+// encoding, which is what the API returns
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML; decoding into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quoting of nodes decoded from JSON
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type student struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Username *string `json:"username,omitempty"`
+}
+
+var studentTable = &Table[student]{
+	Columns: []Column[student]{
+		{Name: "id", Header: "ID", Value: func(s student) string { return strconv.FormatInt(s.ID, 10) }},
+		{Name: "name", Header: "Name", Value: func(s student) string { return s.Name }},
+		{Name: "username", Header: "Forgejo user", Value: func(s student) string {
+			if s.Username == nil {
+				return ""
+			}
+			return *s.Username
+		}},
+	},
+	Default: []string{"id", "name"},
+}
+
+func students() []student {
+	jdoe := "jdoe"
+	return []student{
+		{ID: 1, Name: "Jane Doe", Username: &jdoe},
+		{ID: 22, Name: "Smith, John\nJr."},
+	}
+}
+
+func render(t *testing.T, opts *Options, items []student) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, opts, studentTable, items))
+	return buf.String()
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "table with default columns",
+			opts: Options{Format: FormatTable},
+			want: "ID  NAME\n1   Jane Doe\n22  Smith, John Jr.\n",
+		},
+		{
+			name: "table with selected columns and no headers",
+			opts: Options{Format: FormatTable, Columns: []string{"username", "ID"}, NoHeaders: true},
+			want: "jdoe  1\n-     22\n",
+		},
+		{
+			name: "csv",
+			opts: Options{Format: FormatCSV, Columns: []string{"id", "name", "username"}},
+			want: "id,name,username\n1,Jane Doe,jdoe\n22,\"Smith, John\nJr.\",\n",
+		},
+		{
+			name: "json",
+			opts: Options{Format: FormatJSON},
+			want: "[\n  {\n    \"id\": 1,\n    \"name\": \"Jane Doe\",\n    \"username\": \"jdoe\"\n  },\n" +
+				"  {\n    \"id\": 22,\n    \"name\": \"Smith, John\\nJr.\"\n  }\n]\n",
+		},
+		{
+			name: "yaml keeps the json field names and order",
+			opts: Options{Format: FormatYAML},
+			want: "- id: 1\n  name: Jane Doe\n  username: jdoe\n- id: 22\n  name: |-\n    Smith, John\n    Jr.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, render(t, &tt.opts, students()))
+		})
+	}
+
+	t.Run("empty lists", func(t *testing.T) {
+		assert.Equal(t, "[]\n", render(t, &Options{Format: FormatJSON}, nil))
+		assert.Equal(t, "ID  NAME\n", render(t, &Options{Format: FormatTable}, nil))
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		err := Render(&bytes.Buffer{}, &Options{Format: FormatTable, Columns: []string{"email"}}, studentTable, students())
+		assert.EqualError(t, err, `unknown column "email": must be one of id, name, username`)
+	})
+}
+
+func TestRenderOne(t *testing.T) {
+	item := students()[0]
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderOne(&buf, &Options{Format: FormatTable}, studentTable, item))
+	assert.Equal(t, "ID:           1\nName:         Jane Doe\nForgejo user: jdoe\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, RenderOne(&buf, &Options{Format: FormatYAML}, studentTable, item))
+	assert.Equal(t, "id: 1\nname: Jane Doe\nusername: jdoe\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, RenderOne(&buf, &Options{Format: FormatCSV, NoHeaders: true, Columns: []string{"name"}}, studentTable, item))
+	assert.Equal(t, "Jane Doe\n", buf.String())
+}
+
+func TestFromFlags(t *testing.T) {
+	parse := func(args ...string) (*Options, error) {
+		cmd := &cobra.Command{}
+		AddFlags(cmd)
+		require.NoError(t, cmd.ParseFlags(args))
+		return FromFlags(cmd)
+	}
+
+	opts, err := parse()
+	require.NoError(t, err)
+	assert.Equal(t, &Options{Format: FormatTable, Columns: []string{}}, opts)
+	assert.True(t, opts.Decorated())
+
+	opts, err = parse("-f", "csv", "--columns", "id,name", "--no-headers")
+	require.NoError(t, err)
+	assert.Equal(t, &Options{Format: FormatCSV, Columns: []string{"id", "name"}, NoHeaders: true}, opts)
+	assert.False(t, opts.Decorated())
+
+	_, err = parse("--format", "xml")
+	assert.EqualError(t, err, `invalid format "xml": must be one of table, json, yaml, csv`)
+
+	_, err = parse("--format", "json", "--columns", "id")
+	assert.Error(t, err)
+}
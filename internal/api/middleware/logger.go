@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+)
+
+// Logger logs every request once it has been served, with the request ID
+// set by RequestID, so that the request_id of an error response can be
+// found in the server logs. Server errors are logged at error level and
+// everything else at info level. It must run after RequestID.
+func Logger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("request_id", c.GetString(ContextRequestID)),
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if userID := c.GetInt64(auth.ContextUserID); userID != 0 {
+			fields = append(fields, zap.Int64("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		if status >= http.StatusInternalServerError {
+			logger.Error("Request served", fields...)
+			return
+		}
+		logger.Info("Request served", fields...)
+	}
+}
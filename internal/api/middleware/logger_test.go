@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+)
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	router := gin.New()
+	router.Use(RequestID(), Logger(zap.New(core)))
+	router.GET("/things", func(c *gin.Context) {
+		c.Set(auth.ContextUserID, int64(7))
+		c.Status(http.StatusOK)
+	})
+	router.GET("/broken", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	serve := func(path string) {
+		req := httptest.NewRequest(http.MethodGet, path+"?page=2", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve("/things")
+	serve("/broken")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "abc-123", fields["request_id"])
+	assert.Equal(t, "/things", fields["path"])
+	assert.Equal(t, int64(http.StatusOK), fields["status"])
+	assert.Equal(t, int64(7), fields["user_id"])
+
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "abc-123", entries[1].ContextMap()["request_id"])
+	assert.NotContains(t, entries[1].ContextMap(), "user_id")
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// unmatchedRoute is the route label of requests that matched no route
+const unmatchedRoute = "unmatched"
+
+// Metrics records the latency of every request in m by method, route and
+// status. Routes are the registered patterns, such as
+// /api/v1/classrooms/:id, so that IDs do not create new series.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsToken requires scrapers to send token as a bearer token. An empty
+// token lets everyone scrape.
+func MetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			abortUnauthorized(c, response.ErrAuthMissingToken)
+			return
+		}
+		scheme, sent, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "bearer") || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			abortUnauthorized(c, response.ErrAuthInvalidToken)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortUnauthorized responds with the standard message for code and stops
+// the chain
+func abortUnauthorized(c *gin.Context, code string) {
+	response.RespondWithError(c, http.StatusUnauthorized, code, response.GetErrorMessage(code), nil)
+	c.Abort()
+}
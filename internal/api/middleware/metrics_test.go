@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New(zap.NewNop())
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/classrooms/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/metrics", MetricsToken("s3cret"), gin.WrapH(m.Handler()))
+
+	serve := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serve("/classrooms/1", "")
+	serve("/classrooms/2", "")
+	serve("/nowhere", "")
+
+	t.Run("token required", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("/metrics", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("/metrics", "Bearer wrong").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("/metrics", "token s3cret").Code)
+	})
+
+	t.Run("requests recorded by route", func(t *testing.T) {
+		rec := serve("/metrics", "Bearer s3cret")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `fgc_http_request_duration_seconds_count{method="GET",route="/classrooms/:id",status="204"} 2`)
+		assert.Contains(t, body, `fgc_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+		assert.Contains(t, body, `fgc_http_request_duration_seconds_count{method="GET",route="/metrics",status="401"} 3`)
+	})
+
+	t.Run("no token configured", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/metrics", MetricsToken(""), gin.WrapH(m.Handler()))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
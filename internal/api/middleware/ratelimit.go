@@ -0,0 +1,176 @@
+// Package middleware contains HTTP middleware shared by the API routes.
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// sweepInterval is how often buckets of clients that went quiet are dropped
+const sweepInterval = time.Minute
+
+// budget is the token bucket configuration for one class of requests
+type budget struct {
+	name   string
+	limit  rate.Limit
+	burst  int
+	policy string // RateLimit-Policy header value
+}
+
+// RateLimiter limits API requests per client with token buckets. Clients are
+// identified by their authenticated user, or by their IP address as resolved
+// by gin with the configured trusted proxies. Reads (GET, HEAD, OPTIONS) and
+// writes are counted against separate budgets. A third budget counts every
+// request per IP before authentication, so that requests with bad tokens
+// are limited before they reach Forgejo.
+//
+// Buckets are kept in memory, so every server process enforces the limits on
+// its own.
+type RateLimiter struct {
+	read  budget
+	write budget
+	ip    budget
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*rate.Limiter // by budget and client
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with the budgets configured in cfg
+func NewRateLimiter(cfg *config.APIRateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		read:    newBudget("read", &cfg.Read),
+		write:   newBudget("write", &cfg.Write),
+		ip:      newBudget("ip", &cfg.IP),
+		now:     time.Now,
+		buckets: make(map[string]*rate.Limiter),
+	}
+}
+
+func newBudget(name string, cfg *config.RateLimitConfig) budget {
+	perMinute := max(cfg.RequestsPerMinute, 0)
+	burst := max(cfg.BurstSize, 1)
+	return budget{
+		name:   name,
+		limit:  rate.Limit(float64(perMinute) / 60),
+		burst:  burst,
+		policy: fmt.Sprintf("%d;w=60;burst=%d", perMinute, burst),
+	}
+}
+
+// Middleware returns a handler that rejects requests over their client's
+// budget with 429 RATE_LIMIT_EXCEEDED. Every response carries the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers. It must run after auth.Middleware to count requests per user.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		b := l.write
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			b = l.read
+		}
+		l.limit(c, b, clientKey(c))
+	}
+}
+
+// PerIP returns a handler that counts every request against the IP budget
+// of its client IP address. It runs before auth.Middleware, and its
+// headers are replaced by those of Middleware on requests that pass both.
+func (l *RateLimiter) PerIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l.limit(c, l.ip, "ip:"+c.ClientIP())
+	}
+}
+
+// limit takes a token from the bucket of client for b and continues the
+// chain, or responds with 429 if there was none
+func (l *RateLimiter) limit(c *gin.Context, b budget, client string) {
+	allowed, tokens := l.take(b, client)
+
+	c.Header("RateLimit-Limit", strconv.Itoa(b.burst))
+	c.Header("RateLimit-Remaining", strconv.Itoa(int(tokens)))
+	c.Header("RateLimit-Reset", strconv.Itoa(b.secondsUntil(float64(b.burst)-tokens)))
+	c.Header("RateLimit-Policy", b.policy)
+
+	if !allowed {
+		retryAfter := b.secondsUntil(1 - tokens)
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		response.RespondWithError(c, http.StatusTooManyRequests, response.ErrRateLimitExceeded,
+			response.GetErrorMessage(response.ErrRateLimitExceeded),
+			map[string]interface{}{
+				"limit":       b.burst,
+				"policy":      b.name,
+				"retry_after": retryAfter,
+			})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// take takes a token from the bucket of client for b. It reports whether
+// one was available and how many are left.
+func (l *RateLimiter) take(b budget, client string) (bool, float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	key := b.name + ":" + client
+	limiter, ok := l.buckets[key]
+	if !ok {
+		limiter = rate.NewLimiter(b.limit, b.burst)
+		l.buckets[key] = limiter
+	}
+	allowed := limiter.AllowN(now, 1)
+	return allowed, max(limiter.TokensAt(now), 0)
+}
+
+// sweep drops buckets that have refilled completely since they were last
+// used; a new bucket behaves the same. Callers must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, limiter := range l.buckets {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// secondsUntil returns how many whole seconds the bucket needs to refill
+// tokens
+func (b budget) secondsUntil(tokens float64) int {
+	if tokens <= 0 {
+		return 0
+	}
+	if b.limit <= 0 {
+		// Budgets without a refill rate never recover; report a minute
+		return 60
+	}
+	return int(math.Ceil(tokens / float64(b.limit)))
+}
+
+// clientKey identifies the client of a request: its user when authenticated,
+// its IP address otherwise
+func clientKey(c *gin.Context) string {
+	if userID := c.GetInt64(auth.ContextUserID); userID != 0 {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return "ip:" + c.ClientIP()
+}
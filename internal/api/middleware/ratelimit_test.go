@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func newTestRouter(t *testing.T, limiter *RateLimiter, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(trustedProxies))
+	// Stand-in for auth.Middleware
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user == "1" {
+			c.Set(auth.ContextUserID, int64(1))
+		} else if user == "2" {
+			c.Set(auth.ContextUserID, int64(2))
+		}
+	})
+	router.Use(limiter.Middleware())
+	router.GET("/things", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/things", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return router
+}
+
+func serve(router *gin.Engine, method, user, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/things", nil)
+	req.RemoteAddr = remoteAddr
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func newTestLimiter(now *time.Time) *RateLimiter {
+	limiter := NewRateLimiter(&config.APIRateLimitConfig{
+		Enabled: true,
+		Read:    config.RateLimitConfig{RequestsPerMinute: 60, BurstSize: 3},
+		Write:   config.RateLimitConfig{RequestsPerMinute: 6, BurstSize: 1},
+		IP:      config.RateLimitConfig{RequestsPerMinute: 60, BurstSize: 2},
+	})
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("headers and rejection", func(t *testing.T) {
+		router := newTestRouter(t, newTestLimiter(&now), nil)
+
+		for i := 2; i >= 0; i-- {
+			rec := serve(router, http.MethodGet, "1", "10.0.0.1:1234", "")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, strconv.Itoa(i), rec.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, strconv.Itoa(3-i), rec.Header().Get("RateLimit-Reset"))
+			assert.Equal(t, "60;w=60;burst=3", rec.Header().Get("RateLimit-Policy"))
+		}
+
+		rec := serve(router, http.MethodGet, "1", "10.0.0.1:1234", "")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		var body response.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, response.ErrRateLimitExceeded, body.Error.Code)
+		assert.Equal(t, "read", body.Error.Details["policy"])
+		assert.EqualValues(t, 1, body.Error.Details["retry_after"])
+
+		// The bucket refills over time
+		now = now.Add(time.Second)
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "1", "10.0.0.1:1234", "").Code)
+	})
+
+	t.Run("reads and writes have separate budgets", func(t *testing.T) {
+		router := newTestRouter(t, newTestLimiter(&now), nil)
+
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "1", "10.0.0.1:1234", "").Code)
+		rec := serve(router, http.MethodPost, "1", "10.0.0.1:1234", "")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "1", "10.0.0.1:1234", "").Code)
+	})
+
+	t.Run("users are limited separately from their address", func(t *testing.T) {
+		router := newTestRouter(t, newTestLimiter(&now), nil)
+
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "1", "10.0.0.1:1234", "").Code)
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "2", "10.0.0.1:1234", "").Code)
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "", "10.0.0.1:1234", "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodPost, "", "10.0.0.1:5678", "").Code)
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "", "10.0.0.2:1234", "").Code)
+	})
+
+	t.Run("forwarded addresses only from trusted proxies", func(t *testing.T) {
+		router := newTestRouter(t, newTestLimiter(&now), []string{"127.0.0.1"})
+
+		// Behind the proxy, clients are told apart by X-Forwarded-For
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "", "127.0.0.1:1234", "203.0.113.1").Code)
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "", "127.0.0.1:1234", "203.0.113.2").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodPost, "", "127.0.0.1:1234", "203.0.113.1").Code)
+
+		// Anyone else cannot escape their limit by forging the header
+		assert.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "", "10.0.0.9:1234", "203.0.113.3").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodPost, "", "10.0.0.9:1234", "203.0.113.4").Code)
+	})
+
+	t.Run("idle buckets are dropped", func(t *testing.T) {
+		limiter := newTestLimiter(&now)
+		router := newTestRouter(t, limiter, nil)
+
+		serve(router, http.MethodPost, "1", "10.0.0.1:1234", "")
+		serve(router, http.MethodGet, "2", "10.0.0.1:1234", "")
+		assert.Len(t, limiter.buckets, 2)
+
+		// Both buckets are full again; only the new request's remains
+		now = now.Add(sweepInterval)
+		serve(router, http.MethodGet, "1", "10.0.0.1:1234", "")
+		assert.Len(t, limiter.buckets, 1)
+	})
+}
+
+func TestRateLimiter_PerIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newTestLimiter(&now)
+
+	// Every request is rejected after the IP limit, as auth.Middleware
+	// does for bad tokens
+	authenticated := 0
+	router := gin.New()
+	router.Use(limiter.PerIP())
+	router.GET("/things", func(c *gin.Context) {
+		authenticated++
+		c.Status(http.StatusUnauthorized)
+	})
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "", "10.0.0.1:1234", "").Code)
+	}
+	rec := serve(router, http.MethodGet, "", "10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, 2, authenticated, "limited requests must not reach authentication")
+
+	assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "", "10.0.0.2:1234", "").Code)
+}
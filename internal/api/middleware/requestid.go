@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// ContextRequestID is the gin context key of the request ID
+const ContextRequestID = "request_id"
+
+// validRequestID matches the request IDs accepted from clients and proxies
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID assigns every request an ID, taken from the X-Request-ID header
+// when it is set to something sensible and generated otherwise. The ID is
+// echoed in the response header, stored in the gin context for logs and
+// error responses, and stored in the request context, along with the client
+// IP, for the audit log.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set(ContextRequestID, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(audit.WithRequest(c.Request.Context(), id, c.ClientIP()))
+		c.Next()
+	}
+}
+
+// newRequestID returns a random request ID, or one made of the current
+// time should the system run out of randomness
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "req_" + time.Now().UTC().Format("20060102150405.000000")
+	}
+	return "req_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var origin audit.Origin
+	var contextID string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/things", func(c *gin.Context) {
+		origin = audit.OriginFrom(c.Request.Context())
+		contextID = c.GetString(ContextRequestID)
+		c.Status(http.StatusOK)
+	})
+
+	serveWithID := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("kept from the request", func(t *testing.T) {
+		rec := serveWithID("abc-123")
+		assert.Equal(t, "abc-123", rec.Header().Get(RequestIDHeader))
+		assert.Equal(t, "abc-123", contextID)
+		assert.Equal(t, audit.Origin{RequestID: "abc-123", IP: "192.0.2.1"}, origin)
+	})
+
+	t.Run("generated when missing or invalid", func(t *testing.T) {
+		for _, id := range []string{"", "has spaces", string(make([]byte, 65))} {
+			rec := serveWithID(id)
+			generated := rec.Header().Get(RequestIDHeader)
+			require.Regexp(t, `^req_[0-9a-f]{16}$`, generated)
+			assert.Equal(t, generated, origin.RequestID)
+		}
+		assert.NotEqual(t, serveWithID("").Header().Get(RequestIDHeader), serveWithID("").Header().Get(RequestIDHeader))
+	})
+}
@@ -6,22 +6,44 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"code.forgejo.org/forgejo/classroom/internal/api/middleware"
 	"code.forgejo.org/forgejo/classroom/internal/api/v1"
+	"code.forgejo.org/forgejo/classroom/internal/auth"
 	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
-// NewRouter creates and configures the main API router
-func NewRouter(cfg *config.Config, logger *zap.Logger) *gin.Engine {
+// NewRouter creates and configures the main API router. Requests are
+// recorded in m, which is served on /metrics; m is nil when metrics are
+// disabled.
+func NewRouter(cfg *config.Config, services *service.Services, tokens *auth.TokenValidator, m *metrics.Metrics, logger *zap.Logger) *gin.Engine {
 	router := gin.New()
 
+	// Only trust forwarding headers set by the configured proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies, trusting none", zap.Error(err))
+		_ = router.SetTrustedProxies(nil)
+	}
+
 	// Middleware
-	router.Use(gin.Logger())
+	router.Use(middleware.RequestID())
+	if m != nil {
+		router.Use(middleware.Metrics(m))
+	}
+	router.Use(middleware.Logger(logger))
 	router.Use(gin.Recovery())
 	router.Use(corsMiddleware())
 
 	// Health check
 	router.GET("/health", healthCheck)
 
+	// Prometheus metrics, outside the API so that scrapes are neither
+	// authenticated with Forgejo nor rate limited
+	if m != nil {
+		router.GET("/metrics", middleware.MetricsToken(cfg.Server.Metrics.Token), gin.WrapH(m.Handler()))
+	}
+
 	// API version info
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -33,18 +55,44 @@ func NewRouter(cfg *config.Config, logger *zap.Logger) *gin.Engine {
 		})
 	})
 
-	// API v1 routes
-	v1Group := router.Group("/api/v1")
+	// Webhooks authenticate with their signature, and deliveries at a
+	// deadline must not be rate limited
+	if cfg.Forgejo.WebhookSecret != "" {
+		v1.RegisterWebhookRoutes(router.Group("/api/v1/webhooks"), services.Webhooks, cfg.Forgejo.WebhookSecret, logger)
+	} else {
+		logger.Info("Webhook secret not configured, push webhooks are disabled")
+	}
+
+	// Requests are limited per IP before authentication, so that bad
+	// tokens cannot make the server call Forgejo at will, and per user or
+	// IP after it
+	authn := auth.Middleware(tokens, logger)
+	var perIP, limits []gin.HandlerFunc
+	if cfg.Server.RateLimit.Enabled {
+		limiter := middleware.NewRateLimiter(&cfg.Server.RateLimit)
+		perIP = append(perIP, limiter.PerIP())
+		limits = append(limits, limiter.Middleware())
+	}
+
+	// API v1 routes. Students open invitation links before signing in, so
+	// those routes are public and rate limited per client IP.
+	v1Group := router.Group("/api/v1", perIP...)
+	public := router.Group("/api/v1", append(perIP, limits...)...)
 	{
-		// TODO: Add authentication middleware
-		// v1Group.Use(authMiddleware())
+		v1Group.Use(authn)
+		v1Group.Use(limits...)
 
 		// Register v1 handlers
-		v1.RegisterClassroomRoutes(v1Group, logger)
-		v1.RegisterAssignmentRoutes(v1Group, logger)
-		v1.RegisterRosterRoutes(v1Group, logger)
-		v1.RegisterSubmissionRoutes(v1Group, logger)
+		v1.RegisterClassroomRoutes(v1Group, services.Classrooms, services.Permissions, logger)
+		v1.RegisterAssignmentRoutes(v1Group, services.Assignments, services.Permissions, logger)
+		v1.RegisterInvitationRoutes(v1Group, public, services.Invitations, services.Assignments, services.Permissions,
+			cfg.Server.PublicURL, authn, logger)
+		v1.RegisterRosterRoutes(v1Group, services.Roster, services.Permissions, logger)
+		v1.RegisterRosterClaimRoutes(v1Group, public, services.Roster, services.Permissions, cfg.Server.PublicURL, authn, logger)
+		v1.RegisterSubmissionRoutes(v1Group, services.Submissions, services.Assignments, services.Permissions, logger)
 		v1.RegisterTeamRoutes(v1Group, logger)
+		v1.RegisterJobRoutes(v1Group, services.Jobs, services.Permissions, logger)
+		v1.RegisterAuditRoutes(v1Group, services.Audit, services.Permissions, logger)
 	}
 
 	return router
@@ -61,7 +109,8 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
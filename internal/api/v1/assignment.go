@@ -6,26 +6,31 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
 // AssignmentHandler handles assignment-related API endpoints
 type AssignmentHandler struct {
-	logger *zap.Logger
-	// TODO: Add service dependencies
-	// service *service.AssignmentService
+	logger  *zap.Logger
+	service *service.AssignmentService
+	checker *auth.Checker
 }
 
 // NewAssignmentHandler creates a new assignment handler
-func NewAssignmentHandler(logger *zap.Logger) *AssignmentHandler {
+func NewAssignmentHandler(svc *service.AssignmentService, checker *auth.Checker, logger *zap.Logger) *AssignmentHandler {
 	return &AssignmentHandler{
-		logger: logger,
+		logger:  logger,
+		service: svc,
+		checker: checker,
 	}
 }
 
 // RegisterAssignmentRoutes registers assignment routes with the router group
-func RegisterAssignmentRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
-	handler := NewAssignmentHandler(logger)
+func RegisterAssignmentRoutes(rg *gin.RouterGroup, svc *service.AssignmentService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewAssignmentHandler(svc, checker, logger)
 
 	assignments := rg.Group("/assignments")
 	{
@@ -43,118 +48,183 @@ func RegisterAssignmentRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 func (h *AssignmentHandler) CreateAssignment(c *gin.Context) {
 	h.logger.Info("Creating assignment", zap.String("request_id", c.GetString("request_id")))
 
-	// TODO: Implement assignment creation
-	// 1. Parse and validate request body (classroom_id, template_repo, deadline, etc.)
-	// 2. Validate template repository exists
-	// 3. Call service layer to create assignment
-	// 4. Return created assignment
+	var req model.CreateAssignmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if _, ok := authorize(c, h.logger, h.checker, req.ClassroomID, auth.PermManageAssignments); !ok {
+		return
+	}
+
+	assignment, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
 
-	response.RespondWithData(c, http.StatusCreated, gin.H{
-		"message": "Assignment creation not yet implemented",
-		"todo":    "Parse request, validate template repo, call service layer",
-	})
+	response.RespondWithData(c, http.StatusCreated, assignment)
 }
 
-// ListAssignments handles GET /api/v1/assignments
+// ListAssignments handles GET /api/v1/assignments?classroom_id=...
 func (h *AssignmentHandler) ListAssignments(c *gin.Context) {
 	h.logger.Info("Listing assignments", zap.String("request_id", c.GetString("request_id")))
 
-	// TODO: Implement assignment listing
-	// 1. Parse query parameters (classroom_id, status, pagination)
-	// 2. Call service layer to get assignments
-	// 3. Return paginated list
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
 
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment listing not yet implemented",
-		"todo":    "Parse query params, call service layer, return paginated results",
-	})
+	var req model.AssignmentListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if req.ClassroomID != 0 {
+		if _, ok := authorize(c, h.logger, h.checker, req.ClassroomID, auth.PermViewClassroom); !ok {
+			return
+		}
+	} else if !c.GetBool(auth.ContextUserAdmin) {
+		req.MemberUserID = actor.ID
+	}
+
+	list, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Assignments,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
 }
 
 // GetAssignment handles GET /api/v1/assignments/:id
 func (h *AssignmentHandler) GetAssignment(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Getting assignment", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement assignment retrieval
-	// 1. Validate ID parameter
-	// 2. Call service layer to get assignment
-	// 3. Return assignment details
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment retrieval not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, call service layer, return assignment",
-	})
+	h.logger.Info("Getting assignment", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	assignment, ok := h.load(c, auth.PermViewClassroom)
+	if !ok {
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, assignment)
 }
 
 // UpdateAssignment handles PUT /api/v1/assignments/:id
 func (h *AssignmentHandler) UpdateAssignment(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Updating assignment", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement assignment update
-	// 1. Validate ID parameter
-	// 2. Parse and validate request body
-	// 3. Call service layer to update assignment
-	// 4. Return updated assignment
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment update not yet implemented",
-		"id":      id,
-		"todo":    "Parse request, validate input, call service layer",
-	})
+	h.logger.Info("Updating assignment", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	assignment, ok := h.load(c, auth.PermManageAssignments)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateAssignmentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	updated, err := h.service.Update(c.Request.Context(), assignment.ID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, updated)
 }
 
 // DeleteAssignment handles DELETE /api/v1/assignments/:id
 func (h *AssignmentHandler) DeleteAssignment(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Deleting assignment", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement assignment deletion
-	// 1. Validate ID parameter
-	// 2. Check permissions and dependencies
-	// 3. Call service layer to delete assignment
-	// 4. Return success response
-
-	response.RespondWithData(c, http.StatusNoContent, gin.H{
-		"message": "Assignment deletion not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, check dependencies, call service layer",
-	})
+	h.logger.Info("Deleting assignment", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	assignment, ok := h.load(c, auth.PermManageAssignments)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), assignment.ID); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
-// GetAssignmentStats handles GET /api/v1/assignments/:id/stats
+// GetAssignmentStats handles GET /api/v1/assignments/:id/stats?detailed=true
 func (h *AssignmentHandler) GetAssignmentStats(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Getting assignment stats", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement assignment statistics
-	// 1. Validate ID parameter
-	// 2. Call service layer to get statistics
-	// 3. Return stats (submissions, acceptance rate, etc.)
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment stats not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, call service layer, return stats",
-	})
+	h.logger.Info("Getting assignment stats", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.AssignmentStatsRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	assignment, err := h.service.Get(ctx, id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	if _, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, auth.PermGradeAssignments, "Assignment"); !ok {
+		return
+	}
+
+	stats, err := h.service.Stats(ctx, assignment, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, stats)
 }
 
 // AcceptAssignment handles POST /api/v1/assignments/:id/accept
 func (h *AssignmentHandler) AcceptAssignment(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Accepting assignment", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement assignment acceptance
-	// 1. Validate ID parameter
-	// 2. Check if user is in roster
-	// 3. Check if deadline hasn't passed
-	// 4. Create student repository from template
-	// 5. Return submission details
-
-	response.RespondWithData(c, http.StatusCreated, gin.H{
-		"message": "Assignment acceptance not yet implemented",
-		"id":      id,
-		"todo":    "Validate user, check deadline, create repo, return submission",
-	})
+	h.logger.Info("Accepting assignment", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional; it only carries the team name for team assignments
+	var req model.AcceptAssignmentRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	submission, err := h.service.Accept(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusCreated, submission)
+}
+
+// load returns the assignment named by the :id parameter if the caller
+// holds perm in its classroom. It is not found for non-members.
+func (h *AssignmentHandler) load(c *gin.Context, perm auth.Permission) (*model.Assignment, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	assignment, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return nil, false
+	}
+	if _, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, perm, "Assignment"); !ok {
+		return nil, false
+	}
+	return assignment, true
 }
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// AuditHandler handles the audit log API endpoints
+type AuditHandler struct {
+	logger  *zap.Logger
+	service *service.AuditService
+	checker *auth.Checker
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(svc *service.AuditService, checker *auth.Checker, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		logger:  logger,
+		service: svc,
+		checker: checker,
+	}
+}
+
+// RegisterAuditRoutes registers audit log routes with the router group
+func RegisterAuditRoutes(rg *gin.RouterGroup, svc *service.AuditService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewAuditHandler(svc, checker, logger)
+
+	rg.GET("/classrooms/:id/audit", handler.ListEvents)
+}
+
+// ListEvents handles GET /api/v1/classrooms/:id/audit. The log is visible
+// to those who can manage the classroom, and to site admins also once the
+// classroom is deleted.
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	h.logger.Info("Listing audit events", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if !c.GetBool(auth.ContextUserAdmin) {
+		if _, ok := authorize(c, h.logger, h.checker, classroomID, auth.PermManageClassroom); !ok {
+			return
+		}
+	}
+
+	var req model.AuditListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	list, err := h.service.List(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Events,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
+}
@@ -6,35 +6,42 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
 // ClassroomHandler handles classroom-related API endpoints
 type ClassroomHandler struct {
-	logger *zap.Logger
-	// TODO: Add service dependencies
-	// service *service.ClassroomService
+	logger  *zap.Logger
+	service *service.ClassroomService
 }
 
 // NewClassroomHandler creates a new classroom handler
-func NewClassroomHandler(logger *zap.Logger) *ClassroomHandler {
+func NewClassroomHandler(svc *service.ClassroomService, logger *zap.Logger) *ClassroomHandler {
 	return &ClassroomHandler{
-		logger: logger,
+		logger:  logger,
+		service: svc,
 	}
 }
 
 // RegisterClassroomRoutes registers classroom routes with the router group
-func RegisterClassroomRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
-	handler := NewClassroomHandler(logger)
+func RegisterClassroomRoutes(rg *gin.RouterGroup, svc *service.ClassroomService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewClassroomHandler(svc, logger)
+	view := requireClassroomPermission(checker, auth.PermViewClassroom, "id", logger)
+	manage := requireClassroomPermission(checker, auth.PermManageClassroom, "id", logger)
+	grade := requireClassroomPermission(checker, auth.PermGradeAssignments, "id", logger)
 
 	classrooms := rg.Group("/classrooms")
 	{
 		classrooms.POST("", handler.CreateClassroom)
 		classrooms.GET("", handler.ListClassrooms)
-		classrooms.GET("/:id", handler.GetClassroom)
-		classrooms.PUT("/:id", handler.UpdateClassroom)
-		classrooms.DELETE("/:id", handler.DeleteClassroom)
-		classrooms.POST("/:id/archive", handler.ArchiveClassroom)
+		classrooms.GET("/:id", view, handler.GetClassroom)
+		classrooms.PUT("/:id", manage, handler.UpdateClassroom)
+		classrooms.DELETE("/:id", manage, handler.DeleteClassroom)
+		classrooms.POST("/:id/archive", manage, handler.ArchiveClassroom)
+		classrooms.GET("/:id/stats", grade, handler.GetClassroomStats)
 	}
 }
 
@@ -42,99 +49,137 @@ func RegisterClassroomRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 func (h *ClassroomHandler) CreateClassroom(c *gin.Context) {
 	h.logger.Info("Creating classroom", zap.String("request_id", c.GetString("request_id")))
 
-	// TODO: Implement classroom creation
-	// 1. Parse and validate request body
-	// 2. Call service layer to create classroom
-	// 3. Return created classroom
+	var req model.CreateClassroomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	classroom, err := h.service.Create(c.Request.Context(), actorFromContext(c), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
 
-	response.RespondWithData(c, http.StatusCreated, gin.H{
-		"message": "Classroom creation not yet implemented",
-		"todo":    "Parse request, validate input, call service layer",
-	})
+	response.RespondWithData(c, http.StatusCreated, classroom)
 }
 
 // ListClassrooms handles GET /api/v1/classrooms
 func (h *ClassroomHandler) ListClassrooms(c *gin.Context) {
 	h.logger.Info("Listing classrooms", zap.String("request_id", c.GetString("request_id")))
 
-	// TODO: Implement classroom listing
-	// 1. Parse query parameters (filters, pagination)
-	// 2. Call service layer to get classrooms
-	// 3. Return paginated list
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.ClassroomListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if !c.GetBool(auth.ContextUserAdmin) {
+		req.MemberUserID = actor.ID
+	}
 
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Classroom listing not yet implemented",
-		"todo":    "Parse query params, call service layer, return paginated results",
-	})
+	list, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Classrooms,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
 }
 
 // GetClassroom handles GET /api/v1/classrooms/:id
 func (h *ClassroomHandler) GetClassroom(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Getting classroom", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement classroom retrieval
-	// 1. Validate ID parameter
-	// 2. Call service layer to get classroom
-	// 3. Return classroom details
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Classroom retrieval not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, call service layer, return classroom",
-	})
+	h.logger.Info("Getting classroom", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	classroom, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, classroom)
+}
+
+// GetClassroomStats handles GET /api/v1/classrooms/:id/stats
+func (h *ClassroomHandler) GetClassroomStats(c *gin.Context) {
+	h.logger.Info("Getting classroom stats", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.Stats(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, stats)
 }
 
 // UpdateClassroom handles PUT /api/v1/classrooms/:id
 func (h *ClassroomHandler) UpdateClassroom(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Updating classroom", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement classroom update
-	// 1. Validate ID parameter
-	// 2. Parse and validate request body
-	// 3. Call service layer to update classroom
-	// 4. Return updated classroom
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Classroom update not yet implemented",
-		"id":      id,
-		"todo":    "Parse request, validate input, call service layer",
-	})
+	h.logger.Info("Updating classroom", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateClassroomRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	classroom, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, classroom)
 }
 
 // DeleteClassroom handles DELETE /api/v1/classrooms/:id
 func (h *ClassroomHandler) DeleteClassroom(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Deleting classroom", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement classroom deletion
-	// 1. Validate ID parameter
-	// 2. Check permissions
-	// 3. Call service layer to delete classroom
-	// 4. Return success response
-
-	response.RespondWithData(c, http.StatusNoContent, gin.H{
-		"message": "Classroom deletion not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, check permissions, call service layer",
-	})
+	h.logger.Info("Deleting classroom", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // ArchiveClassroom handles POST /api/v1/classrooms/:id/archive
 func (h *ClassroomHandler) ArchiveClassroom(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Archiving classroom", zap.String("id", id), zap.String("request_id", c.GetString("request_id")))
-
-	// TODO: Implement classroom archiving
-	// 1. Validate ID parameter
-	// 2. Check permissions
-	// 3. Call service layer to archive classroom
-	// 4. Return archived classroom
-
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Classroom archiving not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, check permissions, call service layer",
-	})
+	h.logger.Info("Archiving classroom", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	classroom, err := h.service.Archive(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, classroom)
 }
@@ -0,0 +1,512 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+func newTestRouter(register func(rg *gin.RouterGroup)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	register(router.Group("/api/v1"))
+	return router
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) response.ErrorDetail {
+	t.Helper()
+	var body response.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Error
+}
+
+func TestClassroomHandler_Errors(t *testing.T) {
+	// Requests rejected before reaching the database need no backing store
+	svc := service.NewClassroomService(nil, nil, nil, zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterClassroomRoutes(rg, svc, auth.NewChecker(nil), zap.NewNop())
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"malformed body", http.MethodPost, "/api/v1/classrooms", "{", http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"missing fields", http.MethodPost, "/api/v1/classrooms", `{"name":""}`, http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"invalid id", http.MethodGet, "/api/v1/classrooms/abc", "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"invalid update id", http.MethodPut, "/api/v1/classrooms/0", `{}`, http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"unauthenticated get", http.MethodGet, "/api/v1/classrooms/1", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"unauthenticated update", http.MethodPut, "/api/v1/classrooms/1", `{"name":"x"}`, http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"unauthenticated list", http.MethodGet, "/api/v1/classrooms", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"invalid stats id", http.MethodGet, "/api/v1/classrooms/abc/stats", "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"unauthenticated stats", http.MethodGet, "/api/v1/classrooms/1/stats", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestAssignmentHandler_Errors(t *testing.T) {
+	svc := service.NewAssignmentService(nil, nil, nil, zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterAssignmentRoutes(rg, svc, auth.NewChecker(nil), zap.NewNop())
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"malformed body", http.MethodPost, "/api/v1/assignments", "{", http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"missing fields", http.MethodPost, "/api/v1/assignments", `{"name":"HW1"}`, http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"unauthenticated create", http.MethodPost, "/api/v1/assignments", `{"classroom_id":1,"name":"HW1","template_repository":"cs101/t"}`,
+			http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"unauthenticated list", http.MethodGet, "/api/v1/assignments", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"invalid id", http.MethodGet, "/api/v1/assignments/abc", "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"invalid update id", http.MethodPut, "/api/v1/assignments/0", `{}`, http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"invalid delete id", http.MethodDelete, "/api/v1/assignments/abc", "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestTeamHandler_NotSupported(t *testing.T) {
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterTeamRoutes(rg, zap.NewNop())
+		RegisterSubmissionRoutes(rg, nil, nil, auth.NewChecker(nil), zap.NewNop())
+	})
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/teams"},
+		{http.MethodGet, "/api/v1/teams/1"},
+		{http.MethodPost, "/api/v1/teams/1/join"},
+		{http.MethodPost, "/api/v1/teams/1/leave"},
+		{http.MethodGet, "/api/v1/assignments/1/teams"},
+		{http.MethodGet, "/api/v1/submissions/1/download"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+		assert.Equal(t, http.StatusNotImplemented, rec.Code, route.path)
+		assert.Equal(t, response.ErrSystemUnsupported, decodeError(t, rec).Code, route.path)
+	}
+}
+
+func TestAssignmentHandler_AcceptRequiresAuthentication(t *testing.T) {
+	svc := service.NewAssignmentService(nil, nil, nil, zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterAssignmentRoutes(rg, svc, auth.NewChecker(nil), zap.NewNop())
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/assignments/1/accept", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, response.ErrAuthMissingToken, decodeError(t, rec).Code)
+}
+
+func TestAssignmentHandler_StatsValidation(t *testing.T) {
+	svc := service.NewAssignmentService(nil, nil, nil, zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterAssignmentRoutes(rg, svc, auth.NewChecker(nil), zap.NewNop())
+	})
+
+	for _, path := range []string{
+		"/api/v1/assignments/abc/stats",
+		"/api/v1/assignments/1/stats?detailed=maybe",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+	}
+}
+
+func TestInvitationHandler_Errors(t *testing.T) {
+	svc := service.NewAssignmentService(nil, nil, nil, zap.NewNop())
+	authn := func(c *gin.Context) {
+		response.Unauthorized(c, response.ErrAuthMissingToken, "no token")
+		c.Abort()
+	}
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterInvitationRoutes(rg, rg, service.NewInvitationService(nil, svc, zap.NewNop()), svc,
+			auth.NewChecker(nil), "", authn, zap.NewNop())
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"invalid assignment id", http.MethodGet, "/api/v1/assignments/abc/invitation", "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"malformed update", http.MethodPut, "/api/v1/assignments/1/invitation", "{", http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"accept requires authentication", http.MethodPost, "/api/v1/invitations/abc/accept", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestRosterClaimHandler_Errors(t *testing.T) {
+	// Requests are authenticated as user 7 when they carry a token
+	authn := func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			response.Unauthorized(c, response.ErrAuthMissingToken, "no token")
+			c.Abort()
+			return
+		}
+		c.Set(auth.ContextUserID, int64(7))
+		c.Set(auth.ContextUserLogin, "jdoe")
+	}
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterRosterClaimRoutes(rg, rg, service.NewRosterService(nil, nil, nil, nil, zap.NewNop()),
+			auth.NewChecker(nil), "", authn, zap.NewNop())
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  bool
+		body   string
+		status int
+		code   string
+	}{
+		{"invalid classroom id", http.MethodGet, "/api/v1/classrooms/abc/roster/join-link", false, "", http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"unauthenticated claims list", http.MethodGet, "/api/v1/classrooms/1/roster/claims", false, "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"claim requires authentication", http.MethodPost, "/api/v1/join/abc/claim", false, `{"student_id":"john123"}`, http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"malformed claim", http.MethodPost, "/api/v1/join/abc/claim", true, "{", http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"claim without identifier", http.MethodPost, "/api/v1/join/abc/claim", true, `{}`, http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"claim with invalid email", http.MethodPost, "/api/v1/join/abc/claim", true, `{"student_email":"john"}`, http.StatusBadRequest, response.ErrValidationInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token {
+				req.Header.Set("Authorization", "token abc")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+// TestRegisterRoutes registers every route group on one router, which
+// panics if two routes name the same path segment differently
+func TestRegisterRoutes(t *testing.T) {
+	logger := zap.NewNop()
+	authn := func(c *gin.Context) {}
+	checker := auth.NewChecker(nil)
+
+	assert.NotPanics(t, func() {
+		newTestRouter(func(rg *gin.RouterGroup) {
+			RegisterClassroomRoutes(rg, nil, checker, logger)
+			RegisterAssignmentRoutes(rg, nil, checker, logger)
+			RegisterInvitationRoutes(rg, rg, nil, nil, checker, "", authn, logger)
+			RegisterRosterRoutes(rg, nil, checker, logger)
+			RegisterRosterClaimRoutes(rg, rg, nil, checker, "", authn, logger)
+			RegisterSubmissionRoutes(rg, nil, nil, checker, logger)
+			RegisterTeamRoutes(rg, logger)
+			RegisterJobRoutes(rg, nil, checker, logger)
+			RegisterAuditRoutes(rg, nil, checker, logger)
+			RegisterWebhookRoutes(rg.Group("/webhooks"), nil, "", logger)
+		})
+	})
+}
+
+func TestSubmissionHandler_ListRequiresAssignment(t *testing.T) {
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterSubmissionRoutes(rg, service.NewSubmissionService(nil, nil, nil, zap.NewNop()), service.NewAssignmentService(nil, nil, nil, zap.NewNop()),
+			auth.NewChecker(nil), zap.NewNop())
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/submissions", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, response.ErrValidationMissingField, decodeError(t, rec).Code)
+}
+
+func TestSubmissionHandler_DownloadAllValidation(t *testing.T) {
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterSubmissionRoutes(rg, service.NewSubmissionService(nil, nil, nil, zap.NewNop()), service.NewAssignmentService(nil, nil, nil, zap.NewNop()),
+			auth.NewChecker(nil), zap.NewNop())
+	})
+
+	for _, path := range []string{
+		"/api/v1/assignments/abc/submissions/download",
+		"/api/v1/assignments/1/submissions/download?at_deadline=maybe",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"), path)
+	}
+}
+
+func TestRosterHandler_Import(t *testing.T) {
+	// Uploads rejected before the file is imported need no backing store
+	handler := NewRosterHandler(service.NewRosterService(nil, nil, nil, nil, zap.NewNop()), zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		rg.POST("/classrooms/:id/roster/import", handler.ImportRoster)
+	})
+
+	upload := func(field, content string) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, err := w.CreateFormFile(field, "roster.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/classrooms/1/roster/import", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   string
+	}{
+		{"missing file", upload("other", "x"), http.StatusBadRequest, response.ErrValidationMissingField},
+		{"missing columns", upload("file", "name,email\nJane,jane@example.com\n"), http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"empty file", upload("file", ""), http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"too large", upload("file", strings.Repeat("x", maxRosterImportSize)), http.StatusRequestEntityTooLarge, response.ErrValidationTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestJobHandler_RequiresAuthentication(t *testing.T) {
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterJobRoutes(rg, service.NewJobService(nil, nil), auth.NewChecker(nil), zap.NewNop())
+		RegisterRosterRoutes(rg, service.NewRosterService(nil, nil, nil, nil, zap.NewNop()), auth.NewChecker(nil), zap.NewNop())
+	})
+
+	for _, path := range []string{"/api/v1/jobs/1", "/api/v1/jobs/1/errors", "/api/v1/classrooms/1/roster/students"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+		assert.Equal(t, response.ErrAuthMissingToken, decodeError(t, rec).Code, path)
+	}
+
+	for _, path := range []string{
+		"/api/v1/classrooms/1/roster/import",
+		"/api/v1/classrooms/1/roster/bulk",
+		"/api/v1/classrooms/1/roster/students",
+		"/api/v1/classrooms/1/roster/students/s1/link",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+}
+
+func TestAuditHandler_Errors(t *testing.T) {
+	// Site admins pass the permission check without a database
+	admin := func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Set(auth.ContextUserID, int64(1))
+			c.Set(auth.ContextUserAdmin, true)
+		}
+	}
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		rg.Use(admin)
+		RegisterAuditRoutes(rg, service.NewAuditService(nil), auth.NewChecker(nil), zap.NewNop())
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		token  bool
+		status int
+		code   string
+	}{
+		{"unauthenticated", "/api/v1/classrooms/1/audit", false, http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"invalid classroom id", "/api/v1/classrooms/abc/audit", true, http.StatusBadRequest, response.ErrValidationInvalidFormat},
+		{"invalid since", "/api/v1/classrooms/1/audit?since=yesterday", true, http.StatusBadRequest, response.ErrValidationInvalidInput},
+		{"invalid target id", "/api/v1/classrooms/1/audit?target_id=x", true, http.StatusBadRequest, response.ErrValidationInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token {
+				req.Header.Set("Authorization", "token abc")
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeError(t, rec).Code)
+		})
+	}
+}
+
+func TestRosterHandler_BulkValidation(t *testing.T) {
+	handler := NewRosterHandler(service.NewRosterService(nil, nil, nil, nil, zap.NewNop()), zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		rg.POST("/classrooms/:id/roster/bulk", handler.BulkRoster)
+	})
+
+	for name, body := range map[string]string{
+		"malformed":     `{"operations":`,
+		"no operations": `{"operations":[]}`,
+		"unknown mode":  `{"mode":"maybe","operations":[{"action":"remove","student_id":"s1"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/classrooms/1/roster/bulk", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestRespondError_ForgejoRateLimited(t *testing.T) {
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		rg.GET("/fail", func(c *gin.Context) {
+			respondError(c, zap.NewNop(), fmt.Errorf("failed to generate repository: %w", &forgejo.Error{
+				StatusCode: http.StatusTooManyRequests,
+				Code:       response.ErrIntegrationForgejoRateLimited,
+				RetryAfter: 1500 * time.Millisecond,
+			}))
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fail", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, response.ErrIntegrationForgejoRateLimited, decodeError(t, rec).Code)
+}
+
+func TestWebhookHandler_Forgejo(t *testing.T) {
+	// Requests rejected before the push is looked up need no backing store
+	svc := service.NewWebhookService(nil, nil, nil, nil, zap.NewNop())
+	router := newTestRouter(func(rg *gin.RouterGroup) {
+		RegisterWebhookRoutes(rg.Group("/webhooks"), svc, "s3cret", zap.NewNop())
+	})
+
+	// echo -n '{"ref":"refs/heads/main"}' | openssl dgst -sha256 -hmac s3cret
+	const body = `{"ref":"refs/heads/main"}`
+	const signature = "232a5e067b6a5aa567ed5a1d21a0dffaf6ef0eeecb6bd9bcf43b3d5ef3fc4f43"
+
+	deliver := func(event, body, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/forgejo", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(forgejo.HeaderEvent, event)
+		if signature != "" {
+			req.Header.Set(forgejo.HeaderSignature, signature)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("missing signature", func(t *testing.T) {
+		rec := deliver("push", body, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, response.ErrAuthInvalidSignature, decodeError(t, rec).Code)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		rec := deliver("push", `{"ref":"refs/heads/other"}`, signature)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("other events are ignored", func(t *testing.T) {
+		rec := deliver("issues", body, signature)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"ignored"`)
+	})
+
+	t.Run("push without repository", func(t *testing.T) {
+		rec := deliver("push", body, signature)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, response.ErrValidationInvalidInput, decodeError(t, rec).Code)
+	})
+}
+
+func TestHideNonMember(t *testing.T) {
+	hidden := service.AsError(hideNonMember(auth.ErrForbidden, "Submission"))
+	assert.Equal(t, response.ErrResourceNotFound, hidden.Code)
+	assert.Equal(t, "Submission not found", hidden.Message)
+	assert.Equal(t, http.StatusNotFound, response.StatusForCode(hidden.Code))
+
+	// Members know the resource exists and learn which permission they lack
+	assert.Equal(t, response.ErrAuthzInsufficientPermissions,
+		service.AsError(hideNonMember(auth.ErrInsufficientPermissions, "Submission")).Code)
+}
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// respondError reports a service error using the error taxonomy
+func respondError(c *gin.Context, logger *zap.Logger, err error) {
+	svcErr := service.AsError(err)
+	status := response.StatusForCode(svcErr.Code)
+
+	if status >= http.StatusInternalServerError {
+		logger.Error("Request failed",
+			zap.String("request_id", c.GetString("request_id")),
+			zap.String("code", svcErr.Code),
+			zap.Error(err),
+		)
+	}
+
+	// Forgejo rate limits and open circuits tell clients when to come back
+	if retryAfter, ok := svcErr.Details["retry_after"].(int); ok {
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+	}
+	response.RespondWithError(c, status, svcErr.Code, svcErr.Message, svcErr.Details)
+}
+
+// bindJSON decodes the request body into req, responding with 400 on failure
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.BadRequest(c, response.ErrValidationInvalidInput, "Invalid request body",
+			map[string]interface{}{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// bindQuery decodes the query string into req, responding with 400 on failure
+func bindQuery(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		response.BadRequest(c, response.ErrValidationInvalidInput, "Invalid query parameters",
+			map[string]interface{}{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// paramID parses a numeric path parameter, responding with 400 on failure
+func paramID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, response.ErrValidationInvalidFormat, "Invalid "+name,
+			map[string]interface{}{"field": name})
+		return 0, false
+	}
+	return id, true
+}
+
+// actorFromContext returns the authenticated user, or nil when the request
+// is not authenticated
+func actorFromContext(c *gin.Context) *service.Actor {
+	id := c.GetInt64(auth.ContextUserID)
+	if id == 0 {
+		return nil
+	}
+	return &service.Actor{
+		ID:    id,
+		Login: c.GetString(auth.ContextUserLogin),
+		Admin: c.GetBool(auth.ContextUserAdmin),
+	}
+}
+
+// requireActor returns the authenticated user, responding with 401 when the
+// request is not authenticated
+func requireActor(c *gin.Context) (*service.Actor, bool) {
+	actor := actorFromContext(c)
+	if actor == nil {
+		response.Unauthorized(c, response.ErrAuthMissingToken, response.GetErrorMessage(response.ErrAuthMissingToken))
+		return nil, false
+	}
+	return actor, true
+}
+
+// authorize checks that the caller holds perm in the classroom, responding
+// with the AUTHZ_* error when they do not
+func authorize(c *gin.Context, logger *zap.Logger, checker *auth.Checker, classroomID int64, perm auth.Permission) (*auth.Membership, bool) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return nil, false
+	}
+
+	m, err := checker.Authorize(c.Request.Context(), actor.ID, c.GetBool(auth.ContextUserAdmin), classroomID, perm)
+	if err != nil {
+		respondError(c, logger, err)
+		return nil, false
+	}
+	return m, true
+}
+
+// authorizeFound is authorize for a resource looked up by its own ID.
+// Callers who are not members of its classroom get the same not-found
+// response as for a missing resource, so that they cannot probe IDs; members
+// lacking perm still get the AUTHZ_* error.
+func authorizeFound(c *gin.Context, logger *zap.Logger, checker *auth.Checker, classroomID int64, perm auth.Permission, resource string) (*auth.Membership, bool) {
+	actor, ok := requireActor(c)
+	if !ok {
+		return nil, false
+	}
+
+	m, err := checker.Authorize(c.Request.Context(), actor.ID, c.GetBool(auth.ContextUserAdmin), classroomID, perm)
+	if err != nil {
+		respondError(c, logger, hideNonMember(err, resource))
+		return nil, false
+	}
+	return m, true
+}
+
+// hideNonMember reports auth.ErrForbidden, which means the caller is not a
+// member of the classroom, as resource not being found
+func hideNonMember(err error, resource string) error {
+	if errors.Is(err, auth.ErrForbidden) {
+		return &service.Error{Code: response.ErrResourceNotFound, Message: resource + " not found", Err: err}
+	}
+	return err
+}
+
+// requireClassroomPermission is middleware for routes whose :param path
+// parameter is a classroom ID; it rejects callers without perm there
+func requireClassroomPermission(checker *auth.Checker, perm auth.Permission, param string, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, param)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if _, ok := authorize(c, logger, checker, id, perm); !ok {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// pageMeta builds pagination metadata for list responses
+func pageMeta(page, perPage, totalPages, total int) *response.MetaInfo {
+	return &response.MetaInfo{
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+		TotalCount: total,
+	}
+}
+
+// abortResponse drops the connection of a response that failed after its
+// headers were sent, so that clients see the body cut short rather than
+// taking it for complete
+func abortResponse(c *gin.Context) {
+	c.Abort()
+	w, ok := c.Writer.(interface{ Unwrap() http.ResponseWriter })
+	if !ok {
+		return
+	}
+	if hijacker, ok := w.Unwrap().(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+		}
+	}
+}
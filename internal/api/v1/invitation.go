@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// InvitationHandler handles the invitation links of assignments
+type InvitationHandler struct {
+	logger      *zap.Logger
+	service     *service.InvitationService
+	assignments *service.AssignmentService
+	checker     *auth.Checker
+	publicURL   string
+}
+
+// NewInvitationHandler creates a new invitation handler. Invitation URLs
+// are built on publicURL, and omitted when it is empty.
+func NewInvitationHandler(svc *service.InvitationService, assignments *service.AssignmentService, checker *auth.Checker, publicURL string, logger *zap.Logger) *InvitationHandler {
+	return &InvitationHandler{
+		logger:      logger,
+		service:     svc,
+		assignments: assignments,
+		checker:     checker,
+		publicURL:   strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+// RegisterInvitationRoutes registers the routes that manage the invitation
+// of an assignment with the authenticated router group rg, and the routes
+// that use invitation tokens with public, which must not require
+// authentication: anyone with a token may view its assignment, and
+// accepting authenticates with authn.
+func RegisterInvitationRoutes(rg, public *gin.RouterGroup, svc *service.InvitationService, assignments *service.AssignmentService,
+	checker *auth.Checker, publicURL string, authn gin.HandlerFunc, logger *zap.Logger) {
+	handler := NewInvitationHandler(svc, assignments, checker, publicURL, logger)
+
+	invitation := rg.Group("/assignments/:id/invitation")
+	{
+		invitation.GET("", handler.GetInvitation)
+		invitation.PUT("", handler.UpdateInvitation)
+		invitation.POST("/rotate", handler.RotateInvitation)
+	}
+
+	invitations := public.Group("/invitations")
+	{
+		invitations.GET("/:token", handler.ResolveInvitation)
+		invitations.POST("/:token/accept", authn, handler.AcceptInvitation)
+	}
+}
+
+// GetInvitation handles GET /api/v1/assignments/:id/invitation
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	h.logger.Info("Getting invitation", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := h.authorizeAssignment(c)
+	if !ok {
+		return
+	}
+
+	inv, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, h.withURL(inv))
+}
+
+// RotateInvitation handles POST /api/v1/assignments/:id/invitation/rotate
+func (h *InvitationHandler) RotateInvitation(c *gin.Context) {
+	h.logger.Info("Rotating invitation", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := h.authorizeAssignment(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional; it only carries the expiry
+	var req model.RotateInvitationRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	inv, err := h.service.Rotate(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusCreated, h.withURL(inv))
+}
+
+// UpdateInvitation handles PUT /api/v1/assignments/:id/invitation
+func (h *InvitationHandler) UpdateInvitation(c *gin.Context) {
+	h.logger.Info("Updating invitation", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	var req model.UpdateInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	id, ok := h.authorizeAssignment(c)
+	if !ok {
+		return
+	}
+
+	inv, err := h.service.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, h.withURL(inv))
+}
+
+// ResolveInvitation handles GET /api/v1/invitations/:token
+func (h *InvitationHandler) ResolveInvitation(c *gin.Context) {
+	h.logger.Info("Resolving invitation", zap.String("request_id", c.GetString("request_id")))
+
+	details, err := h.service.Resolve(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, details)
+}
+
+// AcceptInvitation handles POST /api/v1/invitations/:token/accept
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	h.logger.Info("Accepting invitation", zap.String("request_id", c.GetString("request_id")))
+
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional; it only carries the team name for team assignments
+	var req model.AcceptAssignmentRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	submission, err := h.service.Accept(c.Request.Context(), actor, c.Param("token"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusCreated, submission)
+}
+
+// authorizeAssignment checks that the caller manages the assignment of the
+// :id path parameter and returns its ID
+func (h *InvitationHandler) authorizeAssignment(c *gin.Context) (int64, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return 0, false
+	}
+
+	assignment, err := h.assignments.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return 0, false
+	}
+	if _, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, auth.PermManageAssignments, "Assignment"); !ok {
+		return 0, false
+	}
+	return id, true
+}
+
+// withURL sets the URL students open to resolve the invitation
+func (h *InvitationHandler) withURL(inv *model.Invitation) *model.Invitation {
+	if h.publicURL != "" {
+		inv.URL = h.publicURL + "/api/v1/invitations/" + inv.Token
+	}
+	return inv
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// JobHandler handles background job API endpoints
+type JobHandler struct {
+	logger  *zap.Logger
+	service *service.JobService
+	checker *auth.Checker
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(svc *service.JobService, checker *auth.Checker, logger *zap.Logger) *JobHandler {
+	return &JobHandler{
+		logger:  logger,
+		service: svc,
+		checker: checker,
+	}
+}
+
+// RegisterJobRoutes registers job routes with the router group
+func RegisterJobRoutes(rg *gin.RouterGroup, svc *service.JobService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewJobHandler(svc, checker, logger)
+
+	jobs := rg.Group("/jobs")
+	{
+		jobs.GET("/:id", handler.GetJob)
+		jobs.GET("/:id/errors", handler.ListJobErrors)
+	}
+}
+
+// GetJob handles GET /api/v1/jobs/:id
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+	response.RespondWithData(c, http.StatusOK, job)
+}
+
+// ListJobErrors handles GET /api/v1/jobs/:id/errors
+func (h *JobHandler) ListJobErrors(c *gin.Context) {
+	var req model.JobErrorListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	job, ok := h.job(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ListErrors(c.Request.Context(), job.ID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	response.RespondWithSuccess(c, http.StatusOK, result.Errors,
+		pageMeta(result.Page, result.PerPage, result.TotalPages, result.Total))
+}
+
+// job loads the job named by the :id parameter. Jobs of a classroom are
+// visible to those who can manage it; other jobs only to site admins. Jobs
+// are not found for callers outside their classroom.
+func (h *JobHandler) job(c *gin.Context) (*model.Job, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	if _, ok := requireActor(c); !ok {
+		return nil, false
+	}
+
+	job, classroomID, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return nil, false
+	}
+
+	if classroomID == 0 {
+		if !c.GetBool(auth.ContextUserAdmin) {
+			response.NotFound(c, response.ErrResourceNotFound, "Job not found")
+			return nil, false
+		}
+		return job, true
+	}
+	if _, ok := authorizeFound(c, h.logger, h.checker, classroomID, auth.PermManageClassroom, "Job"); !ok {
+		return nil, false
+	}
+	return job, true
+}
@@ -1,86 +1,207 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
+// maxRosterImportSize bounds the size of an uploaded roster file
+const maxRosterImportSize = 10 << 20
+
 // RosterHandler handles roster-related API endpoints
 type RosterHandler struct {
-	logger *zap.Logger
-	// TODO: Add service dependencies
-	// service *service.RosterService
+	logger  *zap.Logger
+	service *service.RosterService
 }
 
 // NewRosterHandler creates a new roster handler
-func NewRosterHandler(logger *zap.Logger) *RosterHandler {
+func NewRosterHandler(svc *service.RosterService, logger *zap.Logger) *RosterHandler {
 	return &RosterHandler{
-		logger: logger,
+		logger:  logger,
+		service: svc,
 	}
 }
 
 // RegisterRosterRoutes registers roster routes with the router group
-func RegisterRosterRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
-	handler := NewRosterHandler(logger)
+func RegisterRosterRoutes(rg *gin.RouterGroup, svc *service.RosterService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewRosterHandler(svc, logger)
+	canManage := requireClassroomPermission(checker, auth.PermManageClassroom, "id", logger)
+	// The roster holds student emails, so only staff may list it
+	canGrade := requireClassroomPermission(checker, auth.PermGradeAssignments, "id", logger)
 
-	rosters := rg.Group("/classrooms/:classroom_id/roster")
+	rosters := rg.Group("/classrooms/:id/roster")
 	{
-		rosters.POST("/students", handler.AddStudent)
-		rosters.GET("/students", handler.ListStudents)
-		rosters.POST("/students/:student_id/link", handler.LinkStudent)
-		rosters.POST("/import", handler.ImportRoster)
+		rosters.POST("/students", canManage, handler.AddStudent)
+		rosters.GET("/students", canGrade, handler.ListStudents)
+		rosters.POST("/students/:student_id/link", canManage, handler.LinkStudent)
+		rosters.POST("/bulk", canManage, handler.BulkRoster)
+		rosters.POST("/import", canManage, handler.ImportRoster)
 	}
 }
 
-// AddStudent handles POST /api/v1/classrooms/:classroom_id/roster/students
+// AddStudent handles POST /api/v1/classrooms/:id/roster/students
 func (h *RosterHandler) AddStudent(c *gin.Context) {
-	classroomID := c.Param("classroom_id")
-	h.logger.Info("Adding student to roster", zap.String("classroom_id", classroomID))
-
-	// TODO: Implement student addition
-	response.RespondWithData(c, http.StatusCreated, gin.H{
-		"message": "Student addition not yet implemented",
-		"todo":    "Parse request, validate student data, call service layer",
-	})
+	h.logger.Info("Adding student to roster", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.AddStudentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	entry, err := h.service.Add(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	response.RespondWithData(c, http.StatusCreated, entry)
 }
 
-// ListStudents handles GET /api/v1/classrooms/:classroom_id/roster/students
+// ListStudents handles GET /api/v1/classrooms/:id/roster/students
 func (h *RosterHandler) ListStudents(c *gin.Context) {
-	classroomID := c.Param("classroom_id")
-	h.logger.Info("Listing roster students", zap.String("classroom_id", classroomID))
-
-	// TODO: Implement student listing
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Student listing not yet implemented",
-		"todo":    "Parse filters, call service layer, return paginated results",
-	})
+	h.logger.Info("Listing roster students", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.RosterListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	list, err := h.service.List(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Students,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
 }
 
-// LinkStudent handles POST /api/v1/classrooms/:classroom_id/roster/students/:student_id/link
+// LinkStudent handles POST /api/v1/classrooms/:id/roster/students/:student_id/link
 func (h *RosterHandler) LinkStudent(c *gin.Context) {
-	classroomID := c.Param("classroom_id")
-	studentID := c.Param("student_id")
-	h.logger.Info("Linking student account", zap.String("classroom_id", classroomID), zap.String("student_id", studentID))
-
-	// TODO: Implement account linking
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Account linking not yet implemented",
-		"todo":    "Parse forgejo username, validate, call service layer",
-	})
+	h.logger.Info("Linking student account",
+		zap.String("classroom_id", c.Param("id")),
+		zap.String("student_id", c.Param("student_id")),
+		zap.String("request_id", c.GetString("request_id")),
+	)
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.LinkStudentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	entry, err := h.service.Link(c.Request.Context(), classroomID, c.Param("student_id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	response.RespondWithData(c, http.StatusOK, entry)
 }
 
-// ImportRoster handles POST /api/v1/classrooms/:classroom_id/roster/import
+// BulkRoster handles POST /api/v1/classrooms/:id/roster/bulk
+func (h *RosterHandler) BulkRoster(c *gin.Context) {
+	h.logger.Info("Applying bulk roster operations", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.BulkRosterRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.Bulk(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	response.RespondWithData(c, http.StatusOK, result)
+}
+
+// ImportRoster handles POST /api/v1/classrooms/:id/roster/import
+//
+// The CSV file is sent as the multipart field "file", with the dry_run,
+// update and delimiter options as form fields. Small files are imported
+// right away (200); larger ones are queued as a job (202).
 func (h *RosterHandler) ImportRoster(c *gin.Context) {
-	classroomID := c.Param("classroom_id")
-	h.logger.Info("Importing roster", zap.String("classroom_id", classroomID))
-
-	// TODO: Implement roster import
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Roster import not yet implemented",
-		"todo":    "Parse CSV file, validate data, bulk import students",
-	})
+	h.logger.Info("Importing roster", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRosterImportSize)
+
+	var req model.ImportRosterRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			response.BadRequest(c, response.ErrValidationMissingField, "file is required",
+				map[string]interface{}{"field": "file"})
+			return
+		}
+		respondUploadError(c, err)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Import(c.Request.Context(), classroomID, file, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.JobID != nil {
+		status = http.StatusAccepted
+		result.StatusURL = fmt.Sprintf("/api/v1/jobs/%d", *result.JobID)
+	}
+	response.RespondWithData(c, status, result)
+}
+
+// respondUploadError reports an unreadable multipart upload
+func respondUploadError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		response.RespondWithError(c, http.StatusRequestEntityTooLarge, response.ErrValidationTooLong, "File is too large",
+			map[string]interface{}{"max_bytes": tooLarge.Limit})
+		return
+	}
+	response.BadRequest(c, response.ErrValidationInvalidInput, "Invalid upload",
+		map[string]interface{}{"error": err.Error()})
 }
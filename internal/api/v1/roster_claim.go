@@ -0,0 +1,233 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// RosterClaimHandler handles classroom join links and the roster claims
+// students make through them
+type RosterClaimHandler struct {
+	logger    *zap.Logger
+	service   *service.RosterService
+	publicURL string
+}
+
+// NewRosterClaimHandler creates a new roster claim handler. Join link URLs
+// are built on publicURL, and omitted when it is empty.
+func NewRosterClaimHandler(svc *service.RosterService, publicURL string, logger *zap.Logger) *RosterClaimHandler {
+	return &RosterClaimHandler{
+		logger:    logger,
+		service:   svc,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+// RegisterRosterClaimRoutes registers the routes that manage the join link
+// and claims of a classroom with the authenticated router group rg, and the
+// routes that use join link tokens with public, which must not require
+// authentication: anyone with a token may see its classroom, and claiming
+// authenticates with authn.
+func RegisterRosterClaimRoutes(rg, public *gin.RouterGroup, svc *service.RosterService, checker *auth.Checker,
+	publicURL string, authn gin.HandlerFunc, logger *zap.Logger) {
+	handler := NewRosterClaimHandler(svc, publicURL, logger)
+	// Claims link Forgejo accounts to the roster like bulk link operations
+	canManage := requireClassroomPermission(checker, auth.PermManageClassroom, "id", logger)
+
+	roster := rg.Group("/classrooms/:id/roster", canManage)
+	{
+		roster.GET("/join-link", handler.GetJoinLink)
+		roster.PUT("/join-link", handler.UpdateJoinLink)
+		roster.POST("/join-link/rotate", handler.RotateJoinLink)
+		roster.GET("/claims", handler.ListClaims)
+		roster.POST("/claims/:claim_id/approve", handler.ApproveClaim)
+		roster.POST("/claims/:claim_id/reject", handler.RejectClaim)
+	}
+
+	join := public.Group("/join")
+	{
+		join.GET("/:token", handler.ResolveJoinLink)
+		join.POST("/:token/claim", authn, handler.Claim)
+	}
+}
+
+// GetJoinLink handles GET /api/v1/classrooms/:id/roster/join-link
+func (h *RosterClaimHandler) GetJoinLink(c *gin.Context) {
+	h.logger.Info("Getting join link", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	link, err := h.service.GetJoinLink(c.Request.Context(), classroomID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, h.withURL(link))
+}
+
+// RotateJoinLink handles POST /api/v1/classrooms/:id/roster/join-link/rotate
+func (h *RosterClaimHandler) RotateJoinLink(c *gin.Context) {
+	h.logger.Info("Rotating join link", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional; without it, the approval setting is kept
+	var req model.RotateJoinLinkRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	link, err := h.service.RotateJoinLink(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusCreated, h.withURL(link))
+}
+
+// UpdateJoinLink handles PUT /api/v1/classrooms/:id/roster/join-link
+func (h *RosterClaimHandler) UpdateJoinLink(c *gin.Context) {
+	h.logger.Info("Updating join link", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateJoinLinkRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	link, err := h.service.UpdateJoinLink(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, h.withURL(link))
+}
+
+// ListClaims handles GET /api/v1/classrooms/:id/roster/claims
+func (h *RosterClaimHandler) ListClaims(c *gin.Context) {
+	h.logger.Info("Listing roster claims", zap.String("classroom_id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.RosterClaimListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	list, err := h.service.ListClaims(c.Request.Context(), classroomID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Claims,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
+}
+
+// ApproveClaim handles POST /api/v1/classrooms/:id/roster/claims/:claim_id/approve
+func (h *RosterClaimHandler) ApproveClaim(c *gin.Context) {
+	h.decideClaim(c, "Approving roster claim", h.service.ApproveClaim)
+}
+
+// RejectClaim handles POST /api/v1/classrooms/:id/roster/claims/:claim_id/reject
+func (h *RosterClaimHandler) RejectClaim(c *gin.Context) {
+	h.decideClaim(c, "Rejecting roster claim", h.service.RejectClaim)
+}
+
+// decideClaim approves or rejects the claim of the path parameters with decide
+func (h *RosterClaimHandler) decideClaim(c *gin.Context, message string,
+	decide func(ctx context.Context, actor *service.Actor, classroomID, claimID int64) (*model.RosterClaim, error)) {
+	h.logger.Info(message, zap.String("classroom_id", c.Param("id")), zap.String("claim_id", c.Param("claim_id")),
+		zap.String("request_id", c.GetString("request_id")))
+
+	classroomID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	claimID, ok := paramID(c, "claim_id")
+	if !ok {
+		return
+	}
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	claim, err := decide(c.Request.Context(), actor, classroomID, claimID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, claim)
+}
+
+// ResolveJoinLink handles GET /api/v1/join/:token
+func (h *RosterClaimHandler) ResolveJoinLink(c *gin.Context) {
+	h.logger.Info("Resolving join link", zap.String("request_id", c.GetString("request_id")))
+
+	details, err := h.service.ResolveJoinLink(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusOK, details)
+}
+
+// Claim handles POST /api/v1/join/:token/claim. New claims answer 201
+// whether they were approved right away or wait for approval.
+func (h *RosterClaimHandler) Claim(c *gin.Context) {
+	h.logger.Info("Claiming roster entry", zap.String("request_id", c.GetString("request_id")))
+
+	actor, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req model.ClaimRosterRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	claim, err := h.service.Claim(c.Request.Context(), actor, c.Param("token"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithData(c, http.StatusCreated, claim)
+}
+
+// withURL sets the URL students open to resolve the join link
+func (h *RosterClaimHandler) withURL(link *model.JoinLink) *model.JoinLink {
+	if h.publicURL != "" {
+		link.URL = h.publicURL + "/api/v1/join/" + link.Token
+	}
+	return link
+}
@@ -1,31 +1,40 @@
 package v1
 
 import (
+	"mime"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/model"
 	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
 )
 
 // SubmissionHandler handles submission-related API endpoints
 type SubmissionHandler struct {
-	logger *zap.Logger
-	// TODO: Add service dependencies
-	// service *service.SubmissionService
+	logger      *zap.Logger
+	service     *service.SubmissionService
+	assignments *service.AssignmentService
+	checker     *auth.Checker
 }
 
 // NewSubmissionHandler creates a new submission handler
-func NewSubmissionHandler(logger *zap.Logger) *SubmissionHandler {
+func NewSubmissionHandler(svc *service.SubmissionService, assignments *service.AssignmentService, checker *auth.Checker, logger *zap.Logger) *SubmissionHandler {
 	return &SubmissionHandler{
-		logger: logger,
+		logger:      logger,
+		service:     svc,
+		assignments: assignments,
+		checker:     checker,
 	}
 }
 
 // RegisterSubmissionRoutes registers submission routes with the router group
-func RegisterSubmissionRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
-	handler := NewSubmissionHandler(logger)
+func RegisterSubmissionRoutes(rg *gin.RouterGroup, svc *service.SubmissionService, assignments *service.AssignmentService, checker *auth.Checker, logger *zap.Logger) {
+	handler := NewSubmissionHandler(svc, assignments, checker, logger)
 
 	submissions := rg.Group("/submissions")
 	{
@@ -35,70 +44,167 @@ func RegisterSubmissionRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 	}
 
 	// Assignment-specific submissions
-	assignmentSubmissions := rg.Group("/assignments/:assignment_id/submissions")
+	assignmentSubmissions := rg.Group("/assignments/:id/submissions")
 	{
 		assignmentSubmissions.GET("", handler.ListAssignmentSubmissions)
 		assignmentSubmissions.GET("/download", handler.DownloadAllSubmissions)
 	}
 }
 
-// ListSubmissions handles GET /api/v1/submissions
+// ListSubmissions handles GET /api/v1/submissions?assignment_id=...
 func (h *SubmissionHandler) ListSubmissions(c *gin.Context) {
-	h.logger.Info("Listing submissions")
+	h.logger.Info("Listing submissions", zap.String("request_id", c.GetString("request_id")))
 
-	// TODO: Implement submission listing
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Submission listing not yet implemented",
-		"todo":    "Parse filters, call service layer, return paginated results",
-	})
+	var req model.SubmissionListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if req.AssignmentID == nil {
+		response.BadRequest(c, response.ErrValidationMissingField, "assignment_id is required",
+			map[string]interface{}{"field": "assignment_id"})
+		return
+	}
+
+	h.list(c, *req.AssignmentID, &req)
 }
 
 // GetSubmission handles GET /api/v1/submissions/:id
 func (h *SubmissionHandler) GetSubmission(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Getting submission", zap.String("id", id))
-
-	// TODO: Implement submission retrieval
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Submission retrieval not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, call service layer, return submission details",
-	})
+	h.logger.Info("Getting submission", zap.String("id", c.Param("id")), zap.String("request_id", c.GetString("request_id")))
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	submission, err := h.service.Get(ctx, id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	assignment, err := h.assignments.Get(ctx, submission.AssignmentID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	m, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, auth.PermViewSubmissions, "Submission")
+	if !ok {
+		return
+	}
+	if m.IsStudent() {
+		own, err := h.service.BelongsTo(ctx, submission, m.RosterEntry.ID)
+		if err != nil {
+			respondError(c, h.logger, err)
+			return
+		}
+		// Other students' submissions are hidden rather than forbidden
+		if !own {
+			response.NotFound(c, response.ErrResourceNotFound, "Submission not found")
+			return
+		}
+	}
+
+	response.RespondWithData(c, http.StatusOK, submission)
 }
 
-// DownloadSubmission handles GET /api/v1/submissions/:id/download
+// DownloadSubmission handles GET /api/v1/submissions/:id/download, which is
+// not supported: submissions are downloaded per assignment
 func (h *SubmissionHandler) DownloadSubmission(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Downloading submission", zap.String("id", id))
-
-	// TODO: Implement submission download
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Submission download not yet implemented",
-		"id":      id,
-		"todo":    "Generate archive, stream file response",
-	})
+	response.NotSupported(c, "Downloading a single submission is not supported by this server; "+
+		"use GET /api/v1/assignments/:id/submissions/download")
 }
 
-// ListAssignmentSubmissions handles GET /api/v1/assignments/:assignment_id/submissions
+// ListAssignmentSubmissions handles GET /api/v1/assignments/:id/submissions
 func (h *SubmissionHandler) ListAssignmentSubmissions(c *gin.Context) {
-	assignmentID := c.Param("assignment_id")
-	h.logger.Info("Listing assignment submissions", zap.String("assignment_id", assignmentID))
-
-	// TODO: Implement assignment submissions listing
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment submissions listing not yet implemented",
-		"todo":    "Parse filters, call service layer, return submissions",
-	})
+	h.logger.Info("Listing assignment submissions", zap.String("assignment_id", c.Param("id")))
+
+	assignmentID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.SubmissionListRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	h.list(c, assignmentID, &req)
 }
 
-// DownloadAllSubmissions handles GET /api/v1/assignments/:assignment_id/submissions/download
+// list responds with the assignment's submissions visible to the caller:
+// all of them for staff, and only their own (or their team's) for students
+func (h *SubmissionHandler) list(c *gin.Context, assignmentID int64, req *model.SubmissionListRequest) {
+	ctx := c.Request.Context()
+	assignment, err := h.assignments.Get(ctx, assignmentID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	m, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, auth.PermViewSubmissions, "Assignment")
+	if !ok {
+		return
+	}
+
+	req.AssignmentID = &assignmentID
+	req.MemberID = nil
+	if m.IsStudent() {
+		req.MemberID = &m.RosterEntry.ID
+	}
+
+	list, err := h.service.List(ctx, req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	response.RespondWithSuccess(c, http.StatusOK, list.Submissions,
+		pageMeta(list.Page, list.PerPage, list.TotalPages, list.Total))
+}
+
+// DownloadAllSubmissions handles GET /api/v1/assignments/:id/submissions/download
+// and streams an archive with one folder per student or team
 func (h *SubmissionHandler) DownloadAllSubmissions(c *gin.Context) {
-	assignmentID := c.Param("assignment_id")
-	h.logger.Info("Downloading all assignment submissions", zap.String("assignment_id", assignmentID))
-
-	// TODO: Implement bulk submission download
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Bulk submission download not yet implemented",
-		"todo":    "Generate bulk archive, stream response",
-	})
+	h.logger.Info("Downloading all assignment submissions", zap.String("assignment_id", c.Param("id")))
+
+	assignmentID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req model.SubmissionDownloadRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	assignment, err := h.assignments.Get(ctx, assignmentID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	if _, ok := authorizeFound(c, h.logger, h.checker, assignment.ClassroomID, auth.PermGradeAssignments, "Assignment"); !ok {
+		return
+	}
+
+	archive, err := h.service.Archive(ctx, assignmentID, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	// Archives of large classes take longer than the server write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", archive.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archive.Filename}))
+	c.Status(http.StatusOK)
+
+	if err := archive.Write(ctx, c.Writer); err != nil {
+		h.logger.Error("Submission download failed",
+			zap.Int64("assignment_id", assignmentID),
+			zap.Error(err),
+		)
+		abortResponse(c)
+	}
 }
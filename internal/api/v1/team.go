@@ -1,19 +1,22 @@
 package v1
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"code.forgejo.org/forgejo/classroom/internal/response"
 )
 
-// TeamHandler handles team-related API endpoints
+// teamsUnsupported explains how teams are formed while the team endpoints
+// are not supported
+const teamsUnsupported = "Team management is not supported by this server; " +
+	"students form and join teams by accepting a team assignment with a team name"
+
+// TeamHandler handles team-related API endpoints. Teams are created and
+// joined by accepting team assignments, so these endpoints only respond
+// with SYSTEM_NOT_SUPPORTED for now.
 type TeamHandler struct {
 	logger *zap.Logger
-	// TODO: Add service dependencies
-	// service *service.TeamService
 }
 
 // NewTeamHandler creates a new team handler
@@ -29,77 +32,20 @@ func RegisterTeamRoutes(rg *gin.RouterGroup, logger *zap.Logger) {
 
 	teams := rg.Group("/teams")
 	{
-		teams.POST("", handler.CreateTeam)
-		teams.GET("/:id", handler.GetTeam)
-		teams.POST("/:id/join", handler.JoinTeam)
-		teams.POST("/:id/leave", handler.LeaveTeam)
+		teams.POST("", handler.unsupported)
+		teams.GET("/:id", handler.unsupported)
+		teams.POST("/:id/join", handler.unsupported)
+		teams.POST("/:id/leave", handler.unsupported)
 	}
 
 	// Assignment-specific teams
-	assignmentTeams := rg.Group("/assignments/:assignment_id/teams")
+	assignmentTeams := rg.Group("/assignments/:id/teams")
 	{
-		assignmentTeams.GET("", handler.ListAssignmentTeams)
+		assignmentTeams.GET("", handler.unsupported)
 	}
 }
 
-// CreateTeam handles POST /api/v1/teams
-func (h *TeamHandler) CreateTeam(c *gin.Context) {
-	h.logger.Info("Creating team")
-
-	// TODO: Implement team creation
-	response.RespondWithData(c, http.StatusCreated, gin.H{
-		"message": "Team creation not yet implemented",
-		"todo":    "Parse request, validate assignment, call service layer",
-	})
-}
-
-// GetTeam handles GET /api/v1/teams/:id
-func (h *TeamHandler) GetTeam(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Getting team", zap.String("id", id))
-
-	// TODO: Implement team retrieval
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Team retrieval not yet implemented",
-		"id":      id,
-		"todo":    "Validate ID, call service layer, return team details",
-	})
-}
-
-// JoinTeam handles POST /api/v1/teams/:id/join
-func (h *TeamHandler) JoinTeam(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Joining team", zap.String("id", id))
-
-	// TODO: Implement team joining
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Team joining not yet implemented",
-		"id":      id,
-		"todo":    "Validate team size, check eligibility, call service layer",
-	})
-}
-
-// LeaveTeam handles POST /api/v1/teams/:id/leave
-func (h *TeamHandler) LeaveTeam(c *gin.Context) {
-	id := c.Param("id")
-	h.logger.Info("Leaving team", zap.String("id", id))
-
-	// TODO: Implement team leaving
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Team leaving not yet implemented",
-		"id":      id,
-		"todo":    "Validate membership, handle leadership transfer, call service layer",
-	})
-}
-
-// ListAssignmentTeams handles GET /api/v1/assignments/:assignment_id/teams
-func (h *TeamHandler) ListAssignmentTeams(c *gin.Context) {
-	assignmentID := c.Param("assignment_id")
-	h.logger.Info("Listing assignment teams", zap.String("assignment_id", assignmentID))
-
-	// TODO: Implement assignment teams listing
-	response.RespondWithData(c, http.StatusOK, gin.H{
-		"message": "Assignment teams listing not yet implemented",
-		"todo":    "Parse filters, call service layer, return teams",
-	})
+// unsupported responds to every team endpoint with 501
+func (h *TeamHandler) unsupported(c *gin.Context) {
+	response.NotSupported(c, teamsUnsupported)
 }
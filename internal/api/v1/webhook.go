@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/service"
+)
+
+// maxWebhookBody bounds the size of webhook payloads
+const maxWebhookBody = 10 << 20
+
+// WebhookHandler receives the webhooks of Forgejo repositories. Requests
+// are authenticated by their HMAC signature rather than a token.
+type WebhookHandler struct {
+	logger  *zap.Logger
+	service *service.WebhookService
+	secret  []byte
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(svc *service.WebhookService, secret string, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		logger:  logger,
+		service: svc,
+		secret:  []byte(secret),
+	}
+}
+
+// RegisterWebhookRoutes registers webhook routes with the router group.
+// The group must not require authentication.
+func RegisterWebhookRoutes(rg *gin.RouterGroup, svc *service.WebhookService, secret string, logger *zap.Logger) {
+	handler := NewWebhookHandler(svc, secret, logger)
+
+	rg.POST("/forgejo", handler.HandleForgejo)
+}
+
+// HandleForgejo handles POST /api/v1/webhooks/forgejo
+func (h *WebhookHandler) HandleForgejo(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		response.BadRequest(c, response.ErrValidationInvalidInput, "Invalid request body",
+			map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if !forgejo.VerifySignature(h.secret, body, c.GetHeader(forgejo.HeaderSignature)) {
+		h.logger.Warn("Rejected webhook with invalid signature",
+			zap.String("delivery", c.GetHeader(forgejo.HeaderDelivery)),
+			zap.String("client_ip", c.ClientIP()),
+		)
+		response.Unauthorized(c, response.ErrAuthInvalidSignature, response.GetErrorMessage(response.ErrAuthInvalidSignature))
+		return
+	}
+
+	event := c.GetHeader(forgejo.HeaderEvent)
+	if event != forgejo.HookEventPush {
+		response.RespondWithData(c, http.StatusOK, &model.PushResult{Ignored: "event is not a push"})
+		return
+	}
+
+	var payload forgejo.PushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		response.BadRequest(c, response.ErrValidationInvalidInput, "Invalid push payload",
+			map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.HandlePush(c.Request.Context(), &payload)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	h.logger.Info("Push received",
+		zap.String("delivery", c.GetHeader(forgejo.HeaderDelivery)),
+		zap.String("ref", payload.Ref),
+		zap.String("after", payload.After),
+		zap.String("ignored", result.Ignored),
+	)
+	response.RespondWithData(c, http.StatusOK, result)
+}
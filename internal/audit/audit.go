@@ -0,0 +1,108 @@
+// Package audit carries the origin of a request (who made it, from where,
+// under which request ID) through contexts down to the services that record
+// audit events, and computes the field changes those events store.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Origin tells who made a change and through which request. Changes made
+// by the server itself, such as deadline enforcement, have no actor.
+type Origin struct {
+	ActorID    int64  `json:"actor_id,omitempty"`
+	ActorLogin string `json:"actor_login,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	IP         string `json:"ip,omitempty"`
+}
+
+type contextKey struct{}
+
+// OriginFrom returns the origin stored in ctx, or the zero Origin
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(contextKey{}).(Origin)
+	return origin
+}
+
+// WithOrigin returns a copy of ctx carrying origin. Background jobs use it
+// to record changes under the request that enqueued them.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, contextKey{}, origin)
+}
+
+// WithRequest returns a copy of ctx carrying the request ID and client IP
+func WithRequest(ctx context.Context, requestID, ip string) context.Context {
+	origin := OriginFrom(ctx)
+	origin.RequestID = requestID
+	origin.IP = ip
+	return WithOrigin(ctx, origin)
+}
+
+// WithActor returns a copy of ctx carrying the authenticated Forgejo user
+func WithActor(ctx context.Context, id int64, login string) context.Context {
+	origin := OriginFrom(ctx)
+	origin.ActorID = id
+	origin.ActorLogin = login
+	return WithOrigin(ctx, origin)
+}
+
+// Change is the value of a field before and after a change. Before is nil
+// for created targets and After is nil for deleted ones.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ignored lists the fields left out of diffs: timestamps that change on
+// every write, and secrets and values derived from them
+var ignored = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"token":      true,
+	"url":        true,
+}
+
+// Diff returns the top-level JSON fields that differ between before and
+// after, which are structs or pointers to structs; either may be nil. Fields
+// missing on one side (omitempty) are reported as nil there.
+func Diff(before, after interface{}) map[string]Change {
+	b, a := fields(before), fields(after)
+
+	changes := make(map[string]Change)
+	for name, value := range b {
+		if !reflect.DeepEqual(value, a[name]) {
+			changes[name] = Change{Before: value, After: a[name]}
+		}
+	}
+	for name, value := range a {
+		if _, ok := b[name]; !ok && value != nil {
+			changes[name] = Change{After: value}
+		}
+	}
+	return changes
+}
+
+// fields returns the JSON fields of v, except the ignored ones
+func fields(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	for name := range ignored {
+		delete(m, name)
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type target struct {
+	Name      string    `json:"name"`
+	Email     *string   `json:"email,omitempty"`
+	Count     int       `json:"count"`
+	Token     string    `json:"token"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	email := "john@example.com"
+	before := &target{Name: "John", Count: 1, Token: "old", UpdatedAt: time.Unix(1, 0)}
+	after := &target{Name: "John Doe", Email: &email, Count: 1, Token: "new", UpdatedAt: time.Unix(2, 0)}
+
+	t.Run("changed fields only", func(t *testing.T) {
+		assert.Equal(t, map[string]Change{
+			"name":  {Before: "John", After: "John Doe"},
+			"email": {After: email},
+		}, Diff(before, after))
+	})
+
+	t.Run("created targets", func(t *testing.T) {
+		changes := Diff(nil, after)
+		assert.Equal(t, Change{After: "John Doe"}, changes["name"])
+		assert.Equal(t, Change{After: float64(1)}, changes["count"])
+		assert.NotContains(t, changes, "token")
+		assert.NotContains(t, changes, "updated_at")
+	})
+
+	t.Run("deleted targets", func(t *testing.T) {
+		var none *target
+		changes := Diff(before, none)
+		assert.Equal(t, Change{Before: "John"}, changes["name"])
+		assert.NotContains(t, changes, "email")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, Diff(before, before))
+	})
+}
+
+func TestOrigin(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Origin{}, OriginFrom(ctx))
+
+	ctx = WithRequest(ctx, "req_1", "192.0.2.1")
+	ctx = WithActor(ctx, 7, "jdoe")
+	assert.Equal(t, Origin{ActorID: 7, ActorLogin: "jdoe", RequestID: "req_1", IP: "192.0.2.1"}, OriginFrom(ctx))
+}
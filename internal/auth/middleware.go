@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// Gin context keys set for authenticated requests
+const (
+	ContextUserID    = "user_id"    // int64 Forgejo user ID
+	ContextUserLogin = "user_login" // Forgejo login name
+	ContextUserAdmin = "user_admin" // bool, Forgejo site administrator
+)
+
+// Middleware authenticates requests with a Forgejo personal access token
+// passed as "Authorization: token <token>" or "Authorization: Bearer <token>"
+// and stores the resolved user in the gin context, and in the request
+// context for the audit log.
+func Middleware(validator *TokenValidator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			abort(c, http.StatusUnauthorized, response.ErrAuthMissingToken)
+			return
+		}
+
+		token, ok := parseAuthorization(header)
+		if !ok {
+			abort(c, http.StatusUnauthorized, response.ErrAuthInvalidToken)
+			return
+		}
+
+		user, err := validator.Validate(c.Request.Context(), token)
+		if errors.Is(err, ErrInvalidToken) {
+			abort(c, http.StatusUnauthorized, response.ErrAuthInvalidToken)
+			return
+		}
+		if err != nil {
+			code := response.ErrIntegrationForgejoUnavailable
+			var fjErr *forgejo.Error
+			if errors.As(err, &fjErr) {
+				code = fjErr.Code
+			}
+			logger.Warn("Token validation failed", zap.String("code", code), zap.Error(err))
+			abort(c, response.StatusForCode(code), code)
+			return
+		}
+
+		c.Set(ContextUserID, user.ID)
+		c.Set(ContextUserLogin, user.Login)
+		c.Set(ContextUserAdmin, user.IsAdmin)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), user.ID, user.Login))
+		c.Next()
+	}
+}
+
+// parseAuthorization extracts the token from a "token" or "Bearer"
+// Authorization header
+func parseAuthorization(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return "", false
+	}
+	if !strings.EqualFold(scheme, "token") && !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// abort responds with the standard message for code and stops the chain
+func abort(c *gin.Context, status int, code string) {
+	response.RespondWithError(c, status, code, response.GetErrorMessage(code), nil)
+	c.Abort()
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo/forgejotest"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func newTestRouter(t *testing.T, validator *TokenValidator) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware(validator, zap.NewNop()))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"id":    c.GetInt64(ContextUserID),
+			"login": c.GetString(ContextUserLogin),
+		})
+	})
+	return router
+}
+
+func serve(router *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+func countRequests(server *forgejotest.Server, path string) int {
+	n := 0
+	for _, r := range server.Requests() {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMiddleware(t *testing.T) {
+	server := forgejotest.NewServer(t)
+	student := server.AddUser("jdoe", "jdoe-token")
+	validator := NewTokenValidator(server.Client(t), &config.AuthConfig{TokenCacheTTL: time.Minute, TokenCacheSize: 10})
+	router := newTestRouter(t, validator)
+
+	t.Run("token scheme", func(t *testing.T) {
+		rec := serve(router, "token jdoe-token")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			ID    int64  `json:"id"`
+			Login string `json:"login"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, student.ID, body.ID)
+		assert.Equal(t, "jdoe", body.Login)
+	})
+
+	t.Run("bearer scheme", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(router, "Bearer jdoe-token").Code)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		code   string
+	}{
+		{"missing header", "", http.StatusUnauthorized, response.ErrAuthMissingToken},
+		{"unsupported scheme", "Basic amRvZTpzZWNyZXQ=", http.StatusUnauthorized, response.ErrAuthInvalidToken},
+		{"empty token", "token ", http.StatusUnauthorized, response.ErrAuthInvalidToken},
+		{"unknown token", "token nope", http.StatusUnauthorized, response.ErrAuthInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, tt.header)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, errorCode(t, rec))
+		})
+	}
+
+	t.Run("forgejo unavailable", func(t *testing.T) {
+		server.AddUser("other", "other-token")
+		server.FailNext(http.MethodGet, "/user", http.StatusServiceUnavailable, 1)
+
+		rec := serve(router, "token other-token")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, response.ErrIntegrationForgejoUnavailable, errorCode(t, rec))
+	})
+}
+
+func TestTokenValidator_Cache(t *testing.T) {
+	server := forgejotest.NewServer(t)
+	server.AddUser("alice", "alice-token")
+	server.AddUser("bob", "bob-token")
+	server.AddUser("carol", "carol-token")
+
+	now := time.Now()
+	validator := NewTokenValidator(server.Client(t), &config.AuthConfig{
+		TokenCacheTTL: time.Minute, TokenCacheSize: 2, InvalidTokenCacheTTL: 10 * time.Second,
+	})
+	validator.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("valid tokens are cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			user, err := validator.Validate(ctx, "alice-token")
+			require.NoError(t, err)
+			assert.Equal(t, "alice", user.Login)
+		}
+		assert.Equal(t, 1, countRequests(server, "/user"))
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		_, err := validator.Validate(ctx, "alice-token")
+		require.NoError(t, err)
+		assert.Equal(t, 2, countRequests(server, "/user"))
+	})
+
+	t.Run("cache is bounded", func(t *testing.T) {
+		now = now.Add(time.Second)
+		_, err := validator.Validate(ctx, "bob-token")
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		_, err = validator.Validate(ctx, "carol-token")
+		require.NoError(t, err)
+
+		assert.Len(t, validator.entries, 2)
+
+		// alice was the oldest entry and has been evicted
+		_, err = validator.Validate(ctx, "alice-token")
+		require.NoError(t, err)
+		assert.Equal(t, 5, countRequests(server, "/user"))
+	})
+
+	t.Run("rejected tokens are cached briefly", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := validator.Validate(ctx, "nope")
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}
+		assert.Equal(t, 6, countRequests(server, "/user"))
+
+		now = now.Add(11 * time.Second)
+		_, err := validator.Validate(ctx, "nope")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		assert.Equal(t, 7, countRequests(server, "/user"))
+	})
+
+	t.Run("rejections do not evict valid tokens", func(t *testing.T) {
+		for _, token := range []string{"bad-1", "bad-2", "bad-3"} {
+			_, err := validator.Validate(ctx, token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}
+		assert.Len(t, validator.rejected, 2)
+		assert.Len(t, validator.entries, 2)
+	})
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+)
+
+// Permission is an action a caller may take in a classroom
+type Permission string
+
+// Classroom permissions
+const (
+	PermViewClassroom     Permission = "classroom:view"
+	PermManageClassroom   Permission = "classroom:manage"
+	PermManageAssignments Permission = "assignments:manage"
+	PermGradeAssignments  Permission = "assignments:grade"
+	PermViewSubmissions   Permission = "submissions:view"
+	PermAcceptAssignment  Permission = "assignments:accept"
+)
+
+// Role is the caller's relationship to a classroom
+type Role string
+
+// Classroom roles. The student, assistant and instructor roles come from
+// the caller's roster entry; the owner is the classroom's creator and admin
+// is a Forgejo site administrator who is neither.
+const (
+	RoleOwner      Role = "owner"
+	RoleAdmin      Role = "admin"
+	RoleInstructor Role = "instructor"
+	RoleAssistant  Role = "assistant"
+	RoleStudent    Role = "student"
+)
+
+// rolePermissions lists what each role may do. Assistants manage and grade
+// assignments but cannot change or delete the classroom; students may view
+// the classroom, accept assignments and view their own submissions.
+var rolePermissions = map[Role][]Permission{
+	RoleOwner:      {PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions},
+	RoleAdmin:      {PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions},
+	RoleInstructor: {PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions},
+	RoleAssistant:  {PermViewClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions},
+	RoleStudent:    {PermViewClassroom, PermAcceptAssignment, PermViewSubmissions},
+}
+
+// Authorization errors
+var (
+	ErrForbidden               = errors.New("not a member of this classroom")
+	ErrInsufficientPermissions = errors.New("insufficient permissions")
+)
+
+// Membership is the caller's role in a classroom
+type Membership struct {
+	ClassroomID int64
+	Role        Role
+	RosterEntry *model.RosterEntry // nil unless the role comes from the roster
+}
+
+// Can reports whether the role grants perm
+func (m *Membership) Can(perm Permission) bool {
+	for _, p := range rolePermissions[m.Role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStudent reports whether the caller is limited to their own work
+func (m *Membership) IsStudent() bool {
+	return m.Role == RoleStudent
+}
+
+// Checker resolves callers' roles in classrooms
+type Checker struct {
+	repos *repository.Repositories
+}
+
+// NewChecker creates a permission checker
+func NewChecker(repos *repository.Repositories) *Checker {
+	return &Checker{repos: repos}
+}
+
+// Membership returns the role of the Forgejo user userID in the classroom.
+// It returns ErrForbidden if the user has no role there and
+// repository.ErrNotFound if the classroom does not exist.
+func (c *Checker) Membership(ctx context.Context, userID int64, siteAdmin bool, classroomID int64) (*Membership, error) {
+	classroom, err := c.repos.Classrooms.GetByID(ctx, classroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if classroom.InstructorID == userID {
+		return &Membership{ClassroomID: classroomID, Role: RoleOwner}, nil
+	}
+	if siteAdmin {
+		return &Membership{ClassroomID: classroomID, Role: RoleAdmin}, nil
+	}
+
+	entry, err := c.repos.Roster.GetByForgejoUserID(ctx, classroomID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrForbidden
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Membership{ClassroomID: classroomID, Role: Role(entry.Role), RosterEntry: entry}, nil
+}
+
+// Authorize returns the caller's membership if it grants perm, and
+// ErrInsufficientPermissions if it does not
+func (c *Checker) Authorize(ctx context.Context, userID int64, siteAdmin bool, classroomID int64, perm Permission) (*Membership, error) {
+	m, err := c.Membership(ctx, userID, siteAdmin, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Can(perm) {
+		return nil, ErrInsufficientPermissions
+	}
+	return m, nil
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembership_Can(t *testing.T) {
+	all := []Permission{
+		PermViewClassroom, PermManageClassroom, PermManageAssignments,
+		PermGradeAssignments, PermViewSubmissions, PermAcceptAssignment,
+	}
+
+	tests := []struct {
+		role    Role
+		allowed []Permission
+	}{
+		{RoleOwner, []Permission{PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions}},
+		{RoleAdmin, []Permission{PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions}},
+		{RoleInstructor, []Permission{PermViewClassroom, PermManageClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions}},
+		{RoleAssistant, []Permission{PermViewClassroom, PermManageAssignments, PermGradeAssignments, PermViewSubmissions}},
+		{RoleStudent, []Permission{PermViewClassroom, PermAcceptAssignment, PermViewSubmissions}},
+		{Role("unknown"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			m := &Membership{Role: tt.role}
+			for _, perm := range all {
+				assert.Equal(t, contains(tt.allowed, perm), m.Can(perm), "permission %s", perm)
+			}
+		})
+	}
+}
+
+func contains(perms []Permission, perm Permission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
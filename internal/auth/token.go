@@ -0,0 +1,172 @@
+// Package auth authenticates API callers with their Forgejo personal access
+// tokens.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+)
+
+// ErrInvalidToken is returned when Forgejo rejects a token
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenValidator resolves tokens to Forgejo users by calling /user with the
+// token. Valid tokens are cached for a bounded time so that every API
+// request does not cost a round trip to Forgejo; a revoked token therefore
+// keeps working for at most the cache TTL. Rejected tokens are remembered
+// too, for a shorter time, so that a client retrying a bad token does not
+// reach Forgejo on every request.
+type TokenValidator struct {
+	forgejo     *forgejo.Client
+	ttl         time.Duration
+	rejectedTTL time.Duration
+	maxEntries  int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	entries  map[[sha256.Size]byte]cachedUser // keyed by token hash
+	rejected map[[sha256.Size]byte]time.Time  // expiry by token hash
+}
+
+type cachedUser struct {
+	user    forgejo.User
+	expires time.Time
+}
+
+// NewTokenValidator creates a validator that checks tokens against fj
+func NewTokenValidator(fj *forgejo.Client, cfg *config.AuthConfig) *TokenValidator {
+	return &TokenValidator{
+		forgejo:     fj,
+		ttl:         cfg.TokenCacheTTL,
+		rejectedTTL: cfg.InvalidTokenCacheTTL,
+		maxEntries:  cfg.TokenCacheSize,
+		now:         time.Now,
+		entries:     make(map[[sha256.Size]byte]cachedUser),
+		rejected:    make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+// Validate returns the user token belongs to. It returns ErrInvalidToken if
+// Forgejo rejects the token and the Forgejo error if it cannot be reached.
+func (v *TokenValidator) Validate(ctx context.Context, token string) (*forgejo.User, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	key := sha256.Sum256([]byte(token))
+	if user, ok := v.lookup(key); ok {
+		return user, nil
+	}
+	if v.isRejected(key) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := v.forgejo.WithToken(token).CurrentUser(ctx)
+	if err != nil {
+		switch forgejo.StatusCode(err) {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			v.reject(key)
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	v.store(key, user)
+	return user, nil
+}
+
+// lookup returns a cached, unexpired user
+func (v *TokenValidator) lookup(key [sha256.Size]byte) (*forgejo.User, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry, ok := v.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !v.now().Before(entry.expires) {
+		delete(v.entries, key)
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+// store caches user, evicting expired entries (and then the entry closest
+// to expiry) when the cache is full
+func (v *TokenValidator) store(key [sha256.Size]byte, user *forgejo.User) {
+	if v.ttl <= 0 || v.maxEntries <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	if _, exists := v.entries[key]; !exists && len(v.entries) >= v.maxEntries {
+		var oldestKey [sha256.Size]byte
+		var oldest time.Time
+		for k, e := range v.entries {
+			if !now.Before(e.expires) {
+				delete(v.entries, k)
+				continue
+			}
+			if oldest.IsZero() || e.expires.Before(oldest) {
+				oldestKey, oldest = k, e.expires
+			}
+		}
+		if len(v.entries) >= v.maxEntries {
+			delete(v.entries, oldestKey)
+		}
+	}
+
+	v.entries[key] = cachedUser{user: *user, expires: now.Add(v.ttl)}
+}
+
+// isRejected reports whether Forgejo rejected the token recently
+func (v *TokenValidator) isRejected(key [sha256.Size]byte) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	expires, ok := v.rejected[key]
+	if !ok {
+		return false
+	}
+	if !v.now().Before(expires) {
+		delete(v.rejected, key)
+		return false
+	}
+	return true
+}
+
+// reject remembers that Forgejo rejected the token. When the cache is full
+// of unexpired rejections, new ones are not remembered, so that clients
+// sending random tokens cannot grow it.
+func (v *TokenValidator) reject(key [sha256.Size]byte) {
+	if v.rejectedTTL <= 0 || v.maxEntries <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	if len(v.rejected) >= v.maxEntries {
+		for k, expires := range v.rejected {
+			if !now.Before(expires) {
+				delete(v.rejected, k)
+			}
+		}
+		if len(v.rejected) >= v.maxEntries {
+			return
+		}
+	}
+	v.rejected[key] = now.Add(v.rejectedTTL)
+}
@@ -0,0 +1,57 @@
+// Package cache stores serialized read results so that hot API reads do not
+// hit the database on every request.
+//
+// Values live in Redis, shared by all server instances. When Redis cannot
+// be reached the cache can fall back to a bounded in-process LRU; the
+// invalidations made meanwhile are replayed once Redis is back, so entries
+// written before the outage do not outlive their updates.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+// ErrMiss is returned by Get when a key is not cached
+var ErrMiss = errors.New("cache miss")
+
+// Cache is a key-value store with per-entry expiry.
+//
+// Patterns passed to DeletePattern may contain "*" wildcards, which match
+// any sequence of characters; no other character is special.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	DeletePattern(ctx context.Context, pattern string) error
+	Close() error
+}
+
+// New connects to Redis. With cfg.EnableInMemoryFallback the returned cache
+// switches to an in-memory LRU while Redis is unreachable; without it, cache
+// operations fail and callers fall through to the database. An unreachable
+// Redis at startup is logged rather than returned, as the cache is optional.
+func New(redisCfg *config.RedisConfig, cfg *config.CacheConfig, logger *zap.Logger) Cache {
+	redisCache := NewRedisCache(redisCfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCfg.Timeout)
+	defer cancel()
+	if err := redisCache.Ping(ctx); err != nil {
+		logger.Warn("Redis is unreachable",
+			zap.String("address", fmt.Sprintf("%s:%d", redisCfg.Host, redisCfg.Port)),
+			zap.Bool("in_memory_fallback", cfg.EnableInMemoryFallback),
+			zap.Error(err),
+		)
+	}
+
+	if cfg.EnableInMemoryFallback {
+		return NewFallbackCache(redisCache, NewMemoryCache(cfg.MemoryCacheSize), logger)
+	}
+	return redisCache
+}
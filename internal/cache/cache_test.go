@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := NewMemoryCache(3)
+	c.now = func() time.Time { return now }
+
+	t.Run("get and expire", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+
+		value, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("1"), value)
+
+		now = now.Add(time.Minute)
+		_, err = c.Get(ctx, "a")
+		assert.ErrorIs(t, err, ErrMiss)
+		assert.Zero(t, c.Len())
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, c.Set(ctx, key, []byte(key), time.Minute))
+		}
+		_, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+
+		require.NoError(t, c.Set(ctx, "d", []byte("d"), time.Minute))
+		assert.Equal(t, 3, c.Len())
+		_, err = c.Get(ctx, "b")
+		assert.ErrorIs(t, err, ErrMiss)
+		for _, key := range []string{"a", "c", "d"} {
+			_, err := c.Get(ctx, key)
+			assert.NoError(t, err, key)
+		}
+	})
+
+	t.Run("delete pattern", func(t *testing.T) {
+		c.Flush()
+		for _, key := range []string{"classroom:1", "classroom:1:roster:page=1", "classroom:12:roster:page=1"} {
+			require.NoError(t, c.Set(ctx, key, []byte(key), time.Minute))
+		}
+
+		require.NoError(t, c.DeletePattern(ctx, RosterPattern(1)))
+		_, err := c.Get(ctx, "classroom:1:roster:page=1")
+		assert.ErrorIs(t, err, ErrMiss)
+		assert.Equal(t, 2, c.Len())
+
+		require.NoError(t, c.Delete(ctx, ClassroomKey(1)))
+		assert.Equal(t, 1, c.Len())
+	})
+
+	t.Run("zero ttl is not stored", func(t *testing.T) {
+		c.Flush()
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+		assert.Zero(t, c.Len())
+	})
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"classroom:1", "classroom:1", true},
+		{"classroom:1", "classroom:12", false},
+		{"classroom:1:*", "classroom:1:roster:page=1", true},
+		{"classroom:1:*", "classroom:12:roster", false},
+		{"*:roster:*", "classroom:3:roster:page=2", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "abcbc", true},
+		{"a*b*c", "acb", false},
+		{"ab*ba", "aba", false},
+		{"*", "anything", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchPattern(tt.pattern, tt.key), "%s ~ %s", tt.pattern, tt.key)
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(NewMemoryCache(10), &config.CacheConfig{ClassroomTTL: time.Minute}, zap.NewNop())
+
+	classroom := &model.Classroom{ID: 4, Name: "CS 101", Slug: "cs-101"}
+	store.Set(ctx, ClassroomKey(4), classroom, store.Config().ClassroomTTL)
+
+	var cached *model.Classroom
+	require.True(t, store.Get(ctx, ClassroomKey(4), &cached))
+	assert.Equal(t, classroom, cached)
+
+	list := ClassroomListKey(&model.ClassroomListRequest{Page: 1})
+	store.Set(ctx, list, []int{1}, time.Minute)
+	store.Invalidate(ctx, ClassroomKey(4), ClassroomListPattern)
+	assert.False(t, store.Get(ctx, ClassroomKey(4), &cached))
+	assert.False(t, store.Get(ctx, list, &[]int{}))
+
+	t.Run("nil store caches nothing", func(t *testing.T) {
+		var nilStore *Store
+		nilStore.Set(ctx, "a", 1, time.Minute)
+		nilStore.Invalidate(ctx, "a")
+		assert.False(t, nilStore.Get(ctx, "a", new(int)))
+		assert.Zero(t, nilStore.Config().ClassroomTTL)
+	})
+}
+
+func TestListKeys(t *testing.T) {
+	// Fields excluded from JSON must still tell pages apart
+	assert.NotEqual(t,
+		ClassroomListKey(&model.ClassroomListRequest{MemberUserID: 1}),
+		ClassroomListKey(&model.ClassroomListRequest{MemberUserID: 2}))
+
+	one, two := int64(1), int64(2)
+	assert.NotEqual(t,
+		SubmissionListKey(&model.SubmissionListRequest{AssignmentID: &one, MemberID: &one}),
+		SubmissionListKey(&model.SubmissionListRequest{AssignmentID: &one, MemberID: &two}))
+
+	assert.True(t, matchPattern(SubmissionListPattern, SubmissionListKey(&model.SubmissionListRequest{})))
+	assert.True(t, matchPattern(ClassroomPattern(3), RosterListKey(3, &model.RosterListRequest{})))
+	assert.Contains(t, ClassroomListKey(&model.ClassroomListRequest{OrganizationName: "a*b"}), "a%2Ab")
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// fallbackRetryInterval is how long the primary cache is bypassed after a failure
+	fallbackRetryInterval = 10 * time.Second
+	// probeKey is read to check whether the primary is back
+	probeKey = "health:probe"
+	// maxPendingInvalidations bounds the invalidations remembered during an
+	// outage; beyond it the whole primary cache is cleared on recovery
+	maxPendingInvalidations = 1000
+)
+
+// FallbackCache uses a primary cache (Redis) and switches to an in-memory
+// fallback while the primary fails. The primary is retried periodically.
+//
+// Deletions made during an outage are replayed on the primary before it is
+// used again, and the fallback is flushed, so neither serves entries that
+// were invalidated while the other was in use.
+type FallbackCache struct {
+	primary  Cache
+	fallback *MemoryCache
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	down     bool
+	retryAt  time.Time
+	pending  []invalidation
+	overflow bool
+}
+
+// invalidation is a deletion that has not reached the primary cache yet
+type invalidation struct {
+	key     string
+	pattern bool
+}
+
+// NewFallbackCache creates a cache that falls back from primary to fallback
+func NewFallbackCache(primary Cache, fallback *MemoryCache, logger *zap.Logger) *FallbackCache {
+	return &FallbackCache{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Get returns the value of key, or ErrMiss
+func (c *FallbackCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if c.usePrimary(ctx) {
+		value, err := c.primary.Get(ctx, key)
+		if err == nil || errors.Is(err, ErrMiss) || ctx.Err() != nil {
+			return value, err
+		}
+		c.fail(err)
+	}
+	return c.fallback.Get(ctx, key)
+}
+
+// Set stores value under key for ttl
+func (c *FallbackCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.usePrimary(ctx) {
+		err := c.primary.Set(ctx, key, value, ttl)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		c.fail(err)
+	}
+	return c.fallback.Set(ctx, key, value, ttl)
+}
+
+// Delete removes key
+func (c *FallbackCache) Delete(ctx context.Context, key string) error {
+	return c.invalidate(ctx, invalidation{key: key})
+}
+
+// DeletePattern removes every key matching pattern
+func (c *FallbackCache) DeletePattern(ctx context.Context, pattern string) error {
+	return c.invalidate(ctx, invalidation{key: pattern, pattern: true})
+}
+
+// Close closes the primary cache
+func (c *FallbackCache) Close() error {
+	return c.primary.Close()
+}
+
+// invalidate applies inv to the primary, or to the fallback while
+// remembering it for the primary
+func (c *FallbackCache) invalidate(ctx context.Context, inv invalidation) error {
+	if c.usePrimary(ctx) {
+		err := c.apply(ctx, c.primary, inv)
+		if err == nil {
+			return nil
+		}
+		c.fail(err)
+	}
+
+	if !c.remember(inv) {
+		// The primary recovered since usePrimary, so it would never see inv
+		if err := c.apply(ctx, c.primary, inv); err != nil {
+			c.fail(err)
+			c.remember(inv)
+		}
+	}
+	return c.apply(ctx, c.fallback, inv)
+}
+
+// remember queues inv for replay on the primary. It returns false if the
+// primary is up again.
+func (c *FallbackCache) remember(inv invalidation) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.down {
+		return false
+	}
+	if len(c.pending) < maxPendingInvalidations {
+		c.pending = append(c.pending, inv)
+	} else {
+		c.overflow = true
+	}
+	return true
+}
+
+// usePrimary reports whether the primary is believed to be up. Once the
+// retry interval has passed it replays pending invalidations, and only
+// switches back when they all succeed.
+func (c *FallbackCache) usePrimary(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.down {
+		return true
+	}
+	if c.now().Before(c.retryAt) {
+		return false
+	}
+
+	// Probe first, so that the fallback is kept while the primary stays down
+	_, err := c.primary.Get(ctx, probeKey)
+	if errors.Is(err, ErrMiss) {
+		err = nil
+	}
+	if err == nil && c.overflow {
+		err = c.primary.DeletePattern(ctx, "*")
+	}
+	for err == nil && !c.overflow && len(c.pending) > 0 {
+		if err = c.apply(ctx, c.primary, c.pending[0]); err == nil {
+			c.pending = c.pending[1:]
+		}
+	}
+	if err != nil {
+		c.retryAt = c.now().Add(fallbackRetryInterval)
+		return false
+	}
+
+	c.down = false
+	c.pending = nil
+	c.overflow = false
+	c.fallback.Flush()
+	c.logger.Info("Cache recovered, leaving in-memory fallback")
+	return true
+}
+
+// fail switches to the fallback after a primary error
+func (c *FallbackCache) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.down {
+		c.logger.Warn("Cache unavailable, using in-memory fallback", zap.Error(err))
+	}
+	c.down = true
+	c.retryAt = c.now().Add(fallbackRetryInterval)
+}
+
+// apply runs inv against cache
+func (c *FallbackCache) apply(ctx context.Context, cache Cache, inv invalidation) error {
+	if inv.pattern {
+		return cache.DeletePattern(ctx, inv.key)
+	}
+	return cache.Delete(ctx, inv.key)
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyCache is a MemoryCache that fails every operation while down
+type flakyCache struct {
+	*MemoryCache
+	down bool
+}
+
+var errDown = errors.New("connection refused")
+
+func (c *flakyCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if c.down {
+		return nil, errDown
+	}
+	return c.MemoryCache.Get(ctx, key)
+}
+
+func (c *flakyCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.down {
+		return errDown
+	}
+	return c.MemoryCache.Set(ctx, key, value, ttl)
+}
+
+func (c *flakyCache) Delete(ctx context.Context, key string) error {
+	if c.down {
+		return errDown
+	}
+	return c.MemoryCache.Delete(ctx, key)
+}
+
+func (c *flakyCache) DeletePattern(ctx context.Context, pattern string) error {
+	if c.down {
+		return errDown
+	}
+	return c.MemoryCache.DeletePattern(ctx, pattern)
+}
+
+func TestFallbackCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func() (*FallbackCache, *flakyCache) {
+		primary := &flakyCache{MemoryCache: NewMemoryCache(100)}
+		c := NewFallbackCache(primary, NewMemoryCache(100), zap.NewNop())
+		c.now = func() time.Time { return now }
+		return c, primary
+	}
+
+	t.Run("uses the primary while it is up", func(t *testing.T) {
+		c, primary := setup()
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+
+		assert.Equal(t, 1, primary.Len())
+		assert.Zero(t, c.fallback.Len())
+	})
+
+	t.Run("falls back and replays invalidations on recovery", func(t *testing.T) {
+		c, primary := setup()
+		require.NoError(t, c.Set(ctx, "classroom:1", []byte("old"), time.Hour))
+		require.NoError(t, c.Set(ctx, "classroom:list:page=1", []byte("old"), time.Hour))
+		require.NoError(t, c.Set(ctx, "classroom:2", []byte("kept"), time.Hour))
+
+		primary.down = true
+		_, err := c.Get(ctx, "classroom:1")
+		assert.ErrorIs(t, err, ErrMiss)
+
+		require.NoError(t, c.Set(ctx, "classroom:1", []byte("outage"), time.Hour))
+		value, err := c.Get(ctx, "classroom:1")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("outage"), value)
+
+		require.NoError(t, c.Delete(ctx, "classroom:1"))
+		require.NoError(t, c.DeletePattern(ctx, ClassroomListPattern))
+
+		// Still bypassed within the retry interval, even though it is back
+		primary.down = false
+		_, err = c.Get(ctx, "classroom:2")
+		assert.ErrorIs(t, err, ErrMiss)
+
+		now = now.Add(fallbackRetryInterval)
+		value, err = c.Get(ctx, "classroom:2")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("kept"), value)
+
+		for _, key := range []string{"classroom:1", "classroom:list:page=1"} {
+			_, err := c.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrMiss, key)
+		}
+		assert.Zero(t, c.fallback.Len())
+		assert.Empty(t, c.pending)
+	})
+
+	t.Run("stays on the fallback while the primary is down", func(t *testing.T) {
+		c, primary := setup()
+		primary.down = true
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Hour))
+
+		now = now.Add(fallbackRetryInterval)
+		value, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("1"), value)
+	})
+
+	t.Run("clears the primary after too many invalidations", func(t *testing.T) {
+		c, primary := setup()
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Hour))
+
+		primary.down = true
+		for i := 0; i <= maxPendingInvalidations; i++ {
+			require.NoError(t, c.Delete(ctx, "b"))
+		}
+		assert.True(t, c.overflow)
+
+		primary.down = false
+		now = now.Add(fallbackRetryInterval)
+		_, err := c.Get(ctx, "a")
+		assert.ErrorIs(t, err, ErrMiss)
+		assert.False(t, c.overflow)
+	})
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// Key patterns. Everything cached for a classroom is keyed under
+// "classroom:<id>:" so that it can be dropped with the classroom.
+const (
+	ClassroomListPattern  = "classroom:list:*"
+	SubmissionPattern     = "submission:*"
+	SubmissionListPattern = "submission:list:*"
+)
+
+// ClassroomKey is the key of a classroom
+func ClassroomKey(id int64) string {
+	return fmt.Sprintf("classroom:%d", id)
+}
+
+// ClassroomPattern matches every key scoped to a classroom
+func ClassroomPattern(id int64) string {
+	return fmt.Sprintf("classroom:%d:*", id)
+}
+
+// ClassroomListKey is the key of one page of a classroom listing
+func ClassroomListKey(req *model.ClassroomListRequest) string {
+	return fmt.Sprintf("classroom:list:org=%s:archived=%t:member=%d:page=%d:per_page=%d",
+		url.QueryEscape(req.OrganizationName), req.IncludeArchived, req.MemberUserID, req.Page, req.PerPage)
+}
+
+// ClassroomStatsKey is the key of the statistics of a classroom
+func ClassroomStatsKey(id int64) string {
+	return fmt.Sprintf("classroom:%d:stats", id)
+}
+
+// AssignmentStatsKey is the key of the statistics of an assignment
+func AssignmentStatsKey(classroomID, assignmentID int64, detailed bool) string {
+	return fmt.Sprintf("classroom:%d:assignment:%d:stats:detailed=%t", classroomID, assignmentID, detailed)
+}
+
+// RosterListKey is the key of one page of a classroom roster
+func RosterListKey(classroomID int64, req *model.RosterListRequest) string {
+	return fmt.Sprintf("classroom:%d:roster:linked=%t:unlinked=%t:page=%d:per_page=%d",
+		classroomID, req.LinkedOnly, req.UnlinkedOnly, req.Page, req.PerPage)
+}
+
+// RosterPattern matches every roster page of a classroom
+func RosterPattern(classroomID int64) string {
+	return fmt.Sprintf("classroom:%d:roster:*", classroomID)
+}
+
+// SubmissionKey is the key of a submission
+func SubmissionKey(id int64) string {
+	return fmt.Sprintf("submission:%d", id)
+}
+
+// SubmissionListKey is the key of one page of a submission listing
+func SubmissionListKey(req *model.SubmissionListRequest) string {
+	return fmt.Sprintf("submission:list:assignment=%d:status=%s:team=%t:individual=%t:member=%d:page=%d:per_page=%d",
+		deref(req.AssignmentID), url.QueryEscape(req.Status), req.TeamOnly, req.IndividualOnly, deref(req.MemberID), req.Page, req.PerPage)
+}
+
+// deref returns *id, or 0 for nil
+func deref(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+const (
+	// keyPrefix namespaces our keys in a Redis database that may be shared
+	keyPrefix = "fgc:"
+	// scanBatchSize is the number of keys examined per SCAN round trip
+	scanBatchSize = 500
+)
+
+// RedisCache stores entries in Redis
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a cache backed by the Redis server in cfg. It does
+// not connect until the first operation.
+func NewRedisCache(cfg *config.RedisConfig) *RedisCache {
+	return &RedisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Password:     cfg.Password,
+			DB:           cfg.Database,
+			PoolSize:     cfg.PoolSize,
+			DialTimeout:  cfg.Timeout,
+			ReadTimeout:  cfg.Timeout,
+			WriteTimeout: cfg.Timeout,
+		}),
+	}
+}
+
+// Ping checks that Redis is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Get returns the value of key, or ErrMiss
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set stores value under key for ttl
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, keyPrefix+key, value, ttl).Err()
+}
+
+// Delete removes key
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, keyPrefix+key).Err()
+}
+
+// DeletePattern removes every key matching pattern. Keys are found with
+// SCAN, so the server is not blocked on large databases; keys written while
+// the scan runs may survive.
+func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
+	iter := c.client.Scan(ctx, 0, keyPrefix+pattern, scanBatchSize).Iterator()
+
+	batch := make([]string, 0, scanBatchSize)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == scanBatchSize {
+			if err := c.client.Unlink(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return c.client.Unlink(ctx, batch...).Err()
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
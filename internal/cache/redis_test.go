@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+func TestRedisCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cfg := &config.RedisConfig{
+		Host:     getEnv("FGC_REDIS_HOST", "localhost"),
+		Port:     6380,
+		PoolSize: 2,
+		Timeout:  5 * time.Second,
+	}
+	if port := os.Getenv("FGC_REDIS_PORT"); port != "" {
+		_, err := fmt.Sscanf(port, "%d", &cfg.Port)
+		require.NoError(t, err)
+	}
+
+	c := NewRedisCache(cfg)
+	t.Cleanup(func() { c.Close() })
+	ctx := context.Background()
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.DeletePattern(ctx, "*"))
+
+	_, err := c.Get(ctx, "classroom:1")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, c.Set(ctx, "classroom:1", []byte("one"), time.Minute))
+	value, err := c.Get(ctx, "classroom:1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("one"), value)
+
+	ttl, err := c.client.TTL(ctx, keyPrefix+"classroom:1").Result()
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute.Seconds(), ttl.Seconds(), 2)
+
+	// More keys than one SCAN batch
+	for i := 0; i < scanBatchSize+10; i++ {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("classroom:1:roster:page=%d", i), []byte("x"), time.Minute))
+	}
+	require.NoError(t, c.DeletePattern(ctx, RosterPattern(1)))
+	keys, err := c.client.Keys(ctx, keyPrefix+"*").Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{keyPrefix + "classroom:1"}, keys)
+
+	require.NoError(t, c.Delete(ctx, "classroom:1"))
+	_, err = c.Get(ctx, "classroom:1")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+// invalidationTimeout bounds cache deletions, which must not be cut short
+// by the cancellation of the request that triggered them
+const invalidationTimeout = 5 * time.Second
+
+// Store caches JSON-encoded values on top of a Cache. Cache failures are
+// logged and treated as misses, so that reads fall through to the database.
+//
+// A nil *Store caches nothing.
+type Store struct {
+	cache  Cache
+	cfg    config.CacheConfig
+	logger *zap.Logger
+}
+
+// NewStore creates a store on c using the TTLs in cfg
+func NewStore(c Cache, cfg *config.CacheConfig, logger *zap.Logger) *Store {
+	return &Store{
+		cache:  c,
+		cfg:    *cfg,
+		logger: logger,
+	}
+}
+
+// Config returns the cache configuration, holding the TTL of each entity
+func (s *Store) Config() config.CacheConfig {
+	if s == nil {
+		return config.CacheConfig{}
+	}
+	return s.cfg
+}
+
+// Get decodes the value of key into v and reports whether it was cached
+func (s *Store) Get(ctx context.Context, key string, v interface{}) bool {
+	if s == nil {
+		return false
+	}
+
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrMiss) {
+			s.logger.Warn("Cache read failed", zap.String("key", key), zap.Error(err))
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		s.logger.Warn("Discarding undecodable cache entry", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// Set stores v under key for ttl. A ttl of zero or less stores nothing.
+func (s *Store) Set(ctx context.Context, key string, v interface{}, ttl time.Duration) {
+	if s == nil || ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.logger.Warn("Failed to encode cache entry", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := s.cache.Set(ctx, key, data, ttl); err != nil {
+		s.logger.Warn("Cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Invalidate deletes keys; a key containing "*" is a pattern. Call it once
+// the write is committed. A read racing with the write may still cache the
+// old value, which then lives until its TTL expires.
+func (s *Store) Invalidate(ctx context.Context, keys ...string) {
+	if s == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidationTimeout)
+	defer cancel()
+
+	for _, key := range keys {
+		var err error
+		if strings.Contains(key, "*") {
+			err = s.cache.DeletePattern(ctx, key)
+		} else {
+			err = s.cache.Delete(ctx, key)
+		}
+		if err != nil {
+			s.logger.Error("Cache invalidation failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"time"
 
 	"github.com/spf13/viper"
@@ -21,12 +23,33 @@ type Config struct {
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port           int      `mapstructure:"port"`
-	Host           string   `mapstructure:"host"`
-	Mode           string   `mapstructure:"mode"` // debug, release
-	ReadTimeout    int      `mapstructure:"read_timeout"`
-	WriteTimeout   int      `mapstructure:"write_timeout"`
-	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	Port           int                `mapstructure:"port"`
+	Host           string             `mapstructure:"host"`
+	Mode           string             `mapstructure:"mode"` // debug, release
+	ReadTimeout    int                `mapstructure:"read_timeout"`
+	WriteTimeout   int                `mapstructure:"write_timeout"`
+	TrustedProxies []string           `mapstructure:"trusted_proxies"`
+	PublicURL      string             `mapstructure:"public_url"` // external URL of the server, used in invitation links
+	RateLimit      APIRateLimitConfig `mapstructure:"rate_limit"`
+	Metrics        MetricsConfig      `mapstructure:"metrics"`
+}
+
+// MetricsConfig holds configuration of the Prometheus metrics endpoint.
+// When a token is set, scrapers must send it as a bearer token.
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"`
+}
+
+// APIRateLimitConfig holds rate limiting configuration for API clients.
+// Requests are counted per authenticated user, or per client IP for
+// unauthenticated ones, with separate budgets for reads and writes. Every
+// request is also counted per client IP before it is authenticated.
+type APIRateLimitConfig struct {
+	Enabled bool            `mapstructure:"enabled"`
+	Read    RateLimitConfig `mapstructure:"read"`  // GET, HEAD and OPTIONS requests
+	Write   RateLimitConfig `mapstructure:"write"` // all other requests
+	IP      RateLimitConfig `mapstructure:"ip"`    // all requests from one IP, before authentication
 }
 
 // DatabaseConfig holds database connection configuration
@@ -54,10 +77,20 @@ type RedisConfig struct {
 
 // ForgejoConfig holds Forgejo integration configuration
 type ForgejoConfig struct {
-	BaseURL   string          `mapstructure:"base_url"`
-	Token     string          `mapstructure:"token"`
-	Timeout   time.Duration   `mapstructure:"timeout"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	BaseURL        string               `mapstructure:"base_url"`
+	Token          string               `mapstructure:"token"`
+	Timeout        time.Duration        `mapstructure:"timeout"`
+	RateLimit      RateLimitConfig      `mapstructure:"rate_limit"`
+	MaxRetries     int                  `mapstructure:"max_retries"` // retries of rate limited (429) and failed (5xx) calls
+	MaxWait        time.Duration        `mapstructure:"max_wait"`    // longest wait for a rate limit token or before a retry
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	WebhookSecret  string               `mapstructure:"webhook_secret"` // secret of the push webhooks; webhooks are disabled when empty
+}
+
+// CircuitBreakerConfig holds circuit breaker configuration
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"` // consecutive failures that open the circuit
+	Cooldown         time.Duration `mapstructure:"cooldown"`          // how long an open circuit rejects calls
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -73,7 +106,9 @@ type CacheConfig struct {
 	AssignmentTTL          time.Duration `mapstructure:"assignment_ttl"`
 	RosterTTL              time.Duration `mapstructure:"roster_ttl"`
 	SubmissionTTL          time.Duration `mapstructure:"submission_ttl"`
+	StatsTTL               time.Duration `mapstructure:"stats_ttl"` // statistics are not invalidated, so keep this short
 	EnableInMemoryFallback bool          `mapstructure:"enable_in_memory_fallback"`
+	MemoryCacheSize        int           `mapstructure:"memory_cache_size"` // maximum number of entries in the in-memory fallback
 }
 
 // QueueConfig holds async queue configuration
@@ -82,13 +117,17 @@ type QueueConfig struct {
 	ProcessingTimeout time.Duration `mapstructure:"processing_timeout"`
 	RetryAttempts     int           `mapstructure:"retry_attempts"`
 	RetryDelay        time.Duration `mapstructure:"retry_delay"`
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
 }
 
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
-	TokenExpiration time.Duration `mapstructure:"token_expiration"`
-	JWTSecret       string        `mapstructure:"jwt_secret"`
-	RequireHTTPS    bool          `mapstructure:"require_https"`
+	TokenExpiration      time.Duration `mapstructure:"token_expiration"`
+	JWTSecret            string        `mapstructure:"jwt_secret"`
+	RequireHTTPS         bool          `mapstructure:"require_https"`
+	TokenCacheTTL        time.Duration `mapstructure:"token_cache_ttl"`         // how long a validated token is trusted
+	TokenCacheSize       int           `mapstructure:"token_cache_size"`        // maximum number of cached tokens
+	InvalidTokenCacheTTL time.Duration `mapstructure:"invalid_token_cache_ttl"` // how long a rejected token is refused without asking Forgejo
 }
 
 // LoggingConfig holds logging configuration
@@ -134,6 +173,24 @@ func setDefaults(config *Config) {
 	if config.Server.WriteTimeout == 0 {
 		config.Server.WriteTimeout = 30
 	}
+	if config.Server.RateLimit.Read.RequestsPerMinute == 0 {
+		config.Server.RateLimit.Read.RequestsPerMinute = 300
+	}
+	if config.Server.RateLimit.Read.BurstSize == 0 {
+		config.Server.RateLimit.Read.BurstSize = 60
+	}
+	if config.Server.RateLimit.Write.RequestsPerMinute == 0 {
+		config.Server.RateLimit.Write.RequestsPerMinute = 60
+	}
+	if config.Server.RateLimit.Write.BurstSize == 0 {
+		config.Server.RateLimit.Write.BurstSize = 20
+	}
+	if config.Server.RateLimit.IP.RequestsPerMinute == 0 {
+		config.Server.RateLimit.IP.RequestsPerMinute = 1200 // a classroom behind one NAT
+	}
+	if config.Server.RateLimit.IP.BurstSize == 0 {
+		config.Server.RateLimit.IP.BurstSize = 200
+	}
 
 	if config.Database.Host == "" {
 		config.Database.Host = "localhost"
@@ -176,6 +233,18 @@ func setDefaults(config *Config) {
 	if config.Forgejo.RateLimit.BurstSize == 0 {
 		config.Forgejo.RateLimit.BurstSize = 10
 	}
+	if config.Forgejo.MaxRetries == 0 {
+		config.Forgejo.MaxRetries = 3
+	}
+	if config.Forgejo.MaxWait == 0 {
+		config.Forgejo.MaxWait = 30 * time.Second
+	}
+	if config.Forgejo.CircuitBreaker.FailureThreshold == 0 {
+		config.Forgejo.CircuitBreaker.FailureThreshold = 5
+	}
+	if config.Forgejo.CircuitBreaker.Cooldown == 0 {
+		config.Forgejo.CircuitBreaker.Cooldown = 30 * time.Second
+	}
 
 	if config.Cache.DefaultTTL == 0 {
 		config.Cache.DefaultTTL = 15 * time.Minute
@@ -192,6 +261,12 @@ func setDefaults(config *Config) {
 	if config.Cache.SubmissionTTL == 0 {
 		config.Cache.SubmissionTTL = 2 * time.Minute
 	}
+	if config.Cache.StatsTTL == 0 {
+		config.Cache.StatsTTL = time.Minute
+	}
+	if config.Cache.MemoryCacheSize == 0 {
+		config.Cache.MemoryCacheSize = 10000
+	}
 
 	if config.Queue.WorkerCount == 0 {
 		config.Queue.WorkerCount = 3
@@ -205,10 +280,22 @@ func setDefaults(config *Config) {
 	if config.Queue.RetryDelay == 0 {
 		config.Queue.RetryDelay = 30 * time.Second
 	}
+	if config.Queue.PollInterval == 0 {
+		config.Queue.PollInterval = time.Second
+	}
 
 	if config.Auth.TokenExpiration == 0 {
 		config.Auth.TokenExpiration = 24 * time.Hour
 	}
+	if config.Auth.TokenCacheTTL == 0 {
+		config.Auth.TokenCacheTTL = 5 * time.Minute
+	}
+	if config.Auth.TokenCacheSize == 0 {
+		config.Auth.TokenCacheSize = 10000
+	}
+	if config.Auth.InvalidTokenCacheTTL == 0 {
+		config.Auth.InvalidTokenCacheTTL = time.Minute
+	}
 
 	if config.Logging.Level == "" {
 		config.Logging.Level = "info"
@@ -236,6 +323,21 @@ func validate(config *Config) error {
 		return fmt.Errorf("JWT secret is required")
 	}
 
+	for _, proxy := range config.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("invalid trusted proxy: %s", proxy)
+			}
+		}
+	}
+
+	if config.Server.PublicURL != "" {
+		u, err := url.Parse(config.Server.PublicURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid server public URL: %s", config.Server.PublicURL)
+		}
+	}
+
 	// Validate enum values
 	validModes := map[string]bool{"debug": true, "release": true}
 	if !validModes[config.Server.Mode] {
@@ -0,0 +1,103 @@
+package forgejo
+
+import (
+	"sync"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+// breaker is a circuit breaker around Forgejo calls. After a number of
+// consecutive failures (429, 5xx or unreachable) it opens and rejects calls
+// for a cooldown, or for as long as Forgejo asked with Retry-After. Once that
+// has passed a single trial call is let through: its success closes the
+// circuit, its failure opens it again.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	code      string // error code reported while open
+	probing   bool   // a trial call is in flight
+}
+
+// newBreaker creates a breaker, or returns nil if cfg disables it
+func newBreaker(cfg *config.CircuitBreakerConfig) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	return &breaker{
+		threshold: cfg.FailureThreshold,
+		cooldown:  cfg.Cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may proceed. When it may not, it returns the
+// error code to report and how long the circuit stays open.
+func (b *breaker) allow() (string, time.Duration, bool) {
+	if b == nil {
+		return "", 0, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return "", 0, true
+	}
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return b.code, wait, false
+	}
+	if b.probing {
+		return b.code, 0, false
+	}
+	b.probing = true
+	return "", 0, true
+}
+
+// success records a call that reached a healthy Forgejo
+func (b *breaker) success() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// failure records a failed call; retryAfter is the wait Forgejo asked for
+func (b *breaker) failure(code string, retryAfter time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.code = code
+		b.openUntil = b.now().Add(max(b.cooldown, retryAfter))
+	}
+}
+
+// release ends a call whose outcome says nothing about Forgejo, such as
+// one cancelled by the caller
+func (b *breaker) release() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
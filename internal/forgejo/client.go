@@ -0,0 +1,210 @@
+package forgejo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+)
+
+// apiPrefix is the path prefix of the Forgejo REST API
+const apiPrefix = "/api/v1"
+
+// Client is a typed client for the subset of the Forgejo API used by the classroom
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+	throttle   *throttle // shared with copies made by WithToken
+	metrics    *metrics.Metrics
+	logger     *zap.Logger
+}
+
+// New creates a new Forgejo API client from configuration
+func New(cfg *config.ForgejoConfig, logger *zap.Logger) (*Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("forgejo base URL is required")
+	}
+
+	baseURL, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid forgejo base URL: %w", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid forgejo base URL scheme: %q", baseURL.Scheme)
+	}
+
+	return &Client{
+		baseURL: baseURL,
+		token:   cfg.Token,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		throttle: newThrottle(cfg),
+		logger:   logger,
+	}, nil
+}
+
+// WithToken returns a copy of the client that authenticates as the owner
+// of token. The copy shares the underlying HTTP client and rate limit.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.token = token
+	return &clone
+}
+
+// BaseURL returns the base URL of the Forgejo instance
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// newRequest builds an authenticated API request
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + apiPrefix + path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	return req, nil
+}
+
+// send executes a request once the throttle allows it and converts non-2xx
+// responses into *Error. The caller owns the returned response body.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	endpoint := c.endpoint(req.URL.Path)
+	if err := c.throttle.wait(req); err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			c.metrics.ForgejoError(req.Method, endpoint, apiErr.Code)
+		}
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		transportErr := newTransportError(req, err)
+		c.throttle.record(req, transportErr)
+		c.metrics.ObserveForgejo(req.Method, endpoint, 0, time.Since(start))
+		c.metrics.ForgejoError(req.Method, endpoint, transportErr.Code)
+		c.logger.Warn("Forgejo request failed",
+			zap.String("method", req.Method),
+			zap.String("path", req.URL.Path),
+			zap.Error(err),
+		)
+		return nil, transportErr
+	}
+	c.metrics.ObserveForgejo(req.Method, endpoint, resp.StatusCode, time.Since(start))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		apiErr := newResponseError(req, resp)
+		c.throttle.record(req, apiErr)
+		c.metrics.ForgejoError(req.Method, endpoint, apiErr.Code)
+		c.logger.Debug("Forgejo request returned error status",
+			zap.String("method", req.Method),
+			zap.String("path", req.URL.Path),
+			zap.Int("status", resp.StatusCode),
+			zap.String("code", apiErr.Code),
+		)
+		return nil, apiErr
+	}
+
+	c.throttle.record(req, nil)
+	return resp, nil
+}
+
+// sendWithRetry sends a request, retrying rate limited and failed calls as
+// the throttle allows
+func (c *Client) sendWithRetry(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, method, path, query, body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(req)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay, ok := c.throttle.retryDelay(method, attempt, err)
+		if !ok {
+			return nil, err
+		}
+		c.logger.Info("Retrying Forgejo request",
+			zap.String("method", method),
+			zap.String("path", req.URL.Path),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		if sleep(ctx, delay) != nil {
+			return nil, err
+		}
+	}
+}
+
+// do executes a request and decodes the JSON response into out (if non-nil)
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (*http.Response, error) {
+	resp, err := c.sendWithRetry(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+		}
+	} else {
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}
+
+	return resp, nil
+}
+
+// escape escapes a single path segment
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
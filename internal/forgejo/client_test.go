@@ -0,0 +1,465 @@
+package forgejo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// newTestClient creates a client pointed at an httptest server
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(&config.ForgejoConfig{
+		BaseURL: server.URL,
+		Token:   "test-token",
+		Timeout: 5 * time.Second,
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestNew(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		client, err := New(nil, zap.NewNop())
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("nil logger", func(t *testing.T) {
+		client, err := New(&config.ForgejoConfig{BaseURL: "https://forgejo.example.com"}, nil)
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		client, err := New(&config.ForgejoConfig{BaseURL: "ftp://forgejo.example.com"}, zap.NewNop())
+		assert.Error(t, err)
+		assert.Nil(t, client)
+	})
+
+	t.Run("trailing slash is trimmed", func(t *testing.T) {
+		client, err := New(&config.ForgejoConfig{BaseURL: "https://forgejo.example.com/"}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, "https://forgejo.example.com", client.BaseURL())
+	})
+}
+
+func TestClient_GetOrganization(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v1/orgs/cs101", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(Organization{ID: 7, Name: "cs101"})
+	}))
+
+	org, err := client.GetOrganization(context.Background(), "cs101")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), org.ID)
+	assert.Equal(t, "cs101", org.Name)
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantCode string
+	}{
+		{"not found", http.StatusNotFound, response.ErrIntegrationForgejoAPI},
+		{"rate limited", http.StatusTooManyRequests, response.ErrIntegrationForgejoRateLimited},
+		{"bad gateway", http.StatusBadGateway, response.ErrIntegrationForgejoUnavailable},
+		{"service unavailable", http.StatusServiceUnavailable, response.ErrIntegrationForgejoUnavailable},
+		{"internal error", http.StatusInternalServerError, response.ErrIntegrationForgejoAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"message":"boom"}`))
+			}))
+
+			_, err := client.GetUser(context.Background(), "jdoe")
+			require.Error(t, err)
+
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+			assert.Equal(t, "boom", apiErr.Message)
+		})
+	}
+
+	t.Run("not found helper", func(t *testing.T) {
+		client := newTestClient(t, http.NotFoundHandler())
+		_, err := client.GetUser(context.Background(), "missing")
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("transport failure", func(t *testing.T) {
+		client, err := New(&config.ForgejoConfig{
+			BaseURL: "http://127.0.0.1:1",
+			Timeout: time.Second,
+		}, zap.NewNop())
+		require.NoError(t, err)
+
+		_, err = client.CurrentUser(context.Background())
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 0, apiErr.StatusCode)
+		assert.Equal(t, response.ErrIntegrationForgejoUnavailable, apiErr.Code)
+	})
+}
+
+func TestClient_Pagination(t *testing.T) {
+	const total = DefaultPageSize*2 + 3
+
+	requests := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		var branches []Branch
+		for i := (page - 1) * limit; i < page*limit && i < total; i++ {
+			branches = append(branches, Branch{Name: fmt.Sprintf("branch-%d", i)})
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		_ = json.NewEncoder(w).Encode(branches)
+	}))
+
+	t.Run("collects every page", func(t *testing.T) {
+		requests = 0
+		branches, err := client.ListBranches(context.Background(), "cs101", "hw1")
+		require.NoError(t, err)
+		assert.Len(t, branches, total)
+		assert.Equal(t, "branch-0", branches[0].Name)
+		assert.Equal(t, fmt.Sprintf("branch-%d", total-1), branches[total-1].Name)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("stops early when the caller breaks", func(t *testing.T) {
+		requests = 0
+		count := 0
+		for branch, err := range client.Branches(context.Background(), "cs101", "hw1") {
+			require.NoError(t, err)
+			require.NotNil(t, branch)
+			count++
+			if count == 5 {
+				break
+			}
+		}
+		assert.Equal(t, 5, count)
+		assert.Equal(t, 1, requests)
+	})
+}
+
+func TestClient_CountCommits(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("sha"))
+		assert.Equal(t, "1", r.URL.Query().Get("limit"))
+		w.Header().Set("X-Total-Count", "42")
+		_ = json.NewEncoder(w).Encode([]Commit{{SHA: "abc"}})
+	}))
+
+	count, err := client.CountCommits(context.Background(), "cs101", "hw1", CommitListOptions{SHA: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, 42, count)
+}
+
+func TestClient_LatestCommit(t *testing.T) {
+	until := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("returns the newest commit", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "1", r.URL.Query().Get("limit"))
+			assert.Equal(t, "2026-10-16T12:00:00Z", r.URL.Query().Get("until"))
+			_ = json.NewEncoder(w).Encode([]Commit{{SHA: "abc"}})
+		}))
+
+		commit, err := client.LatestCommit(context.Background(), "cs101", "hw1", CommitListOptions{Until: &until})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", commit.SHA)
+	})
+
+	t.Run("empty repository", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"repository is empty"}`))
+		}))
+
+		commit, err := client.LatestCommit(context.Background(), "cs101", "hw1", CommitListOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, commit)
+	})
+}
+
+func TestClient_GetArchive(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/repos/cs101/hw1/archive/deadline-2026.tar.gz", r.URL.Path)
+		_, _ = w.Write([]byte("archive"))
+	}))
+
+	archive, err := client.GetArchive(context.Background(), "cs101", "hw1", "deadline-2026", ArchiveTarGz)
+	require.NoError(t, err)
+	defer archive.Close()
+	content, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(content))
+}
+
+// newThrottledClient creates a test client with retries, rate limiting and
+// circuit breaking configured by configure
+func newThrottledClient(t *testing.T, handler http.Handler, configure func(*config.ForgejoConfig)) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.ForgejoConfig{BaseURL: server.URL, Timeout: 5 * time.Second, MaxWait: time.Second}
+	configure(cfg)
+	client, err := New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	client.throttle.backoff = time.Millisecond
+
+	return client
+}
+
+// statusSequence responds with the given statuses in turn, then 200 with an empty object
+func statusSequence(calls *atomic.Int32, headers http.Header, statuses ...int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			for k, v := range headers {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"login":"prof"}`))
+	})
+}
+
+func TestClient_Retry(t *testing.T) {
+	ctx := context.Background()
+	retries := func(cfg *config.ForgejoConfig) { cfg.MaxRetries = 2 }
+
+	t.Run("retries rate limited calls", func(t *testing.T) {
+		var calls atomic.Int32
+		client := newThrottledClient(t, statusSequence(&calls, http.Header{"Retry-After": {"0"}}, 429, 429), retries)
+
+		_, err := client.CreateOrganization(ctx, CreateOrgOption{UserName: "cs101"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("retries server errors of idempotent calls only", func(t *testing.T) {
+		var calls atomic.Int32
+		client := newThrottledClient(t, statusSequence(&calls, nil, 503), retries)
+		_, err := client.CurrentUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+
+		calls.Store(0)
+		_, err = client.CreateOrganization(ctx, CreateOrgOption{UserName: "cs101"})
+		assert.Equal(t, http.StatusServiceUnavailable, StatusCode(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("gives up after the last retry", func(t *testing.T) {
+		var calls atomic.Int32
+		client := newThrottledClient(t, statusSequence(&calls, nil, 429, 429, 429, 429), retries)
+
+		_, err := client.CurrentUser(ctx)
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, response.ErrIntegrationForgejoRateLimited, apiErr.Code)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not wait longer than allowed", func(t *testing.T) {
+		var calls atomic.Int32
+		client := newThrottledClient(t, statusSequence(&calls, http.Header{"Retry-After": {"3600"}}, 429), retries)
+
+		_, err := client.CurrentUser(ctx)
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, time.Hour, apiErr.RetryAfter)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		client := newThrottledClient(t, statusSequence(&calls, nil, 404), retries)
+
+		_, err := client.CurrentUser(ctx)
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	var calls atomic.Int32
+	client := newThrottledClient(t, statusSequence(&calls, nil), func(cfg *config.ForgejoConfig) {
+		cfg.RateLimit = config.RateLimitConfig{RequestsPerMinute: 1, BurstSize: 2}
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.CurrentUser(ctx)
+		require.NoError(t, err)
+	}
+
+	// The bucket is shared with copies of the client
+	_, err := client.WithToken("other").CurrentUser(ctx)
+	require.ErrorIs(t, err, ErrRateLimited)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, response.ErrIntegrationForgejoRateLimited, apiErr.Code)
+	assert.Greater(t, apiErr.RetryAfter, 50*time.Second)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var calls atomic.Int32
+	client := newThrottledClient(t, statusSequence(&calls, nil, 502, 502, 502), func(cfg *config.ForgejoConfig) {
+		cfg.CircuitBreaker = config.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}
+	})
+	client.throttle.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.CurrentUser(ctx)
+		assert.Equal(t, http.StatusBadGateway, StatusCode(err))
+	}
+
+	_, err := client.CurrentUser(ctx)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, response.ErrIntegrationForgejoUnavailable, apiErr.Code)
+	assert.Equal(t, time.Minute, apiErr.RetryAfter)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// A failed trial call opens the circuit again
+	now = now.Add(time.Minute)
+	_, err = client.CurrentUser(ctx)
+	assert.Equal(t, http.StatusBadGateway, StatusCode(err))
+	_, err = client.CurrentUser(ctx)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A successful one closes it
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err = client.CurrentUser(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestClient_Endpoint(t *testing.T) {
+	client, err := New(&config.ForgejoConfig{BaseURL: "https://forgejo.example.com/git"}, zap.NewNop())
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"/git/api/v1/user":                                    "/user",
+		"/git/api/v1/users/alice":                             "/users/{username}",
+		"/git/api/v1/orgs/cs101/repos":                        "/orgs/{org}/repos",
+		"/git/api/v1/orgs/cs101/members/alice":                "/orgs/{org}/members/{username}",
+		"/git/api/v1/users/alice/orgs/cs101/permissions":      "/users/{username}/orgs/{org}/permissions",
+		"/git/api/v1/admin/users/alice/orgs":                  "/admin/users/{username}/orgs",
+		"/git/api/v1/repos/cs101/hw1":                         "/repos/{owner}/{repo}",
+		"/git/api/v1/repos/cs101/hw1/collaborators/alice":     "/repos/{owner}/{repo}/collaborators/{username}",
+		"/git/api/v1/repos/cs101/hw1/hooks/12":                "/repos/{owner}/{repo}/hooks/{id}",
+		"/git/api/v1/repos/cs101/hw1/commits":                 "/repos/{owner}/{repo}/commits",
+		"/git/api/v1/repos/cs101/hw1/branches/feature/login":  "/repos/{owner}/{repo}/branches/{branch}",
+		"/git/api/v1/repos/cs101/hw1/archive/deadline.tar.gz": "/repos/{owner}/{repo}/archive/{archive}",
+		"/git/api/v1/repos/cs101/template/generate":           "/repos/{owner}/{repo}/generate",
+		"/git/api/v1/repositories/42":                         "/repositories/{id}",
+		"/git/api/v1/teams/3/repos/cs101/hw1":                 "/teams/{id}/repos/{owner}/{repo}",
+		"/git/api/v1/repos/cs101/hw1/unknown/thing":           "/repos/{owner}/{repo}/*/*",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, client.endpoint(path), path)
+	}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/orgs/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Organization{ID: 7, Name: "cs101"})
+	}))
+	m := metrics.New(zap.NewNop())
+	client = client.WithMetrics(m).WithToken("other-token")
+
+	_, err := client.GetOrganization(context.Background(), "cs101")
+	require.NoError(t, err)
+	_, err = client.GetOrganization(context.Background(), "missing")
+	require.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `fgc_forgejo_request_duration_seconds_count{endpoint="/orgs/{org}",method="GET",status="200"} 1`)
+	assert.Contains(t, body, `fgc_forgejo_request_duration_seconds_count{endpoint="/orgs/{org}",method="GET",status="404"} 1`)
+	assert.Contains(t, body, `fgc_forgejo_request_errors_total{code="`+codeForStatus(http.StatusNotFound)+`",endpoint="/orgs/{org}",method="GET"} 1`)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Fri, 16 Oct 2026 12:00:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("Fri, 16 Oct 2026 11:00:00 GMT", now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	// echo -n '{"ref":"refs/heads/main"}' | openssl dgst -sha256 -hmac s3cret
+	const signature = "232a5e067b6a5aa567ed5a1d21a0dffaf6ef0eeecb6bd9bcf43b3d5ef3fc4f43"
+
+	assert.True(t, VerifySignature(secret, body, signature))
+	assert.True(t, VerifySignature(secret, body, "sha256="+signature))
+	assert.False(t, VerifySignature(secret, []byte(`{"ref":"refs/heads/evil"}`), signature))
+	assert.False(t, VerifySignature([]byte("other"), body, signature))
+	assert.False(t, VerifySignature(nil, body, signature))
+	assert.False(t, VerifySignature(secret, body, "not hex"))
+	assert.False(t, VerifySignature(secret, body, ""))
+}
+
+func TestPushPayload(t *testing.T) {
+	p := &PushPayload{Ref: "refs/heads/main", After: "5f3c0a1e"}
+	assert.Equal(t, "main", p.Branch())
+	assert.False(t, p.Deleted())
+
+	p = &PushPayload{Ref: "refs/tags/v1", After: "0000000000000000000000000000000000000000"}
+	assert.Equal(t, "", p.Branch())
+	assert.True(t, p.Deleted())
+}
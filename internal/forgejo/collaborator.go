@@ -0,0 +1,38 @@
+package forgejo
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// AddCollaborator grants username the given permission on a repository
+func (c *Client) AddCollaborator(ctx context.Context, owner, repo, username, permission string) error {
+	path := repoPath(owner, repo) + "/collaborators/" + escape(username)
+	_, err := c.do(ctx, http.MethodPut, path, nil, AddCollaboratorOption{Permission: permission}, nil)
+	return err
+}
+
+// RemoveCollaborator revokes username's access to a repository
+func (c *Client) RemoveCollaborator(ctx context.Context, owner, repo, username string) error {
+	path := repoPath(owner, repo) + "/collaborators/" + escape(username)
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// IsCollaborator reports whether username is a collaborator on a repository
+func (c *Client) IsCollaborator(ctx context.Context, owner, repo, username string) (bool, error) {
+	path := repoPath(owner, repo) + "/collaborators/" + escape(username)
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, nil); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Collaborators iterates over the collaborators of a repository
+func (c *Client) Collaborators(ctx context.Context, owner, repo string) iter.Seq2[*User, error] {
+	return paginate[*User](ctx, c, repoPath(owner, repo)+"/collaborators", nil)
+}
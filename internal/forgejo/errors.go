@@ -0,0 +1,121 @@
+package forgejo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// maxErrorBodySize limits how much of an error response body is read
+const maxErrorBodySize = 64 * 1024
+
+// Error is returned for any failed Forgejo API call. Code is one of the
+// INTEGRATION_FORGEJO_* codes from the error taxonomy.
+type Error struct {
+	StatusCode int    // HTTP status code, 0 for transport failures
+	Code       string // error taxonomy code
+	Message    string // message reported by Forgejo, if any
+	Method     string
+	Path       string
+	RetryAfter time.Duration // wait requested by Forgejo (Retry-After) or the client throttle, if any
+	Err        error         // underlying transport error, if any
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("forgejo %s %s: %v", e.Method, e.Path, e.Err)
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("forgejo %s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("forgejo %s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns the underlying transport error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// apiErrorBody is the error payload returned by Forgejo
+type apiErrorBody struct {
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+	URL     string   `json:"url"`
+}
+
+// newResponseError maps a non-2xx response to an *Error
+func newResponseError(req *http.Request, resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       codeForStatus(resp.StatusCode),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	var body apiErrorBody
+	if err := json.Unmarshal(data, &body); err == nil {
+		apiErr.Message = body.Message
+		if apiErr.Message == "" && len(body.Errors) > 0 {
+			apiErr.Message = body.Errors[0]
+		}
+	}
+
+	return apiErr
+}
+
+// newTransportError wraps a network-level failure
+func newTransportError(req *http.Request, err error) *Error {
+	return &Error{
+		Code:   response.ErrIntegrationForgejoUnavailable,
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Err:    err,
+	}
+}
+
+// codeForStatus maps an HTTP status code to an error taxonomy code
+func codeForStatus(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return response.ErrIntegrationForgejoRateLimited
+	case status == http.StatusBadGateway,
+		status == http.StatusServiceUnavailable,
+		status == http.StatusGatewayTimeout:
+		return response.ErrIntegrationForgejoUnavailable
+	default:
+		return response.ErrIntegrationForgejoAPI
+	}
+}
+
+// StatusCode returns the HTTP status code of a Forgejo error, or 0
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is a Forgejo 404
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err indicates the resource already exists
+func IsConflict(err error) bool {
+	status := StatusCode(err)
+	return status == http.StatusConflict || status == http.StatusUnprocessableEntity
+}
+
+// IsUnauthorized reports whether err is a Forgejo 401
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
@@ -0,0 +1,809 @@
+package forgejotest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+)
+
+// repoLocked looks up the repository addressed by the request path
+func (s *Server) repoLocked(r *http.Request) (*repoState, bool) {
+	state, ok := s.repos[strings.ToLower(r.PathValue("owner")+"/"+r.PathValue("repo"))]
+	return state, ok
+}
+
+// teamLocked looks up the team addressed by the request path
+func (s *Server) teamLocked(r *http.Request) (*teamState, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	team, ok := s.teams[id]
+	return team, ok
+}
+
+// resolveRefLocked resolves a branch name, tag name or commit SHA to the
+// commit history starting at that ref (newest first)
+func resolveRefLocked(state *repoState, ref string) ([]*forgejo.Commit, bool) {
+	if ref == "" {
+		ref = state.repo.DefaultBranch
+	}
+	if commits, ok := state.branches[ref]; ok {
+		return commits, true
+	}
+	if tag, ok := state.tags[ref]; ok {
+		ref = tag.Commit.SHA
+	}
+	for _, commits := range state.branches {
+		for i, c := range commits {
+			if c.SHA == ref {
+				return commits[i:], true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.users[strings.ToLower(r.Header.Get("X-Fake-Login"))])
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[strings.ToLower(r.PathValue("username"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "user does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleCreateOrg(w http.ResponseWriter, r *http.Request) {
+	s.createOrg(w, r, r.Header.Get("X-Fake-Login"))
+}
+
+func (s *Server) handleAdminCreateOrg(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	requester := s.users[strings.ToLower(r.Header.Get("X-Fake-Login"))]
+	_, exists := s.users[strings.ToLower(r.PathValue("username"))]
+	s.mu.Unlock()
+
+	if requester == nil || !requester.IsAdmin {
+		writeError(w, http.StatusForbidden, "must be an admin")
+		return
+	}
+	if !exists {
+		writeError(w, http.StatusNotFound, "user does not exist")
+		return
+	}
+	s.createOrg(w, r, r.PathValue("username"))
+}
+
+// createOrg creates the organization described by the request body and
+// makes owner its owner
+func (s *Server) createOrg(w http.ResponseWriter, r *http.Request, owner string) {
+	var opt forgejo.CreateOrgOption
+	if err := decode(r, &opt); err != nil || opt.UserName == "" {
+		writeError(w, http.StatusUnprocessableEntity, "username is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(opt.UserName)
+	if _, exists := s.orgs[key]; exists {
+		writeError(w, http.StatusUnprocessableEntity, "user already exists")
+		return
+	}
+	if _, exists := s.users[key]; exists {
+		writeError(w, http.StatusUnprocessableEntity, "user already exists")
+		return
+	}
+
+	org := s.addOrganizationLocked(opt.UserName, owner)
+	s.orgOwner[key][strings.ToLower(owner)] = true
+	org.FullName = opt.FullName
+	org.Description = opt.Description
+	if opt.Visibility != "" {
+		org.Visibility = opt.Visibility
+	}
+	writeJSON(w, http.StatusCreated, org)
+}
+
+func (s *Server) handleGetOrg(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org, ok := s.orgs[strings.ToLower(r.PathValue("org"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "organization does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, org)
+}
+
+func (s *Server) handleIsOrgMember(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, ok := s.orgMember[strings.ToLower(r.PathValue("org"))]
+	if !ok || !members[strings.ToLower(r.PathValue("username"))] {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleOrgPermissions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(r.PathValue("org"))
+	if _, ok := s.orgs[key]; !ok {
+		writeError(w, http.StatusNotFound, "organization does not exist")
+		return
+	}
+	login := strings.ToLower(r.PathValue("username"))
+	owner := s.orgOwner[key][login]
+	member := s.orgMember[key][login]
+	writeJSON(w, http.StatusOK, forgejo.OrgPermissions{
+		IsOwner:             owner,
+		IsAdmin:             owner,
+		CanWrite:            owner,
+		CanRead:             member,
+		CanCreateRepository: owner,
+	})
+}
+
+func (s *Server) handleListOrgRepos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := r.PathValue("org")
+	if _, ok := s.orgs[strings.ToLower(org)]; !ok {
+		writeError(w, http.StatusNotFound, "organization does not exist")
+		return
+	}
+
+	var repos []*forgejo.Repository
+	for _, state := range s.repos {
+		if strings.EqualFold(state.repo.Owner.Login, org) {
+			repos = append(repos, state.repo)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].ID < repos[j].ID })
+	writePage(w, r, repos)
+}
+
+func (s *Server) handleListOrgTeams(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var teams []*forgejo.Team
+	for _, team := range s.teams {
+		if strings.EqualFold(team.org, r.PathValue("org")) {
+			teams = append(teams, team.team)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	writePage(w, r, teams)
+}
+
+func (s *Server) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	var opt forgejo.CreateTeamOption
+	if err := decode(r, &opt); err != nil || opt.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orgName := r.PathValue("org")
+	org, ok := s.orgs[strings.ToLower(orgName)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "organization does not exist")
+		return
+	}
+	for _, team := range s.teams {
+		if strings.EqualFold(team.org, orgName) && strings.EqualFold(team.team.Name, opt.Name) {
+			writeError(w, http.StatusUnprocessableEntity, "team already exists")
+			return
+		}
+	}
+
+	team := &teamState{
+		team: &forgejo.Team{
+			ID:                      s.allocID(),
+			Name:                    opt.Name,
+			Description:             opt.Description,
+			Organization:            org,
+			Permission:              opt.Permission,
+			Units:                   opt.Units,
+			IncludesAllRepositories: opt.IncludesAllRepositories,
+			CanCreateOrgRepo:        opt.CanCreateOrgRepo,
+		},
+		org:     org.Name,
+		members: make(map[string]bool),
+		repos:   make(map[string]bool),
+	}
+	s.teams[team.team.ID] = team
+	writeJSON(w, http.StatusCreated, team.team)
+}
+
+func (s *Server) handleGetRepoByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.repos {
+		if state.repo.ID == id {
+			writeJSON(w, http.StatusOK, state.repo)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "repository does not exist")
+}
+
+func (s *Server) handleGetRepo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, state.repo)
+}
+
+func (s *Server) handleDeleteRepo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	delete(s.repos, strings.ToLower(state.repo.FullName))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGenerateRepo(w http.ResponseWriter, r *http.Request) {
+	var opt forgejo.GenerateRepoOption
+	if err := decode(r, &opt); err != nil || opt.Owner == "" || opt.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "owner and name are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	if !template.repo.Template {
+		writeError(w, http.StatusUnprocessableEntity, "repository is not a template")
+		return
+	}
+	_, isOrg := s.orgs[strings.ToLower(opt.Owner)]
+	_, isUser := s.users[strings.ToLower(opt.Owner)]
+	if !isOrg && !isUser {
+		writeError(w, http.StatusNotFound, "owner does not exist")
+		return
+	}
+	if _, exists := s.repos[strings.ToLower(opt.Owner+"/"+opt.Name)]; exists {
+		writeError(w, http.StatusConflict, "repository already exists")
+		return
+	}
+
+	state := s.addRepositoryLocked(opt.Owner, opt.Name, false, opt.Private)
+	state.repo.Description = opt.Description
+	if opt.GitContent {
+		for _, c := range template.branches[template.repo.DefaultBranch] {
+			copied := *c
+			state.branches[DefaultBranch] = append(state.branches[DefaultBranch], &copied)
+		}
+		state.repo.Empty = len(state.branches[DefaultBranch]) == 0
+	}
+	writeJSON(w, http.StatusCreated, state.repo)
+}
+
+func (s *Server) handleListCollaborators(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+
+	var users []*forgejo.User
+	for login := range state.collaborators {
+		if user, ok := s.users[strings.ToLower(login)]; ok {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	writePage(w, r, users)
+}
+
+func (s *Server) handleIsCollaborator(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	if _, ok := state.collaborators[strings.ToLower(r.PathValue("username"))]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAddCollaborator(w http.ResponseWriter, r *http.Request) {
+	var opt forgejo.AddCollaboratorOption
+	if err := decode(r, &opt); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid body")
+		return
+	}
+	if opt.Permission == "" {
+		opt.Permission = forgejo.PermissionWrite
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	login := strings.ToLower(r.PathValue("username"))
+	if _, ok := s.users[login]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "user does not exist")
+		return
+	}
+	state.collaborators[login] = opt.Permission
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRemoveCollaborator(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	delete(state.collaborators, strings.ToLower(r.PathValue("username")))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// branchFor builds the API representation of a branch
+func branchFor(name string, commits []*forgejo.Commit) *forgejo.Branch {
+	branch := &forgejo.Branch{Name: name}
+	if len(commits) > 0 {
+		head := commits[0]
+		branch.Commit = &forgejo.PayloadCommit{
+			ID:        head.SHA,
+			Message:   head.Commit.Message,
+			URL:       head.HTMLURL,
+			Author:    &forgejo.PayloadUser{Name: head.Commit.Author.Name, Email: head.Commit.Author.Email},
+			Committer: &forgejo.PayloadUser{Name: head.Commit.Committer.Name, Email: head.Commit.Committer.Email},
+			Timestamp: head.Created,
+		}
+	}
+	return branch
+}
+
+func (s *Server) handleListBranches(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+
+	names := make([]string, 0, len(state.branches))
+	for name := range state.branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	branches := make([]*forgejo.Branch, 0, len(names))
+	for _, name := range names {
+		branches = append(branches, branchFor(name, state.branches[name]))
+	}
+	writePage(w, r, branches)
+}
+
+func (s *Server) handleGetBranch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	name := r.PathValue("branch")
+	commits, ok := state.branches[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "branch does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, branchFor(name, commits))
+}
+
+func (s *Server) handleListCommits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	if state.repo.Empty {
+		writeError(w, http.StatusConflict, "repository is empty")
+		return
+	}
+
+	history, ok := resolveRefLocked(state, r.URL.Query().Get("sha"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "ref does not exist")
+		return
+	}
+
+	var since, until time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, _ = time.Parse(time.RFC3339, v)
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		until, _ = time.Parse(time.RFC3339, v)
+	}
+
+	commits := make([]*forgejo.Commit, 0, len(history))
+	for _, c := range history {
+		if !since.IsZero() && c.Created.Before(since) {
+			continue
+		}
+		if !until.IsZero() && c.Created.After(until) {
+			continue
+		}
+		commits = append(commits, c)
+	}
+	writePage(w, r, commits)
+}
+
+// handleGetArchive serves an archive with the files of the fake's
+// commits: a README.md and a COMMIT file holding the SHA the ref resolved
+// to. Like git archive, tar archives start with a global header carrying
+// the SHA and nest all entries in a directory named after the repository.
+func (s *Server) handleGetArchive(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	state, ok := s.repoLocked(r)
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	name := r.PathValue("archive")
+	ref, format := strings.TrimSuffix(name, ".tar.gz"), forgejo.ArchiveTarGz
+	if ref == name {
+		ref, format = strings.TrimSuffix(name, ".zip"), forgejo.ArchiveZip
+	}
+	history, found := resolveRefLocked(state, ref)
+	repo := state.repo.Name
+	s.mu.Unlock()
+
+	if ref == name {
+		writeError(w, http.StatusBadRequest, "unknown archive format")
+		return
+	}
+	if !found || len(history) == 0 {
+		writeError(w, http.StatusNotFound, "ref does not exist")
+		return
+	}
+
+	commit := history[0]
+	files := []struct{ name, content string }{
+		{"README.md", "# " + repo + "\n"},
+		{"COMMIT", commit.SHA + "\n"},
+	}
+
+	if format == forgejo.ArchiveZip {
+		w.Header().Set("Content-Type", "application/zip")
+		zw := zip.NewWriter(w)
+		_, _ = zw.Create(repo + "/")
+		for _, f := range files {
+			fw, _ := zw.CreateHeader(&zip.FileHeader{Name: repo + "/" + f.name, Method: zip.Deflate, Modified: commit.Created})
+			_, _ = io.WriteString(fw, f.content)
+		}
+		_ = zw.Close()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	_ = tw.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       "pax_global_header",
+		PAXRecords: map[string]string{"comment": commit.SHA},
+		Format:     tar.FormatPAX,
+	})
+	_ = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: repo + "/", Mode: 0o775, ModTime: commit.Created})
+	for _, f := range files {
+		_ = tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     repo + "/" + f.name,
+			Mode:     0o664,
+			Size:     int64(len(f.content)),
+			ModTime:  commit.Created,
+		})
+		_, _ = io.WriteString(tw, f.content)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+}
+
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+
+	tags := make([]*forgejo.Tag, 0, len(state.tagOrder))
+	for i := len(state.tagOrder) - 1; i >= 0; i-- {
+		tags = append(tags, state.tags[state.tagOrder[i]])
+	}
+	writePage(w, r, tags)
+}
+
+func (s *Server) handleCreateTag(w http.ResponseWriter, r *http.Request) {
+	var opt forgejo.CreateTagOption
+	if err := decode(r, &opt); err != nil || opt.TagName == "" {
+		writeError(w, http.StatusUnprocessableEntity, "tag_name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	if _, exists := state.tags[opt.TagName]; exists {
+		writeError(w, http.StatusConflict, "tag already exists")
+		return
+	}
+	history, ok := resolveRefLocked(state, opt.Target)
+	if !ok || len(history) == 0 {
+		writeError(w, http.StatusNotFound, "target does not exist")
+		return
+	}
+
+	sha := history[0].SHA
+	tag := &forgejo.Tag{
+		Name:       opt.TagName,
+		Message:    opt.Message,
+		ID:         s.newSHA("tag/" + opt.TagName),
+		Commit:     &forgejo.CommitMeta{SHA: sha, URL: history[0].URL, Created: history[0].Created},
+		ZipballURL: state.repo.HTMLURL + "/archive/" + opt.TagName + ".zip",
+		TarballURL: state.repo.HTMLURL + "/archive/" + opt.TagName + ".tar.gz",
+	}
+	state.tags[opt.TagName] = tag
+	state.tagOrder = append(state.tagOrder, opt.TagName)
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+func (s *Server) handleGetTag(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	tag, ok := state.tags[r.PathValue("tag")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "tag does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, tag)
+}
+
+func (s *Server) handleListHooks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	writePage(w, r, state.hooks)
+}
+
+func (s *Server) handleCreateHook(w http.ResponseWriter, r *http.Request) {
+	var opt forgejo.CreateHookOption
+	if err := decode(r, &opt); err != nil || opt.Type == "" || opt.Config["url"] == "" {
+		writeError(w, http.StatusUnprocessableEntity, "type and config.url are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+
+	now := time.Now().UTC()
+	hook := &forgejo.Hook{
+		ID:        s.allocID(),
+		Type:      opt.Type,
+		Config:    opt.Config,
+		Events:    opt.Events,
+		Active:    opt.Active,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	state.hooks = append(state.hooks, hook)
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) handleDeleteHook(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repoLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	for i, h := range state.hooks {
+		if h.ID == id {
+			state.hooks = append(state.hooks[:i], state.hooks[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "hook does not exist")
+}
+
+func (s *Server) handleGetTeam(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+	writeJSON(w, http.StatusOK, team.team)
+}
+
+func (s *Server) handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+	delete(s.teams, team.team.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListTeamMembers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+
+	var users []*forgejo.User
+	for login := range team.members {
+		if user, ok := s.users[login]; ok {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	writePage(w, r, users)
+}
+
+func (s *Server) handleAddTeamMember(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+	login := strings.ToLower(r.PathValue("username"))
+	if _, ok := s.users[login]; !ok {
+		writeError(w, http.StatusNotFound, "user does not exist")
+		return
+	}
+	team.members[login] = true
+	s.orgMember[strings.ToLower(team.org)][login] = true
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+	delete(team.members, strings.ToLower(r.PathValue("username")))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAddTeamRepo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teamLocked(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "team does not exist")
+		return
+	}
+	fullName := strings.ToLower(r.PathValue("org") + "/" + r.PathValue("repo"))
+	if _, ok := s.repos[fullName]; !ok {
+		writeError(w, http.StatusNotFound, "repository does not exist")
+		return
+	}
+	team.repos[fullName] = true
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,314 @@
+// Package forgejotest provides an in-process fake Forgejo server for tests.
+//
+// The fake implements the subset of the Forgejo REST API used by the
+// classroom (users, organizations, template generation, collaborators,
+// teams, branches, commits, archives, tags and webhooks) on top of
+// in-memory state that tests can seed before exercising code and inspect
+// afterwards.
+package forgejotest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+)
+
+// AdminLogin and AdminToken identify the site administrator every fake server starts with
+const (
+	AdminLogin = "classroom-admin"
+	AdminToken = "admin-token"
+)
+
+// Server is a fake Forgejo instance backed by in-memory state
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int64
+	commitNo int64
+
+	users     map[string]*forgejo.User // by login
+	tokens    map[string]string        // token -> login
+	orgs      map[string]*forgejo.Organization
+	orgMember map[string]map[string]bool // org -> login -> member
+	orgOwner  map[string]map[string]bool // org -> login -> owner
+	repos     map[string]*repoState      // by full name
+	teams     map[int64]*teamState
+
+	failures []*injectedFailure
+	requests []RecordedRequest
+}
+
+// RecordedRequest is a request observed by the fake server
+type RecordedRequest struct {
+	Method string
+	Path   string
+}
+
+type repoState struct {
+	repo          *forgejo.Repository
+	branches      map[string][]*forgejo.Commit // newest first
+	tags          map[string]*forgejo.Tag
+	tagOrder      []string
+	collaborators map[string]string // login -> permission
+	hooks         []*forgejo.Hook
+}
+
+type teamState struct {
+	team    *forgejo.Team
+	org     string
+	members map[string]bool
+	repos   map[string]bool
+}
+
+type injectedFailure struct {
+	method    string
+	path      string
+	status    int
+	remaining int
+}
+
+// NewServer starts a fake Forgejo server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		users:     make(map[string]*forgejo.User),
+		tokens:    make(map[string]string),
+		orgs:      make(map[string]*forgejo.Organization),
+		orgMember: make(map[string]map[string]bool),
+		orgOwner:  make(map[string]map[string]bool),
+		repos:     make(map[string]*repoState),
+		teams:     make(map[int64]*teamState),
+	}
+
+	s.AddUser(AdminLogin, AdminToken)
+	s.users[AdminLogin].IsAdmin = true
+
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Client returns a forgejo.Client authenticated as the site administrator
+func (s *Server) Client(t testing.TB) *forgejo.Client {
+	t.Helper()
+	return s.ClientWithToken(t, AdminToken)
+}
+
+// ClientWithToken returns a forgejo.Client authenticated with token
+func (s *Server) ClientWithToken(t testing.TB, token string) *forgejo.Client {
+	t.Helper()
+
+	client, err := forgejo.New(s.Config(token), zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create forgejo client: %v", err)
+	}
+	return client
+}
+
+// Config returns a ForgejoConfig pointing at the fake server
+func (s *Server) Config(token string) *config.ForgejoConfig {
+	return &config.ForgejoConfig{
+		BaseURL: s.URL,
+		Token:   token,
+		Timeout: 5 * time.Second,
+	}
+}
+
+// FailNext makes the next count requests matching method and path
+// (without the /api/v1 prefix) fail with status
+func (s *Server) FailNext(method, path string, status, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &injectedFailure{
+		method:    method,
+		path:      path,
+		status:    status,
+		remaining: count,
+	})
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// routes registers the API endpoints implemented by the fake
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/user", s.handleCurrentUser)
+	mux.HandleFunc("GET /api/v1/users/{username}", s.handleGetUser)
+	mux.HandleFunc("GET /api/v1/users/{username}/orgs/{org}/permissions", s.handleOrgPermissions)
+	mux.HandleFunc("POST /api/v1/admin/users/{username}/orgs", s.handleAdminCreateOrg)
+
+	mux.HandleFunc("POST /api/v1/orgs", s.handleCreateOrg)
+	mux.HandleFunc("GET /api/v1/orgs/{org}", s.handleGetOrg)
+	mux.HandleFunc("GET /api/v1/orgs/{org}/members/{username}", s.handleIsOrgMember)
+	mux.HandleFunc("GET /api/v1/orgs/{org}/repos", s.handleListOrgRepos)
+	mux.HandleFunc("GET /api/v1/orgs/{org}/teams", s.handleListOrgTeams)
+	mux.HandleFunc("POST /api/v1/orgs/{org}/teams", s.handleCreateTeam)
+
+	mux.HandleFunc("GET /api/v1/repositories/{id}", s.handleGetRepoByID)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}", s.handleGetRepo)
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}", s.handleDeleteRepo)
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/generate", s.handleGenerateRepo)
+
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/collaborators", s.handleListCollaborators)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/collaborators/{username}", s.handleIsCollaborator)
+	mux.HandleFunc("PUT /api/v1/repos/{owner}/{repo}/collaborators/{username}", s.handleAddCollaborator)
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}/collaborators/{username}", s.handleRemoveCollaborator)
+
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/branches", s.handleListBranches)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/branches/{branch...}", s.handleGetBranch)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/commits", s.handleListCommits)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/archive/{archive}", s.handleGetArchive)
+
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/tags", s.handleListTags)
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/tags", s.handleCreateTag)
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/tags/{tag...}", s.handleGetTag)
+
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/hooks", s.handleListHooks)
+	mux.HandleFunc("POST /api/v1/repos/{owner}/{repo}/hooks", s.handleCreateHook)
+	mux.HandleFunc("DELETE /api/v1/repos/{owner}/{repo}/hooks/{id}", s.handleDeleteHook)
+
+	mux.HandleFunc("GET /api/v1/teams/{id}", s.handleGetTeam)
+	mux.HandleFunc("DELETE /api/v1/teams/{id}", s.handleDeleteTeam)
+	mux.HandleFunc("GET /api/v1/teams/{id}/members", s.handleListTeamMembers)
+	mux.HandleFunc("PUT /api/v1/teams/{id}/members/{username}", s.handleAddTeamMember)
+	mux.HandleFunc("DELETE /api/v1/teams/{id}/members/{username}", s.handleRemoveTeamMember)
+	mux.HandleFunc("PUT /api/v1/teams/{id}/repos/{org}/{repo}", s.handleAddTeamRepo)
+
+	return s.middleware(mux)
+}
+
+// middleware records requests, applies injected failures and enforces authentication
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+
+		s.mu.Lock()
+		s.requests = append(s.requests, RecordedRequest{Method: r.Method, Path: path})
+		status := s.takeFailureLocked(r.Method, path)
+		s.mu.Unlock()
+
+		if status != 0 {
+			writeError(w, status, http.StatusText(status))
+			return
+		}
+
+		login, ok := s.authenticate(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "token is required")
+			return
+		}
+
+		r.Header.Set("X-Fake-Login", login)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// takeFailureLocked consumes a matching injected failure, if any
+func (s *Server) takeFailureLocked(method, path string) int {
+	for i, f := range s.failures {
+		if f.method == method && f.path == path {
+			f.remaining--
+			if f.remaining <= 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+			return f.status
+		}
+	}
+	return 0
+}
+
+// authenticate resolves the token in the Authorization header to a login
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	token := ""
+	switch {
+	case strings.HasPrefix(header, "token "):
+		token = strings.TrimPrefix(header, "token ")
+	case strings.HasPrefix(header, "Bearer "):
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login, ok := s.tokens[token]
+	return login, ok
+}
+
+// allocID returns the next unique ID
+func (s *Server) allocID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// newSHA returns a unique, stable-looking commit SHA
+func (s *Server) newSHA(seed string) string {
+	s.commitNo++
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s/%d", seed, s.commitNo)))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a Forgejo-style error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writePage writes one page of a list response with X-Total-Count
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 30
+	}
+
+	start := (page - 1) * limit
+	end := start + limit
+	if start > len(items) {
+		start = len(items)
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+// decode reads a JSON request body
+func decode(r *http.Request, v interface{}) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
@@ -0,0 +1,134 @@
+package forgejotest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+)
+
+func TestServer_Authentication(t *testing.T) {
+	server := NewServer(t)
+	server.AddUser("jdoe", "jdoe-token")
+
+	t.Run("known token resolves to its user", func(t *testing.T) {
+		user, err := server.ClientWithToken(t, "jdoe-token").CurrentUser(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", user.Login)
+	})
+
+	t.Run("unknown token is rejected", func(t *testing.T) {
+		_, err := server.ClientWithToken(t, "bogus").CurrentUser(context.Background())
+		assert.True(t, forgejo.IsUnauthorized(err))
+	})
+}
+
+func TestServer_Organizations(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(t)
+	server.AddUser("prof", "prof-token")
+	server.AddOrganization("cs101", "jdoe")
+
+	client := server.Client(t)
+
+	t.Run("members are not owners", func(t *testing.T) {
+		perms, err := client.OrganizationPermissions(ctx, "cs101", "jdoe")
+		require.NoError(t, err)
+		assert.True(t, perms.CanRead)
+		assert.False(t, perms.IsOwner)
+	})
+
+	t.Run("organizations created for a user are owned by them", func(t *testing.T) {
+		_, err := client.CreateOrganizationFor(ctx, "prof", forgejo.CreateOrgOption{UserName: "ee201"})
+		require.NoError(t, err)
+
+		perms, err := client.OrganizationPermissions(ctx, "ee201", "prof")
+		require.NoError(t, err)
+		assert.True(t, perms.IsOwner)
+	})
+
+	t.Run("only site administrators create organizations for others", func(t *testing.T) {
+		_, err := server.ClientWithToken(t, "prof-token").CreateOrganizationFor(ctx, "prof", forgejo.CreateOrgOption{UserName: "ma301"})
+		assert.Equal(t, http.StatusForbidden, forgejo.StatusCode(err))
+	})
+}
+
+func TestServer_GenerateFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	server := NewServer(t)
+	server.AddOrganization("cs101")
+	server.AddUser("jdoe", "")
+	server.AddRepository("cs101", "hw1-template", true)
+	server.Push("cs101", "hw1-template", DefaultBranch, "Add starter code")
+
+	client := server.Client(t)
+
+	repo, err := client.GenerateRepository(ctx, "cs101", "hw1-template", forgejo.GenerateRepoOption{
+		Owner:      "cs101",
+		Name:       "hw1-jdoe",
+		Private:    true,
+		GitContent: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cs101/hw1-jdoe", repo.FullName)
+	assert.True(t, repo.Private)
+
+	t.Run("history is copied from the template", func(t *testing.T) {
+		count, err := client.CountCommits(ctx, "cs101", "hw1-jdoe", forgejo.CommitListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("generating the same name twice conflicts", func(t *testing.T) {
+		_, err := client.GenerateRepository(ctx, "cs101", "hw1-template", forgejo.GenerateRepoOption{
+			Owner: "cs101",
+			Name:  "hw1-jdoe",
+		})
+		assert.True(t, forgejo.IsConflict(err))
+	})
+
+	t.Run("collaborators are recorded", func(t *testing.T) {
+		require.NoError(t, client.AddCollaborator(ctx, "cs101", "hw1-jdoe", "jdoe", forgejo.PermissionWrite))
+		assert.Equal(t, map[string]string{"jdoe": forgejo.PermissionWrite}, server.Collaborators("cs101", "hw1-jdoe"))
+	})
+
+	t.Run("tags point at the branch head", func(t *testing.T) {
+		head := server.HeadSHA("cs101", "hw1-jdoe", DefaultBranch)
+		tag, err := client.CreateTag(ctx, "cs101", "hw1-jdoe", forgejo.CreateTagOption{
+			TagName: "deadline",
+			Target:  DefaultBranch,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, head, tag.Commit.SHA)
+		require.Len(t, server.Tags("cs101", "hw1-jdoe"), 1)
+	})
+
+	t.Run("webhooks are recorded", func(t *testing.T) {
+		_, err := client.CreateRepoHook(ctx, "cs101", "hw1-jdoe", forgejo.CreateHookOption{
+			Type:   forgejo.HookTypeForgejo,
+			Config: map[string]string{"url": "http://classroom/webhooks", "content_type": "json"},
+			Events: []string{"push"},
+			Active: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, server.Hooks("cs101", "hw1-jdoe"), 1)
+	})
+}
+
+func TestServer_FailNext(t *testing.T) {
+	server := NewServer(t)
+	server.AddOrganization("cs101")
+	server.FailNext(http.MethodGet, "/orgs/cs101", http.StatusServiceUnavailable, 1)
+
+	client := server.Client(t)
+
+	_, err := client.GetOrganization(context.Background(), "cs101")
+	assert.Equal(t, http.StatusServiceUnavailable, forgejo.StatusCode(err))
+
+	_, err = client.GetOrganization(context.Background(), "cs101")
+	assert.NoError(t, err)
+}
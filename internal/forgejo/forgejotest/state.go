@@ -0,0 +1,256 @@
+package forgejotest
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+)
+
+// DefaultBranch is the default branch of every repository created by the fake
+const DefaultBranch = "main"
+
+// AddUser registers a user. If token is non-empty it authenticates as that user.
+func (s *Server) AddUser(login, token string) forgejo.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := &forgejo.User{
+		ID:       s.allocID(),
+		Login:    login,
+		FullName: login,
+		Email:    login + "@example.com",
+	}
+	s.users[strings.ToLower(login)] = user
+	if token != "" {
+		s.tokens[token] = login
+	}
+
+	return *user
+}
+
+// AddOrganization registers an organization with the given members
+func (s *Server) AddOrganization(name string, members ...string) forgejo.Organization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addOrganizationLocked(name, members...)
+}
+
+func (s *Server) addOrganizationLocked(name string, members ...string) *forgejo.Organization {
+	org := &forgejo.Organization{
+		ID:         s.allocID(),
+		Name:       name,
+		UserName:   name,
+		FullName:   name,
+		Visibility: "public",
+	}
+	key := strings.ToLower(name)
+	s.orgs[key] = org
+	s.orgMember[key] = make(map[string]bool)
+	s.orgOwner[key] = make(map[string]bool)
+	for _, m := range members {
+		s.orgMember[key][strings.ToLower(m)] = true
+	}
+	return org
+}
+
+// AddOrganizationOwner makes login an owner, and so a member, of org
+func (s *Server) AddOrganizationOwner(org, login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(org)
+	s.orgMember[key][strings.ToLower(login)] = true
+	s.orgOwner[key][strings.ToLower(login)] = true
+}
+
+// AddRepository creates a repository with a single initial commit on DefaultBranch
+func (s *Server) AddRepository(owner, name string, template bool) forgejo.Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.addRepositoryLocked(owner, name, template, false)
+	s.pushLocked(state, DefaultBranch, "Initial commit", owner, time.Now())
+
+	return *state.repo
+}
+
+// AddEmptyRepository creates a repository without commits
+func (s *Server) AddEmptyRepository(owner, name string) forgejo.Repository {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addRepositoryLocked(owner, name, false, false).repo
+}
+
+func (s *Server) addRepositoryLocked(owner, name string, template, private bool) *repoState {
+	fullName := owner + "/" + name
+	now := time.Now().UTC()
+
+	ownerUser := &forgejo.User{Login: owner}
+	if org, ok := s.orgs[strings.ToLower(owner)]; ok {
+		ownerUser.ID = org.ID
+	} else if user, ok := s.users[strings.ToLower(owner)]; ok {
+		ownerUser.ID = user.ID
+	}
+
+	state := &repoState{
+		repo: &forgejo.Repository{
+			ID:            s.allocID(),
+			Owner:         ownerUser,
+			Name:          name,
+			FullName:      fullName,
+			Private:       private,
+			Template:      template,
+			Empty:         true,
+			DefaultBranch: DefaultBranch,
+			HTMLURL:       s.URL + "/" + fullName,
+			CloneURL:      s.URL + "/" + fullName + ".git",
+			SSHURL:        "git@fake-forgejo:" + fullName + ".git",
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		branches:      make(map[string][]*forgejo.Commit),
+		tags:          make(map[string]*forgejo.Tag),
+		collaborators: make(map[string]string),
+	}
+	s.repos[strings.ToLower(fullName)] = state
+
+	return state
+}
+
+// Push adds a commit to branch, creating the branch if needed
+func (s *Server) Push(owner, repo, branch, message string) forgejo.Commit {
+	return s.PushAt(owner, repo, branch, message, time.Now())
+}
+
+// PushAt adds a commit with an explicit timestamp to branch
+func (s *Server) PushAt(owner, repo, branch, message string, when time.Time) forgejo.Commit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repos[strings.ToLower(owner+"/"+repo)]
+	if !ok {
+		panic("forgejotest: unknown repository " + owner + "/" + repo)
+	}
+	return *s.pushLocked(state, branch, message, owner, when)
+}
+
+func (s *Server) pushLocked(state *repoState, branch, message, author string, when time.Time) *forgejo.Commit {
+	when = when.UTC()
+	sha := s.newSHA(state.repo.FullName)
+	commit := &forgejo.Commit{
+		SHA:     sha,
+		URL:     s.URL + "/api/v1/repos/" + state.repo.FullName + "/git/commits/" + sha,
+		HTMLURL: state.repo.HTMLURL + "/commit/" + sha,
+		Commit: &forgejo.RepoCommit{
+			Message:   message,
+			Author:    &forgejo.CommitUser{Name: author, Email: author + "@example.com", Date: when.Format(time.RFC3339)},
+			Committer: &forgejo.CommitUser{Name: author, Email: author + "@example.com", Date: when.Format(time.RFC3339)},
+		},
+		Created: when,
+	}
+
+	state.branches[branch] = append([]*forgejo.Commit{commit}, state.branches[branch]...)
+	state.repo.Empty = false
+	state.repo.UpdatedAt = when
+
+	return commit
+}
+
+// Repository returns a repository by owner and name
+func (s *Server) Repository(owner, name string) (forgejo.Repository, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repos[strings.ToLower(owner+"/"+name)]
+	if !ok {
+		return forgejo.Repository{}, false
+	}
+	return *state.repo, true
+}
+
+// RepositoryCount returns the number of repositories owned by owner
+func (s *Server) RepositoryCount(owner string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, state := range s.repos {
+		if strings.EqualFold(state.repo.Owner.Login, owner) {
+			count++
+		}
+	}
+	return count
+}
+
+// Collaborators returns the collaborators of a repository mapped to their permission
+func (s *Server) Collaborators(owner, repo string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]string)
+	if state, ok := s.repos[strings.ToLower(owner+"/"+repo)]; ok {
+		for login, perm := range state.collaborators {
+			result[login] = perm
+		}
+	}
+	return result
+}
+
+// Tags returns the tags of a repository in creation order
+func (s *Server) Tags(owner, repo string) []forgejo.Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tags []forgejo.Tag
+	if state, ok := s.repos[strings.ToLower(owner+"/"+repo)]; ok {
+		for _, name := range state.tagOrder {
+			tags = append(tags, *state.tags[name])
+		}
+	}
+	return tags
+}
+
+// Hooks returns the webhooks of a repository
+func (s *Server) Hooks(owner, repo string) []forgejo.Hook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var hooks []forgejo.Hook
+	if state, ok := s.repos[strings.ToLower(owner+"/"+repo)]; ok {
+		for _, h := range state.hooks {
+			hooks = append(hooks, *h)
+		}
+	}
+	return hooks
+}
+
+// HeadSHA returns the SHA of the newest commit on branch, or "" if it has none
+func (s *Server) HeadSHA(owner, repo, branch string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.repos[strings.ToLower(owner+"/"+repo)]
+	if !ok || len(state.branches[branch]) == 0 {
+		return ""
+	}
+	return state.branches[branch][0].SHA
+}
+
+// TeamMembers returns the sorted logins of a team's members
+func (s *Server) TeamMembers(teamID int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var members []string
+	if team, ok := s.teams[teamID]; ok {
+		for login := range team.members {
+			members = append(members, login)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
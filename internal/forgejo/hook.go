@@ -0,0 +1,79 @@
+package forgejo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HookTypeForgejo is the native Forgejo webhook type
+const HookTypeForgejo = "forgejo"
+
+// Webhook delivery headers
+const (
+	HeaderEvent     = "X-Forgejo-Event"
+	HeaderSignature = "X-Forgejo-Signature"
+	HeaderDelivery  = "X-Forgejo-Delivery"
+)
+
+// HookEventPush is the event of pushes to a repository
+const HookEventPush = "push"
+
+// VerifySignature reports whether signature, the hex-encoded HMAC-SHA256
+// that Forgejo sends in HeaderSignature, matches body and secret
+func VerifySignature(secret, body []byte, signature string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(secret) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Branch returns the branch pushed to, or "" if the push was to a tag
+func (p *PushPayload) Branch() string {
+	branch, ok := strings.CutPrefix(p.Ref, "refs/heads/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// Deleted reports whether the push deleted its ref, which Forgejo sends
+// as an all-zero after commit
+func (p *PushPayload) Deleted() bool {
+	return p.After != "" && strings.Trim(p.After, "0") == ""
+}
+
+// Created reports whether the push created its ref, which Forgejo sends
+// as an all-zero before commit
+func (p *PushPayload) Created() bool {
+	return p.Before != "" && strings.Trim(p.Before, "0") == ""
+}
+
+// CreateRepoHook creates a webhook on a repository
+func (c *Client) CreateRepoHook(ctx context.Context, owner, repo string, opt CreateHookOption) (*Hook, error) {
+	var h Hook
+	if _, err := c.do(ctx, http.MethodPost, repoPath(owner, repo)+"/hooks", nil, opt, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// RepoHooks iterates over the webhooks of a repository
+func (c *Client) RepoHooks(ctx context.Context, owner, repo string) iter.Seq2[*Hook, error] {
+	return paginate[*Hook](ctx, c, repoPath(owner, repo)+"/hooks", nil)
+}
+
+// DeleteRepoHook deletes a repository webhook
+func (c *Client) DeleteRepoHook(ctx context.Context, owner, repo string, id int64) error {
+	path := repoPath(owner, repo) + "/hooks/" + strconv.FormatInt(id, 10)
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
@@ -0,0 +1,71 @@
+package forgejo
+
+import (
+	"strings"
+
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+)
+
+// endpointParams maps each literal segment of the API paths called by the
+// client to the placeholders of the parameters following it
+var endpointParams = map[string][]string{
+	"repos":         {"{owner}", "{repo}"},
+	"repositories":  {"{id}"},
+	"orgs":          {"{org}"},
+	"teams":         {"{id}"},
+	"users":         {"{username}"},
+	"collaborators": {"{username}"},
+	"members":       {"{username}"},
+	"hooks":         {"{id}"},
+	"branches":      {"{branch}"},
+	"tags":          {"{tag}"},
+	"archive":       {"{archive}"},
+	"admin":         nil,
+	"permissions":   nil,
+	"commits":       nil,
+	"generate":      nil,
+	"user":          nil,
+}
+
+// trailingParams are the segments whose parameter is the rest of the path,
+// as branch and tag names may contain slashes
+var trailingParams = map[string]bool{"branches": true, "tags": true, "archive": true}
+
+// WithMetrics returns a copy of the client that records the latency and
+// errors of its calls in m. Copies made by WithToken keep recording.
+func (c *Client) WithMetrics(m *metrics.Metrics) *Client {
+	clone := *c
+	clone.metrics = m
+	return &clone
+}
+
+// endpoint returns the API path of req with its parameters replaced by
+// placeholders, e.g. /repos/{owner}/{repo}/collaborators/{username}, so
+// that metrics have a series per endpoint rather than per repository.
+// Unknown segments are replaced by *.
+func (c *Client) endpoint(path string) string {
+	path = strings.TrimPrefix(path, c.baseURL.Path+apiPrefix)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var b strings.Builder
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		params, ok := endpointParams[segment]
+		if !ok {
+			b.WriteString("/*")
+			continue
+		}
+		b.WriteString("/" + segment)
+		for _, param := range params {
+			if i+1 >= len(segments) {
+				break
+			}
+			b.WriteString("/" + param)
+			i++
+		}
+		if trailingParams[segment] && i+1 < len(segments) {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package forgejo
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// GetOrganization looks up an organization by name
+func (c *Client) GetOrganization(ctx context.Context, name string) (*Organization, error) {
+	var org Organization
+	if _, err := c.do(ctx, http.MethodGet, "/orgs/"+escape(name), nil, nil, &org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// CreateOrganization creates a new organization owned by the token user
+func (c *Client) CreateOrganization(ctx context.Context, opt CreateOrgOption) (*Organization, error) {
+	var org Organization
+	if _, err := c.do(ctx, http.MethodPost, "/orgs", nil, opt, &org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// CreateOrganizationFor creates a new organization owned by username. It
+// requires a site administrator token.
+func (c *Client) CreateOrganizationFor(ctx context.Context, username string, opt CreateOrgOption) (*Organization, error) {
+	var org Organization
+	if _, err := c.do(ctx, http.MethodPost, "/admin/users/"+escape(username)+"/orgs", nil, opt, &org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// OrganizationPermissions returns the permissions username holds in org
+func (c *Client) OrganizationPermissions(ctx context.Context, org, username string) (*OrgPermissions, error) {
+	var perms OrgPermissions
+	path := "/users/" + escape(username) + "/orgs/" + escape(org) + "/permissions"
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &perms); err != nil {
+		return nil, err
+	}
+	return &perms, nil
+}
+
+// OrganizationRepos iterates over all repositories of an organization
+func (c *Client) OrganizationRepos(ctx context.Context, org string) iter.Seq2[*Repository, error] {
+	return paginate[*Repository](ctx, c, "/orgs/"+escape(org)+"/repos", nil)
+}
+
+// IsOrganizationMember reports whether username is a member of org
+func (c *Client) IsOrganizationMember(ctx context.Context, org, username string) (bool, error) {
+	_, err := c.do(ctx, http.MethodGet, "/orgs/"+escape(org)+"/members/"+escape(username), nil, nil, nil)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
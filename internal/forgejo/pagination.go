@@ -0,0 +1,83 @@
+package forgejo
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the number of items requested per page by iterators
+const DefaultPageSize = 50
+
+// paginate returns an iterator over all items of a paginated list endpoint.
+// Iteration stops at the first error, which is yielded with a zero item.
+func paginate[T any](ctx context.Context, c *Client, path string, query url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("limit", strconv.Itoa(DefaultPageSize))
+
+		for page := 1; ; page++ {
+			q.Set("page", strconv.Itoa(page))
+
+			var items []T
+			resp, err := c.do(ctx, http.MethodGet, path, q, nil, &items)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) < DefaultPageSize || isLastPage(resp, page) {
+				return
+			}
+		}
+	}
+}
+
+// collect drains an iterator into a slice
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// isLastPage uses X-Total-Count, when present, to detect the last page
+func isLastPage(resp *http.Response, page int) bool {
+	total, ok := totalCount(resp)
+	if !ok {
+		return false
+	}
+	return page*DefaultPageSize >= total
+}
+
+// totalCount parses the X-Total-Count header
+func totalCount(resp *http.Response) (int, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("X-Total-Count")
+	if value == "" {
+		return 0, false
+	}
+	total, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
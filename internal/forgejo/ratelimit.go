@@ -0,0 +1,178 @@
+package forgejo
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// retryBaseDelay is the backoff before the first retry; it doubles with
+// every further attempt
+const retryBaseDelay = 500 * time.Millisecond
+
+// Errors wrapped by the *Error of calls rejected before reaching Forgejo
+var (
+	ErrRateLimited = errors.New("client rate limit exceeded")
+	ErrCircuitOpen = errors.New("circuit breaker open")
+)
+
+// throttle paces the calls of a Client and all of its copies: a token
+// bucket spaces them out, failed calls are retried with exponential backoff
+// and a circuit breaker stops calling a Forgejo that keeps failing.
+type throttle struct {
+	limiter    *rate.Limiter // nil when unlimited
+	breaker    *breaker      // nil when disabled
+	maxRetries int
+	maxWait    time.Duration
+	backoff    time.Duration
+}
+
+// newThrottle creates the throttle configured in cfg
+func newThrottle(cfg *config.ForgejoConfig) *throttle {
+	t := &throttle{
+		breaker:    newBreaker(&cfg.CircuitBreaker),
+		maxRetries: cfg.MaxRetries,
+		maxWait:    cfg.MaxWait,
+		backoff:    retryBaseDelay,
+	}
+	if cfg.RateLimit.RequestsPerMinute > 0 {
+		burst := max(cfg.RateLimit.BurstSize, 1)
+		t.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.RateLimit.RequestsPerMinute)), burst)
+	}
+	return t
+}
+
+// wait blocks until req may be sent. Calls that would wait longer than
+// maxWait for a token, or that hit an open circuit, fail right away.
+func (t *throttle) wait(req *http.Request) error {
+	if code, retryAfter, ok := t.breaker.allow(); !ok {
+		return &Error{Code: code, Method: req.Method, Path: req.URL.Path, RetryAfter: retryAfter, Err: ErrCircuitOpen}
+	}
+	if t.limiter == nil {
+		return nil
+	}
+
+	reservation := t.limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if t.maxWait > 0 && delay > t.maxWait {
+		reservation.Cancel()
+		t.breaker.release()
+		return &Error{
+			Code:       response.ErrIntegrationForgejoRateLimited,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			RetryAfter: delay,
+			Err:        ErrRateLimited,
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		reservation.Cancel()
+		t.breaker.release()
+		return newTransportError(req, req.Context().Err())
+	}
+}
+
+// record feeds the outcome of a call sent to Forgejo to the breaker
+func (t *throttle) record(req *http.Request, err error) {
+	var apiErr *Error
+	switch {
+	case err == nil:
+		t.breaker.success()
+	case req.Context().Err() != nil:
+		t.breaker.release()
+	case errors.As(err, &apiErr) && isServerFailure(apiErr):
+		t.breaker.failure(apiErr.Code, apiErr.RetryAfter)
+	default:
+		// Forgejo answered; a 4xx is the caller's problem, not an outage
+		t.breaker.success()
+	}
+}
+
+// retryDelay returns how long to wait before retrying a call that failed
+// with err, or false if it must not be retried. Rate limited calls are
+// always retried, as Forgejo did not process them; other failures only for
+// idempotent methods.
+func (t *throttle) retryDelay(method string, attempt int, err error) (time.Duration, bool) {
+	var apiErr *Error
+	if attempt >= t.maxRetries || !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests && !(isServerFailure(apiErr) && isIdempotent(method)) {
+		return 0, false
+	}
+
+	delay := apiErr.RetryAfter
+	if delay == 0 {
+		// Exponential backoff less up to 50% jitter, so that callers do not retry in lockstep
+		delay = t.backoff << attempt
+		delay -= time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	}
+	if t.maxWait > 0 && delay > t.maxWait {
+		return 0, false
+	}
+	return delay, true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isServerFailure reports whether err means Forgejo is overloaded or down
+func isServerFailure(err *Error) bool {
+	return err.StatusCode == 0 || err.StatusCode == http.StatusTooManyRequests || err.StatusCode >= 500
+}
+
+// isIdempotent reports whether a request with method may be repeated safely
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date, returning 0 if it is absent or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
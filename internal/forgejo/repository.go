@@ -0,0 +1,182 @@
+package forgejo
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// GetRepository looks up a repository by owner and name
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	var r Repository
+	if _, err := c.do(ctx, http.MethodGet, repoPath(owner, repo), nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRepositoryByID looks up a repository by its numeric ID
+func (c *Client) GetRepositoryByID(ctx context.Context, id int64) (*Repository, error) {
+	var r Repository
+	path := "/repositories/" + strconv.FormatInt(id, 10)
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GenerateRepository creates a new repository from a template repository
+func (c *Client) GenerateRepository(ctx context.Context, templateOwner, templateRepo string, opt GenerateRepoOption) (*Repository, error) {
+	var r Repository
+	path := repoPath(templateOwner, templateRepo) + "/generate"
+	if _, err := c.do(ctx, http.MethodPost, path, nil, opt, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// DeleteRepository deletes a repository
+func (c *Client) DeleteRepository(ctx context.Context, owner, repo string) error {
+	_, err := c.do(ctx, http.MethodDelete, repoPath(owner, repo), nil, nil, nil)
+	return err
+}
+
+// Branches iterates over all branches of a repository
+func (c *Client) Branches(ctx context.Context, owner, repo string) iter.Seq2[*Branch, error] {
+	return paginate[*Branch](ctx, c, repoPath(owner, repo)+"/branches", nil)
+}
+
+// ListBranches returns all branches of a repository
+func (c *Client) ListBranches(ctx context.Context, owner, repo string) ([]*Branch, error) {
+	return collect(c.Branches(ctx, owner, repo))
+}
+
+// GetBranch returns a single branch, including its head commit
+func (c *Client) GetBranch(ctx context.Context, owner, repo, branch string) (*Branch, error) {
+	var b Branch
+	path := repoPath(owner, repo) + "/branches/" + escape(branch)
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Commits iterates over the commits of a repository matching opts
+func (c *Client) Commits(ctx context.Context, owner, repo string, opts CommitListOptions) iter.Seq2[*Commit, error] {
+	return paginate[*Commit](ctx, c, repoPath(owner, repo)+"/commits", opts.query())
+}
+
+// ListCommits returns all commits of a repository matching opts
+func (c *Client) ListCommits(ctx context.Context, owner, repo string, opts CommitListOptions) ([]*Commit, error) {
+	return collect(c.Commits(ctx, owner, repo, opts))
+}
+
+// LatestCommit returns the newest commit matching opts (on the default
+// branch unless opts.SHA is set), or nil if there is none. An empty
+// repository has no commits rather than being an error.
+func (c *Client) LatestCommit(ctx context.Context, owner, repo string, opts CommitListOptions) (*Commit, error) {
+	q := opts.query()
+	q.Set("limit", "1")
+
+	var commits []*Commit
+	if _, err := c.do(ctx, http.MethodGet, repoPath(owner, repo)+"/commits", q, nil, &commits); err != nil {
+		if StatusCode(err) == http.StatusConflict {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return commits[0], nil
+}
+
+// CountCommits returns the number of commits reachable from opts.SHA
+// (or the default branch) using the X-Total-Count header
+func (c *Client) CountCommits(ctx context.Context, owner, repo string, opts CommitListOptions) (int, error) {
+	q := opts.query()
+	q.Set("limit", "1")
+
+	var commits []*Commit
+	resp, err := c.do(ctx, http.MethodGet, repoPath(owner, repo)+"/commits", q, nil, &commits)
+	if err != nil {
+		return 0, err
+	}
+	if total, ok := totalCount(resp); ok {
+		return total, nil
+	}
+	return len(commits), nil
+}
+
+// GetTag returns a single tag
+func (c *Client) GetTag(ctx context.Context, owner, repo, tag string) (*Tag, error) {
+	var t Tag
+	path := repoPath(owner, repo) + "/tags/" + escape(tag)
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateTag creates a tag pointing at opt.Target
+func (c *Client) CreateTag(ctx context.Context, owner, repo string, opt CreateTagOption) (*Tag, error) {
+	var t Tag
+	if _, err := c.do(ctx, http.MethodPost, repoPath(owner, repo)+"/tags", nil, opt, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Tags iterates over all tags of a repository
+func (c *Client) Tags(ctx context.Context, owner, repo string) iter.Seq2[*Tag, error] {
+	return paginate[*Tag](ctx, c, repoPath(owner, repo)+"/tags", nil)
+}
+
+// Archive formats of GetArchive
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// GetArchive streams an archive of the repository at ref (a branch, tag or
+// commit SHA) in format. Its entries are nested in a directory named after
+// the repository. The caller must close the returned reader.
+func (c *Client) GetArchive(ctx context.Context, owner, repo, ref, format string) (io.ReadCloser, error) {
+	path := repoPath(owner, repo) + "/archive/" + escape(ref+"."+format)
+	resp, err := c.sendWithRetry(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// query encodes the list options as query parameters
+func (o CommitListOptions) query() url.Values {
+	q := url.Values{}
+	// Skip expensive per-commit details the classroom never uses
+	q.Set("stat", "false")
+	q.Set("verification", "false")
+	q.Set("files", "false")
+	if o.SHA != "" {
+		q.Set("sha", o.SHA)
+	}
+	if o.Path != "" {
+		q.Set("path", o.Path)
+	}
+	if o.Since != nil {
+		q.Set("since", o.Since.UTC().Format(time.RFC3339))
+	}
+	if o.Until != nil {
+		q.Set("until", o.Until.UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+// repoPath returns the API path of a repository
+func repoPath(owner, repo string) string {
+	return "/repos/" + escape(owner) + "/" + escape(repo)
+}
@@ -0,0 +1,66 @@
+package forgejo
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"strconv"
+)
+
+// OrganizationTeams iterates over all teams of an organization
+func (c *Client) OrganizationTeams(ctx context.Context, org string) iter.Seq2[*Team, error] {
+	return paginate[*Team](ctx, c, "/orgs/"+escape(org)+"/teams", nil)
+}
+
+// GetTeam looks up a team by ID
+func (c *Client) GetTeam(ctx context.Context, id int64) (*Team, error) {
+	var t Team
+	if _, err := c.do(ctx, http.MethodGet, teamPath(id), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateTeam creates a team in an organization
+func (c *Client) CreateTeam(ctx context.Context, org string, opt CreateTeamOption) (*Team, error) {
+	var t Team
+	if _, err := c.do(ctx, http.MethodPost, "/orgs/"+escape(org)+"/teams", nil, opt, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTeam deletes a team
+func (c *Client) DeleteTeam(ctx context.Context, id int64) error {
+	_, err := c.do(ctx, http.MethodDelete, teamPath(id), nil, nil, nil)
+	return err
+}
+
+// AddTeamMember adds username to a team
+func (c *Client) AddTeamMember(ctx context.Context, teamID int64, username string) error {
+	_, err := c.do(ctx, http.MethodPut, teamPath(teamID)+"/members/"+escape(username), nil, nil, nil)
+	return err
+}
+
+// RemoveTeamMember removes username from a team
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID int64, username string) error {
+	_, err := c.do(ctx, http.MethodDelete, teamPath(teamID)+"/members/"+escape(username), nil, nil, nil)
+	return err
+}
+
+// TeamMembers iterates over the members of a team
+func (c *Client) TeamMembers(ctx context.Context, teamID int64) iter.Seq2[*User, error] {
+	return paginate[*User](ctx, c, teamPath(teamID)+"/members", nil)
+}
+
+// AddTeamRepository gives a team access to an organization repository
+func (c *Client) AddTeamRepository(ctx context.Context, teamID int64, org, repo string) error {
+	path := teamPath(teamID) + "/repos/" + escape(org) + "/" + escape(repo)
+	_, err := c.do(ctx, http.MethodPut, path, nil, nil, nil)
+	return err
+}
+
+// teamPath returns the API path of a team
+func teamPath(id int64) string {
+	return "/teams/" + strconv.FormatInt(id, 10)
+}
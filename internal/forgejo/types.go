@@ -0,0 +1,223 @@
+package forgejo
+
+import (
+	"time"
+)
+
+// User represents a Forgejo user account
+type User struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	FullName  string `json:"full_name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+	IsAdmin   bool   `json:"is_admin"`
+}
+
+// Organization represents a Forgejo organization
+type Organization struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	UserName    string `json:"username"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+	Visibility  string `json:"visibility"` // public, limited, private
+}
+
+// CreateOrgOption holds the options for creating an organization
+type CreateOrgOption struct {
+	UserName    string `json:"username"`
+	FullName    string `json:"full_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+// OrgPermissions describes what a user may do in an organization
+type OrgPermissions struct {
+	IsOwner             bool `json:"is_owner"`
+	IsAdmin             bool `json:"is_admin"`
+	CanWrite            bool `json:"can_write"`
+	CanRead             bool `json:"can_read"`
+	CanCreateRepository bool `json:"can_create_repository"`
+}
+
+// Repository represents a Forgejo repository
+type Repository struct {
+	ID            int64     `json:"id"`
+	Owner         *User     `json:"owner"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Description   string    `json:"description"`
+	Private       bool      `json:"private"`
+	Template      bool      `json:"template"`
+	Empty         bool      `json:"empty"`
+	Archived      bool      `json:"archived"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	CloneURL      string    `json:"clone_url"`
+	SSHURL        string    `json:"ssh_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GenerateRepoOption holds the options for generating a repository from a template
+type GenerateRepoOption struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Private     bool   `json:"private"`
+	GitContent  bool   `json:"git_content"`
+	Topics      bool   `json:"topics"`
+	Labels      bool   `json:"labels"`
+	Webhooks    bool   `json:"webhooks"`
+}
+
+// Collaborator permission levels
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+// AddCollaboratorOption holds the options for adding a collaborator
+type AddCollaboratorOption struct {
+	Permission string `json:"permission"`
+}
+
+// Team represents a Forgejo organization team
+type Team struct {
+	ID                      int64         `json:"id"`
+	Name                    string        `json:"name"`
+	Description             string        `json:"description"`
+	Organization            *Organization `json:"organization"`
+	Permission              string        `json:"permission"`
+	Units                   []string      `json:"units"`
+	IncludesAllRepositories bool          `json:"includes_all_repositories"`
+	CanCreateOrgRepo        bool          `json:"can_create_org_repo"`
+}
+
+// CreateTeamOption holds the options for creating a team
+type CreateTeamOption struct {
+	Name                    string   `json:"name"`
+	Description             string   `json:"description,omitempty"`
+	Permission              string   `json:"permission"`
+	Units                   []string `json:"units,omitempty"`
+	IncludesAllRepositories bool     `json:"includes_all_repositories"`
+	CanCreateOrgRepo        bool     `json:"can_create_org_repo"`
+}
+
+// CommitUser identifies the author or committer of a commit
+type CommitUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+}
+
+// RepoCommit holds the git-level details of a commit
+type RepoCommit struct {
+	Message   string      `json:"message"`
+	Author    *CommitUser `json:"author"`
+	Committer *CommitUser `json:"committer"`
+}
+
+// Commit represents a commit returned by the commits API
+type Commit struct {
+	SHA       string      `json:"sha"`
+	URL       string      `json:"url"`
+	HTMLURL   string      `json:"html_url"`
+	Commit    *RepoCommit `json:"commit"`
+	Author    *User       `json:"author"`
+	Committer *User       `json:"committer"`
+	Created   time.Time   `json:"created"`
+}
+
+// PayloadUser identifies a user in a branch or webhook payload commit
+type PayloadUser struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	UserName string `json:"username"`
+}
+
+// PayloadCommit is the short commit representation used by branches and webhooks
+type PayloadCommit struct {
+	ID        string       `json:"id"`
+	Message   string       `json:"message"`
+	URL       string       `json:"url"`
+	Author    *PayloadUser `json:"author"`
+	Committer *PayloadUser `json:"committer"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// PushPayload is the payload of a push webhook
+type PushPayload struct {
+	Ref          string           `json:"ref"`
+	Before       string           `json:"before"`
+	After        string           `json:"after"`
+	CompareURL   string           `json:"compare_url"`
+	Commits      []*PayloadCommit `json:"commits"` // at most the newest 20
+	TotalCommits int              `json:"total_commits"`
+	HeadCommit   *PayloadCommit   `json:"head_commit"`
+	Repository   *Repository      `json:"repository"`
+	Pusher       *User            `json:"pusher"`
+	Sender       *User            `json:"sender"`
+}
+
+// Branch represents a repository branch
+type Branch struct {
+	Name      string         `json:"name"`
+	Commit    *PayloadCommit `json:"commit"`
+	Protected bool           `json:"protected"`
+}
+
+// CommitMeta is the short commit reference attached to a tag
+type CommitMeta struct {
+	SHA     string    `json:"sha"`
+	URL     string    `json:"url"`
+	Created time.Time `json:"created"`
+}
+
+// Tag represents a repository tag
+type Tag struct {
+	Name       string      `json:"name"`
+	Message    string      `json:"message"`
+	ID         string      `json:"id"`
+	Commit     *CommitMeta `json:"commit"`
+	ZipballURL string      `json:"zipball_url"`
+	TarballURL string      `json:"tarball_url"`
+}
+
+// CreateTagOption holds the options for creating a tag
+type CreateTagOption struct {
+	TagName string `json:"tag_name"`
+	Message string `json:"message,omitempty"`
+	Target  string `json:"target,omitempty"`
+}
+
+// CommitListOptions filters the commits returned by ListCommits
+type CommitListOptions struct {
+	SHA   string // branch, tag or commit to start listing from
+	Path  string // only commits touching this path
+	Since *time.Time
+	Until *time.Time
+}
+
+// Hook represents a repository webhook
+type Hook struct {
+	ID        int64             `json:"id"`
+	Type      string            `json:"type"`
+	Config    map[string]string `json:"config"`
+	Events    []string          `json:"events"`
+	Active    bool              `json:"active"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CreateHookOption holds the options for creating a repository webhook.
+// Config must contain "url" and "content_type", and may contain "secret".
+type CreateHookOption struct {
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
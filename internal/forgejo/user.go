@@ -0,0 +1,24 @@
+package forgejo
+
+import (
+	"context"
+	"net/http"
+)
+
+// CurrentUser returns the user the client's token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var u User
+	if _, err := c.do(ctx, http.MethodGet, "/user", nil, nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetUser looks up a user by login name
+func (c *Client) GetUser(ctx context.Context, username string) (*User, error) {
+	var u User
+	if _, err := c.do(ctx, http.MethodGet, "/users/"+escape(username), nil, nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	jobsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "jobs"),
+		"Jobs in the queue by type and status (pending, running or dead).",
+		[]string{"type", "status"}, nil,
+	)
+	pendingAcceptancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "assignment", "pending_acceptances"),
+		"Students who have not accepted an active assignment.",
+		[]string{"classroom_id", "assignment_id"}, nil,
+	)
+	assignmentStudentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "assignment", "students"),
+		"Students of the classroom of an active assignment.",
+		[]string{"classroom_id", "assignment_id"}, nil,
+	)
+	assignmentDeadlineDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "assignment", "deadline_timestamp_seconds"),
+		"Deadline of an active assignment as a Unix timestamp.",
+		[]string{"classroom_id", "assignment_id"}, nil,
+	)
+	pendingClaimsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "roster", "claims_pending"),
+		"Roster claims awaiting approval by classroom.",
+		[]string{"classroom_id"}, nil,
+	)
+	overdueDeadlinesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "deadlines_overdue"),
+		"Deadlines that have passed but have not been enforced.",
+		nil, nil,
+	)
+	overdueSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "deadline_overdue_seconds"),
+		"Time since the earliest deadline that has passed without being enforced, 0 if there is none.",
+		nil, nil,
+	)
+)
+
+// queueCollector reports the depth of the job queue at scrape time
+type queueCollector struct {
+	jobs JobCounter
+}
+
+// Describe implements prometheus.Collector
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jobsDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	counts, err := c.jobs.CountJobs(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(jobsDesc, err)
+		return
+	}
+	for jobType, statuses := range counts {
+		for status, n := range statuses {
+			ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(n), jobType, status)
+		}
+	}
+}
+
+// activityCollector reports the progress of active assignments at scrape
+// time. Archived classrooms and past assignments drop out, which keeps the
+// number of series bounded by the work in progress.
+type activityCollector struct {
+	source ActivitySource
+}
+
+// Describe implements prometheus.Collector
+func (c *activityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pendingAcceptancesDesc
+	ch <- assignmentStudentsDesc
+	ch <- assignmentDeadlineDesc
+	ch <- pendingClaimsDesc
+	ch <- overdueDeadlinesDesc
+	ch <- overdueSecondsDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *activityCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	activity, err := c.source.Activity(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(pendingAcceptancesDesc, err)
+		return
+	}
+
+	for _, a := range activity.Assignments {
+		classroomID := strconv.FormatInt(a.ClassroomID, 10)
+		assignmentID := strconv.FormatInt(a.AssignmentID, 10)
+		ch <- prometheus.MustNewConstMetric(pendingAcceptancesDesc, prometheus.GaugeValue,
+			float64(a.PendingAcceptance), classroomID, assignmentID)
+		ch <- prometheus.MustNewConstMetric(assignmentStudentsDesc, prometheus.GaugeValue,
+			float64(a.TotalStudents), classroomID, assignmentID)
+		if a.Deadline != nil {
+			ch <- prometheus.MustNewConstMetric(assignmentDeadlineDesc, prometheus.GaugeValue,
+				float64(a.Deadline.Unix()), classroomID, assignmentID)
+		}
+	}
+	for classroomID, n := range activity.PendingClaims {
+		ch <- prometheus.MustNewConstMetric(pendingClaimsDesc, prometheus.GaugeValue,
+			float64(n), strconv.FormatInt(classroomID, 10))
+	}
+
+	var overdue float64
+	if activity.OldestOverdueDeadline != nil {
+		overdue = time.Since(*activity.OldestOverdueDeadline).Seconds()
+	}
+	ch <- prometheus.MustNewConstMetric(overdueDeadlinesDesc, prometheus.GaugeValue, float64(activity.OverdueDeadlines))
+	ch <- prometheus.MustNewConstMetric(overdueSecondsDesc, prometheus.GaugeValue, overdue)
+}
@@ -0,0 +1,194 @@
+// Package metrics collects the Prometheus metrics of fgc-server: HTTP
+// requests, database connections, background jobs, calls to Forgejo and the
+// progress of active assignments. A nil *Metrics records and watches
+// nothing, so instrumented code runs the same with metrics disabled.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// namespace prefixes the names of all fgc-server metrics
+const namespace = "fgc"
+
+// scrapeTimeout bounds the queries run to collect gauges at scrape time
+const scrapeTimeout = 5 * time.Second
+
+// Job outcomes
+const (
+	JobSucceeded = "succeeded"
+	JobRetried   = "retried"
+	JobDead      = "dead"
+)
+
+// jobBuckets are the bounds of the job duration histogram: jobs call
+// Forgejo once per student, so they take far longer than requests
+var jobBuckets = prometheus.ExponentialBuckets(0.1, 2, 12) // 100ms to about 3.5 minutes
+
+// JobCounter counts the unfinished and dead jobs in the queue, by type
+// and then status
+type JobCounter interface {
+	CountJobs(ctx context.Context) (map[string]map[string]int, error)
+}
+
+// ActivitySource reports the work in progress across all classrooms
+type ActivitySource interface {
+	Activity(ctx context.Context) (*model.Activity, error)
+}
+
+// Metrics holds the registry served on /metrics and the metrics recorded
+// as requests, jobs and Forgejo calls complete
+type Metrics struct {
+	registry *prometheus.Registry
+	logger   *zap.Logger
+
+	httpDuration    *prometheus.HistogramVec
+	jobsProcessed   *prometheus.CounterVec
+	jobDuration     *prometheus.HistogramVec
+	forgejoDuration *prometheus.HistogramVec
+	forgejoErrors   *prometheus.CounterVec
+}
+
+// New creates the metrics with a registry of their own, which also reports
+// the Go runtime and process metrics
+func New(logger *zap.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   logger,
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		jobsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_processed_total",
+			Help:      "Background job attempts by type and outcome (succeeded, retried or dead).",
+		}, []string{"type", "outcome"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "job_duration_seconds",
+			Help:      "Run time of background job attempts by type.",
+			Buckets:   jobBuckets,
+		}, []string{"type"}),
+		forgejoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "forgejo_request_duration_seconds",
+			Help:      "Latency of Forgejo API calls by method, endpoint and status (error if no response was received).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "endpoint", "status"}),
+		forgejoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "forgejo_request_errors_total",
+			Help:      "Failed Forgejo API calls by method, endpoint and error code, including calls refused by the client throttle.",
+		}, []string{"method", "endpoint", "code"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration, m.jobsProcessed, m.jobDuration, m.forgejoDuration, m.forgejoErrors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format. Gauges
+// that fail to collect are logged and left out rather than failing the
+// whole scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      zap.NewStdLog(m.logger),
+		ErrorHandling: promhttp.ContinueOnError,
+		Registry:      m.registry,
+	})
+}
+
+// WatchDB reports the connection pool statistics of db (sql.DBStats) as
+// the go_sql_* metrics
+func (m *Metrics) WatchDB(db *sql.DB) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// WatchQueue reports the number of jobs in q by type and status
+func (m *Metrics) WatchQueue(q JobCounter) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(&queueCollector{jobs: q})
+}
+
+// WatchActivity reports the progress of active assignments, pending roster
+// claims and overdue deadlines taken from src
+func (m *Metrics) WatchActivity(src ActivitySource) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(&activityCollector{source: src})
+}
+
+// ObserveHTTP records a served request. route is the registered route
+// pattern, so that IDs in paths do not create new series.
+func (m *Metrics) ObserveHTTP(method, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpDuration.WithLabelValues(normalizeMethod(method), route, strconv.Itoa(status)).Observe(elapsed.Seconds())
+}
+
+// ObserveJob records one attempt of a job of jobType and its outcome
+func (m *Metrics) ObserveJob(jobType, outcome string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.jobsProcessed.WithLabelValues(jobType, outcome).Inc()
+	m.jobDuration.WithLabelValues(jobType).Observe(elapsed.Seconds())
+}
+
+// ObserveForgejo records a call to a Forgejo endpoint, its parameters
+// replaced by placeholders. status is 0 if no response was received.
+func (m *Metrics) ObserveForgejo(method, endpoint string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	m.forgejoDuration.WithLabelValues(normalizeMethod(method), endpoint, label).Observe(elapsed.Seconds())
+}
+
+// ForgejoError records a failed call to a Forgejo endpoint with the code of
+// its error
+func (m *Metrics) ForgejoError(method, endpoint, code string) {
+	if m == nil {
+		return
+	}
+	m.forgejoErrors.WithLabelValues(normalizeMethod(method), endpoint, code).Inc()
+}
+
+// normalizeMethod returns method, or "other" for methods outside the
+// standard set, so that clients cannot create series at will
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+type fakeJobs map[string]map[string]int
+
+func (f fakeJobs) CountJobs(ctx context.Context) (map[string]map[string]int, error) {
+	return f, nil
+}
+
+type fakeActivity struct {
+	activity *model.Activity
+	err      error
+}
+
+func (f *fakeActivity) Activity(ctx context.Context) (*model.Activity, error) {
+	return f.activity, f.err
+}
+
+// scrape returns the body served by m's handler
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.WatchDB(nil)
+		m.WatchQueue(fakeJobs{})
+		m.WatchActivity(&fakeActivity{})
+		m.ObserveHTTP(http.MethodGet, "/health", http.StatusOK, time.Millisecond)
+		m.ObserveJob("roster.import", JobSucceeded, time.Second)
+		m.ObserveForgejo(http.MethodGet, "/user", 0, time.Millisecond)
+		m.ForgejoError(http.MethodGet, "/user", "INTEGRATION_FORGEJO_UNAVAILABLE")
+	})
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	m := New(zap.NewNop())
+	m.ObserveHTTP(http.MethodGet, "/api/v1/classrooms/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveHTTP("PROPFIND", "unmatched", http.StatusNotFound, time.Millisecond)
+	m.ObserveJob("deadline.enforce", JobRetried, 2*time.Second)
+	m.ObserveForgejo(http.MethodPost, "/repos/{owner}/{repo}/generate", 0, time.Second)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `fgc_http_request_duration_seconds_count{method="GET",route="/api/v1/classrooms/:id",status="200"} 1`)
+	assert.Contains(t, body, `fgc_http_request_duration_seconds_count{method="other",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `fgc_jobs_processed_total{outcome="retried",type="deadline.enforce"} 1`)
+	assert.Contains(t, body, `fgc_job_duration_seconds_count{type="deadline.enforce"} 1`)
+	assert.Contains(t, body, `fgc_forgejo_request_duration_seconds_count{endpoint="/repos/{owner}/{repo}/generate",method="POST",status="error"} 1`)
+}
+
+func TestMetrics_Gauges(t *testing.T) {
+	deadline := time.Date(2026, 11, 1, 23, 59, 0, 0, time.UTC)
+	overdue := time.Now().Add(-10 * time.Minute)
+	source := &fakeActivity{activity: &model.Activity{
+		Assignments: []model.ActiveAssignment{
+			{ClassroomID: 1, AssignmentID: 10, Deadline: &deadline, TotalStudents: 30, PendingAcceptance: 4},
+			{ClassroomID: 2, AssignmentID: 20, TotalStudents: 12, PendingAcceptance: 12},
+		},
+		PendingClaims:         map[int64]int{1: 3},
+		OverdueDeadlines:      2,
+		OldestOverdueDeadline: &overdue,
+	}}
+
+	m := New(zap.NewNop())
+	m.WatchQueue(fakeJobs{"roster.import": {"pending": 5, "dead": 1}})
+	m.WatchActivity(source)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `fgc_jobs{status="pending",type="roster.import"} 5`)
+	assert.Contains(t, body, `fgc_jobs{status="dead",type="roster.import"} 1`)
+	assert.Contains(t, body, `fgc_assignment_pending_acceptances{assignment_id="10",classroom_id="1"} 4`)
+	assert.Contains(t, body, `fgc_assignment_pending_acceptances{assignment_id="20",classroom_id="2"} 12`)
+	assert.Contains(t, body, `fgc_assignment_students{assignment_id="10",classroom_id="1"} 30`)
+	assert.Contains(t, body, `fgc_assignment_deadline_timestamp_seconds{assignment_id="10",classroom_id="1"} `+
+		strconv.FormatFloat(float64(deadline.Unix()), 'g', -1, 64))
+	assert.NotContains(t, body, `fgc_assignment_deadline_timestamp_seconds{assignment_id="20"`)
+	assert.Contains(t, body, `fgc_roster_claims_pending{classroom_id="1"} 3`)
+	assert.Contains(t, body, `fgc_deadlines_overdue 2`)
+	assert.Regexp(t, `fgc_deadline_overdue_seconds 60\d\.`, body)
+
+	t.Run("failing source leaves out its gauges", func(t *testing.T) {
+		source.err = errors.New("database is down")
+		body := scrape(t, m)
+		assert.NotContains(t, body, "fgc_assignment_pending_acceptances")
+		assert.Contains(t, body, `fgc_jobs{status="pending",type="roster.import"} 5`)
+	})
+}
@@ -1,7 +1,10 @@
 package model
 
 import (
+	"strings"
 	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/util"
 )
 
 // Assignment represents an assignment entity
@@ -17,6 +20,7 @@ type Assignment struct {
 	MaxTeamSize          int        `json:"max_team_size" db:"max_team_size"`
 	AutoAccept           bool       `json:"auto_accept" db:"auto_accept"`
 	Public               bool       `json:"public" db:"public"`
+	DeadlineEnforcedFor  *time.Time `json:"deadline_enforced_for,omitempty" db:"deadline_enforced_for"` // deadline whose tags have been created
 	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -45,10 +49,11 @@ type UpdateAssignmentRequest struct {
 
 // AssignmentListRequest represents the request to list assignments
 type AssignmentListRequest struct {
-	ClassroomID int64  `form:"classroom_id" json:"classroom_id,omitempty"`
-	Status      string `form:"status" json:"status,omitempty"` // active, past, all
-	Page        int    `form:"page" json:"page,omitempty"`
-	PerPage     int    `form:"per_page" json:"per_page,omitempty"`
+	ClassroomID  int64  `form:"classroom_id" json:"classroom_id,omitempty"`
+	Status       string `form:"status" json:"status,omitempty"` // active, past, all
+	MemberUserID int64  `form:"-" json:"-"`                     // restricts to classrooms the Forgejo user owns or is on the roster of
+	Page         int    `form:"page" json:"page,omitempty"`
+	PerPage      int    `form:"per_page" json:"per_page,omitempty"`
 }
 
 // AssignmentListResponse represents the response for listing assignments
@@ -60,18 +65,51 @@ type AssignmentListResponse struct {
 	TotalPages  int          `json:"total_pages"`
 }
 
-// AssignmentStats represents statistics for an assignment
+// AssignmentStatsRequest represents the request for assignment statistics
+type AssignmentStatsRequest struct {
+	Detailed bool `form:"detailed" json:"detailed,omitempty"`
+}
+
+// AssignmentStats represents statistics for an assignment. Students are
+// the classroom's roster entries with the student role; they have accepted
+// once they have a submission of their own or their team's, and submitted
+// once that submission has been pushed to. All counts are of students, so
+// a team submission counts once for each member; AverageCommits is the
+// mean commit count of accepted students' submissions.
 type AssignmentStats struct {
-	AssignmentID      int64   `json:"assignment_id"`
-	TotalStudents     int     `json:"total_students"`
-	AcceptedCount     int     `json:"accepted_count"`
-	SubmissionCount   int     `json:"submission_count"`
-	TeamCount         int     `json:"team_count"`
-	AcceptanceRate    float64 `json:"acceptance_rate"`
-	SubmissionRate    float64 `json:"submission_rate"`
-	AverageCommits    float64 `json:"average_commits"`
-	OnTimeSubmissions int     `json:"on_time_submissions"`
-	LateSubmissions   int     `json:"late_submissions"`
+	AssignmentID      int64                   `json:"assignment_id"`
+	TotalStudents     int                     `json:"total_students"`
+	AcceptedCount     int                     `json:"accepted_count"`
+	SubmissionCount   int                     `json:"submission_count"`
+	TeamCount         int                     `json:"team_count"`
+	AcceptanceRate    float64                 `json:"acceptance_rate"`
+	SubmissionRate    float64                 `json:"submission_rate"`
+	AverageCommits    float64                 `json:"average_commits"`
+	OnTimeSubmissions int                     `json:"on_time_submissions"`
+	LateSubmissions   int                     `json:"late_submissions"`
+	Details           *AssignmentStatsDetails `json:"details,omitempty"`
+}
+
+// AssignmentStatsDetails holds the distributions of detailed assignment
+// statistics
+type AssignmentStatsDetails struct {
+	CommitDistribution  []StatsBucket `json:"commit_distribution"`  // accepted students by the commit count of their submission
+	AcceptanceHistogram []DateCount   `json:"acceptance_histogram"` // students by the day they accepted
+	NotAccepted         []RosterEntry `json:"not_accepted"`         // students who never accepted
+}
+
+// StatsBucket counts the values between Min and Max, inclusive. The last
+// bucket has no Max.
+type StatsBucket struct {
+	Min   int  `json:"min"`
+	Max   *int `json:"max,omitempty"`
+	Count int  `json:"count"`
+}
+
+// DateCount counts the events of one day
+type DateCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD in UTC
+	Count int    `json:"count"`
 }
 
 // AcceptAssignmentRequest represents the request to accept an assignment
@@ -105,14 +143,61 @@ func (a *Assignment) IsPast() bool {
 	return time.Now().After(*a.Deadline)
 }
 
+// Field limits of assignments. The name and template repository limits are
+// the sizes of their columns.
+const (
+	AssignmentNameMaxLength        = 255
+	AssignmentDescriptionMaxLength = 1000
+	AssignmentTemplateMaxLength    = 512
+	AssignmentMaxTeamSize          = 100
+)
+
 // Validate validates the create assignment request
 func (req *CreateAssignmentRequest) Validate() error {
-	// TODO: Implement validation logic
+	v := util.NewValidator()
+
+	if req.ClassroomID <= 0 {
+		v.AddError("classroom_id", "Classroom ID is required", "VALIDATION_MISSING_REQUIRED_FIELD")
+	}
+	v.ValidateRequired("name", req.Name, "Name")
+	v.ValidateLength("name", strings.TrimSpace(req.Name), "Name", 0, AssignmentNameMaxLength)
+	v.ValidateLength("description", req.Description, "Description", 0, AssignmentDescriptionMaxLength)
+	v.ValidateRequired("template_repository", req.TemplateRepository, "Template repository")
+	v.ValidateLength("template_repository", req.TemplateRepository, "Template repository", 0, AssignmentTemplateMaxLength)
+	v.ValidateDateTime("deadline", req.Deadline, "Deadline")
+	v.ValidateFutureDate("deadline", req.Deadline, "Deadline")
+	if req.MaxTeamSize != 0 {
+		v.ValidateRange("max_team_size", req.MaxTeamSize, 1, AssignmentMaxTeamSize, "Maximum team size")
+	}
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
 	return nil
 }
 
-// Validate validates the update assignment request
+// Validate validates the update assignment request. An empty deadline
+// removes it.
 func (req *UpdateAssignmentRequest) Validate() error {
-	// TODO: Implement validation logic
+	v := util.NewValidator()
+
+	if req.Name != nil {
+		v.ValidateRequired("name", *req.Name, "Name")
+		v.ValidateLength("name", strings.TrimSpace(*req.Name), "Name", 0, AssignmentNameMaxLength)
+	}
+	if req.Description != nil {
+		v.ValidateLength("description", *req.Description, "Description", 0, AssignmentDescriptionMaxLength)
+	}
+	if req.Deadline != nil {
+		v.ValidateDateTime("deadline", *req.Deadline, "Deadline")
+		v.ValidateFutureDate("deadline", *req.Deadline, "Deadline")
+	}
+	if req.MaxTeamSize != nil {
+		v.ValidateRange("max_team_size", *req.MaxTeamSize, 1, AssignmentMaxTeamSize, "Maximum team size")
+	}
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
 	return nil
 }
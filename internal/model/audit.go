@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// Audited actions
+const (
+	AuditClassroomCreate       = "classroom.create"
+	AuditClassroomUpdate       = "classroom.update"
+	AuditClassroomDelete       = "classroom.delete"
+	AuditClassroomArchive      = "classroom.archive"
+	AuditAssignmentCreate      = "assignment.create"
+	AuditAssignmentUpdate      = "assignment.update"
+	AuditAssignmentDelete      = "assignment.delete"
+	AuditRosterAdd             = "roster.add"
+	AuditRosterUpdate          = "roster.update"
+	AuditRosterRemove          = "roster.remove"
+	AuditRosterLink            = "roster.link"
+	AuditInvitationRotate      = "invitation.rotate"
+	AuditInvitationUpdate      = "invitation.update"
+	AuditJoinLinkRotate        = "join_link.rotate"
+	AuditJoinLinkUpdate        = "join_link.update"
+	AuditClaimCreate           = "roster_claim.create"
+	AuditClaimApprove          = "roster_claim.approve"
+	AuditClaimReject           = "roster_claim.reject"
+	AuditSubmissionCreate      = "submission.create" // assignment accepted
+	AuditSubmissionPush        = "submission.push"
+	AuditSubmissionLate        = "submission.late"
+	AuditSubmissionDeadlineTag = "submission.deadline_tag"
+)
+
+// Audited target types
+const (
+	AuditTargetClassroom   = "classroom"
+	AuditTargetAssignment  = "assignment"
+	AuditTargetRosterEntry = "roster_entry"
+	AuditTargetInvitation  = "invitation" // target ID is the assignment ID
+	AuditTargetJoinLink    = "join_link"  // target ID is the classroom ID
+	AuditTargetClaim       = "roster_claim"
+	AuditTargetSubmission  = "submission"
+)
+
+// AuditEvent records one change to a classroom or something in it. Events
+// are never updated or deleted, and outlive the classroom.
+type AuditEvent struct {
+	ID          int64                   `json:"id" db:"id"`
+	ClassroomID int64                   `json:"classroom_id" db:"classroom_id"`
+	ActorID     *int64                  `json:"actor_id,omitempty" db:"actor_id"` // nil for changes made by the server
+	ActorLogin  string                  `json:"actor_login" db:"actor_login"`
+	Action      string                  `json:"action" db:"action"`
+	TargetType  string                  `json:"target_type" db:"target_type"`
+	TargetID    int64                   `json:"target_id" db:"target_id"`
+	Changes     map[string]audit.Change `json:"changes" db:"changes"` // by JSON field of the target
+	RequestID   string                  `json:"request_id,omitempty" db:"request_id"`
+	IP          string                  `json:"ip,omitempty" db:"ip"`
+	CreatedAt   time.Time               `json:"created_at" db:"created_at"`
+}
+
+// AuditListRequest represents the request to list audit events
+type AuditListRequest struct {
+	Action     string `form:"action" json:"action,omitempty"`
+	TargetType string `form:"target_type" json:"target_type,omitempty"`
+	TargetID   int64  `form:"target_id" json:"target_id,omitempty"`
+	Actor      string `form:"actor" json:"actor,omitempty"` // Forgejo login
+	Since      string `form:"since" json:"since,omitempty"` // RFC3339, inclusive
+	Until      string `form:"until" json:"until,omitempty"` // RFC3339, exclusive
+	Page       int    `form:"page" json:"page,omitempty"`
+	PerPage    int    `form:"per_page" json:"per_page,omitempty"`
+}
+
+// AuditListResponse represents the response for listing audit events
+type AuditListResponse struct {
+	Events     []AuditEvent `json:"events"`
+	Total      int          `json:"total"`
+	Page       int          `json:"page"`
+	PerPage    int          `json:"per_page"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// Validate validates the audit list request
+func (req *AuditListRequest) Validate() error {
+	v := util.NewValidator()
+
+	v.ValidateDateTime("since", req.Since, "Since")
+	v.ValidateDateTime("until", req.Until, "Until")
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// JoinLink is the join link of a classroom. Students who follow it claim
+// their roster entry and link it to their own Forgejo account.
+type JoinLink struct {
+	ClassroomID      int64     `json:"classroom_id" db:"classroom_id"`
+	Token            string    `json:"token" db:"token"`
+	URL              string    `json:"url,omitempty" db:"-"` // set when the server's public URL is configured
+	Enabled          bool      `json:"enabled" db:"enabled"`
+	RequiresApproval bool      `json:"requires_approval" db:"requires_approval"` // claims wait for staff approval
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`               // when the current token was issued
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RotateJoinLinkRequest represents the request to issue a new join link
+// token, which replaces the previous one. Without RequiresApproval, the
+// previous link's setting is kept, and a classroom's first link requires
+// approval.
+type RotateJoinLinkRequest struct {
+	RequiresApproval *bool `json:"requires_approval,omitempty"`
+}
+
+// UpdateJoinLinkRequest represents the request to enable or disable a join
+// link or to change whether its claims need approval
+type UpdateJoinLinkRequest struct {
+	Enabled          *bool `json:"enabled,omitempty"`
+	RequiresApproval *bool `json:"requires_approval,omitempty"`
+}
+
+// JoinLinkDetails is the public view of a join link, shown to students
+// before they claim their roster entry
+type JoinLinkDetails struct {
+	ClassroomName    string `json:"classroom_name"`
+	OrganizationName string `json:"organization_name"`
+	RequiresApproval bool   `json:"requires_approval"`
+}
+
+// ClaimRosterRequest identifies the roster entry a student claims. Every
+// identifier given must match the same unlinked student entry.
+type ClaimRosterRequest struct {
+	StudentEmail string `json:"student_email,omitempty"`
+	StudentID    string `json:"student_id,omitempty"`
+}
+
+// Roster claim statuses
+const (
+	ClaimStatusPending  = "pending" // waiting for staff approval
+	ClaimStatusApproved = "approved"
+	ClaimStatusRejected = "rejected"
+)
+
+// ClaimStatuses lists the valid roster claim statuses
+var ClaimStatuses = []string{ClaimStatusPending, ClaimStatusApproved, ClaimStatusRejected}
+
+// RosterClaim records a student claiming a roster entry for their Forgejo
+// account. Claims through links without approval are approved right away.
+type RosterClaim struct {
+	ID              int64      `json:"id" db:"id"`
+	ClassroomID     int64      `json:"classroom_id" db:"classroom_id"`
+	RosterEntryID   int64      `json:"roster_entry_id" db:"roster_entry_id"`
+	StudentName     string     `json:"student_name" db:"-"` // from the roster entry
+	StudentID       string     `json:"student_id" db:"-"`   // from the roster entry
+	ForgejoUserID   int64      `json:"forgejo_user_id" db:"forgejo_user_id"`
+	ForgejoUsername string     `json:"forgejo_username" db:"forgejo_username"`
+	Status          string     `json:"status" db:"status"`
+	DecidedBy       *string    `json:"decided_by,omitempty" db:"decided_by"` // staff login; empty for automatic approvals
+	DecidedAt       *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// RosterClaimListRequest represents the request to list roster claims
+type RosterClaimListRequest struct {
+	Status  string `form:"status" json:"status,omitempty"` // all statuses when empty
+	Page    int    `form:"page" json:"page,omitempty"`
+	PerPage int    `form:"per_page" json:"per_page,omitempty"`
+}
+
+// RosterClaimListResponse represents the response for listing roster claims
+type RosterClaimListResponse struct {
+	Claims     []RosterClaim `json:"claims"`
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"per_page"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// Validate validates the claim request
+func (req *ClaimRosterRequest) Validate() error {
+	v := util.NewValidator()
+
+	if req.StudentEmail == "" && req.StudentID == "" {
+		v.AddError("student_id", "Student ID or email is required", "VALIDATION_MISSING_REQUIRED_FIELD")
+	}
+	if req.StudentEmail != "" {
+		v.ValidateEmail("student_email", req.StudentEmail, "Email")
+	}
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
+// Validate validates the claim list request
+func (req *RosterClaimListRequest) Validate() error {
+	v := util.NewValidator()
+
+	v.ValidateEnum("status", req.Status, "Status", ClaimStatuses)
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
@@ -1,7 +1,11 @@
 package model
 
 import (
+	"regexp"
+	"strings"
 	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/util"
 )
 
 // Classroom represents a classroom entity
@@ -40,6 +44,7 @@ type UpdateClassroomRequest struct {
 type ClassroomListRequest struct {
 	OrganizationName string `form:"organization" json:"organization,omitempty"`
 	IncludeArchived  bool   `form:"archived" json:"archived,omitempty"`
+	MemberUserID     int64  `form:"-" json:"-"` // restricts to classrooms the Forgejo user owns or is on the roster of
 	Page             int    `form:"page" json:"page,omitempty"`
 	PerPage          int    `form:"per_page" json:"per_page,omitempty"`
 }
@@ -53,25 +58,99 @@ type ClassroomListResponse struct {
 	TotalPages int         `json:"total_pages"`
 }
 
-// ClassroomStats represents statistics for a classroom
+// ClassroomStats represents statistics for a classroom. Students are the
+// roster entries with the student role; linked students have a Forgejo
+// account.
 type ClassroomStats struct {
-	ClassroomID       int64 `json:"classroom_id"`
-	TotalStudents     int   `json:"total_students"`
-	LinkedStudents    int   `json:"linked_students"`
-	TotalAssignments  int   `json:"total_assignments"`
-	ActiveAssignments int   `json:"active_assignments"`
-	TotalSubmissions  int   `json:"total_submissions"`
+	ClassroomID       int64                `json:"classroom_id"`
+	TotalStudents     int                  `json:"total_students"`
+	LinkedStudents    int                  `json:"linked_students"`
+	TotalAssignments  int                  `json:"total_assignments"`
+	ActiveAssignments int                  `json:"active_assignments"`
+	TotalSubmissions  int                  `json:"total_submissions"`
+	Assignments       []AssignmentProgress `json:"assignments"` // by deadline, then creation
+}
+
+// AssignmentProgress is the row of one assignment in ClassroomStats. Like
+// AssignmentStats, it counts students, who accept through their team on
+// team assignments; rates are relative to the classroom's students.
+type AssignmentProgress struct {
+	AssignmentID    int64      `json:"assignment_id"`
+	Name            string     `json:"name"`
+	Slug            string     `json:"slug"`
+	Deadline        *time.Time `json:"deadline,omitempty"`
+	AcceptedCount   int        `json:"accepted_count"`
+	AcceptanceRate  float64    `json:"acceptance_rate"`
+	SubmissionCount int        `json:"submission_count"`
+	SubmissionRate  float64    `json:"submission_rate"`
+	LateCount       int        `json:"late_count"`
+}
+
+// ActiveAssignment is the acceptance progress of an assignment whose
+// deadline has not passed, in a classroom that is not archived
+type ActiveAssignment struct {
+	ClassroomID       int64      `json:"classroom_id"`
+	AssignmentID      int64      `json:"assignment_id"`
+	Deadline          *time.Time `json:"deadline,omitempty"`
+	TotalStudents     int        `json:"total_students"`
+	PendingAcceptance int        `json:"pending_acceptance"` // students who have not accepted
+}
+
+// Activity is a snapshot of the work in progress across all classrooms,
+// reported as metrics to alert on stalls around deadlines
+type Activity struct {
+	Assignments   []ActiveAssignment `json:"assignments"`
+	PendingClaims map[int64]int      `json:"pending_claims"` // roster claims awaiting approval, by classroom ID
+	// Deadlines that have passed but are not enforced yet, and the earliest
+	// of them
+	OverdueDeadlines      int        `json:"overdue_deadlines"`
+	OldestOverdueDeadline *time.Time `json:"oldest_overdue_deadline,omitempty"`
 }
 
+// Field limits mirroring the chk_classrooms_*_length database constraints
+const (
+	ClassroomNameMaxLength        = 255
+	ClassroomDescriptionMaxLength = 1000
+	OrganizationNameMaxLength     = 40
+)
+
+// organizationNameRegex matches Forgejo user and organization names
+var organizationNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[-_.][a-zA-Z0-9]+)*$`)
+
 // Validate validates the create classroom request
 func (req *CreateClassroomRequest) Validate() error {
-	// TODO: Implement validation logic
-	// This would be called by the validation utility
+	v := util.NewValidator()
+
+	v.ValidateRequired("name", req.Name, "Name")
+	v.ValidateLength("name", strings.TrimSpace(req.Name), "Name", 0, ClassroomNameMaxLength)
+	v.ValidateLength("description", req.Description, "Description", 0, ClassroomDescriptionMaxLength)
+
+	v.ValidateRequired("organization_name", req.OrganizationName, "Organization name")
+	v.ValidateLength("organization_name", req.OrganizationName, "Organization name", 0, OrganizationNameMaxLength)
+	if req.OrganizationName != "" && !organizationNameRegex.MatchString(req.OrganizationName) {
+		v.AddError("organization_name", "Organization name must contain only letters, numbers, dashes, underscores and dots", "VALIDATION_INVALID_FORMAT")
+	}
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
 	return nil
 }
 
 // Validate validates the update classroom request
 func (req *UpdateClassroomRequest) Validate() error {
-	// TODO: Implement validation logic
+	v := util.NewValidator()
+
+	if req.Name != nil {
+		v.ValidateRequired("name", *req.Name, "Name")
+		v.ValidateLength("name", strings.TrimSpace(*req.Name), "Name", 0, ClassroomNameMaxLength)
+	}
+	if req.Description != nil {
+		v.ValidateLength("description", *req.Description, "Description", 0, ClassroomDescriptionMaxLength)
+	}
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
 	return nil
 }
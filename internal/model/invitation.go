@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// Invitation is the invitation link of an assignment. Students accept the
+// assignment with its token, without knowing the assignment ID.
+type Invitation struct {
+	AssignmentID int64      `json:"assignment_id" db:"assignment_id"`
+	Token        string     `json:"token" db:"token"`
+	URL          string     `json:"url,omitempty" db:"-"` // set when the server's public URL is configured
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"` // when the current token was issued
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// RotateInvitationRequest represents the request to issue a new invitation
+// token, which replaces the previous one
+type RotateInvitationRequest struct {
+	ExpiresAt string `json:"expires_at,omitempty"` // RFC3339 format; no expiry when empty
+}
+
+// UpdateInvitationRequest represents the request to enable, disable or
+// change the expiry of an invitation
+type UpdateInvitationRequest struct {
+	Enabled   *bool   `json:"enabled,omitempty"`
+	ExpiresAt *string `json:"expires_at,omitempty"` // RFC3339 format; empty removes the expiry
+}
+
+// InvitationDetails is the public view of an invitation, shown to students
+// before they accept
+type InvitationDetails struct {
+	ClassroomName    string     `json:"classroom_name"`
+	OrganizationName string     `json:"organization_name"`
+	AssignmentName   string     `json:"assignment_name"`
+	AssignmentSlug   string     `json:"assignment_slug"`
+	Description      string     `json:"description,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	MaxTeamSize      int        `json:"max_team_size"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+}
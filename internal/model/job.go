@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is the API view of a background job
+type Job struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"` // pending, running, succeeded, dead
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   *string         `json:"last_error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	ErrorCount  int             `json:"error_count"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// JobError is a failure of a single input row of a job
+type JobError struct {
+	Row     int    `json:"row" db:"row_number"`
+	Field   string `json:"field,omitempty" db:"field"`
+	Code    string `json:"code" db:"code"`
+	Message string `json:"message" db:"message"`
+}
+
+// JobErrorListRequest represents the request to list job errors
+type JobErrorListRequest struct {
+	Page    int `form:"page" json:"page,omitempty"`
+	PerPage int `form:"per_page" json:"per_page,omitempty"`
+}
+
+// JobErrorListResponse represents the response for listing job errors
+type JobErrorListResponse struct {
+	Errors     []JobError `json:"errors"`
+	Total      int        `json:"total"`
+	Page       int        `json:"page"`
+	PerPage    int        `json:"per_page"`
+	TotalPages int        `json:"total_pages"`
+}
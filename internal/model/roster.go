@@ -1,7 +1,11 @@
 package model
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/util"
 )
 
 // RosterEntry represents a student in a classroom roster
@@ -49,6 +53,196 @@ type RosterListResponse struct {
 	TotalPages int           `json:"total_pages"`
 }
 
+// RosterImportRow is one student parsed from a roster CSV file
+type RosterImportRow struct {
+	Row          int    `json:"row"` // line number in the file
+	StudentName  string `json:"student_name"`
+	StudentEmail string `json:"student_email"`
+	StudentID    string `json:"student_id"`
+	Role         string `json:"role,omitempty"`
+}
+
+// ImportRosterRequest holds the options of a roster CSV import
+type ImportRosterRequest struct {
+	DryRun    bool   `form:"dry_run" json:"dry_run,omitempty"`
+	Update    bool   `form:"update" json:"update,omitempty"`       // update existing students instead of skipping them
+	Delimiter string `form:"delimiter" json:"delimiter,omitempty"` // defaults to ","
+}
+
+// RosterImportResult summarizes a roster import
+type RosterImportResult struct {
+	Total   int        `json:"total"`
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Skipped int        `json:"skipped"`
+	Failed  int        `json:"failed"`
+	DryRun  bool       `json:"dry_run"`
+	Errors  []JobError `json:"errors,omitempty"`
+}
+
+// ImportRosterResponse is returned by the roster import endpoint. Small
+// files are imported inline and carry the Result; larger ones are queued
+// and carry the JobID to poll.
+type ImportRosterResponse struct {
+	Status    string              `json:"status"`
+	JobID     *int64              `json:"job_id,omitempty"`
+	StatusURL string              `json:"status_url,omitempty"`
+	Result    *RosterImportResult `json:"result,omitempty"`
+}
+
+// Roster roles
+var RosterRoles = []string{"student", "assistant", "instructor"}
+
+// Bulk roster operation actions
+const (
+	RosterActionAdd    = "add"
+	RosterActionUpdate = "update"
+	RosterActionRemove = "remove"
+	RosterActionLink   = "link"
+)
+
+// Bulk roster modes
+const (
+	BulkModeBestEffort   = "best_effort"    // apply every operation that succeeds
+	BulkModeAllOrNothing = "all_or_nothing" // roll back everything if one operation fails
+)
+
+// Bulk operation result statuses
+const (
+	BulkStatusSuccess    = "success"
+	BulkStatusError      = "error"
+	BulkStatusRolledBack = "rolled_back" // succeeded, then undone by a later failure
+	BulkStatusSkipped    = "skipped"     // not attempted after an earlier failure
+)
+
+// MaxBulkRosterOperations bounds the operations of one bulk request
+const MaxBulkRosterOperations = 100
+
+// RosterOperation is one operation of a bulk roster request. Entries are
+// identified by StudentID; the other fields depend on Action.
+type RosterOperation struct {
+	Action          string `json:"action"`
+	StudentID       string `json:"student_id"`
+	StudentName     string `json:"student_name,omitempty"`     // add, update
+	StudentEmail    string `json:"student_email,omitempty"`    // add, update
+	Role            string `json:"role,omitempty"`             // add, update
+	ForgejoUsername string `json:"forgejo_username,omitempty"` // link
+	Force           bool   `json:"force,omitempty"`            // link: replace an existing link
+}
+
+// BulkRosterRequest represents the request to apply roster operations
+type BulkRosterRequest struct {
+	Operations []RosterOperation `json:"operations"`
+	Mode       string            `json:"mode,omitempty"` // defaults to best_effort
+}
+
+// OperationError describes why a bulk operation failed
+type OperationError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// RosterOperationResult is the outcome of the operation at Index
+type RosterOperationResult struct {
+	Index  int             `json:"index"`
+	Status string          `json:"status"`
+	ID     *int64          `json:"id"` // roster entry ID; null for removals
+	Error  *OperationError `json:"error,omitempty"`
+}
+
+// BulkRosterSummary counts the outcomes of a bulk request. Failed counts
+// every operation that did not take effect.
+type BulkRosterSummary struct {
+	Total      int  `json:"total"`
+	Succeeded  int  `json:"succeeded"`
+	Failed     int  `json:"failed"`
+	RolledBack bool `json:"rolled_back"`
+}
+
+// BulkRosterResponse represents the response of a bulk roster request,
+// with Results aligned to the request operations
+type BulkRosterResponse struct {
+	Results []RosterOperationResult `json:"results"`
+	Summary BulkRosterSummary       `json:"summary"`
+}
+
+// Field limits mirroring the roster_entries columns
+const (
+	StudentNameMaxLength  = 255
+	StudentEmailMaxLength = 255
+	StudentIDMaxLength    = 255
+)
+
+// Validate validates an imported roster row
+func (r *RosterImportRow) Validate() error {
+	v := util.NewValidator()
+
+	v.ValidateRequired("student_name", r.StudentName, "Name")
+	v.ValidateLength("student_name", strings.TrimSpace(r.StudentName), "Name", 0, StudentNameMaxLength)
+	v.ValidateRequired("student_email", r.StudentEmail, "Email")
+	v.ValidateLength("student_email", r.StudentEmail, "Email", 0, StudentEmailMaxLength)
+	v.ValidateEmail("student_email", r.StudentEmail, "Email")
+	v.ValidateRequired("student_id", r.StudentID, "Student ID")
+	v.ValidateLength("student_id", r.StudentID, "Student ID", 0, StudentIDMaxLength)
+	v.ValidateEnum("role", r.Role, "Role", RosterRoles)
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
+// Validate validates the bulk request as a whole; operations are
+// validated one by one when they are applied
+func (req *BulkRosterRequest) Validate() error {
+	v := util.NewValidator()
+
+	if len(req.Operations) == 0 {
+		v.AddError("operations", "At least one operation is required", "VALIDATION_MISSING_REQUIRED_FIELD")
+	}
+	if len(req.Operations) > MaxBulkRosterOperations {
+		v.AddError("operations", fmt.Sprintf("No more than %d operations are allowed per request", MaxBulkRosterOperations), "VALIDATION_TOO_LONG")
+	}
+	v.ValidateEnum("mode", req.Mode, "Mode", []string{BulkModeBestEffort, BulkModeAllOrNothing})
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
+// Validate validates a single bulk roster operation
+func (op *RosterOperation) Validate() error {
+	v := util.NewValidator()
+
+	v.ValidateRequired("action", op.Action, "Action")
+	v.ValidateEnum("action", op.Action, "Action", []string{RosterActionAdd, RosterActionUpdate, RosterActionRemove, RosterActionLink})
+	v.ValidateRequired("student_id", op.StudentID, "Student ID")
+	v.ValidateLength("student_id", op.StudentID, "Student ID", 0, StudentIDMaxLength)
+
+	switch op.Action {
+	case RosterActionAdd:
+		v.ValidateRequired("student_name", op.StudentName, "Name")
+		v.ValidateRequired("student_email", op.StudentEmail, "Email")
+	case RosterActionUpdate:
+		if strings.TrimSpace(op.StudentName) == "" && op.StudentEmail == "" && op.Role == "" {
+			v.AddError("operation", "Update requires a name, email or role", "VALIDATION_MISSING_REQUIRED_FIELD")
+		}
+	case RosterActionLink:
+		v.ValidateRequired("forgejo_username", op.ForgejoUsername, "Forgejo username")
+	}
+	v.ValidateLength("student_name", strings.TrimSpace(op.StudentName), "Name", 0, StudentNameMaxLength)
+	v.ValidateLength("student_email", op.StudentEmail, "Email", 0, StudentEmailMaxLength)
+	v.ValidateEmail("student_email", op.StudentEmail, "Email")
+	v.ValidateEnum("role", op.Role, "Role", RosterRoles)
+
+	if v.HasErrors() {
+		return v.Errors()
+	}
+	return nil
+}
+
 // IsLinked returns true if the student has a linked Forgejo account
 func (r *RosterEntry) IsLinked() bool {
 	return r.ForgejoUsername != nil && *r.ForgejoUsername != ""
@@ -6,20 +6,27 @@ import (
 
 // Submission represents a student's assignment submission
 type Submission struct {
-	ID                int64      `json:"id" db:"id"`
-	AssignmentID      int64      `json:"assignment_id" db:"assignment_id"`
-	StudentID         *int64     `json:"student_id,omitempty" db:"student_id"`
-	TeamID            *int64     `json:"team_id,omitempty" db:"team_id"`
-	RepositoryName    string     `json:"repository_name" db:"repository_name"`
-	RepositoryID      int64      `json:"repository_id" db:"repository_id"`
-	RepositoryURL     string     `json:"repository_url" db:"repository_url"`
-	Status            string     `json:"status" db:"status"` // pending, accepted, late
-	AcceptedAt        *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
-	LastCommitSHA     *string    `json:"last_commit_sha,omitempty" db:"last_commit_sha"`
-	LastCommitMessage *string    `json:"last_commit_message,omitempty" db:"last_commit_message"`
-	CommitCount       int        `json:"commit_count" db:"commit_count"`
-	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	ID                 int64      `json:"id" db:"id"`
+	AssignmentID       int64      `json:"assignment_id" db:"assignment_id"`
+	StudentID          *int64     `json:"student_id,omitempty" db:"student_id"`
+	TeamID             *int64     `json:"team_id,omitempty" db:"team_id"`
+	RepositoryName     string     `json:"repository_name" db:"repository_name"`
+	RepositoryID       int64      `json:"repository_id" db:"repository_id"`
+	RepositoryURL      string     `json:"repository_url" db:"repository_url"`
+	Status             string     `json:"status" db:"status"` // pending, accepted, late
+	AcceptedAt         *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	LastCommitSHA      *string    `json:"last_commit_sha,omitempty" db:"last_commit_sha"`
+	LastCommitMessage  *string    `json:"last_commit_message,omitempty" db:"last_commit_message"`
+	LastCommitAt       *time.Time `json:"last_commit_at,omitempty" db:"last_commit_at"` // when LastCommitSHA was pushed
+	CommitCount        int        `json:"commit_count" db:"commit_count"`
+	DeadlineTag        *string    `json:"deadline_tag,omitempty" db:"deadline_tag"`
+	DeadlineSHA        *string    `json:"deadline_sha,omitempty" db:"deadline_sha"`
+	DeadlineTaggedAt   *time.Time `json:"deadline_tagged_at,omitempty" db:"deadline_tagged_at"`
+	DeadlineUnresolved bool       `json:"deadline_unresolved,omitempty" db:"deadline_unresolved"` // no commit was tagged: the head at the deadline is unknown
+	DeadlineHeadSHA    *string    `json:"-" db:"deadline_head_sha"`                               // head at DeadlineHeadFor as of the first later push
+	DeadlineHeadFor    *time.Time `json:"-" db:"deadline_head_for"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // SubmissionListRequest represents the request to list submissions
@@ -28,6 +35,7 @@ type SubmissionListRequest struct {
 	Status         string `form:"status" json:"status,omitempty"`
 	TeamOnly       bool   `form:"team_only" json:"team_only,omitempty"`
 	IndividualOnly bool   `form:"individual_only" json:"individual_only,omitempty"`
+	MemberID       *int64 `form:"-" json:"-"` // restricts to the roster entry's own and team submissions
 	Page           int    `form:"page" json:"page,omitempty"`
 	PerPage        int    `form:"per_page" json:"per_page,omitempty"`
 }
@@ -41,6 +49,29 @@ type SubmissionListResponse struct {
 	TotalPages  int          `json:"total_pages"`
 }
 
+// Archive formats of a bulk submission download
+const (
+	SubmissionArchiveZip   = "zip"
+	SubmissionArchiveTar   = "tar"
+	SubmissionArchiveTarGz = "tar.gz"
+)
+
+// SubmissionDownloadRequest represents the request to download all
+// submissions of an assignment as one archive
+type SubmissionDownloadRequest struct {
+	Format     string `form:"format" json:"format,omitempty"`           // zip (default), tar or tar.gz
+	AtDeadline bool   `form:"at_deadline" json:"at_deadline,omitempty"` // the deadline tags instead of the default branch heads
+}
+
+// PushResult reports what a push webhook changed
+type PushResult struct {
+	SubmissionID  *int64 `json:"submission_id,omitempty"`
+	LastCommitSHA string `json:"last_commit_sha,omitempty"`
+	CommitCount   int    `json:"commit_count,omitempty"`
+	Status        string `json:"status,omitempty"`
+	Ignored       string `json:"ignored,omitempty"` // why nothing changed
+}
+
 // IsTeamSubmission returns true if this is a team submission
 func (s *Submission) IsTeamSubmission() bool {
 	return s.TeamID != nil
@@ -0,0 +1,28 @@
+package queue
+
+import "context"
+
+// Handler runs jobs of one type
+type Handler interface {
+	Handle(ctx context.Context, job *Job) error
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Handle calls f
+func (f HandlerFunc) Handle(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// Typed returns a Handler that decodes the job payload into T before
+// calling fn. A payload that does not decode fails the job permanently.
+func Typed[T any](fn func(ctx context.Context, job *Job, payload T) error) Handler {
+	return HandlerFunc(func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Decode(&payload); err != nil {
+			return Permanent(err)
+		}
+		return fn(ctx, job, payload)
+	})
+}
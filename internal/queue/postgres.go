@@ -0,0 +1,221 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+)
+
+// staleGrace is how long past the processing timeout a running job may
+// go without finishing before it is considered abandoned by a crashed
+// worker and handed out again
+const staleGrace = time.Minute
+
+const jobColumns = `id, type, payload, status, priority, attempts, max_attempts, run_at, locked_at,
+	last_error, result, created_at, updated_at, finished_at`
+
+// PostgresQueue is a Queue stored in the jobs table. Workers claim jobs
+// with SELECT ... FOR UPDATE SKIP LOCKED, so any number of workers across
+// any number of fgc-server instances can share it without Redis.
+type PostgresQueue struct {
+	db                 *sql.DB
+	defaultMaxAttempts int
+	staleAfter         time.Duration
+}
+
+// NewPostgresQueue creates a queue backed by db
+func NewPostgresQueue(db *sql.DB, cfg *config.QueueConfig) *PostgresQueue {
+	return &PostgresQueue{
+		db:                 db,
+		defaultMaxAttempts: cfg.RetryAttempts + 1,
+		staleAfter:         cfg.ProcessingTimeout + staleGrace,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row scanner) (*Job, error) {
+	j := &Job{}
+	var payload, result []byte
+	err := row.Scan(&j.ID, &j.Type, &payload, &j.Status, &j.Priority, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LockedAt, &j.LastError, &result, &j.CreatedAt, &j.UpdatedAt, &j.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	j.Payload = payload
+	j.Result = result
+	return j, nil
+}
+
+// Enqueue stores a new pending job
+func (q *PostgresQueue) Enqueue(ctx context.Context, job *Job) error {
+	return q.EnqueueWith(ctx, q.db, job)
+}
+
+// EnqueueWith stores a new pending job using db, which may be a
+// transaction on the queue's database
+func (q *PostgresQueue) EnqueueWith(ctx context.Context, db Querier, job *Job) error {
+	if job.Type == "" {
+		return fmt.Errorf("job type is required")
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.defaultMaxAttempts
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = []byte("{}")
+	}
+	runAt := job.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+
+	query := `INSERT INTO jobs (type, payload, priority, max_attempts, run_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + jobColumns
+
+	row := db.QueryRowContext(ctx, query, job.Type, []byte(job.Payload), job.Priority, job.MaxAttempts, runAt)
+	stored, err := scanJob(row)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", job.Type, err)
+	}
+	*job = *stored
+	return nil
+}
+
+// Dequeue claims the next runnable job. Pending jobs whose run_at has
+// passed are eligible, as are running jobs whose worker has gone silent
+// for longer than the processing timeout.
+func (q *PostgresQueue) Dequeue(ctx context.Context, types []string) (*Job, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	query := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE type = ANY($1)
+			  AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_at < $2))
+			ORDER BY priority DESC, run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns
+
+	staleBefore := time.Now().Add(-q.staleAfter)
+	job, err := scanJob(q.db.QueryRowContext(ctx, query, pq.Array(types), staleBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dequeue job: %w", err)
+	}
+	return job, nil
+}
+
+// Complete marks a job as succeeded and stores its result
+func (q *PostgresQueue) Complete(ctx context.Context, job *Job) error {
+	var result []byte
+	if len(job.Result) > 0 {
+		result = job.Result
+	}
+
+	query := `UPDATE jobs SET status = 'succeeded', locked_at = NULL, last_error = NULL, result = $3,
+		finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND locked_at = $2
+		RETURNING ` + jobColumns
+	return q.update(ctx, job, query, job.ID, job.LockedAt, result)
+}
+
+// Retry puts a job back in the pending state
+func (q *PostgresQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	query := `UPDATE jobs SET status = 'pending', locked_at = NULL, last_error = $3, run_at = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND locked_at = $2
+		RETURNING ` + jobColumns
+	return q.update(ctx, job, query, job.ID, job.LockedAt, errorText(cause), runAt)
+}
+
+// Bury moves a job to the dead-letter state
+func (q *PostgresQueue) Bury(ctx context.Context, job *Job, cause error) error {
+	query := `UPDATE jobs SET status = 'dead', locked_at = NULL, last_error = $3, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND locked_at = $2
+		RETURNING ` + jobColumns
+	return q.update(ctx, job, query, job.ID, job.LockedAt, errorText(cause))
+}
+
+// Get returns a job by ID
+func (q *PostgresQueue) Get(ctx context.Context, id int64) (*Job, error) {
+	job, err := scanJob(q.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// CountJobs returns the number of pending, running and dead jobs by type
+// and then status. Succeeded jobs are not counted.
+func (q *PostgresQueue) CountJobs(ctx context.Context) (map[string]map[string]int, error) {
+	query := `SELECT type, status, COUNT(*) FROM jobs
+		WHERE status IN ($1, $2, $3)
+		GROUP BY type, status`
+
+	rows, err := q.db.QueryContext(ctx, query, StatusPending, StatusRunning, StatusDead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var jobType, status string
+		var n int
+		if err := rows.Scan(&jobType, &status, &n); err != nil {
+			return nil, fmt.Errorf("failed to count jobs: %w", err)
+		}
+		if counts[jobType] == nil {
+			counts[jobType] = make(map[string]int)
+		}
+		counts[jobType][status] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return counts, nil
+}
+
+// update runs a state transition of a job claimed by this worker and
+// refreshes job from the stored row. It returns ErrLockLost if the job is
+// no longer running under that claim.
+func (q *PostgresQueue) update(ctx context.Context, job *Job, query string, args ...interface{}) error {
+	stored, err := scanJob(q.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := q.Get(ctx, job.ID); err != nil {
+			return err
+		}
+		return ErrLockLost
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update job %d: %w", job.ID, err)
+	}
+	*job = *stored
+	return nil
+}
+
+// errorText returns the message stored for a failure
+func errorText(err error) *string {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	return &msg
+}
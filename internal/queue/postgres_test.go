@@ -0,0 +1,219 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/database"
+)
+
+// setupTestQueue connects to the test database, applies migrations and empties the jobs table
+func setupTestQueue(t *testing.T, cfg *config.QueueConfig) *PostgresQueue {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	dbCfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:                  getEnv("FGC_DATABASE_HOST", "localhost"),
+			Port:                  5432,
+			User:                  getEnv("FGC_DATABASE_USER", "fgc_test"),
+			Password:              getEnv("FGC_DATABASE_PASSWORD", "fgc_test_password"),
+			Name:                  getEnv("FGC_DATABASE_NAME", "forgejo_classroom_test"),
+			SSLMode:               "disable",
+			MaxConnections:        10,
+			MaxIdleConnections:    2,
+			ConnectionMaxLifetime: time.Hour,
+		},
+	}
+
+	logger := zap.NewNop()
+	db, err := database.New(dbCfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.RunMigrations(db.DB, database.MigrateConfig{MigrationsPath: "../../migrations"}, logger))
+	_, err = db.Exec(`TRUNCATE jobs RESTART IDENTITY`)
+	require.NoError(t, err)
+
+	return NewPostgresQueue(db.DB, cfg)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestPostgresQueue(t *testing.T) {
+	q := setupTestQueue(t, &config.QueueConfig{RetryAttempts: 2, ProcessingTimeout: time.Minute})
+	ctx := context.Background()
+
+	t.Run("enqueue applies defaults", func(t *testing.T) {
+		job, err := NewJob("greet", greeting{Name: "alice"})
+		require.NoError(t, err)
+		require.NoError(t, q.Enqueue(ctx, job))
+
+		assert.NotZero(t, job.ID)
+		assert.Equal(t, StatusPending, job.Status)
+		assert.Equal(t, 3, job.MaxAttempts)
+
+		var g greeting
+		require.NoError(t, job.Decode(&g))
+		assert.Equal(t, "alice", g.Name)
+
+		claimed, err := q.Dequeue(ctx, []string{"greet"})
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, job.ID, claimed.ID)
+		assert.Equal(t, StatusRunning, claimed.Status)
+		assert.Equal(t, 1, claimed.Attempts)
+
+		require.NoError(t, claimed.SetResult(greeting{Name: "bob"}))
+		require.NoError(t, q.Complete(ctx, claimed))
+		assert.Equal(t, StatusSucceeded, claimed.Status)
+		assert.NotNil(t, claimed.FinishedAt)
+
+		stored, err := q.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"bob"}`, string(stored.Result))
+	})
+
+	t.Run("only requested types are dequeued", func(t *testing.T) {
+		job := &Job{Type: "other"}
+		require.NoError(t, q.Enqueue(ctx, job))
+
+		claimed, err := q.Dequeue(ctx, []string{"greet"})
+		require.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		claimed, err = q.Dequeue(ctx, []string{"other"})
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		require.NoError(t, q.Complete(ctx, claimed))
+	})
+
+	t.Run("priority and run_at ordering", func(t *testing.T) {
+		low := &Job{Type: "order"}
+		high := &Job{Type: "order", Priority: 10}
+		later := &Job{Type: "order", Priority: 100, RunAt: time.Now().Add(time.Hour)}
+		for _, j := range []*Job{low, high, later} {
+			require.NoError(t, q.Enqueue(ctx, j))
+		}
+
+		first, err := q.Dequeue(ctx, []string{"order"})
+		require.NoError(t, err)
+		second, err := q.Dequeue(ctx, []string{"order"})
+		require.NoError(t, err)
+		none, err := q.Dequeue(ctx, []string{"order"})
+		require.NoError(t, err)
+
+		assert.Equal(t, high.ID, first.ID)
+		assert.Equal(t, low.ID, second.ID)
+		assert.Nil(t, none, "jobs scheduled in the future are not ready")
+	})
+
+	t.Run("concurrent workers never claim the same job", func(t *testing.T) {
+		const jobs = 50
+		for i := 0; i < jobs; i++ {
+			require.NoError(t, q.Enqueue(ctx, &Job{Type: "concurrent"}))
+		}
+
+		var mu sync.Mutex
+		seen := make(map[int64]int)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					job, err := q.Dequeue(ctx, []string{"concurrent"})
+					if !assert.NoError(t, err) || job == nil {
+						return
+					}
+					mu.Lock()
+					seen[job.ID]++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, seen, jobs)
+		for id, n := range seen {
+			assert.Equal(t, 1, n, "job %d claimed %d times", id, n)
+		}
+	})
+
+	t.Run("retry and bury", func(t *testing.T) {
+		job := &Job{Type: "failing"}
+		require.NoError(t, q.Enqueue(ctx, job))
+
+		claimed, err := q.Dequeue(ctx, []string{"failing"})
+		require.NoError(t, err)
+		require.NoError(t, q.Retry(ctx, claimed, time.Now().Add(-time.Second), errors.New("try again")))
+		assert.Equal(t, StatusPending, claimed.Status)
+		require.NotNil(t, claimed.LastError)
+		assert.Equal(t, "try again", *claimed.LastError)
+
+		claimed, err = q.Dequeue(ctx, []string{"failing"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, claimed.Attempts)
+		require.NoError(t, q.Bury(ctx, claimed, errors.New("gave up")))
+
+		stored, err := q.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusDead, stored.Status)
+		assert.True(t, stored.IsFinished())
+	})
+
+	t.Run("get missing job", func(t *testing.T) {
+		_, err := q.Get(ctx, 999999)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestPostgresQueue_RecoversAbandonedJobs(t *testing.T) {
+	// Treat every running job as stale, whatever the clock skew to the database
+	q := setupTestQueue(t, &config.QueueConfig{RetryAttempts: 3})
+	q.staleAfter = -time.Minute
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, &Job{Type: "abandoned"}))
+	first, err := q.Dequeue(ctx, []string{"abandoned"})
+	require.NoError(t, err)
+	require.NotNil(t, first)
+
+	// The worker "crashed": the job is still running but its lock is stale
+	again, err := q.Dequeue(ctx, []string{"abandoned"})
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 2, again.Attempts)
+
+	t.Run("only the latest claim records the outcome", func(t *testing.T) {
+		assert.ErrorIs(t, q.Complete(ctx, first), ErrLockLost)
+		assert.ErrorIs(t, q.Bury(ctx, first, errors.New("late")), ErrLockLost)
+		assert.ErrorIs(t, q.Retry(ctx, first, time.Now(), errors.New("late")), ErrLockLost)
+
+		require.NoError(t, q.Complete(ctx, again))
+		assert.Equal(t, StatusSucceeded, again.Status)
+		assert.Nil(t, again.LockedAt)
+		assert.ErrorIs(t, q.Complete(ctx, again), ErrLockLost, "finished jobs are not running")
+	})
+
+	t.Run("missing jobs are not found", func(t *testing.T) {
+		assert.ErrorIs(t, q.Complete(ctx, &Job{ID: 999999}), ErrNotFound)
+	})
+}
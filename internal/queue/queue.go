@@ -0,0 +1,136 @@
+// Package queue runs background jobs outside of HTTP requests.
+//
+// Jobs are typed by name and carry a JSON payload. A Pool of workers
+// dequeues jobs, dispatches them to the Handler registered for their type
+// and retries failures with exponential backoff; a job that keeps failing
+// (or fails permanently) is moved to the dead-letter state for inspection.
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// ErrNotFound is returned when a job does not exist
+var ErrNotFound = errors.New("job not found")
+
+// ErrLockLost is returned when a worker records the outcome of a job that
+// is no longer its own: the job ran past the processing timeout and was
+// handed out again, so the new claim decides its outcome
+var ErrLockLost = errors.New("job was claimed by another worker")
+
+// Job is a unit of background work
+type Job struct {
+	ID          int64           `json:"id" db:"id"`
+	Type        string          `json:"type" db:"type"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      string          `json:"status" db:"status"`
+	Priority    int             `json:"priority" db:"priority"` // higher runs first
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LockedAt    *time.Time      `json:"locked_at,omitempty" db:"locked_at"` // when a worker claimed it, while running
+	LastError   *string         `json:"last_error,omitempty" db:"last_error"`
+	Result      json.RawMessage `json:"result,omitempty" db:"result"` // set by the handler
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// NewJob creates a job of the given type with payload encoded as JSON
+func NewJob(jobType string, payload interface{}) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+	}
+	return &Job{Type: jobType, Payload: data}, nil
+}
+
+// Decode decodes the job payload into v
+func (j *Job) Decode(v interface{}) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", j.Type, err)
+	}
+	return nil
+}
+
+// SetResult records v, encoded as JSON, as the outcome of the job. It is
+// stored when the job completes.
+func (j *Job) SetResult(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s result: %w", j.Type, err)
+	}
+	j.Result = data
+	return nil
+}
+
+// IsFinished returns true if the job will not run again
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusDead
+}
+
+// Queue stores jobs and hands them out to workers
+type Queue interface {
+	// Enqueue stores a new pending job, filling in its ID and defaults
+	Enqueue(ctx context.Context, job *Job) error
+	// Dequeue claims the next runnable job of one of the given types and
+	// marks it running. It returns nil when no job is ready.
+	Dequeue(ctx context.Context, types []string) (*Job, error)
+	// Complete marks a running job as succeeded and stores its result.
+	// Complete, Retry and Bury return ErrLockLost unless job is still
+	// running under the claim returned by Dequeue.
+	Complete(ctx context.Context, job *Job) error
+	// Retry returns a failed job to the queue to run again at runAt
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error
+	// Bury moves a failed job to the dead-letter state
+	Bury(ctx context.Context, job *Job, cause error) error
+	// Get returns a job by ID
+	Get(ctx context.Context, id int64) (*Job, error)
+}
+
+// Querier runs queries on a database or within a transaction
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// TxEnqueuer is implemented by queues stored in the application database.
+// EnqueueWith is Enqueue running on db, so that a job is only stored if
+// the caller's transaction commits.
+type TxEnqueuer interface {
+	EnqueueWith(ctx context.Context, db Querier, job *Job) error
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered without further retries
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
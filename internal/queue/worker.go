@@ -0,0 +1,265 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+)
+
+// Worker pool defaults
+const (
+	DefaultPollInterval = time.Second
+	maxRetryDelay       = time.Hour
+)
+
+// Pool runs registered handlers on jobs taken from a Queue
+type Pool struct {
+	queue        Queue
+	logger       *zap.Logger
+	workers      int
+	timeout      time.Duration
+	retryDelay   time.Duration
+	pollInterval time.Duration
+	metrics      *metrics.Metrics
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	started  bool
+	stop     context.CancelFunc // stops dequeuing
+	abort    context.CancelFunc // cancels running jobs
+	wg       sync.WaitGroup
+}
+
+// NewPool creates a worker pool for q configured by cfg
+func NewPool(q Queue, cfg *config.QueueConfig, logger *zap.Logger) *Pool {
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	workers := cfg.WorkerCount
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &Pool{
+		queue:        q,
+		logger:       logger,
+		workers:      workers,
+		timeout:      cfg.ProcessingTimeout,
+		retryDelay:   cfg.RetryDelay,
+		pollInterval: pollInterval,
+		handlers:     make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for jobs of jobType. It must be called before Start.
+func (p *Pool) Register(jobType string, h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		panic(fmt.Sprintf("queue: Register(%q) called after Start", jobType))
+	}
+	p.handlers[jobType] = h
+}
+
+// SetMetrics records the outcome and run time of every job attempt in m.
+// It must be called before Start.
+func (p *Pool) SetMetrics(m *metrics.Metrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		panic("queue: SetMetrics called after Start")
+	}
+	p.metrics = m
+}
+
+// Start launches the workers. They poll until Shutdown is called.
+func (p *Pool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return
+	}
+	p.started = true
+
+	types := make([]string, 0, len(p.handlers))
+	for t := range p.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var pollCtx, jobCtx context.Context
+	pollCtx, p.stop = context.WithCancel(context.Background())
+	jobCtx, p.abort = context.WithCancel(context.Background())
+
+	p.logger.Info("Starting job workers", zap.Int("workers", p.workers), zap.Strings("types", types))
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(pollCtx, jobCtx, types)
+	}
+}
+
+// Shutdown stops taking new jobs and waits for running jobs to finish.
+// If ctx expires first, running jobs are cancelled (and later retried) and
+// ctx's error is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.started {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stop()
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.abort()
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work is the loop run by each worker
+func (p *Pool) work(pollCtx, jobCtx context.Context, types []string) {
+	defer p.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-pollCtx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// Keep draining while jobs are ready, then wait for the next poll
+		for pollCtx.Err() == nil {
+			job, err := p.queue.Dequeue(pollCtx, types)
+			if err != nil {
+				if pollCtx.Err() == nil {
+					p.logger.Error("Failed to dequeue job", zap.Error(err))
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			p.run(jobCtx, job)
+		}
+		timer.Reset(p.pollInterval)
+	}
+}
+
+// run executes one job and records the outcome
+func (p *Pool) run(ctx context.Context, job *Job) {
+	logger := p.logger.With(zap.Int64("job_id", job.ID), zap.String("type", job.Type), zap.Int("attempt", job.Attempts))
+
+	start := time.Now()
+	err := p.execute(ctx, job)
+	elapsed := time.Since(start)
+
+	// Record the outcome even if the pool is being aborted
+	recordCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err == nil {
+		if err := p.queue.Complete(recordCtx, job); err != nil {
+			logRecordError(logger, "Failed to mark job succeeded", err)
+		}
+		logger.Debug("Job succeeded")
+		p.metrics.ObserveJob(job.Type, metrics.JobSucceeded, elapsed)
+		return
+	}
+
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		logger.Error("Job failed, moving to dead letter", zap.Error(err))
+		if err := p.queue.Bury(recordCtx, job, err); err != nil {
+			logRecordError(logger, "Failed to dead-letter job", err)
+		}
+		p.metrics.ObserveJob(job.Type, metrics.JobDead, elapsed)
+		return
+	}
+
+	delay := p.backoff(job.Attempts)
+	logger.Warn("Job failed, retrying", zap.Duration("delay", delay), zap.Error(err))
+	if err := p.queue.Retry(recordCtx, job, time.Now().Add(delay), err); err != nil {
+		logRecordError(logger, "Failed to reschedule job", err)
+	}
+	p.metrics.ObserveJob(job.Type, metrics.JobRetried, elapsed)
+}
+
+// logRecordError logs a failure to record the outcome of a job. A job
+// handed to another worker is expected after a timeout and only warned
+// about, since its new claim records the outcome.
+func logRecordError(logger *zap.Logger, msg string, err error) {
+	if errors.Is(err, ErrLockLost) {
+		logger.Warn("Job was claimed by another worker, dropping its outcome", zap.Error(err))
+		return
+	}
+	logger.Error(msg, zap.Error(err))
+}
+
+// execute calls the job's handler with the processing timeout, turning
+// panics into errors
+func (p *Pool) execute(ctx context.Context, job *Job) (err error) {
+	if job.Attempts > job.MaxAttempts {
+		// Claimed again after its worker died on the final attempt
+		return Permanent(fmt.Errorf("abandoned after %d attempts", job.MaxAttempts))
+	}
+
+	p.mu.Lock()
+	h, ok := p.handlers[job.Type]
+	p.mu.Unlock()
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	err = h.Handle(ctx, job)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("job exceeded processing timeout of %s: %w", p.timeout, err)
+	}
+	return err
+}
+
+// backoff returns the delay before retrying after the given attempt:
+// RetryDelay, doubled for each further attempt and capped at an hour
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.retryDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,351 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/metrics"
+)
+
+// memoryQueue is an in-memory Queue for exercising the worker pool
+type memoryQueue struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*Job
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{jobs: make(map[int64]*Job)}
+}
+
+func (q *memoryQueue) Enqueue(_ context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	job.ID = q.nextID
+	job.Status = StatusPending
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 3
+	}
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+func (q *memoryQueue) Dequeue(_ context.Context, types []string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id := int64(1); id <= q.nextID; id++ {
+		j := q.jobs[id]
+		if j.Status != StatusPending || j.RunAt.After(time.Now()) {
+			continue
+		}
+		for _, t := range types {
+			if j.Type == t {
+				now := time.Now()
+				j.Status = StatusRunning
+				j.Attempts++
+				j.LockedAt = &now
+				claimed := *j
+				return &claimed, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (q *memoryQueue) set(job *Job, status string, runAt time.Time, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j := q.jobs[job.ID]
+	if j.Status != StatusRunning || j.LockedAt == nil || job.LockedAt == nil || !j.LockedAt.Equal(*job.LockedAt) {
+		return ErrLockLost
+	}
+	j.Status = status
+	j.RunAt = runAt
+	j.LockedAt = nil
+	j.LastError = errorText(cause)
+	*job = *j
+	return nil
+}
+
+func (q *memoryQueue) Complete(_ context.Context, job *Job) error {
+	return q.set(job, StatusSucceeded, job.RunAt, nil)
+}
+
+func (q *memoryQueue) Retry(_ context.Context, job *Job, runAt time.Time, cause error) error {
+	// Run retries immediately so tests do not wait for the backoff
+	return q.set(job, StatusPending, time.Time{}, cause)
+}
+
+func (q *memoryQueue) Bury(_ context.Context, job *Job, cause error) error {
+	return q.set(job, StatusDead, job.RunAt, cause)
+}
+
+func (q *memoryQueue) Get(_ context.Context, id int64) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *j
+	return &copied, nil
+}
+
+func testConfig() *config.QueueConfig {
+	return &config.QueueConfig{
+		WorkerCount:       2,
+		ProcessingTimeout: time.Second,
+		RetryDelay:        time.Millisecond,
+		PollInterval:      5 * time.Millisecond,
+	}
+}
+
+func waitForStatus(t *testing.T, q Queue, id int64, status string) *Job {
+	t.Helper()
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Get(context.Background(), id)
+		require.NoError(t, err)
+		return job.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	q := newMemoryQueue()
+	pool := NewPool(q, testConfig(), zap.NewNop())
+
+	var greeted sync.Map
+	pool.Register("greet", Typed(func(_ context.Context, _ *Job, g greeting) error {
+		greeted.Store(g.Name, true)
+		return nil
+	}))
+
+	var flakyCalls atomic.Int32
+	pool.Register("flaky", HandlerFunc(func(context.Context, *Job) error {
+		if flakyCalls.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}))
+
+	pool.Register("broken", HandlerFunc(func(context.Context, *Job) error {
+		return errors.New("always fails")
+	}))
+	pool.Register("fatal", HandlerFunc(func(context.Context, *Job) error {
+		return Permanent(errors.New("bad input"))
+	}))
+	pool.Register("panics", HandlerFunc(func(context.Context, *Job) error {
+		panic("boom")
+	}))
+
+	overtaken := make(chan struct{})
+	pool.Register("overtaken", HandlerFunc(func(_ context.Context, job *Job) error {
+		// Another worker claims the job while this one is still running it
+		q.mu.Lock()
+		reclaimed := job.LockedAt.Add(time.Minute)
+		q.jobs[job.ID].LockedAt = &reclaimed
+		q.mu.Unlock()
+		close(overtaken)
+		return nil
+	}))
+
+	enqueue := func(jobType string, payload interface{}) *Job {
+		job, err := NewJob(jobType, payload)
+		require.NoError(t, err)
+		require.NoError(t, q.Enqueue(ctx, job))
+		return job
+	}
+
+	pool.Start()
+	defer func() { require.NoError(t, pool.Shutdown(ctx)) }()
+
+	t.Run("typed handler", func(t *testing.T) {
+		job := enqueue("greet", greeting{Name: "alice"})
+		waitForStatus(t, q, job.ID, StatusSucceeded)
+		_, ok := greeted.Load("alice")
+		assert.True(t, ok)
+	})
+
+	t.Run("undecodable payload is dead-lettered", func(t *testing.T) {
+		job := &Job{Type: "greet", Payload: []byte(`"not an object"`)}
+		require.NoError(t, q.Enqueue(ctx, job))
+		dead := waitForStatus(t, q, job.ID, StatusDead)
+		assert.Equal(t, 1, dead.Attempts)
+	})
+
+	t.Run("outcome of an overtaken job is dropped", func(t *testing.T) {
+		job := enqueue("overtaken", nil)
+		<-overtaken
+		assert.Never(t, func() bool {
+			stored, err := q.Get(ctx, job.ID)
+			return err != nil || stored.Status != StatusRunning
+		}, 50*time.Millisecond, 5*time.Millisecond)
+	})
+
+	t.Run("retries until success", func(t *testing.T) {
+		job := enqueue("flaky", nil)
+		done := waitForStatus(t, q, job.ID, StatusSucceeded)
+		assert.Equal(t, 3, done.Attempts)
+		assert.Nil(t, done.LastError)
+	})
+
+	t.Run("dead-lettered after max attempts", func(t *testing.T) {
+		job := enqueue("broken", nil)
+		dead := waitForStatus(t, q, job.ID, StatusDead)
+		assert.Equal(t, 3, dead.Attempts)
+		require.NotNil(t, dead.LastError)
+		assert.Equal(t, "always fails", *dead.LastError)
+	})
+
+	t.Run("permanent errors skip retries", func(t *testing.T) {
+		job := enqueue("fatal", nil)
+		dead := waitForStatus(t, q, job.ID, StatusDead)
+		assert.Equal(t, 1, dead.Attempts)
+	})
+
+	t.Run("panics fail the job", func(t *testing.T) {
+		job := enqueue("panics", nil)
+		dead := waitForStatus(t, q, job.ID, StatusDead)
+		assert.Contains(t, *dead.LastError, "boom")
+	})
+}
+
+func TestPool_Metrics(t *testing.T) {
+	ctx := context.Background()
+	q := newMemoryQueue()
+	m := metrics.New(zap.NewNop())
+	pool := NewPool(q, testConfig(), zap.NewNop())
+	pool.SetMetrics(m)
+
+	var calls atomic.Int32
+	pool.Register("flaky", HandlerFunc(func(context.Context, *Job) error {
+		if calls.Add(1) == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}))
+	pool.Register("fatal", HandlerFunc(func(context.Context, *Job) error {
+		return Permanent(errors.New("bad input"))
+	}))
+
+	pool.Start()
+	defer func() { require.NoError(t, pool.Shutdown(ctx)) }()
+	assert.Panics(t, func() { pool.SetMetrics(m) })
+
+	for _, jobType := range []string{"flaky", "fatal"} {
+		job, err := NewJob(jobType, nil)
+		require.NoError(t, err)
+		require.NoError(t, q.Enqueue(ctx, job))
+	}
+
+	expected := []string{
+		`fgc_jobs_processed_total{outcome="retried",type="flaky"} 1`,
+		`fgc_jobs_processed_total{outcome="succeeded",type="flaky"} 1`,
+		`fgc_jobs_processed_total{outcome="dead",type="fatal"} 1`,
+		`fgc_job_duration_seconds_count{type="flaky"} 2`,
+	}
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		for _, line := range expected {
+			if !strings.Contains(rec.Body.String(), line) {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestPool_ShutdownDrainsRunningJobs(t *testing.T) {
+	ctx := context.Background()
+	q := newMemoryQueue()
+	pool := NewPool(q, testConfig(), zap.NewNop())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool.Register("slow", HandlerFunc(func(context.Context, *Job) error {
+		close(started)
+		<-release
+		return nil
+	}))
+
+	job, err := NewJob("slow", nil)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(ctx, job))
+
+	pool.Start()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- pool.Shutdown(ctx) }()
+
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned while a job was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdown)
+
+	stored, err := q.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, stored.Status)
+}
+
+func TestPool_ShutdownTimeoutCancelsJobs(t *testing.T) {
+	q := newMemoryQueue()
+	pool := NewPool(q, testConfig(), zap.NewNop())
+
+	started := make(chan struct{})
+	pool.Register("stuck", HandlerFunc(func(ctx context.Context, _ *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	job, err := NewJob("stuck", nil)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(context.Background(), job))
+
+	pool.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+
+	stored, err := q.Get(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, stored.Status, "cancelled jobs are retried")
+}
+
+func TestPool_Backoff(t *testing.T) {
+	pool := NewPool(newMemoryQueue(), &config.QueueConfig{RetryDelay: 30 * time.Second}, zap.NewNop())
+
+	assert.Equal(t, 30*time.Second, pool.backoff(1))
+	assert.Equal(t, time.Minute, pool.backoff(2))
+	assert.Equal(t, 2*time.Minute, pool.backoff(3))
+	assert.Equal(t, time.Hour, pool.backoff(20))
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const assignmentColumns = `id, classroom_id, name, slug, COALESCE(description, ''), template_repository,
+	template_repository_id, deadline, max_team_size, auto_accept, public, deadline_enforced_for,
+	created_at, updated_at`
+
+// Assignment status filters accepted by AssignmentListRequest.Status
+const (
+	AssignmentStatusActive = "active"
+	AssignmentStatusPast   = "past"
+	AssignmentStatusAll    = "all"
+)
+
+// AssignmentRepository stores assignments
+type AssignmentRepository struct {
+	db DBTX
+}
+
+// NewAssignmentRepository creates a new assignment repository
+func NewAssignmentRepository(db DBTX) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+func scanAssignment(row scanner) (*model.Assignment, error) {
+	var a model.Assignment
+	err := row.Scan(
+		&a.ID, &a.ClassroomID, &a.Name, &a.Slug, &a.Description, &a.TemplateRepository,
+		&a.TemplateRepositoryID, &a.Deadline, &a.MaxTeamSize, &a.AutoAccept, &a.Public, &a.DeadlineEnforcedFor,
+		&a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &a, nil
+}
+
+// Create inserts an assignment and fills in its ID and timestamps
+func (r *AssignmentRepository) Create(ctx context.Context, a *model.Assignment) error {
+	query := `
+		INSERT INTO assignments (classroom_id, name, slug, description, template_repository,
+			template_repository_id, deadline, max_team_size, auto_accept, public)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		a.ClassroomID, a.Name, a.Slug, a.Description, a.TemplateRepository,
+		a.TemplateRepositoryID, a.Deadline, a.MaxTeamSize, a.AutoAccept, a.Public,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns the assignment with the given ID
+func (r *AssignmentRepository) GetByID(ctx context.Context, id int64) (*model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments WHERE id = $1`
+	return scanAssignment(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetBySlug returns the assignment with the given slug in a classroom
+func (r *AssignmentRepository) GetBySlug(ctx context.Context, classroomID int64, slug string) (*model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments WHERE classroom_id = $1 AND slug = $2`
+	return scanAssignment(r.db.QueryRowContext(ctx, query, classroomID, slug))
+}
+
+// Update writes the mutable fields of an assignment and refreshes UpdatedAt
+func (r *AssignmentRepository) Update(ctx context.Context, a *model.Assignment) error {
+	query := `
+		UPDATE assignments
+		SET name = $2, slug = $3, description = $4, deadline = $5, max_team_size = $6,
+			auto_accept = $7, public = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		a.ID, a.Name, a.Slug, a.Description, a.Deadline, a.MaxTeamSize, a.AutoAccept, a.Public,
+	).Scan(&a.UpdatedAt)
+	return mapError(err)
+}
+
+// enforceDeadlineJob is the type of the jobs that enforce deadlines
+// (service.JobTypeEnforceDeadline)
+const enforceDeadlineJob = "deadline.enforce"
+
+// deadlineUnscheduled matches assignments whose deadline has not been
+// enforced and has no enforcement job pending or running, nor one that was
+// dead-lettered after $1. Its parameters are that time and the job type.
+const deadlineUnscheduled = `deadline_enforced_for IS DISTINCT FROM deadline AND NOT EXISTS (
+		SELECT 1 FROM jobs
+		WHERE jobs.type = $2
+			AND jobs.payload @> jsonb_build_object('assignment_id', assignments.id)
+			AND (jobs.payload->>'deadline')::TIMESTAMPTZ = assignments.deadline
+			AND (jobs.status IN ('pending', 'running') OR (jobs.status = 'dead' AND jobs.finished_at > $1)))`
+
+// ListDueDeadlines returns up to limit assignments whose deadline has passed
+// at now but is neither enforced nor being enforced, earliest first.
+// Deadlines whose enforcement job was dead-lettered after deadAfter are left
+// out, so that they are retried at intervals during long outages.
+func (r *AssignmentRepository) ListDueDeadlines(ctx context.Context, now, deadAfter time.Time, limit int) ([]model.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments
+		WHERE deadline <= $3 AND ` + deadlineUnscheduled + `
+		ORDER BY deadline, id LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, deadAfter, enforceDeadlineJob, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due deadlines: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []model.Assignment
+	for rows.Next() {
+		a, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list due deadlines: %w", err)
+	}
+	return assignments, nil
+}
+
+// NextDeadline returns the earliest deadline after now, or nil if there is none
+func (r *AssignmentRepository) NextDeadline(ctx context.Context, now time.Time) (*time.Time, error) {
+	var next *time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT MIN(deadline) FROM assignments WHERE deadline > $1`, now).Scan(&next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find next deadline: %w", err)
+	}
+	return next, nil
+}
+
+// OverdueDeadlines returns the number of deadlines that have passed at now
+// but have not been enforced, and the earliest of them
+func (r *AssignmentRepository) OverdueDeadlines(ctx context.Context, now time.Time) (int, *time.Time, error) {
+	var count int
+	var oldest *time.Time
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(deadline) FROM assignments
+		WHERE deadline <= $1 AND deadline_enforced_for IS DISTINCT FROM deadline`, now).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count overdue deadlines: %w", err)
+	}
+	return count, oldest, nil
+}
+
+// ClaimDeadline records the assignment's deadline as scheduled for
+// enforcement. It returns false if the deadline has changed, was enforced
+// or has a job as in ListDueDeadlines. It must run in the transaction that
+// enqueues the job, after a lock that serializes schedulers, so that only
+// one of them enqueues each deadline.
+func (r *AssignmentRepository) ClaimDeadline(ctx context.Context, id int64, deadline, deadAfter time.Time) (bool, error) {
+	err := execAffectingOne(ctx, r.db, `
+		UPDATE assignments SET deadline_scheduled_for = deadline
+		WHERE id = $3 AND deadline = $4 AND `+deadlineUnscheduled, deadAfter, enforceDeadlineJob, id, deadline)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MarkDeadlineEnforced records that every submission has been tagged for deadline
+func (r *AssignmentRepository) MarkDeadlineEnforced(ctx context.Context, id int64, deadline time.Time) error {
+	return execAffectingOne(ctx, r.db,
+		`UPDATE assignments SET deadline_enforced_for = $2, updated_at = NOW() WHERE id = $1`, id, deadline)
+}
+
+// Delete removes an assignment together with its teams and submissions
+func (r *AssignmentRepository) Delete(ctx context.Context, id int64) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM assignments WHERE id = $1`, id)
+}
+
+// List returns one page of assignments matching req, ordered by deadline
+// (assignments without a deadline last)
+func (r *AssignmentRepository) List(ctx context.Context, req *model.AssignmentListRequest) (*model.AssignmentListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	if req.ClassroomID != 0 {
+		f.add("classroom_id = $%d", req.ClassroomID)
+	}
+	if req.MemberUserID != 0 {
+		f.add(`classroom_id IN (
+			SELECT id FROM classrooms WHERE instructor_id = $%[1]d
+			UNION SELECT classroom_id FROM roster_entries WHERE forgejo_user_id = $%[1]d)`, req.MemberUserID)
+	}
+	switch req.Status {
+	case "", AssignmentStatusAll:
+	case AssignmentStatusActive:
+		f.addRaw("(deadline IS NULL OR deadline > NOW())")
+	case AssignmentStatusPast:
+		f.addRaw("deadline <= NOW()")
+	default:
+		return nil, fmt.Errorf("invalid assignment status filter %q", req.Status)
+	}
+
+	total, err := count(ctx, r.db, "assignments", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + assignmentColumns + ` FROM assignments` + f.where() +
+		` ORDER BY deadline ASC NULLS LAST, id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := make([]model.Assignment, 0, perPage)
+	for rows.Next() {
+		a, err := scanAssignment(rows)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list assignments: %w", err)
+	}
+
+	return &model.AssignmentListResponse{
+		Assignments: assignments,
+		Total:       total,
+		Page:        page,
+		PerPage:     perPage,
+		TotalPages:  totalPages(total, perPage),
+	}, nil
+}
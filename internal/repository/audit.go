@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const auditColumns = `id, classroom_id, actor_id, actor_login, action, target_type, target_id,
+	changes, request_id, ip, created_at`
+
+// AuditRepository stores the audit log. The log is append-only: there is
+// no way to update or delete events, and the database rejects both.
+type AuditRepository struct {
+	db DBTX
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db DBTX) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func scanAuditEvent(row scanner) (*model.AuditEvent, error) {
+	var e model.AuditEvent
+	var changes []byte
+	err := row.Scan(
+		&e.ID, &e.ClassroomID, &e.ActorID, &e.ActorLogin, &e.Action, &e.TargetType, &e.TargetID,
+		&changes, &e.RequestID, &e.IP, &e.CreatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if err := json.Unmarshal(changes, &e.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode changes of audit event %d: %w", e.ID, err)
+	}
+	return &e, nil
+}
+
+// Create appends an event and fills in its ID and creation time
+func (r *AuditRepository) Create(ctx context.Context, e *model.AuditEvent) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+	if e.Changes == nil {
+		changes = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_events (classroom_id, actor_id, actor_login, action, target_type, target_id,
+			changes, request_id, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		e.ClassroomID, e.ActorID, e.ActorLogin, e.Action, e.TargetType, e.TargetID,
+		changes, e.RequestID, e.IP,
+	).Scan(&e.ID, &e.CreatedAt)
+	return mapError(err)
+}
+
+// List returns one page of a classroom's events, newest first
+func (r *AuditRepository) List(ctx context.Context, classroomID int64, req *model.AuditListRequest) (*model.AuditListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	f.add("classroom_id = $%d", classroomID)
+	if req.Action != "" {
+		f.add("action = $%d", req.Action)
+	}
+	if req.TargetType != "" {
+		f.add("target_type = $%d", req.TargetType)
+	}
+	if req.TargetID != 0 {
+		f.add("target_id = $%d", req.TargetID)
+	}
+	if req.Actor != "" {
+		f.add("LOWER(actor_login) = LOWER($%d)", req.Actor)
+	}
+	if req.Since != "" {
+		f.add("created_at >= $%d", req.Since)
+	}
+	if req.Until != "" {
+		f.add("created_at < $%d", req.Until)
+	}
+
+	total, err := count(ctx, r.db, "audit_events", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + auditColumns + ` FROM audit_events` + f.where() +
+		` ORDER BY created_at DESC, id DESC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.AuditEvent, 0, perPage)
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return &model.AuditListResponse{
+		Events:     events,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
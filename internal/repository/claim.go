@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// claimColumns selects a claim with the name and ID of its student, from
+// roster_claims c joined with roster_entries e
+const claimColumns = `c.id, c.classroom_id, c.roster_entry_id, e.student_name, e.student_id, c.forgejo_user_id,
+	c.forgejo_username, c.status, c.decided_by, c.decided_at, c.created_at, c.updated_at`
+
+const claimTables = `roster_claims c JOIN roster_entries e ON e.id = c.roster_entry_id`
+
+// ClaimRepository stores the claims students make on roster entries
+type ClaimRepository struct {
+	db DBTX
+}
+
+// NewClaimRepository creates a new claim repository
+func NewClaimRepository(db DBTX) *ClaimRepository {
+	return &ClaimRepository{db: db}
+}
+
+func scanClaim(row scanner) (*model.RosterClaim, error) {
+	var c model.RosterClaim
+	err := row.Scan(
+		&c.ID, &c.ClassroomID, &c.RosterEntryID, &c.StudentName, &c.StudentID, &c.ForgejoUserID,
+		&c.ForgejoUsername, &c.Status, &c.DecidedBy, &c.DecidedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &c, nil
+}
+
+// Create inserts a claim and fills in its ID and timestamps
+func (r *ClaimRepository) Create(ctx context.Context, c *model.RosterClaim) error {
+	query := `
+		INSERT INTO roster_claims (classroom_id, roster_entry_id, forgejo_user_id, forgejo_username,
+			status, decided_by, decided_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.ClassroomID, c.RosterEntryID, c.ForgejoUserID, c.ForgejoUsername,
+		c.Status, c.DecidedBy, c.DecidedAt,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns a claim of a classroom by its ID
+func (r *ClaimRepository) GetByID(ctx context.Context, classroomID, id int64) (*model.RosterClaim, error) {
+	query := `SELECT ` + claimColumns + ` FROM ` + claimTables + ` WHERE c.classroom_id = $1 AND c.id = $2`
+	return scanClaim(r.db.QueryRowContext(ctx, query, classroomID, id))
+}
+
+// GetPending returns the pending claim of a Forgejo account in a classroom
+func (r *ClaimRepository) GetPending(ctx context.Context, classroomID, userID int64) (*model.RosterClaim, error) {
+	query := `SELECT ` + claimColumns + ` FROM ` + claimTables + `
+		WHERE c.classroom_id = $1 AND c.forgejo_user_id = $2 AND c.status = 'pending'`
+	return scanClaim(r.db.QueryRowContext(ctx, query, classroomID, userID))
+}
+
+// Decide records the approval or rejection of a pending claim. It returns
+// ErrNotFound if the claim is no longer pending.
+func (r *ClaimRepository) Decide(ctx context.Context, c *model.RosterClaim) error {
+	query := `
+		UPDATE roster_claims SET status = $2, decided_by = $3, decided_at = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, c.ID, c.Status, c.DecidedBy, c.DecidedAt).Scan(&c.UpdatedAt)
+	return mapError(err)
+}
+
+// List returns one page of a classroom's claims, oldest first
+func (r *ClaimRepository) List(ctx context.Context, classroomID int64, req *model.RosterClaimListRequest) (*model.RosterClaimListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	f.add("c.classroom_id = $%d", classroomID)
+	if req.Status != "" {
+		f.add("c.status = $%d", req.Status)
+	}
+
+	total, err := count(ctx, r.db, claimTables, f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + claimColumns + ` FROM ` + claimTables + f.where() +
+		` ORDER BY c.created_at ASC, c.id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roster claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims := make([]model.RosterClaim, 0, perPage)
+	for rows.Next() {
+		c, err := scanClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list roster claims: %w", err)
+	}
+
+	return &model.RosterClaimListResponse{
+		Claims:     claims,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
+
+// CountPending returns the number of claims awaiting approval in each
+// classroom that has any
+func (r *ClaimRepository) CountPending(ctx context.Context) (map[int64]int, error) {
+	query := `SELECT classroom_id, COUNT(*) FROM roster_claims WHERE status = $1 GROUP BY classroom_id`
+
+	rows, err := r.db.QueryContext(ctx, query, model.ClaimStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending claims: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var classroomID int64
+		var n int
+		if err := rows.Scan(&classroomID, &n); err != nil {
+			return nil, fmt.Errorf("failed to count pending claims: %w", err)
+		}
+		counts[classroomID] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count pending claims: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const classroomColumns = `id, name, slug, COALESCE(description, ''), organization_name, organization_id,
+	instructor_id, instructor_login, public, archived, created_at, updated_at, archived_at`
+
+// ClassroomRepository stores classrooms
+type ClassroomRepository struct {
+	db DBTX
+}
+
+// NewClassroomRepository creates a new classroom repository
+func NewClassroomRepository(db DBTX) *ClassroomRepository {
+	return &ClassroomRepository{db: db}
+}
+
+func scanClassroom(row scanner) (*model.Classroom, error) {
+	var c model.Classroom
+	err := row.Scan(
+		&c.ID, &c.Name, &c.Slug, &c.Description, &c.OrganizationName, &c.OrganizationID,
+		&c.InstructorID, &c.InstructorLogin, &c.Public, &c.Archived, &c.CreatedAt, &c.UpdatedAt, &c.ArchivedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &c, nil
+}
+
+// Create inserts a classroom and fills in its ID and timestamps
+func (r *ClassroomRepository) Create(ctx context.Context, c *model.Classroom) error {
+	query := `
+		INSERT INTO classrooms (name, slug, description, organization_name, organization_id,
+			instructor_id, instructor_login, public, archived, archived_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.Name, c.Slug, c.Description, c.OrganizationName, c.OrganizationID,
+		c.InstructorID, c.InstructorLogin, c.Public, c.Archived, c.ArchivedAt,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns the classroom with the given ID
+func (r *ClassroomRepository) GetByID(ctx context.Context, id int64) (*model.Classroom, error) {
+	query := `SELECT ` + classroomColumns + ` FROM classrooms WHERE id = $1`
+	return scanClassroom(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetBySlug returns the classroom with the given slug
+func (r *ClassroomRepository) GetBySlug(ctx context.Context, slug string) (*model.Classroom, error) {
+	query := `SELECT ` + classroomColumns + ` FROM classrooms WHERE slug = $1`
+	return scanClassroom(r.db.QueryRowContext(ctx, query, slug))
+}
+
+// Update writes the mutable fields of a classroom and refreshes UpdatedAt
+func (r *ClassroomRepository) Update(ctx context.Context, c *model.Classroom) error {
+	query := `
+		UPDATE classrooms
+		SET name = $2, slug = $3, description = $4, public = $5, archived = $6, archived_at = $7,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.ID, c.Name, c.Slug, c.Description, c.Public, c.Archived, c.ArchivedAt,
+	).Scan(&c.UpdatedAt)
+	return mapError(err)
+}
+
+// Delete removes a classroom together with its roster, assignments and submissions
+func (r *ClassroomRepository) Delete(ctx context.Context, id int64) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM classrooms WHERE id = $1`, id)
+}
+
+// List returns one page of classrooms matching req, newest first
+func (r *ClassroomRepository) List(ctx context.Context, req *model.ClassroomListRequest) (*model.ClassroomListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	if req.OrganizationName != "" {
+		f.add("organization_name = $%d", req.OrganizationName)
+	}
+	if !req.IncludeArchived {
+		f.addRaw("archived = false")
+	}
+	if req.MemberUserID != 0 {
+		f.add(`(instructor_id = $%[1]d OR id IN (
+			SELECT classroom_id FROM roster_entries WHERE forgejo_user_id = $%[1]d))`, req.MemberUserID)
+	}
+
+	total, err := count(ctx, r.db, "classrooms", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + classroomColumns + ` FROM classrooms` + f.where() +
+		` ORDER BY created_at DESC, id DESC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list classrooms: %w", err)
+	}
+	defer rows.Close()
+
+	classrooms := make([]model.Classroom, 0, perPage)
+	for rows.Next() {
+		c, err := scanClassroom(rows)
+		if err != nil {
+			return nil, err
+		}
+		classrooms = append(classrooms, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list classrooms: %w", err)
+	}
+
+	return &model.ClassroomListResponse{
+		Classrooms: classrooms,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
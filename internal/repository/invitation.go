@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const invitationColumns = `assignment_id, token, enabled, expires_at, created_at, updated_at`
+
+// InvitationRepository stores the invitation tokens of assignments
+type InvitationRepository struct {
+	db DBTX
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db DBTX) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+func scanInvitation(row scanner) (*model.Invitation, error) {
+	var inv model.Invitation
+	err := row.Scan(&inv.AssignmentID, &inv.Token, &inv.Enabled, &inv.ExpiresAt, &inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &inv, nil
+}
+
+// Get returns the invitation of an assignment
+func (r *InvitationRepository) Get(ctx context.Context, assignmentID int64) (*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM assignment_invitations WHERE assignment_id = $1`
+	return scanInvitation(r.db.QueryRowContext(ctx, query, assignmentID))
+}
+
+// GetByToken returns the invitation with the given token
+func (r *InvitationRepository) GetByToken(ctx context.Context, token string) (*model.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM assignment_invitations WHERE token = $1`
+	return scanInvitation(r.db.QueryRowContext(ctx, query, token))
+}
+
+// Rotate stores inv as the invitation of its assignment, replacing the
+// previous token, and fills in its timestamps
+func (r *InvitationRepository) Rotate(ctx context.Context, inv *model.Invitation) error {
+	query := `
+		INSERT INTO assignment_invitations (assignment_id, token, enabled, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (assignment_id) DO UPDATE
+		SET token = EXCLUDED.token, enabled = EXCLUDED.enabled, expires_at = EXCLUDED.expires_at,
+			created_at = NOW(), updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, inv.AssignmentID, inv.Token, inv.Enabled, inv.ExpiresAt).
+		Scan(&inv.CreatedAt, &inv.UpdatedAt)
+	return mapError(err)
+}
+
+// Update writes whether an invitation is enabled and its expiry, and
+// refreshes UpdatedAt
+func (r *InvitationRepository) Update(ctx context.Context, inv *model.Invitation) error {
+	query := `
+		UPDATE assignment_invitations SET enabled = $2, expires_at = $3, updated_at = NOW()
+		WHERE assignment_id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, inv.AssignmentID, inv.Enabled, inv.ExpiresAt).Scan(&inv.UpdatedAt)
+	return mapError(err)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// jobErrorBatchSize bounds the rows written by one INSERT
+const jobErrorBatchSize = 500
+
+// JobErrorRepository stores the per-row failures reported by jobs
+type JobErrorRepository struct {
+	db DBTX
+}
+
+// NewJobErrorRepository creates a new job error repository
+func NewJobErrorRepository(db DBTX) *JobErrorRepository {
+	return &JobErrorRepository{db: db}
+}
+
+// Replace stores errs as the errors of a job, discarding any recorded by
+// an earlier attempt
+func (r *JobErrorRepository) Replace(ctx context.Context, jobID int64, errs []model.JobError) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM job_errors WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to clear job errors: %w", err)
+	}
+
+	for start := 0; start < len(errs); start += jobErrorBatchSize {
+		end := start + jobErrorBatchSize
+		if end > len(errs) {
+			end = len(errs)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 5*(end-start))
+		for _, e := range errs[start:end] {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+			args = append(args, jobID, e.Row, e.Field, e.Code, e.Message)
+		}
+
+		query := `INSERT INTO job_errors (job_id, row_number, field, code, message) VALUES ` + strings.Join(values, ", ")
+		if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+			return mapError(err)
+		}
+	}
+	return nil
+}
+
+// Count returns the number of errors recorded for a job
+func (r *JobErrorRepository) Count(ctx context.Context, jobID int64) (int, error) {
+	f := &filter{}
+	f.add("job_id = $%d", jobID)
+	return count(ctx, r.db, "job_errors", f)
+}
+
+// List returns one page of a job's errors in row order
+func (r *JobErrorRepository) List(ctx context.Context, jobID int64, req *model.JobErrorListRequest) (*model.JobErrorListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	f.add("job_id = $%d", jobID)
+
+	total, err := count(ctx, r.db, "job_errors", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT row_number, field, code, message FROM job_errors` + f.where() +
+		` ORDER BY row_number ASC, id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job errors: %w", err)
+	}
+	defer rows.Close()
+
+	errs := make([]model.JobError, 0, perPage)
+	for rows.Next() {
+		var e model.JobError
+		if err := rows.Scan(&e.Row, &e.Field, &e.Code, &e.Message); err != nil {
+			return nil, err
+		}
+		errs = append(errs, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list job errors: %w", err)
+	}
+
+	return &model.JobErrorListResponse{
+		Errors:     errs,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const joinLinkColumns = `classroom_id, token, enabled, requires_approval, created_at, updated_at`
+
+// JoinLinkRepository stores the join link tokens of classrooms
+type JoinLinkRepository struct {
+	db DBTX
+}
+
+// NewJoinLinkRepository creates a new join link repository
+func NewJoinLinkRepository(db DBTX) *JoinLinkRepository {
+	return &JoinLinkRepository{db: db}
+}
+
+func scanJoinLink(row scanner) (*model.JoinLink, error) {
+	var link model.JoinLink
+	err := row.Scan(&link.ClassroomID, &link.Token, &link.Enabled, &link.RequiresApproval, &link.CreatedAt, &link.UpdatedAt)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &link, nil
+}
+
+// Get returns the join link of a classroom
+func (r *JoinLinkRepository) Get(ctx context.Context, classroomID int64) (*model.JoinLink, error) {
+	query := `SELECT ` + joinLinkColumns + ` FROM classroom_join_links WHERE classroom_id = $1`
+	return scanJoinLink(r.db.QueryRowContext(ctx, query, classroomID))
+}
+
+// GetByToken returns the join link with the given token
+func (r *JoinLinkRepository) GetByToken(ctx context.Context, token string) (*model.JoinLink, error) {
+	query := `SELECT ` + joinLinkColumns + ` FROM classroom_join_links WHERE token = $1`
+	return scanJoinLink(r.db.QueryRowContext(ctx, query, token))
+}
+
+// Rotate stores link as the join link of its classroom, replacing the
+// previous token, and fills in its timestamps
+func (r *JoinLinkRepository) Rotate(ctx context.Context, link *model.JoinLink) error {
+	query := `
+		INSERT INTO classroom_join_links (classroom_id, token, enabled, requires_approval)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (classroom_id) DO UPDATE
+		SET token = EXCLUDED.token, enabled = EXCLUDED.enabled, requires_approval = EXCLUDED.requires_approval,
+			created_at = NOW(), updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, link.ClassroomID, link.Token, link.Enabled, link.RequiresApproval).
+		Scan(&link.CreatedAt, &link.UpdatedAt)
+	return mapError(err)
+}
+
+// Update writes whether a join link is enabled and requires approval, and
+// refreshes UpdatedAt
+func (r *JoinLinkRepository) Update(ctx context.Context, link *model.JoinLink) error {
+	query := `
+		UPDATE classroom_join_links SET enabled = $2, requires_approval = $3, updated_at = NOW()
+		WHERE classroom_id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, link.ClassroomID, link.Enabled, link.RequiresApproval).Scan(&link.UpdatedAt)
+	return mapError(err)
+}
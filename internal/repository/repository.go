@@ -0,0 +1,191 @@
+// Package repository implements the PostgreSQL data access layer.
+//
+// Each repository works against a DBTX, so the same code runs on the
+// connection pool or inside a transaction started with
+// Repositories.WithTransaction.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"code.forgejo.org/forgejo/classroom/internal/database"
+)
+
+// Sentinel errors returned by every repository. Callers should test for
+// them with errors.Is.
+var (
+	// ErrNotFound is returned when the requested row does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrAlreadyExists is returned when a write violates a unique constraint
+	ErrAlreadyExists = errors.New("record already exists")
+	// ErrInvalidReference is returned when a write references a missing parent row
+	ErrInvalidReference = errors.New("referenced record does not exist")
+)
+
+// Pagination defaults shared by all list queries
+const (
+	DefaultPerPage = 30
+	MaxPerPage     = 100
+)
+
+// DBTX is the subset of *sql.DB and *sql.Tx used by the repositories
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Repositories groups the repositories for every model
+type Repositories struct {
+	db *database.DB // nil when bound to a transaction
+	q  DBTX
+
+	Classrooms  *ClassroomRepository
+	Assignments *AssignmentRepository
+	Roster      *RosterRepository
+	Submissions *SubmissionRepository
+	Teams       *TeamRepository
+	JobErrors   *JobErrorRepository
+	Invitations *InvitationRepository
+	JoinLinks   *JoinLinkRepository
+	Claims      *ClaimRepository
+	AuditEvents *AuditRepository
+}
+
+// New creates the repositories on top of a database connection
+func New(db *database.DB) *Repositories {
+	r := newRepositories(db)
+	r.db = db
+	return r
+}
+
+// newRepositories creates the repositories on top of any DBTX
+func newRepositories(db DBTX) *Repositories {
+	return &Repositories{
+		q:           db,
+		Classrooms:  NewClassroomRepository(db),
+		Assignments: NewAssignmentRepository(db),
+		Roster:      NewRosterRepository(db),
+		Submissions: NewSubmissionRepository(db),
+		Teams:       NewTeamRepository(db),
+		JobErrors:   NewJobErrorRepository(db),
+		Invitations: NewInvitationRepository(db),
+		JoinLinks:   NewJoinLinkRepository(db),
+		Claims:      NewClaimRepository(db),
+		AuditEvents: NewAuditRepository(db),
+	}
+}
+
+// mapError translates driver errors into the package sentinel errors.
+// The original error is kept in the chain for logging.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, pqErr.Constraint)
+		case "23503": // foreign_key_violation
+			return fmt.Errorf("%w: %s", ErrInvalidReference, pqErr.Constraint)
+		}
+	}
+
+	return err
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// filter accumulates parameterized WHERE conditions
+type filter struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition; format refers to arg as $%d, or as $%[1]d when
+// it is used more than once
+func (f *filter) add(format string, arg interface{}) {
+	f.args = append(f.args, arg)
+	f.conditions = append(f.conditions, fmt.Sprintf(format, len(f.args)))
+}
+
+// addRaw appends a condition without parameters
+func (f *filter) addRaw(condition string) {
+	f.conditions = append(f.conditions, condition)
+}
+
+// where renders the WHERE clause, or "" when there are no conditions
+func (f *filter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.conditions, " AND ")
+}
+
+// page renders LIMIT/OFFSET for the given page and returns the matching args
+func (f *filter) page(page, perPage int) (string, []interface{}) {
+	args := append(append([]interface{}(nil), f.args...), perPage, (page-1)*perPage)
+	return fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args
+}
+
+// normalizePage applies the pagination defaults and bounds
+func normalizePage(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+	return page, perPage
+}
+
+// totalPages returns the number of pages needed for total items
+func totalPages(total, perPage int) int {
+	if total == 0 {
+		return 0
+	}
+	return (total + perPage - 1) / perPage
+}
+
+// count runs a COUNT(*) query for table with the filter applied
+func count(ctx context.Context, db DBTX, table string, f *filter) (int, error) {
+	var total int
+	query := "SELECT COUNT(*) FROM " + table + f.where()
+	if err := db.QueryRowContext(ctx, query, f.args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	return total, nil
+}
+
+// execAffectingOne runs a statement that must affect exactly one row
+func execAffectingOne(ctx context.Context, db DBTX, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
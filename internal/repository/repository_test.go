@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/database"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+func TestMapError(t *testing.T) {
+	t.Run("unique violation", func(t *testing.T) {
+		err := mapError(&pq.Error{Code: "23505", Constraint: "idx_classrooms_slug"})
+		assert.True(t, errors.Is(err, ErrAlreadyExists))
+	})
+
+	t.Run("foreign key violation", func(t *testing.T) {
+		err := mapError(&pq.Error{Code: "23503"})
+		assert.True(t, errors.Is(err, ErrInvalidReference))
+	})
+
+	t.Run("other errors pass through", func(t *testing.T) {
+		original := fmt.Errorf("connection reset")
+		assert.Equal(t, original, mapError(original))
+	})
+}
+
+func TestFilter(t *testing.T) {
+	f := &filter{}
+	f.add("classroom_id = $%d", int64(7))
+	f.addRaw("forgejo_user_id IS NULL")
+	f.add("status = $%d", "late")
+
+	assert.Equal(t, " WHERE classroom_id = $1 AND forgejo_user_id IS NULL AND status = $2", f.where())
+
+	limit, args := f.page(3, 20)
+	assert.Equal(t, " LIMIT $3 OFFSET $4", limit)
+	assert.Equal(t, []interface{}{int64(7), "late", 20, 40}, args)
+	assert.Len(t, f.args, 2, "page must not modify the filter args")
+
+	f.add("(student_id = $%[1]d OR leader_id = $%[1]d)", int64(9))
+	assert.Equal(t, "(student_id = $3 OR leader_id = $3)", f.conditions[3])
+
+	page, perPage := normalizePage(0, 500)
+	assert.Equal(t, 1, page)
+	assert.Equal(t, MaxPerPage, perPage)
+	assert.Equal(t, 3, totalPages(61, 30))
+}
+
+// setupTestDB connects to the test database, applies migrations and empties every table
+func setupTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:                  getEnv("FGC_DATABASE_HOST", "localhost"),
+			Port:                  5432,
+			User:                  getEnv("FGC_DATABASE_USER", "fgc_test"),
+			Password:              getEnv("FGC_DATABASE_PASSWORD", "fgc_test_password"),
+			Name:                  getEnv("FGC_DATABASE_NAME", "forgejo_classroom_test"),
+			SSLMode:               "disable",
+			MaxConnections:        5,
+			MaxIdleConnections:    2,
+			ConnectionMaxLifetime: time.Hour,
+		},
+	}
+
+	logger := zap.NewNop()
+	db, err := database.New(cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.RunMigrations(db.DB, database.MigrateConfig{MigrationsPath: "../../migrations"}, logger))
+
+	_, err = db.Exec(`TRUNCATE team_members, teams, submissions, assignments, roster_entries, classrooms, audit_events RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestRepositories_Integration(t *testing.T) {
+	db := setupTestDB(t)
+	repos := New(db)
+	ctx := context.Background()
+
+	classroom := &model.Classroom{
+		Name:             "Intro to Go",
+		Slug:             "intro-to-go",
+		OrganizationName: "cs101",
+		OrganizationID:   1,
+		InstructorID:     1,
+		InstructorLogin:  "instructor",
+	}
+	require.NoError(t, repos.Classrooms.Create(ctx, classroom))
+	assert.NotZero(t, classroom.ID)
+
+	t.Run("duplicate slug is reported as already exists", func(t *testing.T) {
+		dup := *classroom
+		err := repos.Classrooms.Create(ctx, &dup)
+		assert.True(t, errors.Is(err, ErrAlreadyExists))
+	})
+
+	t.Run("missing rows are reported as not found", func(t *testing.T) {
+		_, err := repos.Classrooms.GetByID(ctx, 9999)
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.True(t, errors.Is(repos.Classrooms.Delete(ctx, 9999), ErrNotFound))
+	})
+
+	t.Run("roster filters", func(t *testing.T) {
+		login, userID := "jdoe", int64(101)
+		linked := &model.RosterEntry{
+			ClassroomID: classroom.ID, StudentName: "John Doe", StudentEmail: "john@example.com",
+			StudentID: "john123", ForgejoUsername: &login, ForgejoUserID: &userID, Role: "student",
+		}
+		unlinked := &model.RosterEntry{
+			ClassroomID: classroom.ID, StudentName: "Bob Wilson", StudentEmail: "bob@example.com",
+			StudentID: "bob789", Role: "student",
+		}
+		require.NoError(t, repos.Roster.Create(ctx, linked))
+		require.NoError(t, repos.Roster.Create(ctx, unlinked))
+
+		resp, err := repos.Roster.List(ctx, classroom.ID, &model.RosterListRequest{LinkedOnly: true})
+		require.NoError(t, err)
+		require.Len(t, resp.Students, 1)
+		assert.Equal(t, "john123", resp.Students[0].StudentID)
+
+		resp, err = repos.Roster.List(ctx, classroom.ID, &model.RosterListRequest{PerPage: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, resp.Total)
+		assert.Equal(t, 2, resp.TotalPages)
+		assert.Equal(t, "Bob Wilson", resp.Students[0].StudentName)
+	})
+
+	t.Run("one submission per student per assignment", func(t *testing.T) {
+		assignment := &model.Assignment{
+			ClassroomID: classroom.ID, Name: "Homework 1", Slug: "homework-1",
+			TemplateRepository: "cs101/hw1-template", TemplateRepositoryID: 10, MaxTeamSize: 1,
+		}
+		require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+		roster, err := repos.Roster.List(ctx, classroom.ID, &model.RosterListRequest{})
+		require.NoError(t, err)
+		studentID := roster.Students[0].ID
+
+		err = repos.WithTransaction(ctx, func(tx *Repositories) error {
+			return tx.Submissions.Create(ctx, &model.Submission{
+				AssignmentID: assignment.ID, StudentID: &studentID, RepositoryName: "homework-1-bob",
+				RepositoryID: 20, RepositoryURL: "https://forgejo.example.com/cs101/homework-1-bob", Status: "accepted",
+			})
+		})
+		require.NoError(t, err)
+
+		err = repos.Submissions.Create(ctx, &model.Submission{
+			AssignmentID: assignment.ID, StudentID: &studentID, RepositoryName: "homework-1-bob-2",
+			RepositoryID: 21, RepositoryURL: "https://forgejo.example.com/cs101/homework-1-bob-2", Status: "accepted",
+		})
+		assert.True(t, errors.Is(err, ErrAlreadyExists))
+
+		resp, err := repos.Submissions.List(ctx, &model.SubmissionListRequest{AssignmentID: &assignment.ID, IndividualOnly: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Total)
+	})
+}
+
+func TestAuditRepository_AppendOnly(t *testing.T) {
+	db := setupTestDB(t)
+	repos := New(db)
+	ctx := context.Background()
+
+	event := &model.AuditEvent{
+		ClassroomID: 1,
+		Action:      model.AuditClassroomCreate,
+		TargetType:  model.AuditTargetClassroom,
+		TargetID:    1,
+	}
+	require.NoError(t, repos.AuditEvents.Create(ctx, event))
+	assert.NotZero(t, event.ID)
+
+	_, err := db.ExecContext(ctx, `UPDATE audit_events SET actor_login = 'someone' WHERE id = $1`, event.ID)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.ExecContext(ctx, `DELETE FROM audit_events WHERE id = $1`, event.ID)
+	assert.ErrorContains(t, err, "append-only")
+
+	list, err := repos.AuditEvents.List(ctx, 1, &model.AuditListRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.Events, 1)
+	assert.Empty(t, list.Events[0].Changes)
+	assert.Nil(t, list.Events[0].ActorID)
+}
+
+func TestClassroomDelete_CascadesToTeams(t *testing.T) {
+	db := setupTestDB(t)
+	repos := New(db)
+	ctx := context.Background()
+
+	classroom := &model.Classroom{
+		Name: "Team Projects", Slug: "team-projects", OrganizationName: "cs201",
+		OrganizationID: 2, InstructorID: 1, InstructorLogin: "instructor",
+	}
+	require.NoError(t, repos.Classrooms.Create(ctx, classroom))
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Project", Slug: "project",
+		TemplateRepository: "cs201/project-template", TemplateRepositoryID: 10, MaxTeamSize: 3,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+	leader := &model.RosterEntry{
+		ClassroomID: classroom.ID, StudentName: "Ada Lovelace", StudentEmail: "ada@example.com",
+		StudentID: "ada1", Role: "student",
+	}
+	require.NoError(t, repos.Roster.Create(ctx, leader))
+	team := &model.Team{AssignmentID: assignment.ID, Name: "Engines", Slug: "engines", LeaderID: leader.ID}
+	require.NoError(t, repos.Teams.Create(ctx, team))
+	require.NoError(t, repos.Teams.AddMember(ctx, &model.TeamMember{TeamID: team.ID, StudentID: leader.ID, Role: TeamRoleLeader}))
+
+	t.Run("team leaders cannot be removed on their own", func(t *testing.T) {
+		err := repos.Roster.Delete(ctx, classroom.ID, leader.ID)
+		assert.True(t, errors.Is(err, ErrInvalidReference))
+	})
+
+	t.Run("deleting the classroom removes its teams", func(t *testing.T) {
+		require.NoError(t, repos.Classrooms.Delete(ctx, classroom.ID))
+		_, err := repos.Teams.GetByID(ctx, team.ID)
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+}
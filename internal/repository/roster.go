@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const rosterColumns = `id, classroom_id, student_name, student_email, student_id, forgejo_username,
+	forgejo_user_id, role, linked_at, created_at, updated_at`
+
+// RosterRepository stores classroom roster entries
+type RosterRepository struct {
+	db DBTX
+}
+
+// NewRosterRepository creates a new roster repository
+func NewRosterRepository(db DBTX) *RosterRepository {
+	return &RosterRepository{db: db}
+}
+
+func scanRosterEntry(row scanner) (*model.RosterEntry, error) {
+	var e model.RosterEntry
+	err := row.Scan(
+		&e.ID, &e.ClassroomID, &e.StudentName, &e.StudentEmail, &e.StudentID, &e.ForgejoUsername,
+		&e.ForgejoUserID, &e.Role, &e.LinkedAt, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &e, nil
+}
+
+// Create inserts a roster entry and fills in its ID and timestamps
+func (r *RosterRepository) Create(ctx context.Context, e *model.RosterEntry) error {
+	query := `
+		INSERT INTO roster_entries (classroom_id, student_name, student_email, student_id,
+			forgejo_username, forgejo_user_id, role, linked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		e.ClassroomID, e.StudentName, e.StudentEmail, e.StudentID,
+		e.ForgejoUsername, e.ForgejoUserID, e.Role, e.LinkedAt,
+	).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns a roster entry of a classroom by its ID
+func (r *RosterRepository) GetByID(ctx context.Context, classroomID, id int64) (*model.RosterEntry, error) {
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries WHERE classroom_id = $1 AND id = $2`
+	return scanRosterEntry(r.db.QueryRowContext(ctx, query, classroomID, id))
+}
+
+// GetByForgejoUserID returns the roster entry linked to a Forgejo account
+func (r *RosterRepository) GetByForgejoUserID(ctx context.Context, classroomID, userID int64) (*model.RosterEntry, error) {
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries WHERE classroom_id = $1 AND forgejo_user_id = $2`
+	return scanRosterEntry(r.db.QueryRowContext(ctx, query, classroomID, userID))
+}
+
+// GetByStudentID returns the roster entry with an institutional student ID
+func (r *RosterRepository) GetByStudentID(ctx context.Context, classroomID int64, studentID string) (*model.RosterEntry, error) {
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries WHERE classroom_id = $1 AND student_id = $2`
+	return scanRosterEntry(r.db.QueryRowContext(ctx, query, classroomID, studentID))
+}
+
+// GetByEmail returns the roster entry with an email address, ignoring case
+func (r *RosterRepository) GetByEmail(ctx context.Context, classroomID int64, email string) (*model.RosterEntry, error) {
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries WHERE classroom_id = $1 AND lower(student_email) = lower($2)`
+	return scanRosterEntry(r.db.QueryRowContext(ctx, query, classroomID, email))
+}
+
+// Update writes the mutable fields of a roster entry and refreshes UpdatedAt
+func (r *RosterRepository) Update(ctx context.Context, e *model.RosterEntry) error {
+	query := `
+		UPDATE roster_entries
+		SET student_name = $3, student_email = $4, student_id = $5, forgejo_username = $6,
+			forgejo_user_id = $7, role = $8, linked_at = $9, updated_at = NOW()
+		WHERE classroom_id = $1 AND id = $2
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		e.ClassroomID, e.ID, e.StudentName, e.StudentEmail, e.StudentID,
+		e.ForgejoUsername, e.ForgejoUserID, e.Role, e.LinkedAt,
+	).Scan(&e.UpdatedAt)
+	return mapError(err)
+}
+
+// Delete removes a roster entry from a classroom
+func (r *RosterRepository) Delete(ctx context.Context, classroomID, id int64) error {
+	return execAffectingOne(ctx, r.db,
+		`DELETE FROM roster_entries WHERE classroom_id = $1 AND id = $2`, classroomID, id)
+}
+
+// List returns one page of a classroom's roster matching req, ordered by name
+func (r *RosterRepository) List(ctx context.Context, classroomID int64, req *model.RosterListRequest) (*model.RosterListResponse, error) {
+	if req.LinkedOnly && req.UnlinkedOnly {
+		return nil, fmt.Errorf("linked_only and unlinked_only are mutually exclusive")
+	}
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	f.add("classroom_id = $%d", classroomID)
+	if req.LinkedOnly {
+		f.addRaw("forgejo_user_id IS NOT NULL")
+	}
+	if req.UnlinkedOnly {
+		f.addRaw("forgejo_user_id IS NULL")
+	}
+
+	total, err := count(ctx, r.db, "roster_entries", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + rosterColumns + ` FROM roster_entries` + f.where() +
+		` ORDER BY student_name ASC, id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roster entries: %w", err)
+	}
+	defer rows.Close()
+
+	students := make([]model.RosterEntry, 0, perPage)
+	for rows.Next() {
+		e, err := scanRosterEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		students = append(students, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list roster entries: %w", err)
+	}
+
+	return &model.RosterListResponse{
+		Students:   students,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// studentSubmissions defines, for the assignments matching scope, each
+// student of the assignment's classroom and the submission they work on:
+// their own or their team's, with the time they accepted or joined the
+// team. Students who have not accepted have a NULL submission_id.
+func studentSubmissions(scope string) string {
+	return `
+	WITH scoped_assignments AS (
+		SELECT id, classroom_id FROM assignments WHERE ` + scope + `
+	),
+	students AS (
+		SELECT a.id AS assignment_id, r.id
+		FROM scoped_assignments a
+		JOIN roster_entries r ON r.classroom_id = a.classroom_id AND r.role = 'student'
+	),
+	student_submissions AS (
+		SELECT st.assignment_id, st.id AS student_id, s.id AS submission_id, s.status, s.last_commit_sha,
+			s.commit_count, CASE WHEN s.team_id IS NULL THEN s.accepted_at ELSE tm.joined_at END AS accepted_at
+		FROM students st
+		LEFT JOIN team_members tm ON tm.student_id = st.id
+			AND tm.team_id IN (SELECT id FROM teams WHERE assignment_id = st.assignment_id)
+		LEFT JOIN submissions s ON s.assignment_id = st.assignment_id
+			AND (s.student_id = st.id OR s.team_id = tm.team_id)
+	)`
+}
+
+// assignmentStudentSubmissions is studentSubmissions of assignment $1
+var assignmentStudentSubmissions = studentSubmissions("id = $1")
+
+// Stats returns the acceptance and submission statistics of an assignment,
+// without rates or details. Like ListAssignmentProgress, it counts students
+// rather than submissions, so a team submission counts once per member.
+func (r *AssignmentRepository) Stats(ctx context.Context, assignmentID int64) (*model.AssignmentStats, error) {
+	query := assignmentStudentSubmissions + `,
+	accepted_students AS (
+		SELECT DISTINCT ON (student_id) student_id, commit_count
+		FROM student_submissions
+		WHERE submission_id IS NOT NULL
+		ORDER BY student_id, submission_id
+	)
+		SELECT
+			COUNT(DISTINCT student_id),
+			COUNT(DISTINCT student_id) FILTER (WHERE submission_id IS NOT NULL),
+			COUNT(DISTINCT student_id) FILTER (WHERE last_commit_sha IS NOT NULL),
+			(SELECT COUNT(*) FROM teams WHERE assignment_id = $1),
+			(SELECT COALESCE(AVG(commit_count), 0) FROM accepted_students),
+			COUNT(DISTINCT student_id) FILTER (WHERE last_commit_sha IS NOT NULL AND status <> 'late'),
+			COUNT(DISTINCT student_id) FILTER (WHERE status = 'late')
+		FROM student_submissions`
+
+	stats := &model.AssignmentStats{AssignmentID: assignmentID}
+	err := r.db.QueryRowContext(ctx, query, assignmentID).Scan(
+		&stats.TotalStudents, &stats.AcceptedCount, &stats.SubmissionCount, &stats.TeamCount,
+		&stats.AverageCommits, &stats.OnTimeSubmissions, &stats.LateSubmissions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute assignment stats: %w", err)
+	}
+	return stats, nil
+}
+
+// CommitCounts returns how many accepted students of an assignment have
+// each commit count
+func (r *AssignmentRepository) CommitCounts(ctx context.Context, assignmentID int64) (map[int]int, error) {
+	query := assignmentStudentSubmissions + `
+		SELECT commit_count, COUNT(DISTINCT student_id)
+		FROM student_submissions
+		WHERE submission_id IS NOT NULL
+		GROUP BY commit_count`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var commits, students int
+		if err := rows.Scan(&commits, &students); err != nil {
+			return nil, fmt.Errorf("failed to count commits: %w", err)
+		}
+		counts[commits] = students
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	return counts, nil
+}
+
+// AcceptanceHistogram returns how many students of an assignment accepted
+// on each day, oldest first
+func (r *AssignmentRepository) AcceptanceHistogram(ctx context.Context, assignmentID int64) ([]model.DateCount, error) {
+	query := assignmentStudentSubmissions + `
+		SELECT to_char(accepted_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(DISTINCT student_id)
+		FROM student_submissions
+		WHERE accepted_at IS NOT NULL
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build acceptance histogram: %w", err)
+	}
+	defer rows.Close()
+
+	histogram := []model.DateCount{}
+	for rows.Next() {
+		var day model.DateCount
+		if err := rows.Scan(&day.Date, &day.Count); err != nil {
+			return nil, fmt.Errorf("failed to build acceptance histogram: %w", err)
+		}
+		histogram = append(histogram, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to build acceptance histogram: %w", err)
+	}
+	return histogram, nil
+}
+
+// ListNotAccepted returns the students of an assignment's classroom who
+// have not accepted it, ordered by name
+func (r *AssignmentRepository) ListNotAccepted(ctx context.Context, assignmentID int64) ([]model.RosterEntry, error) {
+	query := assignmentStudentSubmissions + `
+		SELECT ` + rosterColumns + `
+		FROM roster_entries
+		WHERE id IN (SELECT ss.student_id FROM student_submissions ss)
+			AND id NOT IN (SELECT ss.student_id FROM student_submissions ss WHERE ss.submission_id IS NOT NULL)
+		ORDER BY student_name ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students who have not accepted: %w", err)
+	}
+	defer rows.Close()
+
+	students := []model.RosterEntry{}
+	for rows.Next() {
+		e, err := scanRosterEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		students = append(students, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list students who have not accepted: %w", err)
+	}
+	return students, nil
+}
+
+// Stats returns the roster, assignment and submission counts of a
+// classroom, without the per-assignment statistics
+func (r *ClassroomRepository) Stats(ctx context.Context, classroomID int64) (*model.ClassroomStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM roster_entries WHERE classroom_id = $1 AND role = 'student'),
+			(SELECT COUNT(*) FROM roster_entries
+				WHERE classroom_id = $1 AND role = 'student' AND forgejo_user_id IS NOT NULL),
+			(SELECT COUNT(*) FROM assignments WHERE classroom_id = $1),
+			(SELECT COUNT(*) FROM assignments WHERE classroom_id = $1 AND (deadline IS NULL OR deadline > NOW())),
+			(SELECT COUNT(*) FROM submissions s JOIN assignments a ON a.id = s.assignment_id
+				WHERE a.classroom_id = $1)`
+
+	stats := &model.ClassroomStats{ClassroomID: classroomID}
+	err := r.db.QueryRowContext(ctx, query, classroomID).Scan(
+		&stats.TotalStudents, &stats.LinkedStudents, &stats.TotalAssignments,
+		&stats.ActiveAssignments, &stats.TotalSubmissions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute classroom stats: %w", err)
+	}
+	return stats, nil
+}
+
+// ListAssignmentProgress returns how many students accepted and submitted
+// each assignment of a classroom, by deadline
+func (r *ClassroomRepository) ListAssignmentProgress(ctx context.Context, classroomID int64) ([]model.AssignmentProgress, error) {
+	query := studentSubmissions("classroom_id = $1") + `
+		SELECT a.id, a.name, a.slug, a.deadline,
+			COUNT(DISTINCT ss.student_id) FILTER (WHERE ss.submission_id IS NOT NULL),
+			COUNT(DISTINCT ss.student_id) FILTER (WHERE ss.last_commit_sha IS NOT NULL),
+			COUNT(DISTINCT ss.student_id) FILTER (WHERE ss.status = 'late')
+		FROM assignments a
+		LEFT JOIN student_submissions ss ON ss.assignment_id = a.id
+		WHERE a.classroom_id = $1
+		GROUP BY a.id
+		ORDER BY a.deadline ASC NULLS LAST, a.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, classroomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute assignment progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := []model.AssignmentProgress{}
+	for rows.Next() {
+		var p model.AssignmentProgress
+		if err := rows.Scan(&p.AssignmentID, &p.Name, &p.Slug, &p.Deadline,
+			&p.AcceptedCount, &p.SubmissionCount, &p.LateCount); err != nil {
+			return nil, fmt.Errorf("failed to compute assignment progress: %w", err)
+		}
+		progress = append(progress, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to compute assignment progress: %w", err)
+	}
+	return progress, nil
+}
+
+// ListActive returns the acceptance progress of the assignments whose
+// deadline has not passed, in classrooms that are not archived
+func (r *AssignmentRepository) ListActive(ctx context.Context) ([]model.ActiveAssignment, error) {
+	query := studentSubmissions(`(deadline IS NULL OR deadline > NOW())
+			AND classroom_id IN (SELECT id FROM classrooms WHERE NOT archived)`) + `
+		SELECT a.classroom_id, a.id, a.deadline,
+			COUNT(DISTINCT ss.student_id),
+			COUNT(DISTINCT ss.student_id) - COUNT(DISTINCT ss.student_id) FILTER (WHERE ss.submission_id IS NOT NULL)
+		FROM assignments a
+		JOIN scoped_assignments sa ON sa.id = a.id
+		LEFT JOIN student_submissions ss ON ss.assignment_id = a.id
+		GROUP BY a.id
+		ORDER BY a.classroom_id, a.id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active assignments: %w", err)
+	}
+	defer rows.Close()
+
+	active := []model.ActiveAssignment{}
+	for rows.Next() {
+		var a model.ActiveAssignment
+		if err := rows.Scan(&a.ClassroomID, &a.AssignmentID, &a.Deadline,
+			&a.TotalStudents, &a.PendingAcceptance); err != nil {
+			return nil, fmt.Errorf("failed to list active assignments: %w", err)
+		}
+		active = append(active, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list active assignments: %w", err)
+	}
+	return active, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const submissionColumns = `id, assignment_id, student_id, team_id, repository_name, repository_id,
+	repository_url, status, accepted_at, last_commit_sha, last_commit_message, last_commit_at, commit_count,
+	deadline_tag, deadline_sha, deadline_tagged_at, deadline_unresolved, deadline_head_sha, deadline_head_for,
+	created_at, updated_at`
+
+// SubmissionRepository stores assignment submissions
+type SubmissionRepository struct {
+	db DBTX
+}
+
+// NewSubmissionRepository creates a new submission repository
+func NewSubmissionRepository(db DBTX) *SubmissionRepository {
+	return &SubmissionRepository{db: db}
+}
+
+func scanSubmission(row scanner) (*model.Submission, error) {
+	var s model.Submission
+	err := row.Scan(
+		&s.ID, &s.AssignmentID, &s.StudentID, &s.TeamID, &s.RepositoryName, &s.RepositoryID,
+		&s.RepositoryURL, &s.Status, &s.AcceptedAt, &s.LastCommitSHA, &s.LastCommitMessage, &s.LastCommitAt, &s.CommitCount,
+		&s.DeadlineTag, &s.DeadlineSHA, &s.DeadlineTaggedAt, &s.DeadlineUnresolved, &s.DeadlineHeadSHA, &s.DeadlineHeadFor,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &s, nil
+}
+
+// Create inserts a submission and fills in its ID and timestamps.
+// A second submission for the same student or team returns ErrAlreadyExists.
+func (r *SubmissionRepository) Create(ctx context.Context, s *model.Submission) error {
+	query := `
+		INSERT INTO submissions (assignment_id, student_id, team_id, repository_name, repository_id,
+			repository_url, status, accepted_at, last_commit_sha, last_commit_message, commit_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.AssignmentID, s.StudentID, s.TeamID, s.RepositoryName, s.RepositoryID,
+		s.RepositoryURL, s.Status, s.AcceptedAt, s.LastCommitSHA, s.LastCommitMessage, s.CommitCount,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns the submission with the given ID
+func (r *SubmissionRepository) GetByID(ctx context.Context, id int64) (*model.Submission, error) {
+	query := `SELECT ` + submissionColumns + ` FROM submissions WHERE id = $1`
+	return scanSubmission(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByStudent returns a student's submission for an assignment
+func (r *SubmissionRepository) GetByStudent(ctx context.Context, assignmentID, studentID int64) (*model.Submission, error) {
+	query := `SELECT ` + submissionColumns + ` FROM submissions WHERE assignment_id = $1 AND student_id = $2`
+	return scanSubmission(r.db.QueryRowContext(ctx, query, assignmentID, studentID))
+}
+
+// GetByTeam returns a team's submission for an assignment
+func (r *SubmissionRepository) GetByTeam(ctx context.Context, assignmentID, teamID int64) (*model.Submission, error) {
+	query := `SELECT ` + submissionColumns + ` FROM submissions WHERE assignment_id = $1 AND team_id = $2`
+	return scanSubmission(r.db.QueryRowContext(ctx, query, assignmentID, teamID))
+}
+
+// GetByRepositoryID returns the submission for a Forgejo repository
+func (r *SubmissionRepository) GetByRepositoryID(ctx context.Context, repositoryID int64) (*model.Submission, error) {
+	query := `SELECT ` + submissionColumns + ` FROM submissions WHERE repository_id = $1`
+	return scanSubmission(r.db.QueryRowContext(ctx, query, repositoryID))
+}
+
+// UpdateLastCommit records the latest commit on the default branch of a
+// submission and refreshes CommitCount and UpdatedAt. Only the last commit
+// columns and the commit count are written, so that concurrent pushes and
+// deadline enforcement cannot undo each other. If counted is false,
+// CommitCount holds the number of pushed commits, which is added to the
+// stored count.
+func (r *SubmissionRepository) UpdateLastCommit(ctx context.Context, s *model.Submission, counted bool) error {
+	query := `
+		UPDATE submissions
+		SET last_commit_sha = $2, last_commit_message = $3, last_commit_at = $4,
+			commit_count = CASE WHEN $6 THEN $5 ELSE commit_count + $5 END, updated_at = NOW()
+		WHERE id = $1
+		RETURNING commit_count, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.ID, s.LastCommitSHA, s.LastCommitMessage, s.LastCommitAt, s.CommitCount, counted,
+	).Scan(&s.CommitCount, &s.UpdatedAt)
+	return mapError(err)
+}
+
+// RecordDeadlineTag records the deadline tag of a submission: DeadlineTag,
+// DeadlineSHA (nil if there was no commit to tag), DeadlineTaggedAt and
+// DeadlineUnresolved, and marks it late if late is set. No other column is
+// written, so that a concurrent push is never undone. Status and UpdatedAt
+// are refreshed.
+func (r *SubmissionRepository) RecordDeadlineTag(ctx context.Context, s *model.Submission, late bool) error {
+	query := `
+		UPDATE submissions
+		SET deadline_tag = $2, deadline_sha = $3, deadline_tagged_at = $4, deadline_unresolved = $5,
+			status = CASE WHEN $6 THEN 'late' ELSE status END, updated_at = NOW()
+		WHERE id = $1
+		RETURNING status, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		s.ID, s.DeadlineTag, s.DeadlineSHA, s.DeadlineTaggedAt, s.DeadlineUnresolved, late,
+	).Scan(&s.Status, &s.UpdatedAt)
+	return mapError(err)
+}
+
+// RecordDeadlineHead records sha as the head of the submission's default
+// branch at deadline, unless a head was recorded for deadline already. It
+// reports whether it was recorded.
+func (r *SubmissionRepository) RecordDeadlineHead(ctx context.Context, id int64, sha *string, deadline time.Time) (bool, error) {
+	err := execAffectingOne(ctx, r.db, `
+		UPDATE submissions SET deadline_head_sha = $2, deadline_head_for = $3
+		WHERE id = $1 AND deadline_head_for IS DISTINCT FROM $3`, id, sha, deadline)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MarkLate sets the status of a submission to late and reports whether it
+// was not late already
+func (r *SubmissionRepository) MarkLate(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE submissions SET status = 'late', updated_at = NOW() WHERE id = $1 AND status <> 'late'`, id)
+	if err != nil {
+		return false, mapError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Delete removes a submission
+func (r *SubmissionRepository) Delete(ctx context.Context, id int64) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM submissions WHERE id = $1`, id)
+}
+
+// List returns one page of submissions matching req, oldest first
+func (r *SubmissionRepository) List(ctx context.Context, req *model.SubmissionListRequest) (*model.SubmissionListResponse, error) {
+	if req.TeamOnly && req.IndividualOnly {
+		return nil, fmt.Errorf("team_only and individual_only are mutually exclusive")
+	}
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	if req.AssignmentID != nil {
+		f.add("assignment_id = $%d", *req.AssignmentID)
+	}
+	if req.Status != "" {
+		f.add("status = $%d", req.Status)
+	}
+	if req.TeamOnly {
+		f.addRaw("team_id IS NOT NULL")
+	}
+	if req.IndividualOnly {
+		f.addRaw("student_id IS NOT NULL")
+	}
+	if req.MemberID != nil {
+		f.add(`(student_id = $%[1]d OR team_id IN (
+			SELECT team_id FROM team_members WHERE student_id = $%[1]d))`, *req.MemberID)
+	}
+
+	total, err := count(ctx, r.db, "submissions", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + submissionColumns + ` FROM submissions` + f.where() +
+		` ORDER BY created_at ASC, id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submissions: %w", err)
+	}
+	defer rows.Close()
+
+	submissions := make([]model.Submission, 0, perPage)
+	for rows.Next() {
+		s, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list submissions: %w", err)
+	}
+
+	return &model.SubmissionListResponse{
+		Submissions: submissions,
+		Total:       total,
+		Page:        page,
+		PerPage:     perPage,
+		TotalPages:  totalPages(total, perPage),
+	}, nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+const teamColumns = `id, assignment_id, name, slug, COALESCE(description, ''), leader_id, member_count,
+	created_at, updated_at`
+
+// Team member roles
+const (
+	TeamRoleLeader = "leader"
+	TeamRoleMember = "member"
+)
+
+// TeamRepository stores teams and their members
+type TeamRepository struct {
+	db DBTX
+}
+
+// NewTeamRepository creates a new team repository
+func NewTeamRepository(db DBTX) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+func scanTeam(row scanner) (*model.Team, error) {
+	var t model.Team
+	err := row.Scan(
+		&t.ID, &t.AssignmentID, &t.Name, &t.Slug, &t.Description, &t.LeaderID, &t.MemberCount,
+		&t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return &t, nil
+}
+
+// Create inserts a team and fills in its ID and timestamps.
+// Members are added separately with AddMember.
+func (r *TeamRepository) Create(ctx context.Context, t *model.Team) error {
+	query := `
+		INSERT INTO teams (assignment_id, name, slug, description, leader_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, member_count, created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.AssignmentID, t.Name, t.Slug, t.Description, t.LeaderID,
+	).Scan(&t.ID, &t.MemberCount, &t.CreatedAt, &t.UpdatedAt)
+	return mapError(err)
+}
+
+// GetByID returns the team with the given ID
+func (r *TeamRepository) GetByID(ctx context.Context, id int64) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1`
+	return scanTeam(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetBySlug returns the team with the given slug in an assignment
+func (r *TeamRepository) GetBySlug(ctx context.Context, assignmentID int64, slug string) (*model.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE assignment_id = $1 AND slug = $2`
+	return scanTeam(r.db.QueryRowContext(ctx, query, assignmentID, slug))
+}
+
+// GetByMember returns the team a roster entry belongs to within an assignment
+func (r *TeamRepository) GetByMember(ctx context.Context, assignmentID, studentID int64) (*model.Team, error) {
+	query := `
+		SELECT t.id, t.assignment_id, t.name, t.slug, COALESCE(t.description, ''), t.leader_id,
+			t.member_count, t.created_at, t.updated_at
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id
+		WHERE t.assignment_id = $1 AND m.student_id = $2`
+	return scanTeam(r.db.QueryRowContext(ctx, query, assignmentID, studentID))
+}
+
+// Update writes the mutable fields of a team and refreshes UpdatedAt
+func (r *TeamRepository) Update(ctx context.Context, t *model.Team) error {
+	query := `
+		UPDATE teams
+		SET name = $2, slug = $3, description = $4, leader_id = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.ID, t.Name, t.Slug, t.Description, t.LeaderID,
+	).Scan(&t.UpdatedAt)
+	return mapError(err)
+}
+
+// Delete removes a team and its memberships
+func (r *TeamRepository) Delete(ctx context.Context, id int64) error {
+	return execAffectingOne(ctx, r.db, `DELETE FROM teams WHERE id = $1`, id)
+}
+
+// AddMember adds a roster entry to a team and increments the team's member count.
+// Call it inside a transaction so the count stays consistent.
+func (r *TeamRepository) AddMember(ctx context.Context, m *model.TeamMember) error {
+	query := `
+		INSERT INTO team_members (team_id, student_id, role)
+		VALUES ($1, $2, $3)
+		RETURNING id, joined_at`
+
+	if err := r.db.QueryRowContext(ctx, query, m.TeamID, m.StudentID, m.Role).Scan(&m.ID, &m.JoinedAt); err != nil {
+		return mapError(err)
+	}
+
+	return execAffectingOne(ctx, r.db,
+		`UPDATE teams SET member_count = member_count + 1, updated_at = NOW() WHERE id = $1`, m.TeamID)
+}
+
+// RemoveMember removes a roster entry from a team and decrements the team's member count.
+// Call it inside a transaction so the count stays consistent.
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, studentID int64) error {
+	if err := execAffectingOne(ctx, r.db,
+		`DELETE FROM team_members WHERE team_id = $1 AND student_id = $2`, teamID, studentID); err != nil {
+		return err
+	}
+
+	return execAffectingOne(ctx, r.db,
+		`UPDATE teams SET member_count = member_count - 1, updated_at = NOW() WHERE id = $1`, teamID)
+}
+
+// ListMembers returns the members of a team with their roster details, leader first
+func (r *TeamRepository) ListMembers(ctx context.Context, teamID int64) ([]model.TeamMemberInfo, error) {
+	query := `
+		SELECT m.id, m.team_id, m.student_id, m.role, m.joined_at, e.student_name,
+			COALESCE(e.forgejo_username, '')
+		FROM team_members m
+		JOIN roster_entries e ON e.id = m.student_id
+		WHERE m.team_id = $1
+		ORDER BY m.role = 'leader' DESC, m.joined_at ASC, m.id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []model.TeamMemberInfo
+	for rows.Next() {
+		var m model.TeamMemberInfo
+		if err := rows.Scan(
+			&m.ID, &m.TeamID, &m.StudentID, &m.Role, &m.JoinedAt, &m.StudentName, &m.ForgejoUsername,
+		); err != nil {
+			return nil, mapError(err)
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+
+	return members, nil
+}
+
+// List returns one page of teams matching req, ordered by name.
+// Members are loaded when req.ShowMembers is set.
+func (r *TeamRepository) List(ctx context.Context, req *model.TeamListRequest) (*model.TeamListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+
+	f := &filter{}
+	if req.AssignmentID != 0 {
+		f.add("assignment_id = $%d", req.AssignmentID)
+	}
+
+	total, err := count(ctx, r.db, "teams", f)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, args := f.page(page, perPage)
+	query := `SELECT ` + teamColumns + ` FROM teams` + f.where() + ` ORDER BY name ASC, id ASC` + limit
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	teams := make([]model.TeamWithMembers, 0, perPage)
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		teams = append(teams, model.TeamWithMembers{Team: *t})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	// Members are loaded after the team rows are closed so a transaction
+	// connection is not used for two result sets at once
+	if req.ShowMembers {
+		for i := range teams {
+			members, err := r.ListMembers(ctx, teams[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			teams[i].Members = members
+		}
+	}
+
+	return &model.TeamListResponse{
+		Teams:      teams,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages(total, perPage),
+	}, nil
+}
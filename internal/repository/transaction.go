@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithTransaction runs fn with repositories bound to a single transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+func (r *Repositories) WithTransaction(ctx context.Context, fn func(tx *Repositories) error) error {
+	if r.db == nil {
+		return fmt.Errorf("nested transactions are not supported")
+	}
+
+	return r.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		return fn(newRepositories(tx))
+	})
+}
+
+// AdvisoryLock takes a transaction-scoped Postgres advisory lock on key,
+// blocking until it is available. The lock is released when the
+// transaction ends, so it must be called on repositories passed to
+// WithTransaction.
+func (r *Repositories) AdvisoryLock(ctx context.Context, key string) error {
+	if r.db != nil {
+		return fmt.Errorf("advisory locks require a transaction")
+	}
+	if _, err := r.q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+		return fmt.Errorf("failed to acquire lock %q: %w", key, err)
+	}
+	return nil
+}
+
+// Querier returns what the repositories run their queries on: the
+// transaction they are bound to, or the database. Other stores in the same
+// database use it to take part in a transaction.
+func (r *Repositories) Querier() DBTX {
+	return r.q
+}
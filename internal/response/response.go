@@ -17,7 +17,7 @@ type ErrorDetail struct {
 	Code             string                 `json:"code"`
 	Message          string                 `json:"message"`
 	Details          map[string]interface{} `json:"details,omitempty"`
-	RequestID        string                 `json:"request_id"`
+	RequestID        string                 `json:"request_id,omitempty"`
 	Timestamp        string                 `json:"timestamp"`
 	DocumentationURL string                 `json:"documentation_url,omitempty"`
 }
@@ -98,10 +98,16 @@ func InternalServerError(c *gin.Context, errorCode, message string) {
 	RespondWithError(c, http.StatusInternalServerError, errorCode, message, nil)
 }
 
+func NotSupported(c *gin.Context, message string) {
+	RespondWithError(c, http.StatusNotImplemented, ErrSystemUnsupported, message, nil)
+}
+
 // Helper functions
+
+// getRequestID returns the ID set by middleware.RequestID, or "" if the
+// route runs without it
 func getRequestID(c *gin.Context) string {
-	// TODO: Implement request ID generation/extraction from middleware
-	return "req_" + time.Now().Format("20060102150405")
+	return c.GetString("request_id")
 }
 
 func getDocumentationURL(errorCode string) string {
@@ -0,0 +1,42 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StatusForCode returns the HTTP status for an error code as defined in
+// design.md Section 6.3
+func StatusForCode(code string) int {
+	switch code {
+	case ErrResourceNotFound:
+		return http.StatusNotFound
+	case ErrResourceConflict, ErrResourceAlreadyExists:
+		return http.StatusConflict
+	case ErrIntegrationForgejoAPI:
+		return http.StatusBadGateway
+	case ErrIntegrationForgejoRateLimited, ErrIntegrationForgejoUnavailable, ErrIntegrationDatabase:
+		return http.StatusServiceUnavailable
+	case ErrRateLimitExceeded:
+		return http.StatusTooManyRequests
+	case ErrSystemUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrSystemTimeout:
+		return http.StatusGatewayTimeout
+	case ErrSystemUnsupported:
+		return http.StatusNotImplemented
+	}
+
+	switch {
+	case strings.HasPrefix(code, "AUTH_"):
+		return http.StatusUnauthorized
+	case strings.HasPrefix(code, "AUTHZ_"):
+		return http.StatusForbidden
+	case strings.HasPrefix(code, "VALIDATION_"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(code, "BUSINESS_"):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
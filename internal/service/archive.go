@@ -0,0 +1,250 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// archiveWriter writes the entries of a download archive in one of the
+// model.SubmissionArchive* formats
+type archiveWriter interface {
+	// add writes an entry. hdr.Name ends in "/" for directories; r holds the
+	// hdr.Size bytes of a regular file and is nil otherwise.
+	add(hdr *tar.Header, r io.Reader) error
+	// Close finishes the archive without closing the underlying writer
+	Close() error
+}
+
+// archiveWriteError marks errors writing the archive itself, as opposed to
+// reading an entry's content
+type archiveWriteError struct{ err error }
+
+func (e *archiveWriteError) Error() string { return e.err.Error() }
+func (e *archiveWriteError) Unwrap() error { return e.err }
+
+// newArchiveWriter returns a writer for format that writes to w
+func newArchiveWriter(format string, w io.Writer) archiveWriter {
+	switch format {
+	case model.SubmissionArchiveTar:
+		return &tarArchive{tw: tar.NewWriter(w)}
+	case model.SubmissionArchiveTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarArchive{tw: tar.NewWriter(gz), gz: gz}
+	default:
+		return &zipArchive{zw: zip.NewWriter(w)}
+	}
+}
+
+// archiveContentType returns the MIME type of format
+func archiveContentType(format string) string {
+	switch format {
+	case model.SubmissionArchiveTar:
+		return "application/x-tar"
+	case model.SubmissionArchiveTarGz:
+		return "application/gzip"
+	default:
+		return "application/zip"
+	}
+}
+
+type tarArchive struct {
+	tw *tar.Writer
+	gz *gzip.Writer // nil for plain tar
+}
+
+func (a *tarArchive) add(hdr *tar.Header, r io.Reader) error {
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return &archiveWriteError{err}
+	}
+	w := &countingWriter{w: a.tw}
+	err := copyEntry(w, r)
+	var writeErr *archiveWriteError
+	if err != nil && !errors.As(err, &writeErr) && w.n < hdr.Size {
+		// The header already promised hdr.Size bytes and tar.Writer
+		// refuses to write anything else until it has them, so pad the
+		// entry to keep the rest of the archive intact
+		if _, perr := io.CopyN(a.tw, zeroReader{}, hdr.Size-w.n); perr != nil {
+			return &archiveWriteError{perr}
+		}
+	}
+	return err
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return &archiveWriteError{err}
+	}
+	if a.gz != nil {
+		if err := a.gz.Close(); err != nil {
+			return &archiveWriteError{err}
+		}
+	}
+	return nil
+}
+
+type zipArchive struct {
+	zw *zip.Writer
+}
+
+func (a *zipArchive) add(hdr *tar.Header, r io.Reader) error {
+	fh, err := zip.FileInfoHeader(hdr.FileInfo())
+	if err != nil {
+		return &archiveWriteError{err}
+	}
+	fh.Name = hdr.Name
+	fh.Modified = hdr.ModTime
+	if hdr.Typeflag == tar.TypeDir {
+		fh.Method = zip.Store
+	} else {
+		fh.Method = zip.Deflate
+	}
+
+	w, err := a.zw.CreateHeader(fh)
+	if err != nil {
+		return &archiveWriteError{err}
+	}
+	if hdr.Typeflag == tar.TypeSymlink {
+		// Symbolic links are stored with their target as content
+		r = strings.NewReader(hdr.Linkname)
+	}
+	return copyEntry(w, r)
+}
+
+func (a *zipArchive) Close() error {
+	if err := a.zw.Close(); err != nil {
+		return &archiveWriteError{err}
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// zeroReader reads an endless stream of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// copyEntry copies the content of an entry, telling read and write errors
+// apart
+func copyEntry(w io.Writer, r io.Reader) error {
+	if r == nil {
+		return nil
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return &archiveWriteError{werr}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyRepositoryArchive copies the entries of a Forgejo tar.gz archive into
+// out below folder, in place of the archive's top-level directory. Only
+// directories, regular files and symbolic links that stay inside the
+// repository are kept.
+func copyRepositoryArchive(out archiveWriter, folder string, src io.Reader) error {
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return fmt.Errorf("invalid repository archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid repository archive: %w", err)
+		}
+
+		name, ok := repositoryEntryPath(hdr.Name)
+		if !ok {
+			continue
+		}
+		entry := &tar.Header{
+			Typeflag: hdr.Typeflag,
+			Name:     path.Join(folder, name),
+			Mode:     hdr.Mode & 0o777,
+			ModTime:  hdr.ModTime,
+		}
+
+		var content io.Reader
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entry.Name += "/"
+		case tar.TypeReg:
+			entry.Size = hdr.Size
+			content = tr
+		case tar.TypeSymlink:
+			if !isContainedLink(name, hdr.Linkname) {
+				continue
+			}
+			entry.Linkname = hdr.Linkname
+		default:
+			continue // pax headers, hard links and devices
+		}
+
+		if err := out.add(entry, content); err != nil {
+			return err
+		}
+	}
+}
+
+// repositoryEntryPath strips the top-level directory from the name of an
+// entry in a Forgejo archive. It returns "." for that directory itself and
+// false for names that would escape it.
+func repositoryEntryPath(name string) (string, bool) {
+	_, rest, found := strings.Cut(strings.TrimPrefix(name, "./"), "/")
+	if !found {
+		return "", false
+	}
+	if rest == "" {
+		return ".", true
+	}
+	cleaned := path.Clean(rest)
+	if path.IsAbs(rest) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// isContainedLink reports whether a symbolic link at name pointing to
+// target resolves inside the repository
+func isContainedLink(name, target string) bool {
+	if target == "" || path.IsAbs(target) {
+		return false
+	}
+	resolved := path.Join(path.Dir(name), target)
+	return resolved != ".." && !strings.HasPrefix(resolved, "../")
+}
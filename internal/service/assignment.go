@@ -0,0 +1,548 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// Submission statuses
+const (
+	SubmissionStatusPending  = "pending"
+	SubmissionStatusAccepted = "accepted"
+	SubmissionStatusLate     = "late"
+)
+
+// AssignmentService manages assignments and their acceptance
+type AssignmentService struct {
+	repos   *repository.Repositories
+	forgejo *forgejo.Client
+	cache   *cache.Store
+	logger  *zap.Logger
+}
+
+// NewAssignmentService creates a new assignment service
+func NewAssignmentService(repos *repository.Repositories, fj *forgejo.Client, store *cache.Store, logger *zap.Logger) *AssignmentService {
+	return &AssignmentService{
+		repos:   repos,
+		forgejo: fj,
+		cache:   store,
+		logger:  logger,
+	}
+}
+
+// Get returns an assignment by ID
+func (s *AssignmentService) Get(ctx context.Context, id int64) (*model.Assignment, error) {
+	assignment, err := s.repos.Assignments.GetByID(ctx, id)
+	if err != nil {
+		return nil, assignmentError(err)
+	}
+	return assignment, nil
+}
+
+// List returns one page of assignments matching req
+func (s *AssignmentService) List(ctx context.Context, req *model.AssignmentListRequest) (*model.AssignmentListResponse, error) {
+	v := util.NewValidator()
+	v.ValidateEnum("status", req.Status, "Status",
+		[]string{repository.AssignmentStatusActive, repository.AssignmentStatusPast, repository.AssignmentStatusAll})
+	if v.HasErrors() {
+		return nil, validationError(v.Errors())
+	}
+	return s.repos.Assignments.List(ctx, req)
+}
+
+// Create creates an assignment in a classroom from a template repository,
+// given as owner/name or as its URL. The repository must be a template.
+func (s *AssignmentService) Create(ctx context.Context, req *model.CreateAssignmentRequest) (*model.Assignment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	baseSlug := util.GenerateSlug(req.Name)
+	if !util.IsValidSlug(baseSlug) {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "name",
+			Message: "Name must contain at least one letter or digit",
+			Code:    response.ErrValidationInvalidFormat,
+		}})
+	}
+
+	classroom, err := s.repos.Classrooms.GetByID(ctx, req.ClassroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+	template, err := s.resolveTemplate(ctx, req.TemplateRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := &model.Assignment{
+		ClassroomID:          classroom.ID,
+		Name:                 strings.TrimSpace(req.Name),
+		Description:          req.Description,
+		TemplateRepository:   template.FullName,
+		TemplateRepositoryID: template.ID,
+		MaxTeamSize:          req.MaxTeamSize,
+		AutoAccept:           req.AutoAccept,
+		Public:               req.Public,
+	}
+	if assignment.MaxTeamSize == 0 {
+		assignment.MaxTeamSize = 1
+	}
+	if req.Deadline != "" {
+		deadline, _ := time.Parse(time.RFC3339, req.Deadline) // checked by Validate
+		deadline = deadline.UTC()
+		assignment.Deadline = &deadline
+	}
+
+	for i := 1; i <= maxSlugCandidates; i++ {
+		assignment.Slug = slugCandidate(baseSlug, i)
+
+		if _, err := s.repos.Assignments.GetBySlug(ctx, classroom.ID, assignment.Slug); err == nil {
+			continue
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+
+		err := s.repos.Assignments.Create(ctx, assignment)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			continue // taken by a concurrent create
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Invalidate(ctx, cache.ClassroomStatsKey(classroom.ID))
+		logAudit(ctx, s.repos, s.logger,
+			auditEvent(classroom.ID, model.AuditAssignmentCreate, model.AuditTargetAssignment, assignment.ID), nil, assignment)
+
+		s.logger.Info("Assignment created",
+			zap.Int64("assignment_id", assignment.ID),
+			zap.Int64("classroom_id", classroom.ID),
+			zap.String("template", assignment.TemplateRepository),
+		)
+		return assignment, nil
+	}
+
+	return nil, &Error{
+		Code:    response.ErrResourceAlreadyExists,
+		Message: "Could not generate a unique slug for this assignment name",
+		Details: map[string]interface{}{"field": "name"},
+	}
+}
+
+// Update changes the fields of an assignment set in req. A new deadline is
+// picked up by the deadline scheduler on its next pass; the slug and the
+// template repository never change.
+func (s *AssignmentService) Update(ctx context.Context, id int64, req *model.UpdateAssignmentRequest) (*model.Assignment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	assignment, err := s.repos.Assignments.GetByID(ctx, id)
+	if err != nil {
+		return nil, assignmentError(err)
+	}
+	before := *assignment
+
+	if req.Name != nil {
+		assignment.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		assignment.Description = *req.Description
+	}
+	if req.Deadline != nil {
+		assignment.Deadline = nil
+		if *req.Deadline != "" {
+			deadline, _ := time.Parse(time.RFC3339, *req.Deadline) // checked by Validate
+			deadline = deadline.UTC()
+			assignment.Deadline = &deadline
+		}
+	}
+	if req.MaxTeamSize != nil {
+		assignment.MaxTeamSize = *req.MaxTeamSize
+	}
+	if req.AutoAccept != nil {
+		assignment.AutoAccept = *req.AutoAccept
+	}
+	if req.Public != nil {
+		assignment.Public = *req.Public
+	}
+
+	if err := s.repos.Assignments.Update(ctx, assignment); err != nil {
+		return nil, assignmentError(err)
+	}
+	s.cache.Invalidate(ctx, cache.ClassroomStatsKey(assignment.ClassroomID),
+		cache.AssignmentStatsKey(assignment.ClassroomID, id, false),
+		cache.AssignmentStatsKey(assignment.ClassroomID, id, true))
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(assignment.ClassroomID, model.AuditAssignmentUpdate, model.AuditTargetAssignment, id), &before, assignment)
+	return assignment, nil
+}
+
+// Delete removes an assignment together with its teams and submissions.
+// The submission repositories are kept in Forgejo.
+func (s *AssignmentService) Delete(ctx context.Context, id int64) error {
+	assignment, err := s.repos.Assignments.GetByID(ctx, id)
+	if err != nil {
+		return assignmentError(err)
+	}
+	if err := s.repos.Assignments.Delete(ctx, id); err != nil {
+		return assignmentError(err)
+	}
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(assignment.ClassroomID, model.AuditAssignmentDelete, model.AuditTargetAssignment, id), assignment, nil)
+	// Submissions are cached by their own ID, which is not at hand here
+	s.cache.Invalidate(ctx, cache.ClassroomPattern(assignment.ClassroomID), cache.SubmissionPattern)
+	s.logger.Info("Assignment deleted", zap.Int64("assignment_id", id))
+	return nil
+}
+
+// resolveTemplate looks up the template repository named by ref, either
+// owner/name or the repository's URL
+func (s *AssignmentService) resolveTemplate(ctx context.Context, ref string) (*forgejo.Repository, error) {
+	owner, name, ok := parseRepositoryRef(ref)
+	if !ok {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "template_repository",
+			Message: "Template repository must be owner/name or a repository URL",
+			Code:    response.ErrValidationInvalidFormat,
+		}})
+	}
+
+	template, err := s.forgejo.GetRepository(ctx, owner, name)
+	if err != nil {
+		if forgejo.IsNotFound(err) {
+			return nil, newError(response.ErrBusinessTemplateNotFound, err)
+		}
+		return nil, fmt.Errorf("failed to look up template repository: %w", err)
+	}
+	if !template.Template {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "template_repository",
+			Message: template.FullName + " is not a template repository",
+			Code:    response.ErrValidationInvalidInput,
+		}})
+	}
+	return template, nil
+}
+
+// parseRepositoryRef splits owner/name, or a repository URL such as
+// https://forgejo.example.com/owner/name.git, into its parts. The owner and
+// name of a URL are the last two segments of its path, so that instances
+// served under a sub-path work too.
+func parseRepositoryRef(ref string) (owner, name string, ok bool) {
+	ref = strings.TrimSpace(ref)
+	segments := strings.Split(strings.Trim(ref, "/"), "/")
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" && u.Host != "" {
+		segments = strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) > 2 {
+			segments = segments[len(segments)-2:]
+		}
+	}
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", false
+	}
+	return segments[0], strings.TrimSuffix(segments[1], ".git"), true
+}
+
+// acceptance holds what Accept resolved before provisioning
+type acceptance struct {
+	assignment *model.Assignment
+	classroom  *model.Classroom
+	student    *model.RosterEntry
+}
+
+// Accept accepts an assignment on behalf of actor: it generates the
+// student's (or team's) repository from the template, grants write access
+// and records the submission.
+//
+// Accepting is serialized per student with an advisory lock, so repeated or
+// concurrent requests provision at most one repository; every request after
+// the first fails with BUSINESS_ALREADY_ACCEPTED.
+func (s *AssignmentService) Accept(ctx context.Context, actor *Actor, assignmentID int64, req *model.AcceptAssignmentRequest) (*model.Submission, error) {
+	if actor == nil {
+		return nil, newError(response.ErrAuthMissingToken, nil)
+	}
+
+	a := &acceptance{}
+	var err error
+
+	a.assignment, err = s.repos.Assignments.GetByID(ctx, assignmentID)
+	if err != nil {
+		return nil, assignmentError(err)
+	}
+	a.classroom, err = s.repos.Classrooms.GetByID(ctx, a.assignment.ClassroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+
+	a.student, err = s.repos.Roster.GetByForgejoUserID(ctx, a.classroom.ID, actor.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, newError(response.ErrBusinessRosterNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if a.assignment.IsPast() {
+		return nil, &Error{
+			Code:    response.ErrBusinessDeadlinePassed,
+			Message: response.GetErrorMessage(response.ErrBusinessDeadlinePassed),
+			Details: map[string]interface{}{"deadline": a.assignment.Deadline},
+		}
+	}
+
+	var submission *model.Submission
+	err = s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+		lockKey := fmt.Sprintf("accept:%d:student:%d", a.assignment.ID, a.student.ID)
+		if err := tx.AdvisoryLock(ctx, lockKey); err != nil {
+			return err
+		}
+
+		if a.assignment.IsTeamAssignment() {
+			submission, err = s.acceptTeam(ctx, tx, a, actor, req)
+		} else {
+			submission, err = s.acceptIndividual(ctx, tx, a, actor)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Joining a team also changes which submissions its members see
+	s.cache.Invalidate(ctx, cache.SubmissionKey(submission.ID), cache.SubmissionListPattern)
+
+	s.logger.Info("Assignment accepted",
+		zap.Int64("assignment_id", a.assignment.ID),
+		zap.Int64("roster_entry_id", a.student.ID),
+		zap.String("repository", submission.RepositoryName),
+	)
+	return submission, nil
+}
+
+// acceptIndividual provisions a personal repository
+func (s *AssignmentService) acceptIndividual(ctx context.Context, tx *repository.Repositories, a *acceptance, actor *Actor) (*model.Submission, error) {
+	existing, err := tx.Submissions.GetByStudent(ctx, a.assignment.ID, a.student.ID)
+	if err == nil {
+		return nil, alreadyAccepted(existing)
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	repoName := util.GenerateRepositoryName(a.classroom.Slug, a.assignment.Slug, actor.Login)
+	repo, err := s.provisionRepository(ctx, a, repoName, []string{actor.Login})
+	if err != nil {
+		return nil, err
+	}
+
+	submission := newSubmission(a.assignment.ID, repo)
+	submission.StudentID = &a.student.ID
+	if err := createSubmission(ctx, tx, a, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// acceptTeam joins or creates the caller's team and provisions the team
+// repository on first acceptance. Members joining a team whose repository
+// already exists are granted access to it.
+func (s *AssignmentService) acceptTeam(ctx context.Context, tx *repository.Repositories, a *acceptance, actor *Actor, req *model.AcceptAssignmentRequest) (*model.Submission, error) {
+	team, err := tx.Teams.GetByMember(ctx, a.assignment.ID, a.student.ID)
+	alreadyMember := err == nil
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if !alreadyMember {
+		team, err = s.joinOrCreateTeam(ctx, tx, a, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := tx.Submissions.GetByTeam(ctx, a.assignment.ID, team.ID)
+	switch {
+	case err == nil && alreadyMember:
+		return nil, alreadyAccepted(existing)
+	case err == nil:
+		// New member of a team that has already accepted
+		if err := s.forgejo.AddCollaborator(ctx, a.classroom.OrganizationName, existing.RepositoryName, actor.Login, forgejo.PermissionWrite); err != nil {
+			return nil, fmt.Errorf("failed to grant repository access: %w", err)
+		}
+		return existing, nil
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	members, err := tx.Teams.ListMembers(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	logins := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.ForgejoUsername != "" {
+			logins = append(logins, m.ForgejoUsername)
+		}
+	}
+
+	repoName := util.GenerateTeamRepositoryName(a.classroom.Slug, a.assignment.Slug, team.Slug)
+	repo, err := s.provisionRepository(ctx, a, repoName, logins)
+	if err != nil {
+		return nil, err
+	}
+
+	submission := newSubmission(a.assignment.ID, repo)
+	submission.TeamID = &team.ID
+	if err := createSubmission(ctx, tx, a, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// createSubmission records the submission of an acceptance
+func createSubmission(ctx context.Context, tx *repository.Repositories, a *acceptance, submission *model.Submission) error {
+	if err := tx.Submissions.Create(ctx, submission); err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx,
+		auditEvent(a.classroom.ID, model.AuditSubmissionCreate, model.AuditTargetSubmission, submission.ID), nil, submission)
+}
+
+// joinOrCreateTeam adds the student to the team named in req, creating it
+// with the student as leader if it does not exist
+func (s *AssignmentService) joinOrCreateTeam(ctx context.Context, tx *repository.Repositories, a *acceptance, req *model.AcceptAssignmentRequest) (*model.Team, error) {
+	name := ""
+	if req != nil {
+		name = strings.TrimSpace(req.TeamName)
+	}
+	slug := util.GenerateSlug(name)
+	if !util.IsValidSlug(slug) {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "team_name",
+			Message: "Team name is required for team assignments",
+			Code:    response.ErrValidationMissingField,
+		}})
+	}
+
+	// Serialize joins of the same team so the size limit holds
+	if err := tx.AdvisoryLock(ctx, fmt.Sprintf("accept:%d:team:%s", a.assignment.ID, slug)); err != nil {
+		return nil, err
+	}
+
+	team, err := tx.Teams.GetBySlug(ctx, a.assignment.ID, slug)
+	switch {
+	case err == nil:
+		if team.IsFull(a.assignment.MaxTeamSize) {
+			return nil, &Error{
+				Code:    response.ErrBusinessTeamSizeExceeded,
+				Message: response.GetErrorMessage(response.ErrBusinessTeamSizeExceeded),
+				Details: map[string]interface{}{"team": team.Name, "max_team_size": a.assignment.MaxTeamSize},
+			}
+		}
+		member := &model.TeamMember{TeamID: team.ID, StudentID: a.student.ID, Role: repository.TeamRoleMember}
+		if err := tx.Teams.AddMember(ctx, member); err != nil {
+			return nil, err
+		}
+		team.MemberCount++
+		return team, nil
+
+	case errors.Is(err, repository.ErrNotFound):
+		team = &model.Team{AssignmentID: a.assignment.ID, Name: name, Slug: slug, LeaderID: a.student.ID}
+		if err := tx.Teams.Create(ctx, team); err != nil {
+			return nil, err
+		}
+		leader := &model.TeamMember{TeamID: team.ID, StudentID: a.student.ID, Role: repository.TeamRoleLeader}
+		if err := tx.Teams.AddMember(ctx, leader); err != nil {
+			return nil, err
+		}
+		team.MemberCount++
+		return team, nil
+
+	default:
+		return nil, err
+	}
+}
+
+// provisionRepository generates repoName from the assignment template and
+// grants write access to logins. A repository left behind by an earlier,
+// interrupted attempt is reused.
+func (s *AssignmentService) provisionRepository(ctx context.Context, a *acceptance, repoName string, logins []string) (*forgejo.Repository, error) {
+	org := a.classroom.OrganizationName
+
+	template, err := s.forgejo.GetRepositoryByID(ctx, a.assignment.TemplateRepositoryID)
+	if err != nil {
+		if forgejo.IsNotFound(err) {
+			return nil, newError(response.ErrBusinessTemplateNotFound, err)
+		}
+		return nil, fmt.Errorf("failed to look up template repository: %w", err)
+	}
+
+	repo, err := s.forgejo.GenerateRepository(ctx, template.Owner.Login, template.Name, forgejo.GenerateRepoOption{
+		Owner:       org,
+		Name:        repoName,
+		Description: a.assignment.Name,
+		Private:     !a.assignment.Public,
+		GitContent:  true,
+	})
+	if forgejo.IsConflict(err) {
+		s.logger.Warn("Reusing existing repository", zap.String("repository", org+"/"+repoName))
+		repo, err = s.forgejo.GetRepository(ctx, org, repoName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate repository %s/%s: %w", org, repoName, err)
+	}
+
+	for _, login := range logins {
+		if err := s.forgejo.AddCollaborator(ctx, org, repo.Name, login, forgejo.PermissionWrite); err != nil {
+			return nil, fmt.Errorf("failed to grant %s access to %s: %w", login, repo.FullName, err)
+		}
+	}
+
+	return repo, nil
+}
+
+// newSubmission builds an accepted submission for a provisioned repository
+func newSubmission(assignmentID int64, repo *forgejo.Repository) *model.Submission {
+	now := time.Now().UTC()
+	return &model.Submission{
+		AssignmentID:   assignmentID,
+		RepositoryName: repo.Name,
+		RepositoryID:   repo.ID,
+		RepositoryURL:  repo.HTMLURL,
+		Status:         SubmissionStatusAccepted,
+		AcceptedAt:     &now,
+	}
+}
+
+// alreadyAccepted reports a repeated acceptance, pointing at the existing repository
+func alreadyAccepted(existing *model.Submission) *Error {
+	return &Error{
+		Code:    response.ErrBusinessAlreadyAccepted,
+		Message: response.GetErrorMessage(response.ErrBusinessAlreadyAccepted),
+		Details: map[string]interface{}{
+			"submission_id":  existing.ID,
+			"repository_url": existing.RepositoryURL,
+		},
+	}
+}
+
+// assignmentError reports a missing assignment as RESOURCE_NOT_FOUND
+func assignmentError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Assignment")
+	}
+	return err
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+func TestAssignmentService_Validation(t *testing.T) {
+	// Validation runs before any database or Forgejo access
+	svc := NewAssignmentService(nil, nil, nil, zap.NewNop())
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name  string
+		req   model.CreateAssignmentRequest
+		field string
+	}{
+		{"missing classroom", model.CreateAssignmentRequest{Name: "HW1", TemplateRepository: "cs101/t"}, "classroom_id"},
+		{"missing name", model.CreateAssignmentRequest{ClassroomID: 1, TemplateRepository: "cs101/t"}, "name"},
+		{"missing template", model.CreateAssignmentRequest{ClassroomID: 1, Name: "HW1"}, "template_repository"},
+		{"invalid deadline", model.CreateAssignmentRequest{ClassroomID: 1, Name: "HW1", TemplateRepository: "cs101/t", Deadline: "friday"}, "deadline"},
+		{"past deadline", model.CreateAssignmentRequest{ClassroomID: 1, Name: "HW1", TemplateRepository: "cs101/t", Deadline: past}, "deadline"},
+		{"negative team size", model.CreateAssignmentRequest{ClassroomID: 1, Name: "HW1", TemplateRepository: "cs101/t", MaxTeamSize: -1}, "max_team_size"},
+		{"name without slug characters", model.CreateAssignmentRequest{ClassroomID: 1, Name: "!!!", TemplateRepository: "cs101/t"}, "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(ctx, &tt.req)
+			require.Error(t, err)
+
+			svcErr := AsError(err)
+			assert.Equal(t, response.ErrValidationInvalidInput, svcErr.Code)
+
+			fields, ok := svcErr.Details["fields"].(util.ValidationErrors)
+			require.True(t, ok, "details must contain field errors")
+			require.NotEmpty(t, fields)
+			assert.Equal(t, tt.field, fields[0].Field)
+		})
+	}
+}
+
+func TestParseRepositoryRef(t *testing.T) {
+	tests := []struct {
+		ref         string
+		owner, name string
+		ok          bool
+	}{
+		{"cs101/hw1-template", "cs101", "hw1-template", true},
+		{"https://forgejo.example.com/cs101/hw1-template", "cs101", "hw1-template", true},
+		{"https://forgejo.example.com/cs101/hw1-template.git", "cs101", "hw1-template", true},
+		{"https://example.com/git/cs101/hw1-template/", "cs101", "hw1-template", true},
+		{"hw1-template", "", "", false},
+		{"cs101/hw1/extra", "", "", false},
+		{"https://forgejo.example.com/cs101", "", "", false},
+	}
+	for _, tt := range tests {
+		owner, name, ok := parseRepositoryRef(tt.ref)
+		assert.Equal(t, tt.ok, ok, tt.ref)
+		assert.Equal(t, tt.owner, owner, tt.ref)
+		assert.Equal(t, tt.name, name, tt.ref)
+	}
+}
+
+func TestAssignmentService_CRUD(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Assignments
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	template := server.AddRepository("cs101", "hw1-template", true)
+	server.AddRepository("cs101", "notes", false)
+
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	created, err := svc.Create(ctx, &model.CreateAssignmentRequest{
+		ClassroomID: classroom.ID, Name: "Homework 1", TemplateRepository: template.HTMLURL,
+		Deadline: deadline.Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "homework-1", created.Slug)
+	assert.Equal(t, template.ID, created.TemplateRepositoryID)
+	assert.Equal(t, "cs101/hw1-template", created.TemplateRepository)
+	assert.Equal(t, 1, created.MaxTeamSize)
+	require.NotNil(t, created.Deadline)
+	assert.True(t, deadline.Equal(*created.Deadline))
+
+	t.Run("template must exist and be a template", func(t *testing.T) {
+		_, err := svc.Create(ctx, &model.CreateAssignmentRequest{
+			ClassroomID: classroom.ID, Name: "Homework 2", TemplateRepository: "cs101/missing",
+		})
+		assert.Equal(t, response.ErrBusinessTemplateNotFound, AsError(err).Code)
+
+		_, err = svc.Create(ctx, &model.CreateAssignmentRequest{
+			ClassroomID: classroom.ID, Name: "Homework 2", TemplateRepository: "cs101/notes",
+		})
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+	})
+
+	t.Run("duplicate names get a numbered slug", func(t *testing.T) {
+		second, err := svc.Create(ctx, &model.CreateAssignmentRequest{
+			ClassroomID: classroom.ID, Name: "Homework 1", TemplateRepository: template.FullName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "homework-1-2", second.Slug)
+		require.NoError(t, svc.Delete(ctx, second.ID))
+	})
+
+	t.Run("update clears the deadline", func(t *testing.T) {
+		name, empty := "Homework One", ""
+		updated, err := svc.Update(ctx, created.ID, &model.UpdateAssignmentRequest{Name: &name, Deadline: &empty})
+		require.NoError(t, err)
+		assert.Equal(t, "Homework One", updated.Name)
+		assert.Equal(t, "homework-1", updated.Slug)
+		assert.Nil(t, updated.Deadline)
+	})
+
+	t.Run("list by classroom", func(t *testing.T) {
+		list, err := svc.List(ctx, &model.AssignmentListRequest{ClassroomID: classroom.ID})
+		require.NoError(t, err)
+		require.Equal(t, 1, list.Total)
+		assert.Equal(t, created.ID, list.Assignments[0].ID)
+
+		_, err = svc.List(ctx, &model.AssignmentListRequest{Status: "soon"})
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, svc.Delete(ctx, created.ID))
+		_, err := svc.Get(ctx, created.ID)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+}
+
+func TestAssignmentService_Accept(t *testing.T) {
+	services, server := setupTestServices(t)
+	repos := services.Assignments.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	template := server.AddRepository("cs101", "hw1-template", true)
+	student := server.AddUser("jdoe", "jdoe-token")
+	outsider := server.AddUser("mallory", "mallory-token")
+
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	login := student.Login
+	require.NoError(t, repos.Roster.Create(ctx, &model.RosterEntry{
+		ClassroomID: classroom.ID, StudentName: "John Doe", StudentEmail: "john@example.com",
+		StudentID: "john123", ForgejoUsername: &login, ForgejoUserID: &student.ID, Role: "student",
+	}))
+
+	deadline := time.Now().Add(24 * time.Hour)
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: template.FullName,
+		TemplateRepositoryID: template.ID, Deadline: &deadline, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	actor := &Actor{ID: student.ID, Login: student.Login}
+
+	t.Run("caller must be on the roster", func(t *testing.T) {
+		_, err := services.Assignments.Accept(ctx, &Actor{ID: outsider.ID, Login: outsider.Login}, assignment.ID, nil)
+		assert.Equal(t, response.ErrBusinessRosterNotFound, AsError(err).Code)
+	})
+
+	t.Run("concurrent accepts provision one repository", func(t *testing.T) {
+		const attempts = 5
+		var wg sync.WaitGroup
+		codes := make(chan string, attempts)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := services.Assignments.Accept(ctx, actor, assignment.ID, nil)
+				if err != nil {
+					codes <- AsError(err).Code
+					return
+				}
+				codes <- ""
+			}()
+		}
+		wg.Wait()
+		close(codes)
+
+		succeeded := 0
+		for code := range codes {
+			if code == "" {
+				succeeded++
+			} else {
+				assert.Equal(t, response.ErrBusinessAlreadyAccepted, code)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+
+		_, ok := server.Repository("cs101", "cs-101-hw1-jdoe")
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"jdoe": "write"}, server.Collaborators("cs101", "cs-101-hw1-jdoe"))
+
+		submission, err := repos.Submissions.GetByStudent(ctx, assignment.ID, mustRosterID(t, services, classroom.ID, student.ID))
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusAccepted, submission.Status)
+	})
+
+	t.Run("deadline passed", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		closed := &model.Assignment{
+			ClassroomID: classroom.ID, Name: "Homework 0", Slug: "hw0", TemplateRepository: template.FullName,
+			TemplateRepositoryID: template.ID, Deadline: &past, MaxTeamSize: 1,
+		}
+		require.NoError(t, repos.Assignments.Create(ctx, closed))
+
+		_, err := services.Assignments.Accept(ctx, actor, closed.ID, nil)
+		assert.Equal(t, response.ErrBusinessDeadlinePassed, AsError(err).Code)
+	})
+}
+
+func mustRosterID(t *testing.T, services *Services, classroomID, userID int64) int64 {
+	t.Helper()
+	entry, err := services.Assignments.repos.Roster.GetByForgejoUserID(context.Background(), classroomID, userID)
+	require.NoError(t, err)
+	return entry.ID
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+)
+
+// AuditService reads the audit log. Events are recorded by the services
+// making the changes.
+type AuditService struct {
+	repos *repository.Repositories
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repos *repository.Repositories) *AuditService {
+	return &AuditService{repos: repos}
+}
+
+// List returns one page of a classroom's audit events, newest first. The
+// log of a deleted classroom can still be listed.
+func (s *AuditService) List(ctx context.Context, classroomID int64, req *model.AuditListRequest) (*model.AuditListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+	return s.repos.AuditEvents.List(ctx, classroomID, req)
+}
+
+// auditEvent returns an event for action on a target in a classroom
+func auditEvent(classroomID int64, action, targetType string, targetID int64) *model.AuditEvent {
+	return &model.AuditEvent{
+		ClassroomID: classroomID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+	}
+}
+
+// recordAudit appends event, which changed its target from before to after,
+// to the audit log. The actor, request ID and client IP are taken from ctx.
+// Changes made in a transaction must be recorded with its repositories, so
+// that they are committed or rolled back along with their event.
+func recordAudit(ctx context.Context, repos *repository.Repositories, event *model.AuditEvent, before, after interface{}) error {
+	origin := audit.OriginFrom(ctx)
+	if origin.ActorID != 0 {
+		event.ActorID = &origin.ActorID
+	}
+	event.ActorLogin = origin.ActorLogin
+	event.RequestID = origin.RequestID
+	event.IP = origin.IP
+	event.Changes = audit.Diff(before, after)
+
+	if err := repos.AuditEvents.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to record %s audit event: %w", event.Action, err)
+	}
+	return nil
+}
+
+// logAudit records event like recordAudit for a change made outside of a
+// transaction. The change is already done by then, so a failure to record
+// it is logged rather than returned.
+func logAudit(ctx context.Context, repos *repository.Repositories, logger *zap.Logger, event *model.AuditEvent, before, after interface{}) {
+	if err := recordAudit(ctx, repos, event, before, after); err != nil {
+		logger.Error("Failed to record audit event",
+			zap.Int64("classroom_id", event.ClassroomID),
+			zap.String("action", event.Action),
+			zap.String("target_type", event.TargetType),
+			zap.Int64("target_id", event.TargetID),
+			zap.Error(err),
+		)
+	}
+}
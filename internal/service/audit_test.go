@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func TestAuditLog(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Audit
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	prof := &Actor{ID: 1, Login: "prof"}
+
+	ctx := audit.WithActor(audit.WithRequest(context.Background(), "req_test", "192.0.2.1"), prof.ID, prof.Login)
+	classroom, err := services.Classrooms.Create(ctx, prof,
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	name := "CS 101 (Fall)"
+	_, err = services.Classrooms.Update(ctx, classroom.ID, &model.UpdateClassroomRequest{Name: &name})
+	require.NoError(t, err)
+
+	_, err = services.Roster.Bulk(ctx, classroom.ID, &model.BulkRosterRequest{Operations: []model.RosterOperation{
+		{Action: model.RosterActionAdd, StudentName: "John Doe", StudentEmail: "john@example.com", StudentID: "john123"},
+		{Action: model.RosterActionRemove, StudentID: "john123"},
+	}})
+	require.NoError(t, err)
+
+	t.Run("events are listed newest first with their origin", func(t *testing.T) {
+		list, err := svc.List(ctx, classroom.ID, &model.AuditListRequest{})
+		require.NoError(t, err)
+		require.Equal(t, 4, list.Total)
+
+		var actions []string
+		for _, e := range list.Events {
+			actions = append(actions, e.Action)
+		}
+		assert.Equal(t, []string{model.AuditRosterRemove, model.AuditRosterAdd, model.AuditClassroomUpdate, model.AuditClassroomCreate}, actions)
+
+		update := list.Events[2]
+		assert.Equal(t, "prof", update.ActorLogin)
+		assert.Equal(t, int64(1), *update.ActorID)
+		assert.Equal(t, "req_test", update.RequestID)
+		assert.Equal(t, "192.0.2.1", update.IP)
+		assert.Equal(t, audit.Change{Before: "CS 101", After: name}, update.Changes["name"])
+		assert.Len(t, update.Changes, 1)
+
+		removed := list.Events[0]
+		assert.Equal(t, model.AuditTargetRosterEntry, removed.TargetType)
+		assert.Equal(t, audit.Change{Before: "john123"}, removed.Changes["student_id"])
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		list, err := svc.List(ctx, classroom.ID, &model.AuditListRequest{TargetType: model.AuditTargetRosterEntry})
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+
+		list, err = svc.List(ctx, classroom.ID, &model.AuditListRequest{Action: model.AuditClassroomCreate, Actor: "PROF"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, list.Total)
+
+		list, err = svc.List(ctx, classroom.ID, &model.AuditListRequest{Since: "2999-01-01T00:00:00Z"})
+		require.NoError(t, err)
+		assert.Zero(t, list.Total)
+
+		_, err = svc.List(ctx, classroom.ID, &model.AuditListRequest{Until: "tomorrow"})
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+	})
+
+	t.Run("the log outlives the classroom", func(t *testing.T) {
+		require.NoError(t, services.Classrooms.Delete(ctx, classroom.ID))
+		list, err := svc.List(ctx, classroom.ID, &model.AuditListRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 5, list.Total)
+		assert.Equal(t, model.AuditClassroomDelete, list.Events[0].Action)
+	})
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// maxSlugCandidates bounds the number of "-N" suffixes tried for a unique slug
+const maxSlugCandidates = 100
+
+// ClassroomService manages classrooms and their Forgejo organizations
+type ClassroomService struct {
+	repos   *repository.Repositories
+	forgejo *forgejo.Client
+	cache   *cache.Store
+	logger  *zap.Logger
+}
+
+// NewClassroomService creates a new classroom service
+func NewClassroomService(repos *repository.Repositories, fj *forgejo.Client, store *cache.Store, logger *zap.Logger) *ClassroomService {
+	return &ClassroomService{
+		repos:   repos,
+		forgejo: fj,
+		cache:   store,
+		logger:  logger,
+	}
+}
+
+// Create creates a classroom bound to a Forgejo organization. An existing
+// organization can only be bound by its owners and admins or by a site
+// administrator; a missing one is created with the actor as its owner.
+// When actor is nil the owner of the service token becomes the instructor.
+func (s *ClassroomService) Create(ctx context.Context, actor *Actor, req *model.CreateClassroomRequest) (*model.Classroom, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	baseSlug := util.GenerateSlug(req.Name)
+	if !util.IsValidSlug(baseSlug) {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "name",
+			Message: "Name must contain at least one letter or digit",
+			Code:    response.ErrValidationInvalidFormat,
+		}})
+	}
+
+	if actor == nil {
+		user, err := s.forgejo.CurrentUser(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve instructor: %w", err)
+		}
+		actor = &Actor{ID: user.ID, Login: user.Login, Admin: user.IsAdmin}
+	}
+
+	org, err := s.resolveOrganization(ctx, actor, req)
+	if err != nil {
+		return nil, err
+	}
+
+	classroom := &model.Classroom{
+		Name:             strings.TrimSpace(req.Name),
+		Description:      req.Description,
+		OrganizationName: org.UserName,
+		OrganizationID:   org.ID,
+		InstructorID:     actor.ID,
+		InstructorLogin:  actor.Login,
+		Public:           req.Public,
+	}
+	if classroom.OrganizationName == "" {
+		classroom.OrganizationName = req.OrganizationName
+	}
+
+	for i := 1; i <= maxSlugCandidates; i++ {
+		classroom.Slug = slugCandidate(baseSlug, i)
+
+		if _, err := s.repos.Classrooms.GetBySlug(ctx, classroom.Slug); err == nil {
+			continue
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+
+		err := s.repos.Classrooms.Create(ctx, classroom)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			continue // taken by a concurrent create
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Invalidate(ctx, cache.ClassroomListPattern)
+		logAudit(ctx, s.repos, s.logger,
+			auditEvent(classroom.ID, model.AuditClassroomCreate, model.AuditTargetClassroom, classroom.ID), nil, classroom)
+
+		s.logger.Info("Classroom created",
+			zap.Int64("classroom_id", classroom.ID),
+			zap.String("slug", classroom.Slug),
+			zap.String("organization", classroom.OrganizationName),
+		)
+		return classroom, nil
+	}
+
+	return nil, &Error{
+		Code:    response.ErrResourceAlreadyExists,
+		Message: "Could not generate a unique slug for this classroom name",
+		Details: map[string]interface{}{"field": "name"},
+	}
+}
+
+// resolveOrganization returns the organization named in req, creating it
+// on behalf of actor if it does not exist. Binding an existing organization
+// requires the actor to own or administer it, so that nobody can take over
+// the repositories of another organization through the service token.
+func (s *ClassroomService) resolveOrganization(ctx context.Context, actor *Actor, req *model.CreateClassroomRequest) (*forgejo.Organization, error) {
+	org, err := s.forgejo.GetOrganization(ctx, req.OrganizationName)
+	if err == nil {
+		if actor.Admin {
+			return org, nil
+		}
+		perms, err := s.forgejo.OrganizationPermissions(ctx, org.UserName, actor.Login)
+		if err != nil && !forgejo.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to check permissions in organization %q: %w", req.OrganizationName, err)
+		}
+		if perms == nil || (!perms.IsOwner && !perms.IsAdmin) {
+			return nil, &Error{
+				Code:    response.ErrAuthzInsufficientPermissions,
+				Message: "Only owners and admins of the organization can bind it to a classroom",
+				Details: map[string]interface{}{"field": "organization_name"},
+			}
+		}
+		return org, nil
+	}
+	if !forgejo.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to resolve organization %q: %w", req.OrganizationName, err)
+	}
+
+	visibility := "private"
+	if req.Public {
+		visibility = "public"
+	}
+	org, err = s.forgejo.CreateOrganizationFor(ctx, actor.Login, forgejo.CreateOrgOption{
+		UserName:    req.OrganizationName,
+		FullName:    strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Visibility:  visibility,
+	})
+	if err != nil {
+		if forgejo.IsConflict(err) {
+			return nil, &Error{
+				Code:    response.ErrResourceAlreadyExists,
+				Message: "Organization name already exists",
+				Details: map[string]interface{}{"field": "organization_name"},
+				Err:     err,
+			}
+		}
+		return nil, fmt.Errorf("failed to create organization %q: %w", req.OrganizationName, err)
+	}
+	return org, nil
+}
+
+// slugCandidate returns the n-th candidate slug: base, base-2, base-3, ...
+func slugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, n)
+}
+
+// Get returns a classroom by ID
+func (s *ClassroomService) Get(ctx context.Context, id int64) (*model.Classroom, error) {
+	key := cache.ClassroomKey(id)
+	var classroom *model.Classroom
+	if s.cache.Get(ctx, key, &classroom) {
+		return classroom, nil
+	}
+
+	classroom, err := s.repos.Classrooms.GetByID(ctx, id)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+	s.cache.Set(ctx, key, classroom, s.cache.Config().ClassroomTTL)
+	return classroom, nil
+}
+
+// List returns one page of classrooms, filtered by organization and archived state
+func (s *ClassroomService) List(ctx context.Context, req *model.ClassroomListRequest) (*model.ClassroomListResponse, error) {
+	key := cache.ClassroomListKey(req)
+	var list *model.ClassroomListResponse
+	if s.cache.Get(ctx, key, &list) {
+		return list, nil
+	}
+
+	list, err := s.repos.Classrooms.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, key, list, s.cache.Config().ClassroomTTL)
+	return list, nil
+}
+
+// Update applies the non-nil fields of req. The slug is kept stable so that
+// existing repository names and links continue to work.
+func (s *ClassroomService) Update(ctx context.Context, id int64, req *model.UpdateClassroomRequest) (*model.Classroom, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+
+	classroom, err := s.repos.Classrooms.GetByID(ctx, id)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+	before := *classroom
+
+	if req.Name != nil {
+		classroom.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		classroom.Description = *req.Description
+	}
+	if req.Public != nil {
+		classroom.Public = *req.Public
+	}
+
+	if err := s.repos.Classrooms.Update(ctx, classroom); err != nil {
+		return nil, classroomError(err)
+	}
+	s.cache.Invalidate(ctx, cache.ClassroomKey(id), cache.ClassroomListPattern)
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(id, model.AuditClassroomUpdate, model.AuditTargetClassroom, id), &before, classroom)
+	return classroom, nil
+}
+
+// Delete removes a classroom and, through cascading foreign keys, its roster,
+// assignments, teams and submissions. The Forgejo organization and the
+// audit log are kept.
+func (s *ClassroomService) Delete(ctx context.Context, id int64) error {
+	classroom, err := s.repos.Classrooms.GetByID(ctx, id)
+	if err != nil {
+		return classroomError(err)
+	}
+	if err := s.repos.Classrooms.Delete(ctx, id); err != nil {
+		return classroomError(err)
+	}
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(id, model.AuditClassroomDelete, model.AuditTargetClassroom, id), classroom, nil)
+	// Submissions are cached by their own ID, which is not at hand here
+	s.cache.Invalidate(ctx, cache.ClassroomKey(id), cache.ClassroomPattern(id), cache.ClassroomListPattern, cache.SubmissionPattern)
+	s.logger.Info("Classroom deleted", zap.Int64("classroom_id", id))
+	return nil
+}
+
+// Archive marks a classroom as archived. Archiving an archived classroom is a no-op.
+func (s *ClassroomService) Archive(ctx context.Context, id int64) (*model.Classroom, error) {
+	classroom, err := s.repos.Classrooms.GetByID(ctx, id)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+	if classroom.Archived {
+		return classroom, nil
+	}
+
+	before := *classroom
+	now := time.Now().UTC()
+	classroom.Archived = true
+	classroom.ArchivedAt = &now
+
+	if err := s.repos.Classrooms.Update(ctx, classroom); err != nil {
+		return nil, classroomError(err)
+	}
+	s.cache.Invalidate(ctx, cache.ClassroomKey(id), cache.ClassroomListPattern)
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(id, model.AuditClassroomArchive, model.AuditTargetClassroom, id), &before, classroom)
+	s.logger.Info("Classroom archived", zap.Int64("classroom_id", id))
+	return classroom, nil
+}
+
+// classroomError reports a missing classroom as RESOURCE_NOT_FOUND
+func classroomError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Classroom")
+	}
+	return err
+}
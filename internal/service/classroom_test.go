@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/config"
+	"code.forgejo.org/forgejo/classroom/internal/database"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo/forgejotest"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+func TestClassroomService_Validation(t *testing.T) {
+	// Validation runs before any database or Forgejo access
+	svc := NewClassroomService(nil, nil, nil, zap.NewNop())
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		req   model.CreateClassroomRequest
+		field string
+	}{
+		{"missing name", model.CreateClassroomRequest{OrganizationName: "cs101"}, "name"},
+		{"name too long", model.CreateClassroomRequest{Name: string(make([]byte, 256)), OrganizationName: "cs101"}, "name"},
+		{"missing organization", model.CreateClassroomRequest{Name: "CS101"}, "organization_name"},
+		{"invalid organization", model.CreateClassroomRequest{Name: "CS101", OrganizationName: "cs 101"}, "organization_name"},
+		{"name without slug characters", model.CreateClassroomRequest{Name: "!!!", OrganizationName: "cs101"}, "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(ctx, nil, &tt.req)
+			require.Error(t, err)
+
+			svcErr := AsError(err)
+			assert.Equal(t, response.ErrValidationInvalidInput, svcErr.Code)
+
+			fields, ok := svcErr.Details["fields"].(util.ValidationErrors)
+			require.True(t, ok, "details must contain field errors")
+			require.NotEmpty(t, fields)
+			assert.Equal(t, tt.field, fields[0].Field)
+		})
+	}
+}
+
+func setupTestServices(t *testing.T) (*Services, *forgejotest.Server) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:                  getEnv("FGC_DATABASE_HOST", "localhost"),
+			Port:                  5432,
+			User:                  getEnv("FGC_DATABASE_USER", "fgc_test"),
+			Password:              getEnv("FGC_DATABASE_PASSWORD", "fgc_test_password"),
+			Name:                  getEnv("FGC_DATABASE_NAME", "forgejo_classroom_test"),
+			SSLMode:               "disable",
+			MaxConnections:        5,
+			MaxIdleConnections:    2,
+			ConnectionMaxLifetime: time.Hour,
+		},
+	}
+
+	logger := zap.NewNop()
+	db, err := database.New(cfg, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.RunMigrations(db.DB, database.MigrateConfig{MigrationsPath: "../../migrations"}, logger))
+	_, err = db.Exec(`TRUNCATE jobs, team_members, teams, submissions, assignments, roster_entries, classrooms, audit_events RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+
+	server := forgejotest.NewServer(t)
+	q := queue.NewPostgresQueue(db.DB, &config.QueueConfig{RetryAttempts: 3, ProcessingTimeout: time.Minute})
+	// Caching every read makes the tests fail on a missing invalidation
+	store := cache.NewStore(cache.NewMemoryCache(1000), &config.CacheConfig{
+		ClassroomTTL:  time.Hour,
+		RosterTTL:     time.Hour,
+		SubmissionTTL: time.Hour,
+	}, logger)
+	return New(repository.New(db), server.Client(t), q, store, logger), server
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestClassroomService_Integration(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Classrooms
+	ctx := context.Background()
+
+	server.AddUser("prof", "")
+	instructor := &Actor{ID: 42, Login: "prof"}
+
+	first, err := svc.Create(ctx, instructor, &model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+	assert.Equal(t, "cs-101", first.Slug)
+	assert.NotZero(t, first.OrganizationID)
+	assert.Equal(t, "prof", first.InstructorLogin)
+
+	t.Run("organization is created in Forgejo for the instructor", func(t *testing.T) {
+		assert.Contains(t, server.Requests(), forgejotest.RecordedRequest{Method: "POST", Path: "/admin/users/prof/orgs"})
+	})
+
+	t.Run("non-members cannot bind an existing organization", func(t *testing.T) {
+		server.AddOrganization("ee201", "student")
+		for _, actor := range []*Actor{{ID: 43, Login: "mallory"}, {ID: 44, Login: "student"}} {
+			_, err := svc.Create(ctx, actor, &model.CreateClassroomRequest{Name: "EE 201", OrganizationName: "ee201"})
+			assert.Equal(t, response.ErrAuthzInsufficientPermissions, AsError(err).Code, actor.Login)
+		}
+
+		server.AddOrganizationOwner("ee201", "lecturer")
+		_, err := svc.Create(ctx, &Actor{ID: 45, Login: "lecturer"}, &model.CreateClassroomRequest{Name: "EE 201", OrganizationName: "ee201"})
+		assert.NoError(t, err)
+
+		_, err = svc.Create(ctx, &Actor{ID: 46, Login: "root", Admin: true}, &model.CreateClassroomRequest{Name: "EE 201", OrganizationName: "ee201"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("duplicate names get a numbered slug", func(t *testing.T) {
+		second, err := svc.Create(ctx, instructor, &model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+		require.NoError(t, err)
+		assert.Equal(t, "cs-101-2", second.Slug)
+		assert.Equal(t, first.OrganizationID, second.OrganizationID)
+	})
+
+	t.Run("archive sets archived_at and hides the classroom", func(t *testing.T) {
+		archived, err := svc.Archive(ctx, first.ID)
+		require.NoError(t, err)
+		assert.True(t, archived.Archived)
+		require.NotNil(t, archived.ArchivedAt)
+
+		list, err := svc.List(ctx, &model.ClassroomListRequest{OrganizationName: "cs101"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, list.Total)
+
+		list, err = svc.List(ctx, &model.ClassroomListRequest{OrganizationName: "cs101", IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+	})
+
+	t.Run("missing classroom", func(t *testing.T) {
+		_, err := svc.Get(ctx, 9999)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+}
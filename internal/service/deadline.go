@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+)
+
+// JobTypeEnforceDeadline tags every submission repository of an assignment
+const JobTypeEnforceDeadline = "deadline.enforce"
+
+const (
+	// deadlineCheckInterval bounds how long the scheduler sleeps, so that
+	// new or moved deadlines are noticed
+	deadlineCheckInterval = time.Minute
+	// deadlineBatchSize is how many due assignments are scheduled per query
+	deadlineBatchSize = 100
+	// deadlineRetryDelay is how long a deadline whose enforcement job was
+	// dead-lettered waits before it is scheduled again
+	deadlineRetryDelay = 15 * time.Minute
+)
+
+// deadlineTagLayout is RFC 3339 with the time separators replaced by '-',
+// since git does not allow ':' in ref names
+const deadlineTagLayout = "2006-01-02T15-04-05Z"
+
+// DeadlineTagName returns the name of the tag marking deadline
+func DeadlineTagName(deadline time.Time) string {
+	return "deadline-" + deadline.UTC().Format(deadlineTagLayout)
+}
+
+// EnforceDeadlinePayload is the payload of JobTypeEnforceDeadline
+type EnforceDeadlinePayload struct {
+	AssignmentID int64     `json:"assignment_id"`
+	Deadline     time.Time `json:"deadline"`
+}
+
+// DeadlineService enforces assignment deadlines: when a deadline passes,
+// every submission repository is tagged at the commit that was the head of
+// its default branch at the deadline, and submissions pushed to after it
+// are marked late. Commit dates are set by whoever commits, so only push
+// times taken by the server count, and the head at the deadline is known
+// from the pushes recorded around it.
+type DeadlineService struct {
+	repos   *repository.Repositories
+	forgejo *forgejo.Client
+	queue   queue.Queue
+	cache   *cache.Store
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewDeadlineService creates a new deadline service
+func NewDeadlineService(repos *repository.Repositories, fj *forgejo.Client, q queue.Queue, store *cache.Store, logger *zap.Logger) *DeadlineService {
+	return &DeadlineService{
+		repos:   repos,
+		forgejo: fj,
+		queue:   q,
+		cache:   store,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// RegisterJobs registers the deadline job handler with pool
+func (s *DeadlineService) RegisterJobs(pool *queue.Pool) {
+	pool.Register(JobTypeEnforceDeadline, queue.Typed(s.enforceJob))
+}
+
+// Run schedules deadlines as they pass until ctx is cancelled. It wakes up
+// at the next deadline, or after deadlineCheckInterval at the latest.
+// Deadlines that passed while no scheduler was running are picked up on
+// the first pass.
+func (s *DeadlineService) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.ScheduleDue(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to schedule deadlines", zap.Error(err))
+		}
+		timer.Reset(s.untilNextCheck(ctx))
+	}
+}
+
+// untilNextCheck returns how long Run should sleep
+func (s *DeadlineService) untilNextCheck(ctx context.Context) time.Duration {
+	now := s.now()
+	next, err := s.repos.Assignments.NextDeadline(ctx, now)
+	if err != nil || next == nil {
+		return deadlineCheckInterval
+	}
+	if wait := next.Sub(now); wait < deadlineCheckInterval {
+		return wait
+	}
+	return deadlineCheckInterval
+}
+
+// ScheduleDue enqueues an enforcement job for every passed deadline that
+// is neither enforced nor being enforced and returns how many were
+// enqueued. Deadlines whose job was dead-lettered are scheduled again after
+// deadlineRetryDelay.
+func (s *DeadlineService) ScheduleDue(ctx context.Context) (int, error) {
+	scheduled := 0
+	for {
+		now := s.now()
+		deadAfter := now.Add(-deadlineRetryDelay)
+		due, err := s.repos.Assignments.ListDueDeadlines(ctx, now, deadAfter, deadlineBatchSize)
+		if err != nil {
+			return scheduled, err
+		}
+
+		for _, a := range due {
+			ok, err := s.schedule(ctx, &a, deadAfter)
+			if err != nil {
+				return scheduled, err
+			}
+			if ok {
+				scheduled++
+			}
+		}
+
+		if len(due) < deadlineBatchSize {
+			return scheduled, nil
+		}
+	}
+}
+
+// schedule claims the assignment's deadline and enqueues its enforcement
+// job in one transaction, so that no claim outlives a failed enqueue. It
+// returns false if another scheduler got there first.
+func (s *DeadlineService) schedule(ctx context.Context, a *model.Assignment, deadAfter time.Time) (bool, error) {
+	job, err := queue.NewJob(JobTypeEnforceDeadline, EnforceDeadlinePayload{AssignmentID: a.ID, Deadline: *a.Deadline})
+	if err != nil {
+		return false, err
+	}
+
+	claimed := false
+	err = s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+		// Serializes schedulers, so that the claim sees jobs they enqueued
+		if err := tx.AdvisoryLock(ctx, fmt.Sprintf("deadline:%d", a.ID)); err != nil {
+			return err
+		}
+		var err error
+		claimed, err = tx.Assignments.ClaimDeadline(ctx, a.ID, *a.Deadline, deadAfter)
+		if err != nil || !claimed {
+			return err
+		}
+		if q, ok := s.queue.(queue.TxEnqueuer); ok {
+			return q.EnqueueWith(ctx, tx.Querier(), job)
+		}
+		return s.queue.Enqueue(ctx, job)
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	s.logger.Info("Scheduled deadline enforcement",
+		zap.Int64("assignment_id", a.ID),
+		zap.Time("deadline", *a.Deadline),
+		zap.Int64("job_id", job.ID),
+	)
+	return true, nil
+}
+
+// enforceJob is the JobTypeEnforceDeadline handler
+func (s *DeadlineService) enforceJob(ctx context.Context, _ *queue.Job, p EnforceDeadlinePayload) error {
+	return s.Enforce(ctx, p.AssignmentID, p.Deadline)
+}
+
+// Enforce tags every submission repository of the assignment for deadline.
+// Repositories that were already tagged are skipped, so a failed run can be
+// retried; an error is returned if any repository could not be tagged.
+func (s *DeadlineService) Enforce(ctx context.Context, assignmentID int64, deadline time.Time) error {
+	a, err := s.repos.Assignments.GetByID(ctx, assignmentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil // deleted since it was scheduled
+	}
+	if err != nil {
+		return err
+	}
+	if a.Deadline == nil || !a.Deadline.Equal(deadline) {
+		s.logger.Info("Skipping outdated deadline",
+			zap.Int64("assignment_id", assignmentID),
+			zap.Time("deadline", deadline),
+		)
+		return nil
+	}
+
+	classroom, err := s.repos.Classrooms.GetByID(ctx, a.ClassroomID)
+	if err != nil {
+		return err
+	}
+
+	tag := DeadlineTagName(deadline)
+	total, failed := 0, 0
+	for page := 1; ; page++ {
+		list, err := s.repos.Submissions.List(ctx, &model.SubmissionListRequest{
+			AssignmentID: &a.ID,
+			Page:         page,
+			PerPage:      repository.MaxPerPage,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range list.Submissions {
+			submission := &list.Submissions[i]
+			if submission.DeadlineTag != nil && *submission.DeadlineTag == tag {
+				continue
+			}
+			total++
+
+			if err := s.tagSubmission(ctx, classroom.OrganizationName, a, submission, tag); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				failed++
+				s.logger.Warn("Failed to tag submission at deadline",
+					zap.Int64("submission_id", submission.ID),
+					zap.String("repository", submission.RepositoryName),
+					zap.Error(err),
+				)
+			}
+		}
+
+		if page >= list.TotalPages {
+			break
+		}
+	}
+	// Listings are dropped once rather than for every tagged submission
+	if total > 0 {
+		s.cache.Invalidate(ctx, cache.SubmissionListPattern)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to tag %d of %d repositories for %s", failed, total, tag)
+	}
+
+	if err := s.repos.Assignments.MarkDeadlineEnforced(ctx, a.ID, deadline); err != nil {
+		return err
+	}
+	s.logger.Info("Deadline enforced",
+		zap.Int64("assignment_id", a.ID),
+		zap.String("tag", tag),
+		zap.Int("repositories", total),
+	)
+	return nil
+}
+
+// tagSubmission tags the commit that was the head of the default branch at
+// the deadline and records the tag, marking the submission late if it was
+// pushed to after the deadline. A repository without commits then is
+// recorded as enforced with no tagged commit, and one whose head at the
+// deadline is unknown is flagged as unresolved rather than tagged.
+func (s *DeadlineService) tagSubmission(ctx context.Context, org string, a *model.Assignment, submission *model.Submission, tag string) error {
+	repo := submission.RepositoryName
+
+	sha, resolved, err := s.deadlineCommit(ctx, org, *a.Deadline, submission)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		s.logger.Warn("Commit at the deadline is unknown, flagging submission",
+			zap.Int64("submission_id", submission.ID),
+			zap.String("repository", repo),
+		)
+	}
+	if sha != "" {
+		_, err = s.forgejo.CreateTag(ctx, org, repo, forgejo.CreateTagOption{
+			TagName: tag,
+			Message: fmt.Sprintf("Deadline for %s", a.Name),
+			Target:  sha,
+		})
+		if forgejo.IsConflict(err) {
+			// Tagged by an earlier attempt; keep what the tag points at
+			existing, getErr := s.forgejo.GetTag(ctx, org, repo, tag)
+			if getErr != nil {
+				return getErr
+			}
+			if existing.Commit != nil {
+				sha = existing.Commit.SHA
+			}
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	before := *submission
+	now := s.now().UTC()
+	submission.DeadlineTag = &tag
+	submission.DeadlineSHA = nil
+	if sha != "" {
+		submission.DeadlineSHA = &sha
+	}
+	submission.DeadlineTaggedAt = &now
+	submission.DeadlineUnresolved = !resolved
+	late := submission.LastCommitAt != nil && submission.LastCommitAt.After(*a.Deadline)
+	if err := s.repos.Submissions.RecordDeadlineTag(ctx, submission, late); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, cache.SubmissionKey(submission.ID))
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(a.ClassroomID, model.AuditSubmissionDeadlineTag, model.AuditTargetSubmission, submission.ID), &before, submission)
+	return nil
+}
+
+// deadlineCommit returns the commit that was the head of the submission's
+// default branch at deadline, "" if there was none, and false if it cannot
+// be told. The head is the one recorded by the first push after the
+// deadline; without a later push it is the current head, provided that it
+// is the last push recorded or, if none was, the commit the repository was
+// created with. A differing head means pushes were missed.
+func (s *DeadlineService) deadlineCommit(ctx context.Context, org string, deadline time.Time, submission *model.Submission) (string, bool, error) {
+	if submission.DeadlineHeadFor != nil && submission.DeadlineHeadFor.Equal(deadline) {
+		if submission.DeadlineHeadSHA == nil {
+			return "", true, nil
+		}
+		return *submission.DeadlineHeadSHA, true, nil
+	}
+	if submission.LastCommitAt != nil && submission.LastCommitAt.After(deadline) {
+		return "", false, nil
+	}
+
+	repo := submission.RepositoryName
+	head, err := s.headCommit(ctx, org, repo)
+	if err != nil || head == "" {
+		return "", err == nil, err
+	}
+	if submission.LastCommitSHA != nil {
+		if head != *submission.LastCommitSHA {
+			return "", false, nil
+		}
+		return head, true, nil
+	}
+	initial, err := s.isInitialCommit(ctx, org, repo, head)
+	if err != nil || !initial {
+		return "", false, err
+	}
+	return head, true, nil
+}
+
+// isInitialCommit reports whether sha is the only commit of its history,
+// as the commit a repository is generated from a template with is
+func (s *DeadlineService) isInitialCommit(ctx context.Context, owner, repo, sha string) (bool, error) {
+	n, err := s.forgejo.CountCommits(ctx, owner, repo, forgejo.CommitListOptions{SHA: sha})
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// headCommit returns the SHA at the head of the default branch of a
+// repository, or "" if it has no commits
+func (s *DeadlineService) headCommit(ctx context.Context, owner, repo string) (string, error) {
+	r, err := s.forgejo.GetRepository(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if r.Empty {
+		return "", nil
+	}
+
+	branch, err := s.forgejo.GetBranch(ctx, owner, repo, r.DefaultBranch)
+	if forgejo.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if branch.Commit == nil {
+		return "", nil
+	}
+	return branch.Commit.ID, nil
+}
+
+// RecordPush handles a push p made at pushedAt to the default branch of a
+// submission; previous is the submission as it was before the push was
+// recorded. A push after the deadline marks the submission late, and the
+// first such push records the head it replaced as the head at the
+// deadline, for enforcement to tag.
+func (s *DeadlineService) RecordPush(ctx context.Context, previous *model.Submission, p *forgejo.PushPayload, pushedAt time.Time) error {
+	a, err := s.repos.Assignments.GetByID(ctx, previous.AssignmentID)
+	if err != nil {
+		return err
+	}
+	if a.Deadline == nil || !pushedAt.After(*a.Deadline) {
+		return nil
+	}
+
+	if err := s.recordDeadlineHead(ctx, *a.Deadline, previous, p); err != nil {
+		return err
+	}
+	if previous.IsLate() {
+		return nil
+	}
+
+	marked, err := s.repos.Submissions.MarkLate(ctx, previous.ID)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return nil // marked by a concurrent push
+	}
+	submission := *previous
+	submission.Status = SubmissionStatusLate
+	s.cache.Invalidate(ctx, cache.SubmissionKey(submission.ID), cache.SubmissionListPattern)
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(a.ClassroomID, model.AuditSubmissionLate, model.AuditTargetSubmission, submission.ID), previous, &submission)
+	s.logger.Info("Submission marked late",
+		zap.Int64("submission_id", submission.ID),
+		zap.Time("pushed_at", pushedAt),
+	)
+	return nil
+}
+
+// recordDeadlineHead records the head that push p replaced as the head at
+// deadline if p is the first push after the deadline: the pushes recorded
+// before it all came before the deadline and it replaced the last of them,
+// or, if none was recorded, the commit the repository was created with.
+// Otherwise pushes were missed and nothing is recorded.
+func (s *DeadlineService) recordDeadlineHead(ctx context.Context, deadline time.Time, previous *model.Submission, p *forgejo.PushPayload) error {
+	if previous.LastCommitAt != nil && previous.LastCommitAt.After(deadline) {
+		return nil
+	}
+
+	var head *string
+	switch {
+	case previous.LastCommitSHA != nil:
+		if p.Before != *previous.LastCommitSHA {
+			return nil
+		}
+		head = &p.Before
+	case p.Created():
+		// The branch had no commits at the deadline
+	default:
+		owner := ""
+		if p.Repository.Owner != nil {
+			owner = p.Repository.Owner.Login
+		}
+		initial, err := s.isInitialCommit(ctx, owner, p.Repository.Name, p.Before)
+		if err != nil {
+			s.logger.Warn("Failed to check the head at the deadline",
+				zap.Int64("submission_id", previous.ID),
+				zap.Error(err),
+			)
+			return nil
+		}
+		if !initial {
+			return nil
+		}
+		head = &p.Before
+	}
+
+	recorded, err := s.repos.Submissions.RecordDeadlineHead(ctx, previous.ID, head, deadline)
+	if err != nil {
+		return err
+	}
+	if recorded {
+		s.cache.Invalidate(ctx, cache.SubmissionKey(previous.ID))
+	}
+	return nil
+}
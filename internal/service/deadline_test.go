@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo/forgejotest"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+func TestDeadlineTagName(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 23, 59, 0, 0, time.FixedZone("CET", 3600))
+	assert.Equal(t, "deadline-2026-03-01T22-59-00Z", DeadlineTagName(deadline))
+}
+
+func TestDeadlineService_Enforce(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Deadlines
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: "cs101/hw1-template",
+		TemplateRepositoryID: 1, Deadline: &deadline, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	// newSubmission creates a submission repository with the commit it was
+	// generated with
+	newSubmission := func(name string) (*model.Submission, forgejo.Repository) {
+		repo := server.AddRepository("cs101", name, false)
+		login := name
+		student := &model.RosterEntry{
+			ClassroomID: classroom.ID, StudentName: name, StudentEmail: name + "@example.com",
+			StudentID: name, ForgejoUsername: &login, Role: "student",
+		}
+		require.NoError(t, repos.Roster.Create(ctx, student))
+		submission := &model.Submission{
+			AssignmentID: assignment.ID, StudentID: &student.ID, RepositoryName: repo.Name,
+			RepositoryID: repo.ID, RepositoryURL: repo.HTMLURL, Status: SubmissionStatusAccepted,
+		}
+		require.NoError(t, repos.Submissions.Create(ctx, submission))
+		return submission, repo
+	}
+	// push pushes a commit at pushedAt and records it as the push webhook
+	// does, returning its SHA
+	push := func(submission *model.Submission, repo forgejo.Repository, message string, pushedAt time.Time) string {
+		previous, err := repos.Submissions.GetByID(ctx, submission.ID)
+		require.NoError(t, err)
+		before := server.HeadSHA("cs101", repo.Name, forgejotest.DefaultBranch)
+		// Commit dates are up to the student
+		sha := server.PushAt("cs101", repo.Name, forgejotest.DefaultBranch, message, deadline.Add(-time.Hour)).SHA
+
+		submission.LastCommitSHA, submission.LastCommitAt = &sha, &pushedAt
+		require.NoError(t, repos.Submissions.UpdateLastCommit(ctx, submission, false))
+		require.NoError(t, svc.RecordPush(ctx, previous, &forgejo.PushPayload{
+			Ref: "refs/heads/" + forgejotest.DefaultBranch, Before: before, After: sha, Repository: &repo,
+		}, pushedAt))
+		return sha
+	}
+
+	// alice finishes on time and bob keeps pushing after the deadline
+	punctual, punctualRepo := newSubmission("alice")
+	punctualSHA := push(punctual, punctualRepo, "Solution", deadline.Add(-time.Minute))
+	tardy, tardyRepo := newSubmission("bob")
+	tardySHA := push(tardy, tardyRepo, "Solution", deadline.Add(-time.Minute))
+	push(tardy, tardyRepo, "Fix", deadline.Add(time.Minute))
+	push(tardy, tardyRepo, "Another fix", deadline.Add(2*time.Minute))
+
+	// dave's last push before the deadline was missed, so his head at the
+	// deadline is unknown
+	unknown, unknownRepo := newSubmission("dave")
+	push(unknown, unknownRepo, "Start", deadline.Add(-time.Hour))
+	server.PushAt("cs101", unknownRepo.Name, forgejotest.DefaultBranch, "Missed", deadline.Add(-time.Minute))
+
+	// erin never pushed
+	untouched, untouchedRepo := newSubmission("erin")
+	untouchedSHA := server.HeadSHA("cs101", untouchedRepo.Name, forgejotest.DefaultBranch)
+
+	// carol accepted, but her repository has no commits
+	empty := server.AddEmptyRepository("cs101", "carol")
+	absent := &model.Submission{
+		AssignmentID: assignment.ID, RepositoryName: empty.Name, RepositoryID: empty.ID,
+		RepositoryURL: empty.HTMLURL, Status: SubmissionStatusAccepted,
+	}
+	require.NoError(t, repos.Submissions.Create(ctx, absent))
+
+	t.Run("nothing is scheduled before the deadline", func(t *testing.T) {
+		svc.now = func() time.Time { return deadline.Add(-time.Second) }
+		n, err := svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	svc.now = func() time.Time { return deadline.Add(time.Minute) }
+
+	t.Run("passed deadline is scheduled once", func(t *testing.T) {
+		n, err := svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		n, err = svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		job, err := svc.queue.Dequeue(ctx, []string{JobTypeEnforceDeadline})
+		require.NoError(t, err)
+		require.NotNil(t, job)
+
+		var payload EnforceDeadlinePayload
+		require.NoError(t, job.Decode(&payload))
+		assert.Equal(t, assignment.ID, payload.AssignmentID)
+		assert.True(t, payload.Deadline.Equal(deadline))
+	})
+
+	tag := DeadlineTagName(deadline)
+
+	t.Run("repositories are tagged at their heads at the deadline", func(t *testing.T) {
+		require.NoError(t, svc.Enforce(ctx, assignment.ID, deadline))
+
+		for _, tt := range []struct {
+			submission *model.Submission
+			sha        string
+			status     string
+		}{
+			{punctual, punctualSHA, SubmissionStatusAccepted},
+			{tardy, tardySHA, SubmissionStatusLate},
+			{untouched, untouchedSHA, SubmissionStatusAccepted},
+		} {
+			tags := server.Tags("cs101", tt.submission.RepositoryName)
+			require.Len(t, tags, 1)
+			assert.Equal(t, tag, tags[0].Name)
+			assert.Equal(t, tt.sha, tags[0].Commit.SHA)
+
+			got, err := repos.Submissions.GetByID(ctx, tt.submission.ID)
+			require.NoError(t, err)
+			require.NotNil(t, got.DeadlineTag)
+			assert.Equal(t, tag, *got.DeadlineTag)
+			assert.Equal(t, tt.sha, *got.DeadlineSHA)
+			assert.False(t, got.DeadlineUnresolved)
+			assert.Equal(t, tt.status, got.Status)
+		}
+
+		got, err := repos.Submissions.GetByID(ctx, unknown.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.DeadlineTag, "unknown heads are flagged rather than tagged")
+		assert.True(t, got.DeadlineUnresolved)
+		assert.Nil(t, got.DeadlineSHA)
+		assert.Empty(t, server.Tags("cs101", "dave"))
+
+		got, err = repos.Submissions.GetByID(ctx, absent.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.DeadlineTag, "repositories without commits are recorded as enforced")
+		assert.Equal(t, tag, *got.DeadlineTag)
+		assert.Nil(t, got.DeadlineSHA)
+		assert.False(t, got.DeadlineUnresolved)
+		assert.NotNil(t, got.DeadlineTaggedAt)
+		assert.Empty(t, server.Tags("cs101", "carol"))
+
+		enforced, err := repos.Assignments.GetByID(ctx, assignment.ID)
+		require.NoError(t, err)
+		require.NotNil(t, enforced.DeadlineEnforcedFor)
+		assert.True(t, enforced.DeadlineEnforcedFor.Equal(deadline))
+	})
+
+	t.Run("enforcing again is a no-op", func(t *testing.T) {
+		require.NoError(t, svc.Enforce(ctx, assignment.ID, deadline))
+		assert.Len(t, server.Tags("cs101", "alice"), 1)
+	})
+
+	t.Run("outdated deadline is skipped", func(t *testing.T) {
+		require.NoError(t, svc.Enforce(ctx, assignment.ID, deadline.Add(-time.Hour)))
+		assert.Len(t, server.Tags("cs101", "alice"), 1)
+	})
+
+	t.Run("push after the deadline marks the submission late", func(t *testing.T) {
+		push(punctual, punctualRepo, "Polish", deadline.Add(-time.Second))
+		got, err := repos.Submissions.GetByID(ctx, punctual.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusAccepted, got.Status)
+		assert.Nil(t, got.DeadlineHeadFor)
+
+		headAtDeadline := *got.LastCommitSHA
+		push(punctual, punctualRepo, "Too late", deadline.Add(time.Hour))
+		got, err = repos.Submissions.GetByID(ctx, punctual.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusLate, got.Status)
+		require.NotNil(t, got.DeadlineHeadFor)
+		assert.True(t, got.DeadlineHeadFor.Equal(deadline))
+		assert.Equal(t, headAtDeadline, *got.DeadlineHeadSHA)
+	})
+
+	t.Run("head at the deadline is not recorded after a missed push", func(t *testing.T) {
+		push(unknown, unknownRepo, "Too late", deadline.Add(time.Hour))
+		got, err := repos.Submissions.GetByID(ctx, unknown.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusLate, got.Status)
+		assert.Nil(t, got.DeadlineHeadFor)
+	})
+}
+
+func TestDeadlineService_ScheduleDue(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Deadlines
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: "cs101/hw1-template",
+		TemplateRepositoryID: 1, Deadline: &deadline, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+	svc.now = time.Now
+
+	t.Run("a claim without a job is scheduled", func(t *testing.T) {
+		// As left by a scheduler that died between claiming and enqueueing
+		claimed, err := repos.Assignments.ClaimDeadline(ctx, assignment.ID, deadline, time.Now())
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		n, err := svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		n, err = svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "a pending job is not scheduled again")
+	})
+
+	t.Run("a dead-lettered enforcement is rescheduled", func(t *testing.T) {
+		job, err := svc.queue.Dequeue(ctx, []string{JobTypeEnforceDeadline})
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		require.NoError(t, svc.queue.Bury(ctx, job, errors.New("forgejo is unavailable")))
+
+		n, err := svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "retries wait for deadlineRetryDelay")
+
+		svc.now = func() time.Time { return time.Now().Add(deadlineRetryDelay + time.Minute) }
+		n, err = svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("an enforced deadline is not scheduled", func(t *testing.T) {
+		job, err := svc.queue.Dequeue(ctx, []string{JobTypeEnforceDeadline})
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		require.NoError(t, svc.queue.Complete(ctx, job))
+		require.NoError(t, repos.Assignments.MarkDeadlineEnforced(ctx, assignment.ID, deadline))
+
+		n, err := svc.ScheduleDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// Error is a service failure carrying an error taxonomy code that the API
+// layer can report to clients
+type Error struct {
+	Code    string
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError creates a service error with the default message for code
+func newError(code string, err error) *Error {
+	return &Error{Code: code, Message: response.GetErrorMessage(code), Err: err}
+}
+
+// validationError wraps field-level validation failures
+func validationError(err error) *Error {
+	var fields util.ValidationErrors
+	if !errors.As(err, &fields) {
+		return &Error{Code: response.ErrValidationInvalidInput, Message: err.Error()}
+	}
+	return &Error{
+		Code:    response.ErrValidationInvalidInput,
+		Message: "Request validation failed",
+		Details: map[string]interface{}{"fields": fields},
+	}
+}
+
+// notFound reports a missing resource
+func notFound(resource string) *Error {
+	return &Error{Code: response.ErrResourceNotFound, Message: resource + " not found"}
+}
+
+// AsError classifies any error returned by a service into an *Error.
+// Unknown errors become SYSTEM_INTERNAL_ERROR.
+func AsError(err error) *Error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+
+	var fields util.ValidationErrors
+	if errors.As(err, &fields) {
+		return validationError(fields)
+	}
+
+	var fjErr *forgejo.Error
+	if errors.As(err, &fjErr) {
+		svcErr := newError(fjErr.Code, err)
+		if fjErr.RetryAfter > 0 {
+			svcErr.Details = map[string]interface{}{"retry_after": int(math.Ceil(fjErr.RetryAfter.Seconds()))}
+		}
+		return svcErr
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return newError(response.ErrResourceNotFound, err)
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return newError(response.ErrResourceAlreadyExists, err)
+	case errors.Is(err, repository.ErrInvalidReference):
+		return newError(response.ErrResourceNotFound, err)
+	case errors.Is(err, auth.ErrForbidden):
+		return newError(response.ErrAuthzForbidden, err)
+	case errors.Is(err, auth.ErrInsufficientPermissions):
+		return newError(response.ErrAuthzInsufficientPermissions, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return newError(response.ErrSystemTimeout, err)
+	default:
+		return newError(response.ErrSystemInternal, err)
+	}
+}
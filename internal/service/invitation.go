@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// invitationTokenBytes is the amount of randomness in an invitation token,
+// which is all that protects an invitation from being guessed
+const invitationTokenBytes = 24
+
+// InvitationService manages the invitation links of assignments
+type InvitationService struct {
+	repos       *repository.Repositories
+	assignments *AssignmentService
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(repos *repository.Repositories, assignments *AssignmentService, logger *zap.Logger) *InvitationService {
+	return &InvitationService{
+		repos:       repos,
+		assignments: assignments,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Get returns the invitation of an assignment. Assignments have no
+// invitation until one is issued with Rotate.
+func (s *InvitationService) Get(ctx context.Context, assignmentID int64) (*model.Invitation, error) {
+	inv, err := s.repos.Invitations.Get(ctx, assignmentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Invitation")
+	}
+	return inv, err
+}
+
+// Rotate issues a new enabled invitation token for an assignment. The
+// previous token stops working immediately.
+func (s *InvitationService) Rotate(ctx context.Context, assignmentID int64, req *model.RotateInvitationRequest) (*model.Invitation, error) {
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repos.Invitations.Get(ctx, assignmentID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &model.Invitation{AssignmentID: assignmentID, Token: token, Enabled: true, ExpiresAt: expiresAt}
+	if err := s.repos.Invitations.Rotate(ctx, inv); err != nil {
+		return nil, assignmentError(err)
+	}
+	s.audit(ctx, model.AuditInvitationRotate, previous, inv)
+
+	s.logger.Info("Invitation rotated", zap.Int64("assignment_id", assignmentID))
+	return inv, nil
+}
+
+// Update enables or disables an invitation or changes its expiry
+func (s *InvitationService) Update(ctx context.Context, assignmentID int64, req *model.UpdateInvitationRequest) (*model.Invitation, error) {
+	inv, err := s.Get(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	before := *inv
+
+	if req.Enabled != nil {
+		inv.Enabled = *req.Enabled
+	}
+	if req.ExpiresAt != nil {
+		if inv.ExpiresAt, err = parseExpiry(*req.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repos.Invitations.Update(ctx, inv); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, model.AuditInvitationUpdate, &before, inv)
+	return inv, nil
+}
+
+// audit records action on the invitation of an assignment
+func (s *InvitationService) audit(ctx context.Context, action string, before, after *model.Invitation) {
+	a, err := s.repos.Assignments.GetByID(ctx, after.AssignmentID)
+	if err != nil {
+		s.logger.Error("Failed to record audit event",
+			zap.Int64("assignment_id", after.AssignmentID),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+		return
+	}
+	logAudit(ctx, s.repos, s.logger, auditEvent(a.ClassroomID, action, model.AuditTargetInvitation, a.ID), before, after)
+}
+
+// Resolve returns the public details of the assignment an invitation
+// token leads to. Disabled and expired invitations fail with
+// BUSINESS_INVITATION_CLOSED.
+func (s *InvitationService) Resolve(ctx context.Context, token string) (*model.InvitationDetails, error) {
+	inv, err := s.open(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.repos.Assignments.GetByID(ctx, inv.AssignmentID)
+	if err != nil {
+		return nil, assignmentError(err)
+	}
+	classroom, err := s.repos.Classrooms.GetByID(ctx, assignment.ClassroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+
+	return &model.InvitationDetails{
+		ClassroomName:    classroom.Name,
+		OrganizationName: classroom.OrganizationName,
+		AssignmentName:   assignment.Name,
+		AssignmentSlug:   assignment.Slug,
+		Description:      assignment.Description,
+		Deadline:         assignment.Deadline,
+		MaxTeamSize:      assignment.MaxTeamSize,
+		ExpiresAt:        inv.ExpiresAt,
+	}, nil
+}
+
+// Accept accepts the assignment of an invitation token as actor, like
+// AssignmentService.Accept
+func (s *InvitationService) Accept(ctx context.Context, actor *Actor, token string, req *model.AcceptAssignmentRequest) (*model.Submission, error) {
+	if actor == nil {
+		return nil, newError(response.ErrAuthMissingToken, nil)
+	}
+	inv, err := s.open(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.assignments.Accept(ctx, actor, inv.AssignmentID, req)
+}
+
+// open returns the invitation with token if it can be used now
+func (s *InvitationService) open(ctx context.Context, token string) (*model.Invitation, error) {
+	inv, err := s.repos.Invitations.GetByToken(ctx, token)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Invitation")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var details map[string]interface{}
+	switch {
+	case !inv.Enabled:
+		details = map[string]interface{}{"reason": "disabled"}
+	case inv.ExpiresAt != nil && !s.now().Before(*inv.ExpiresAt):
+		details = map[string]interface{}{"reason": "expired", "expires_at": inv.ExpiresAt}
+	default:
+		return inv, nil
+	}
+	return nil, &Error{
+		Code:    response.ErrBusinessInvitationClosed,
+		Message: response.GetErrorMessage(response.ErrBusinessInvitationClosed),
+		Details: details,
+	}
+}
+
+// parseExpiry parses the RFC3339 expiry of an invitation, which must be in
+// the future; an empty value means no expiry
+func parseExpiry(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	v := util.NewValidator()
+	v.ValidateDateTime("expires_at", value, "Expiry")
+	v.ValidateFutureDate("expires_at", value, "Expiry")
+	if v.HasErrors() {
+		return nil, validationError(v.Errors())
+	}
+	t, _ := time.Parse(time.RFC3339, value)
+	return &t, nil
+}
+
+// newInvitationToken returns a random URL-safe token
+func newInvitationToken() (string, error) {
+	b := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func TestParseExpiry(t *testing.T) {
+	expiry, err := parseExpiry("")
+	require.NoError(t, err)
+	assert.Nil(t, expiry)
+
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	expiry, err = parseExpiry(future.Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.True(t, future.Equal(*expiry))
+
+	for _, value := range []string{"tomorrow", time.Now().Add(-time.Hour).Format(time.RFC3339)} {
+		_, err := parseExpiry(value)
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code, value)
+	}
+}
+
+func TestNewInvitationToken(t *testing.T) {
+	first, err := newInvitationToken()
+	require.NoError(t, err)
+	second, err := newInvitationToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+	assert.Regexp(t, `^[A-Za-z0-9_-]+$`, first)
+}
+
+func TestInvitationService(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Invitations
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	template := server.AddRepository("cs101", "hw1-template", true)
+	student := server.AddUser("jdoe", "jdoe-token")
+
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	login := student.Login
+	require.NoError(t, repos.Roster.Create(ctx, &model.RosterEntry{
+		ClassroomID: classroom.ID, StudentName: "John Doe", StudentEmail: "john@example.com",
+		StudentID: "john123", ForgejoUsername: &login, ForgejoUserID: &student.ID, Role: "student",
+	}))
+
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: template.FullName,
+		TemplateRepositoryID: template.ID, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	t.Run("assignments start without an invitation", func(t *testing.T) {
+		_, err := svc.Get(ctx, assignment.ID)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	inv, err := svc.Rotate(ctx, assignment.ID, &model.RotateInvitationRequest{})
+	require.NoError(t, err)
+	assert.True(t, inv.Enabled)
+
+	t.Run("resolve shows the assignment", func(t *testing.T) {
+		details, err := svc.Resolve(ctx, inv.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "CS 101", details.ClassroomName)
+		assert.Equal(t, "Homework 1", details.AssignmentName)
+
+		_, err = svc.Resolve(ctx, "unknown")
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	t.Run("rotating invalidates the previous token", func(t *testing.T) {
+		rotated, err := svc.Rotate(ctx, assignment.ID, &model.RotateInvitationRequest{})
+		require.NoError(t, err)
+		assert.NotEqual(t, inv.Token, rotated.Token)
+
+		_, err = svc.Resolve(ctx, inv.Token)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+		inv = rotated
+	})
+
+	t.Run("disabled and expired invitations are closed", func(t *testing.T) {
+		disabled := false
+		_, err := svc.Update(ctx, assignment.ID, &model.UpdateInvitationRequest{Enabled: &disabled})
+		require.NoError(t, err)
+		_, err = svc.Accept(ctx, &Actor{ID: student.ID, Login: student.Login}, inv.Token, nil)
+		closed := AsError(err)
+		assert.Equal(t, response.ErrBusinessInvitationClosed, closed.Code)
+		assert.Equal(t, "disabled", closed.Details["reason"])
+
+		enabled := true
+		expiry := time.Now().Add(time.Hour).Format(time.RFC3339)
+		_, err = svc.Update(ctx, assignment.ID, &model.UpdateInvitationRequest{Enabled: &enabled, ExpiresAt: &expiry})
+		require.NoError(t, err)
+		svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { svc.now = time.Now }()
+
+		_, err = svc.Resolve(ctx, inv.Token)
+		closed = AsError(err)
+		assert.Equal(t, response.ErrBusinessInvitationClosed, closed.Code)
+		assert.Equal(t, "expired", closed.Details["reason"])
+	})
+
+	t.Run("accept with the token", func(t *testing.T) {
+		submission, err := svc.Accept(ctx, &Actor{ID: student.ID, Login: student.Login}, inv.Token, nil)
+		require.NoError(t, err)
+		assert.Equal(t, assignment.ID, submission.AssignmentID)
+	})
+}
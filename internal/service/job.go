@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+)
+
+// JobService reports the progress of background jobs
+type JobService struct {
+	repos *repository.Repositories
+	queue queue.Queue
+}
+
+// NewJobService creates a new job service
+func NewJobService(repos *repository.Repositories, q queue.Queue) *JobService {
+	return &JobService{
+		repos: repos,
+		queue: q,
+	}
+}
+
+// Get returns a job with its result and error count. The classroom it
+// belongs to, if any, is returned for authorization.
+func (s *JobService) Get(ctx context.Context, id int64) (*model.Job, int64, error) {
+	job, err := s.queue.Get(ctx, id)
+	if err != nil {
+		return nil, 0, jobError(err)
+	}
+
+	errorCount, err := s.repos.JobErrors.Count(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &model.Job{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		Result:      job.Result,
+		ErrorCount:  errorCount,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		FinishedAt:  job.FinishedAt,
+	}, jobClassroomID(job), nil
+}
+
+// ListErrors returns one page of the per-row failures of a job
+func (s *JobService) ListErrors(ctx context.Context, id int64, req *model.JobErrorListRequest) (*model.JobErrorListResponse, error) {
+	return s.repos.JobErrors.List(ctx, id, req)
+}
+
+// jobClassroomID returns the classroom_id of a job payload, or 0 for jobs
+// that do not belong to a classroom
+func jobClassroomID(job *queue.Job) int64 {
+	var scope struct {
+		ClassroomID int64 `json:"classroom_id"`
+	}
+	if err := json.Unmarshal(job.Payload, &scope); err != nil {
+		return 0
+	}
+	return scope.ClassroomID
+}
+
+// jobError reports a missing job as RESOURCE_NOT_FOUND
+func jobError(err error) error {
+	if errors.Is(err, queue.ErrNotFound) {
+		return notFound("Job")
+	}
+	return err
+}
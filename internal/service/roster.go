@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// JobTypeImportRoster imports roster rows too many to handle in a request
+const JobTypeImportRoster = "roster.import"
+
+// RosterImportJobRows is the size from which roster files are imported
+// by a background job rather than within the request
+const RosterImportJobRows = 100
+
+// defaultRosterRole is assigned to imported rows without a role
+const defaultRosterRole = "student"
+
+// ImportRosterPayload is the payload of JobTypeImportRoster
+type ImportRosterPayload struct {
+	ClassroomID int64                   `json:"classroom_id"`
+	Rows        []model.RosterImportRow `json:"rows"`
+	Update      bool                    `json:"update"`
+	DryRun      bool                    `json:"dry_run"`
+	Origin      audit.Origin            `json:"origin"` // the request that queued the import
+}
+
+// RosterService manages classroom rosters
+type RosterService struct {
+	repos   *repository.Repositories
+	forgejo *forgejo.Client
+	queue   queue.Queue
+	cache   *cache.Store
+	logger  *zap.Logger
+}
+
+// NewRosterService creates a new roster service
+func NewRosterService(repos *repository.Repositories, fj *forgejo.Client, q queue.Queue, store *cache.Store, logger *zap.Logger) *RosterService {
+	return &RosterService{
+		repos:   repos,
+		forgejo: fj,
+		queue:   q,
+		cache:   store,
+		logger:  logger,
+	}
+}
+
+// List returns one page of a classroom's roster, ordered by name
+func (s *RosterService) List(ctx context.Context, classroomID int64, req *model.RosterListRequest) (*model.RosterListResponse, error) {
+	if req.LinkedOnly && req.UnlinkedOnly {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "unlinked_only",
+			Message: "linked_only and unlinked_only are mutually exclusive",
+			Code:    response.ErrValidationInvalidInput,
+		}})
+	}
+
+	key := cache.RosterListKey(classroomID, req)
+	var list *model.RosterListResponse
+	if s.cache.Get(ctx, key, &list) {
+		return list, nil
+	}
+
+	list, err := s.repos.Roster.List(ctx, classroomID, req)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, key, list, s.cache.Config().RosterTTL)
+	return list, nil
+}
+
+// invalidateRoster drops the cached roster pages of a classroom and the
+// classroom listings, which depend on who is linked to it. Removed
+// students take their submissions along, which are cached by their own ID.
+func (s *RosterService) invalidateRoster(ctx context.Context, classroomID int64, removed bool) {
+	keys := []string{cache.RosterPattern(classroomID), cache.ClassroomListPattern}
+	if removed {
+		keys = append(keys, cache.SubmissionPattern)
+	}
+	s.cache.Invalidate(ctx, keys...)
+}
+
+// RegisterJobs registers the roster job handlers with pool
+func (s *RosterService) RegisterJobs(pool *queue.Pool) {
+	pool.Register(JobTypeImportRoster, queue.Typed(s.importJob))
+}
+
+// Import adds the students of a roster CSV file to a classroom. Students
+// already on the roster (by student ID) are skipped, or updated when
+// req.Update is set; with req.DryRun nothing is written.
+//
+// Files of fewer than RosterImportJobRows students are imported right away.
+// Larger files are queued and the response carries the job to poll; its
+// per-row failures are listed by JobService.ListErrors.
+func (s *RosterService) Import(ctx context.Context, classroomID int64, file io.Reader, req *model.ImportRosterRequest) (*model.ImportRosterResponse, error) {
+	rows, err := parseRosterCSV(file, req.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) < RosterImportJobRows {
+		result, err := s.importRows(ctx, classroomID, rows, req.Update, req.DryRun, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &model.ImportRosterResponse{Status: queue.StatusSucceeded, Result: result}, nil
+	}
+
+	if _, err := s.repos.Classrooms.GetByID(ctx, classroomID); err != nil {
+		return nil, classroomError(err)
+	}
+
+	job, err := queue.NewJob(JobTypeImportRoster, ImportRosterPayload{
+		ClassroomID: classroomID,
+		Rows:        rows,
+		Update:      req.Update,
+		DryRun:      req.DryRun,
+		Origin:      audit.OriginFrom(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Roster import queued",
+		zap.Int64("classroom_id", classroomID),
+		zap.Int("rows", len(rows)),
+		zap.Int64("job_id", job.ID),
+	)
+	return &model.ImportRosterResponse{Status: job.Status, JobID: &job.ID}, nil
+}
+
+// importJob is the JobTypeImportRoster handler. Row failures are stored as
+// job errors and the summary becomes the job result.
+func (s *RosterService) importJob(ctx context.Context, job *queue.Job, p ImportRosterPayload) error {
+	// Audit the import as a change made by the request that queued it
+	ctx = audit.WithOrigin(ctx, p.Origin)
+	result, err := s.importRows(ctx, p.ClassroomID, p.Rows, p.Update, p.DryRun, job.ID)
+	if err != nil {
+		if AsError(err).Code == response.ErrResourceNotFound {
+			return queue.Permanent(err)
+		}
+		return err
+	}
+
+	result.Errors = nil
+	return job.SetResult(result)
+}
+
+// importRows validates rows and writes the valid ones in one transaction.
+// When jobID is set the row failures are recorded as that job's errors in
+// the same transaction.
+func (s *RosterService) importRows(ctx context.Context, classroomID int64, rows []model.RosterImportRow, update, dryRun bool, jobID int64) (*model.RosterImportResult, error) {
+	if _, err := s.repos.Classrooms.GetByID(ctx, classroomID); err != nil {
+		return nil, classroomError(err)
+	}
+
+	imp := &rosterImport{
+		classroomID: classroomID,
+		update:      update,
+		dryRun:      dryRun,
+		failed:      make(map[int]bool),
+		result:      &model.RosterImportResult{Total: len(rows), DryRun: dryRun},
+	}
+	valid := imp.validate(rows)
+
+	run := func(repos *repository.Repositories) error {
+		for i := range valid {
+			if err := imp.apply(ctx, repos, &valid[i]); err != nil {
+				return err
+			}
+		}
+		imp.finish()
+		if jobID != 0 {
+			return repos.JobErrors.Replace(ctx, jobID, imp.result.Errors)
+		}
+		return nil
+	}
+
+	var err error
+	if dryRun {
+		err = run(s.repos)
+	} else {
+		err = s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+			// Serialize imports into the same classroom
+			if err := tx.AdvisoryLock(ctx, fmt.Sprintf("roster:%d", classroomID)); err != nil {
+				return err
+			}
+			return run(tx)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun && imp.result.Created+imp.result.Updated > 0 {
+		s.invalidateRoster(ctx, classroomID, false)
+	}
+
+	s.logger.Info("Roster imported",
+		zap.Int64("classroom_id", classroomID),
+		zap.Bool("dry_run", dryRun),
+		zap.Int("created", imp.result.Created),
+		zap.Int("updated", imp.result.Updated),
+		zap.Int("skipped", imp.result.Skipped),
+		zap.Int("failed", imp.result.Failed),
+	)
+	return imp.result, nil
+}
+
+// rosterImport tracks the progress of one import
+type rosterImport struct {
+	classroomID int64
+	update      bool
+	dryRun      bool
+	failed      map[int]bool
+	result      *model.RosterImportResult
+}
+
+// fail records a failure of row
+func (imp *rosterImport) fail(row int, field, code, message string) {
+	imp.failed[row] = true
+	imp.result.Errors = append(imp.result.Errors, model.JobError{Row: row, Field: field, Code: code, Message: message})
+}
+
+// validate checks every row on its own and against the rows before it,
+// returning the rows that passed
+func (imp *rosterImport) validate(rows []model.RosterImportRow) []model.RosterImportRow {
+	seenIDs := make(map[string]int)
+	seenEmails := make(map[string]int)
+
+	valid := make([]model.RosterImportRow, 0, len(rows))
+	for _, row := range rows {
+		var errs util.ValidationErrors
+		errors.As(row.Validate(), &errs)
+
+		email := strings.ToLower(row.StudentEmail)
+		if len(errs) == 0 {
+			if first, ok := seenIDs[row.StudentID]; ok {
+				errs = append(errs, util.ValidationError{
+					Field:   "student_id",
+					Message: fmt.Sprintf("Student ID %s is also on row %d", row.StudentID, first),
+					Code:    response.ErrValidationInvalidInput,
+				})
+			}
+			if first, ok := seenEmails[email]; ok {
+				errs = append(errs, util.ValidationError{
+					Field:   "student_email",
+					Message: fmt.Sprintf("Email %s is also on row %d", row.StudentEmail, first),
+					Code:    response.ErrValidationInvalidInput,
+				})
+			}
+		}
+
+		if len(errs) > 0 {
+			for _, e := range errs {
+				imp.fail(row.Row, e.Field, e.Code, e.Message)
+			}
+			continue
+		}
+		seenIDs[row.StudentID] = row.Row
+		seenEmails[email] = row.Row
+		valid = append(valid, row)
+	}
+	return valid
+}
+
+// apply creates, updates or skips the roster entry for a valid row
+func (imp *rosterImport) apply(ctx context.Context, repos *repository.Repositories, row *model.RosterImportRow) error {
+	existing, err := repos.Roster.GetByStudentID(ctx, imp.classroomID, row.StudentID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		existing = nil
+	case err != nil:
+		return err
+	}
+
+	if existing != nil && (!imp.update || !rosterRowChanges(existing, row)) {
+		imp.result.Skipped++
+		return nil
+	}
+
+	owner, err := repos.Roster.GetByEmail(ctx, imp.classroomID, row.StudentEmail)
+	switch {
+	case err == nil && (existing == nil || owner.ID != existing.ID):
+		imp.fail(row.Row, "student_email", response.ErrResourceAlreadyExists,
+			fmt.Sprintf("Email %s is already used by student %s", row.StudentEmail, owner.StudentID))
+		return nil
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return err
+	}
+
+	if existing == nil {
+		role := row.Role
+		if role == "" {
+			role = defaultRosterRole
+		}
+		entry := &model.RosterEntry{
+			ClassroomID:  imp.classroomID,
+			StudentName:  row.StudentName,
+			StudentEmail: row.StudentEmail,
+			StudentID:    row.StudentID,
+			Role:         role,
+		}
+		if !imp.dryRun {
+			if err := repos.Roster.Create(ctx, entry); err != nil {
+				return err
+			}
+			if err := auditRoster(ctx, repos, model.AuditRosterAdd, nil, entry); err != nil {
+				return err
+			}
+		}
+		imp.result.Created++
+		return nil
+	}
+
+	before := *existing
+	existing.StudentName = row.StudentName
+	existing.StudentEmail = row.StudentEmail
+	if row.Role != "" {
+		existing.Role = row.Role
+	}
+	if !imp.dryRun {
+		if err := repos.Roster.Update(ctx, existing); err != nil {
+			return err
+		}
+		if err := auditRoster(ctx, repos, model.AuditRosterUpdate, &before, existing); err != nil {
+			return err
+		}
+	}
+	imp.result.Updated++
+	return nil
+}
+
+// finish fills in the failure count and orders the errors by row
+func (imp *rosterImport) finish() {
+	imp.result.Failed = len(imp.failed)
+	sort.SliceStable(imp.result.Errors, func(i, j int) bool {
+		return imp.result.Errors[i].Row < imp.result.Errors[j].Row
+	})
+}
+
+// rosterRowChanges reports whether importing row would change entry
+func rosterRowChanges(entry *model.RosterEntry, row *model.RosterImportRow) bool {
+	return entry.StudentName != row.StudentName ||
+		entry.StudentEmail != row.StudentEmail ||
+		(row.Role != "" && entry.Role != row.Role)
+}
+
+// Roster CSV columns
+const (
+	rosterColumnName  = "name"
+	rosterColumnEmail = "email"
+	rosterColumnID    = "identifier"
+	rosterColumnRole  = "role"
+)
+
+// defaultRosterColumns is the column order of files without a header row
+var defaultRosterColumns = []string{rosterColumnName, rosterColumnEmail, rosterColumnID, rosterColumnRole}
+
+// rosterColumnAliases maps accepted header names to columns
+var rosterColumnAliases = map[string]string{
+	"name":          rosterColumnName,
+	"student_name":  rosterColumnName,
+	"full_name":     rosterColumnName,
+	"email":         rosterColumnEmail,
+	"student_email": rosterColumnEmail,
+	"identifier":    rosterColumnID,
+	"id":            rosterColumnID,
+	"student_id":    rosterColumnID,
+	"role":          rosterColumnRole,
+}
+
+// parseRosterCSV reads the students of a roster file. The first line is
+// treated as a header if all of its cells are known column names;
+// otherwise the columns are name, email, identifier and an optional role.
+func parseRosterCSV(file io.Reader, delimiter string) ([]model.RosterImportRow, error) {
+	comma, err := parseDelimiter(delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := defaultRosterColumns
+	var rows []model.RosterImportRow
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidFile(fmt.Sprintf("Invalid CSV: %v", err))
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if header, ok := parseRosterHeader(record); ok {
+				if missing := missingRosterColumns(header); len(missing) > 0 {
+					return nil, invalidFile("Missing required columns: " + strings.Join(missing, ", "))
+				}
+				columns = header
+				continue
+			}
+		}
+
+		row := model.RosterImportRow{Row: line}
+		blank := true
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				blank = false
+			}
+			if i >= len(columns) {
+				continue
+			}
+			switch columns[i] {
+			case rosterColumnName:
+				row.StudentName = value
+			case rosterColumnEmail:
+				row.StudentEmail = value
+			case rosterColumnID:
+				row.StudentID = value
+			case rosterColumnRole:
+				row.Role = strings.ToLower(value)
+			}
+		}
+		if !blank {
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, invalidFile("The file contains no students")
+	}
+	return rows, nil
+}
+
+// parseRosterHeader maps a header record to columns. It returns false if
+// the record is not a header.
+func parseRosterHeader(record []string) ([]string, bool) {
+	columns := make([]string, len(record))
+	for i, cell := range record {
+		name := strings.ToLower(strings.TrimSpace(cell))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		column, ok := rosterColumnAliases[name]
+		if !ok {
+			return nil, false
+		}
+		columns[i] = column
+	}
+	return columns, true
+}
+
+// missingRosterColumns returns the required columns absent from a header
+func missingRosterColumns(columns []string) []string {
+	var missing []string
+	for _, required := range []string{rosterColumnName, rosterColumnEmail, rosterColumnID} {
+		found := false
+		for _, c := range columns {
+			if c == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// parseDelimiter returns the CSV delimiter named by value: a single
+// character, "\t" or "tab". The default is a comma.
+func parseDelimiter(value string) (rune, error) {
+	switch value {
+	case "":
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, validationError(util.ValidationErrors{{
+			Field:   "delimiter",
+			Message: "Delimiter must be a single character other than a quote or newline",
+			Code:    response.ErrValidationInvalidFormat,
+		}})
+	}
+	return r, nil
+}
+
+// invalidFile reports a roster file that cannot be read
+func invalidFile(message string) error {
+	return validationError(util.ValidationErrors{{
+		Field:   "file",
+		Message: message,
+		Code:    response.ErrValidationInvalidFormat,
+	}})
+}
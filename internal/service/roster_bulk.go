@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// errBulkAborted ends an all-or-nothing transaction after a failed operation
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// Bulk applies a list of add, update, remove and link operations to a
+// classroom roster and reports the outcome of each one.
+//
+// In best_effort mode (the default) every operation is applied on its own
+// and failures do not affect the others. In all_or_nothing mode the
+// operations run in one transaction that stops and rolls back at the first
+// failure; earlier operations are then reported as rolled_back and later
+// ones as skipped.
+func (s *RosterService) Bulk(ctx context.Context, classroomID int64, req *model.BulkRosterRequest) (*model.BulkRosterResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+	classroom, err := s.repos.Classrooms.GetByID(ctx, classroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+
+	results := make([]model.RosterOperationResult, len(req.Operations))
+	for i := range results {
+		results[i] = model.RosterOperationResult{Index: i, Status: model.BulkStatusSkipped}
+	}
+
+	rolledBack := false
+	if req.Mode == model.BulkModeAllOrNothing {
+		failedAt := -1
+		err := s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+			if err := tx.AdvisoryLock(ctx, fmt.Sprintf("roster:%d", classroomID)); err != nil {
+				return err
+			}
+			for i := range req.Operations {
+				results[i] = s.runOperation(ctx, tx, classroom, i, &req.Operations[i])
+				if results[i].Status == model.BulkStatusError {
+					failedAt = i
+					return errBulkAborted
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errBulkAborted) {
+			return nil, err
+		}
+		if failedAt >= 0 {
+			rolledBack = true
+			for i := 0; i < failedAt; i++ {
+				results[i] = model.RosterOperationResult{Index: i, Status: model.BulkStatusRolledBack}
+			}
+		}
+	} else {
+		for i := range req.Operations {
+			results[i] = s.runOperation(ctx, s.repos, classroom, i, &req.Operations[i])
+		}
+	}
+
+	summary := model.BulkRosterSummary{Total: len(results), RolledBack: rolledBack}
+	removed := false
+	for i, r := range results {
+		if r.Status == model.BulkStatusSuccess {
+			summary.Succeeded++
+			removed = removed || req.Operations[i].Action == model.RosterActionRemove
+		}
+	}
+	summary.Failed = summary.Total - summary.Succeeded
+	if summary.Succeeded > 0 {
+		s.invalidateRoster(ctx, classroomID, removed)
+	}
+
+	s.logger.Info("Bulk roster operations applied",
+		zap.Int64("classroom_id", classroomID),
+		zap.String("mode", req.Mode),
+		zap.Int("succeeded", summary.Succeeded),
+		zap.Int("failed", summary.Failed),
+	)
+	return &model.BulkRosterResponse{Results: results, Summary: summary}, nil
+}
+
+// Add adds one student to a classroom roster, as a bulk add operation would
+func (s *RosterService) Add(ctx context.Context, classroomID int64, req *model.AddStudentRequest) (*model.RosterEntry, error) {
+	return s.applySingle(ctx, classroomID, &model.RosterOperation{
+		Action:       model.RosterActionAdd,
+		StudentID:    req.StudentID,
+		StudentName:  req.StudentName,
+		StudentEmail: req.StudentEmail,
+		Role:         req.Role,
+	})
+}
+
+// Link links the roster entry with studentID to a Forgejo account, as a
+// bulk link operation without force would
+func (s *RosterService) Link(ctx context.Context, classroomID int64, studentID string, req *model.LinkStudentRequest) (*model.RosterEntry, error) {
+	return s.applySingle(ctx, classroomID, &model.RosterOperation{
+		Action:          model.RosterActionLink,
+		StudentID:       studentID,
+		ForgejoUsername: req.ForgejoUsername,
+	})
+}
+
+// applySingle validates and applies op on its own and returns the roster
+// entry it affected
+func (s *RosterService) applySingle(ctx context.Context, classroomID int64, op *model.RosterOperation) (*model.RosterEntry, error) {
+	if err := op.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+	if _, err := s.repos.Classrooms.GetByID(ctx, classroomID); err != nil {
+		return nil, classroomError(err)
+	}
+
+	id, err := s.applyOperation(ctx, s.repos, classroomID, op)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateRoster(ctx, classroomID, false)
+	return s.repos.Roster.GetByID(ctx, classroomID, *id)
+}
+
+// runOperation validates and applies one operation and reports its result
+func (s *RosterService) runOperation(ctx context.Context, repos *repository.Repositories, classroom *model.Classroom, index int, op *model.RosterOperation) model.RosterOperationResult {
+	result := model.RosterOperationResult{Index: index}
+
+	var id *int64
+	err := op.Validate()
+	if err == nil {
+		id, err = s.applyOperation(ctx, repos, classroom.ID, op)
+	}
+	if err != nil {
+		svcErr := AsError(err)
+		if response.StatusForCode(svcErr.Code) >= http.StatusInternalServerError {
+			s.logger.Error("Bulk roster operation failed",
+				zap.Int64("classroom_id", classroom.ID),
+				zap.Int("index", index),
+				zap.String("action", op.Action),
+				zap.Error(err),
+			)
+		}
+		result.Status = model.BulkStatusError
+		result.Error = &model.OperationError{Code: svcErr.Code, Message: svcErr.Message, Details: svcErr.Details}
+		return result
+	}
+
+	result.Status = model.BulkStatusSuccess
+	result.ID = id
+	return result
+}
+
+// applyOperation dispatches op to its action and returns the ID of the
+// affected roster entry, or nil for removals
+func (s *RosterService) applyOperation(ctx context.Context, repos *repository.Repositories, classroomID int64, op *model.RosterOperation) (*int64, error) {
+	switch op.Action {
+	case model.RosterActionAdd:
+		return s.addStudent(ctx, repos, classroomID, op)
+	case model.RosterActionUpdate:
+		return s.updateStudent(ctx, repos, classroomID, op)
+	case model.RosterActionRemove:
+		return nil, s.removeStudent(ctx, repos, classroomID, op)
+	case model.RosterActionLink:
+		return s.linkStudent(ctx, repos, classroomID, op)
+	default:
+		return nil, fmt.Errorf("unknown roster action %q", op.Action)
+	}
+}
+
+// addStudent creates a roster entry
+func (s *RosterService) addStudent(ctx context.Context, repos *repository.Repositories, classroomID int64, op *model.RosterOperation) (*int64, error) {
+	if _, err := repos.Roster.GetByStudentID(ctx, classroomID, op.StudentID); err == nil {
+		return nil, rosterExists(fmt.Sprintf("Student %s is already on the roster", op.StudentID))
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err := checkEmailAvailable(ctx, repos, classroomID, op.StudentEmail, 0); err != nil {
+		return nil, err
+	}
+
+	role := op.Role
+	if role == "" {
+		role = defaultRosterRole
+	}
+	entry := &model.RosterEntry{
+		ClassroomID:  classroomID,
+		StudentName:  strings.TrimSpace(op.StudentName),
+		StudentEmail: op.StudentEmail,
+		StudentID:    op.StudentID,
+		Role:         role,
+	}
+	if err := repos.Roster.Create(ctx, entry); err != nil {
+		return nil, rosterWriteError(err)
+	}
+	if err := auditRoster(ctx, repos, model.AuditRosterAdd, nil, entry); err != nil {
+		return nil, err
+	}
+	return &entry.ID, nil
+}
+
+// updateStudent changes the name, email or role of a roster entry
+func (s *RosterService) updateStudent(ctx context.Context, repos *repository.Repositories, classroomID int64, op *model.RosterOperation) (*int64, error) {
+	entry, err := getRosterEntry(ctx, repos, classroomID, op.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	before := *entry
+
+	if name := strings.TrimSpace(op.StudentName); name != "" {
+		entry.StudentName = name
+	}
+	if op.StudentEmail != "" {
+		if err := checkEmailAvailable(ctx, repos, classroomID, op.StudentEmail, entry.ID); err != nil {
+			return nil, err
+		}
+		entry.StudentEmail = op.StudentEmail
+	}
+	if op.Role != "" {
+		entry.Role = op.Role
+	}
+
+	if err := repos.Roster.Update(ctx, entry); err != nil {
+		return nil, rosterWriteError(err)
+	}
+	if err := auditRoster(ctx, repos, model.AuditRosterUpdate, &before, entry); err != nil {
+		return nil, err
+	}
+	return &entry.ID, nil
+}
+
+// removeStudent deletes a roster entry along with its submissions
+func (s *RosterService) removeStudent(ctx context.Context, repos *repository.Repositories, classroomID int64, op *model.RosterOperation) error {
+	entry, err := getRosterEntry(ctx, repos, classroomID, op.StudentID)
+	if err != nil {
+		return err
+	}
+
+	err = repos.Roster.Delete(ctx, classroomID, entry.ID)
+	if errors.Is(err, repository.ErrInvalidReference) {
+		return &Error{
+			Code:    response.ErrResourceConflict,
+			Message: fmt.Sprintf("Student %s leads a team and cannot be removed", op.StudentID),
+			Err:     err,
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return auditRoster(ctx, repos, model.AuditRosterRemove, entry, nil)
+}
+
+// linkStudent binds a roster entry to a Forgejo account. An entry that is
+// already linked to another account is only relinked with op.Force.
+func (s *RosterService) linkStudent(ctx context.Context, repos *repository.Repositories, classroomID int64, op *model.RosterOperation) (*int64, error) {
+	entry, err := getRosterEntry(ctx, repos, classroomID, op.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.forgejo.GetUser(ctx, op.ForgejoUsername)
+	if forgejo.IsNotFound(err) {
+		return nil, &Error{
+			Code:    response.ErrResourceNotFound,
+			Message: fmt.Sprintf("Forgejo user %s not found", op.ForgejoUsername),
+			Err:     err,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.ForgejoUserID != nil && *entry.ForgejoUserID == user.ID {
+		return &entry.ID, nil
+	}
+	if entry.IsLinked() && !op.Force {
+		return nil, &Error{
+			Code:    response.ErrResourceConflict,
+			Message: fmt.Sprintf("Student %s is already linked to %s", op.StudentID, *entry.ForgejoUsername),
+		}
+	}
+
+	if err := checkForgejoUserAvailable(ctx, repos, classroomID, user.ID, user.Login, entry.ID); err != nil {
+		return nil, err
+	}
+	if err := linkEntry(ctx, repos, entry, user.ID, user.Login, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return &entry.ID, nil
+}
+
+// linkEntry binds entry to a Forgejo account as of now
+func linkEntry(ctx context.Context, repos *repository.Repositories, entry *model.RosterEntry, userID int64, login string, now time.Time) error {
+	before := *entry
+	entry.ForgejoUsername = &login
+	entry.ForgejoUserID = &userID
+	entry.LinkedAt = &now
+	if err := repos.Roster.Update(ctx, entry); err != nil {
+		return rosterWriteError(err)
+	}
+	return auditRoster(ctx, repos, model.AuditRosterLink, &before, entry)
+}
+
+// auditRoster records action on a roster entry, which is nil before it
+// was added and after it was removed
+func auditRoster(ctx context.Context, repos *repository.Repositories, action string, before, after *model.RosterEntry) error {
+	entry := after
+	if entry == nil {
+		entry = before
+	}
+	return recordAudit(ctx, repos, auditEvent(entry.ClassroomID, action, model.AuditTargetRosterEntry, entry.ID), before, after)
+}
+
+// getRosterEntry returns the entry with a student ID, or RESOURCE_NOT_FOUND
+func getRosterEntry(ctx context.Context, repos *repository.Repositories, classroomID int64, studentID string) (*model.RosterEntry, error) {
+	entry, err := repos.Roster.GetByStudentID(ctx, classroomID, studentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, &Error{Code: response.ErrResourceNotFound, Message: fmt.Sprintf("Student %s not found", studentID)}
+	}
+	return entry, err
+}
+
+// checkEmailAvailable fails if email belongs to a roster entry other than exceptID
+func checkEmailAvailable(ctx context.Context, repos *repository.Repositories, classroomID int64, email string, exceptID int64) error {
+	owner, err := repos.Roster.GetByEmail(ctx, classroomID, email)
+	switch {
+	case err == nil && owner.ID != exceptID:
+		return rosterExists(fmt.Sprintf("Email %s is already used by student %s", email, owner.StudentID))
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return err
+	}
+	return nil
+}
+
+// checkForgejoUserAvailable fails if a Forgejo account is linked to a
+// roster entry other than exceptID
+func checkForgejoUserAvailable(ctx context.Context, repos *repository.Repositories, classroomID, userID int64, login string, exceptID int64) error {
+	other, err := repos.Roster.GetByForgejoUserID(ctx, classroomID, userID)
+	switch {
+	case err == nil && other.ID != exceptID:
+		return rosterExists(fmt.Sprintf("Forgejo user %s is already linked to student %s", login, other.StudentID))
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		return err
+	}
+	return nil
+}
+
+// rosterExists reports a roster uniqueness conflict
+func rosterExists(message string) *Error {
+	return &Error{Code: response.ErrResourceAlreadyExists, Message: message}
+}
+
+// rosterWriteError reports a unique violation that slipped past the checks
+// (a concurrent write) as RESOURCE_ALREADY_EXISTS
+func rosterWriteError(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return &Error{Code: response.ErrResourceAlreadyExists, Message: "Roster entry conflicts with an existing one", Err: err}
+	}
+	return err
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+// GetJoinLink returns the join link of a classroom. Classrooms have no
+// join link until one is issued with RotateJoinLink.
+func (s *RosterService) GetJoinLink(ctx context.Context, classroomID int64) (*model.JoinLink, error) {
+	link, err := s.repos.JoinLinks.Get(ctx, classroomID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Join link")
+	}
+	return link, err
+}
+
+// RotateJoinLink issues a new enabled join link token for a classroom. The
+// previous token stops working immediately; pending claims are kept.
+//
+// Claims need approval unless staff turn it off: without approval, anyone
+// with the link who knows a student's ID or email can claim their entry.
+func (s *RosterService) RotateJoinLink(ctx context.Context, classroomID int64, req *model.RotateJoinLinkRequest) (*model.JoinLink, error) {
+	previous, err := s.repos.JoinLinks.Get(ctx, classroomID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &model.JoinLink{ClassroomID: classroomID, Token: token, Enabled: true, RequiresApproval: true}
+	if req.RequiresApproval != nil {
+		link.RequiresApproval = *req.RequiresApproval
+	} else if previous != nil {
+		link.RequiresApproval = previous.RequiresApproval
+	}
+	if err := s.repos.JoinLinks.Rotate(ctx, link); err != nil {
+		return nil, classroomError(err)
+	}
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(classroomID, model.AuditJoinLinkRotate, model.AuditTargetJoinLink, classroomID), previous, link)
+
+	s.logger.Info("Join link rotated", zap.Int64("classroom_id", classroomID))
+	return link, nil
+}
+
+// UpdateJoinLink enables or disables a join link or changes whether its
+// claims need approval. Claims already pending stay pending.
+func (s *RosterService) UpdateJoinLink(ctx context.Context, classroomID int64, req *model.UpdateJoinLinkRequest) (*model.JoinLink, error) {
+	link, err := s.GetJoinLink(ctx, classroomID)
+	if err != nil {
+		return nil, err
+	}
+	before := *link
+
+	if req.Enabled != nil {
+		link.Enabled = *req.Enabled
+	}
+	if req.RequiresApproval != nil {
+		link.RequiresApproval = *req.RequiresApproval
+	}
+
+	if err := s.repos.JoinLinks.Update(ctx, link); err != nil {
+		return nil, err
+	}
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(classroomID, model.AuditJoinLinkUpdate, model.AuditTargetJoinLink, classroomID), &before, link)
+	return link, nil
+}
+
+// ResolveJoinLink returns the public details of the classroom a join link
+// token leads to. Disabled links fail with BUSINESS_INVITATION_CLOSED.
+func (s *RosterService) ResolveJoinLink(ctx context.Context, token string) (*model.JoinLinkDetails, error) {
+	link, err := s.openJoinLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	classroom, err := s.repos.Classrooms.GetByID(ctx, link.ClassroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+
+	return &model.JoinLinkDetails{
+		ClassroomName:    classroom.Name,
+		OrganizationName: classroom.OrganizationName,
+		RequiresApproval: link.RequiresApproval,
+	}, nil
+}
+
+// Claim claims the student roster entry matching req for actor's Forgejo
+// account through a join link token. The entry is linked right away, or
+// once staff approve the claim if the link requires approval; the returned
+// claim tells which.
+//
+// A Forgejo account is linked to at most one entry of a classroom and has
+// at most one pending claim there. Claiming the entry of a pending claim
+// again returns that claim.
+func (s *RosterService) Claim(ctx context.Context, actor *Actor, token string, req *model.ClaimRosterRequest) (*model.RosterClaim, error) {
+	if actor == nil {
+		return nil, newError(response.ErrAuthMissingToken, nil)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+	link, err := s.openJoinLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	classroomID := link.ClassroomID
+
+	var claim *model.RosterClaim
+	err = s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+		if err := tx.AdvisoryLock(ctx, fmt.Sprintf("roster:%d", classroomID)); err != nil {
+			return err
+		}
+		if err := checkForgejoUserAvailable(ctx, tx, classroomID, actor.ID, actor.Login, 0); err != nil {
+			return err
+		}
+		entry, err := matchRosterEntry(ctx, tx, classroomID, req)
+		if err != nil {
+			return err
+		}
+		if entry.IsLinked() {
+			return &Error{
+				Code:    response.ErrResourceConflict,
+				Message: fmt.Sprintf("Student %s is already linked to a Forgejo account", entry.StudentID),
+			}
+		}
+
+		pending, err := tx.Claims.GetPending(ctx, classroomID, actor.ID)
+		switch {
+		case err == nil && pending.RosterEntryID == entry.ID:
+			claim = pending
+			return nil
+		case err == nil:
+			return rosterExists(fmt.Sprintf("You already have a pending claim for student %s", pending.StudentID))
+		case !errors.Is(err, repository.ErrNotFound):
+			return err
+		}
+
+		claim = &model.RosterClaim{
+			ClassroomID:     classroomID,
+			RosterEntryID:   entry.ID,
+			StudentName:     entry.StudentName,
+			StudentID:       entry.StudentID,
+			ForgejoUserID:   actor.ID,
+			ForgejoUsername: actor.Login,
+			Status:          model.ClaimStatusPending,
+		}
+		if !link.RequiresApproval {
+			now := time.Now().UTC()
+			if err := linkEntry(ctx, tx, entry, actor.ID, actor.Login, now); err != nil {
+				return err
+			}
+			claim.Status = model.ClaimStatusApproved
+			claim.DecidedAt = &now
+		}
+		if err := tx.Claims.Create(ctx, claim); err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return rosterExists(fmt.Sprintf("Student %s already has a pending claim", entry.StudentID))
+			}
+			return err
+		}
+		return recordAudit(ctx, tx, auditEvent(classroomID, model.AuditClaimCreate, model.AuditTargetClaim, claim.ID), nil, claim)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claim.Status == model.ClaimStatusApproved {
+		s.invalidateRoster(ctx, classroomID, false)
+	}
+	s.logger.Info("Roster entry claimed",
+		zap.Int64("classroom_id", classroomID),
+		zap.Int64("roster_entry_id", claim.RosterEntryID),
+		zap.String("forgejo_username", actor.Login),
+		zap.String("status", claim.Status),
+	)
+	return claim, nil
+}
+
+// ListClaims returns one page of a classroom's roster claims, oldest first
+func (s *RosterService) ListClaims(ctx context.Context, classroomID int64, req *model.RosterClaimListRequest) (*model.RosterClaimListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, validationError(err)
+	}
+	return s.repos.Claims.List(ctx, classroomID, req)
+}
+
+// ApproveClaim links the roster entry of a pending claim to the claimant's
+// Forgejo account, unless the entry or the account was linked since
+func (s *RosterService) ApproveClaim(ctx context.Context, actor *Actor, classroomID, claimID int64) (*model.RosterClaim, error) {
+	claim, err := s.decideClaim(ctx, actor, classroomID, claimID, model.ClaimStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateRoster(ctx, classroomID, false)
+	return claim, nil
+}
+
+// RejectClaim rejects a pending claim. The student may claim again.
+func (s *RosterService) RejectClaim(ctx context.Context, actor *Actor, classroomID, claimID int64) (*model.RosterClaim, error) {
+	return s.decideClaim(ctx, actor, classroomID, claimID, model.ClaimStatusRejected)
+}
+
+// decideClaim moves a pending claim to status, linking its entry when the
+// claim is approved
+func (s *RosterService) decideClaim(ctx context.Context, actor *Actor, classroomID, claimID int64, status string) (*model.RosterClaim, error) {
+	if actor == nil {
+		return nil, newError(response.ErrAuthMissingToken, nil)
+	}
+
+	var claim *model.RosterClaim
+	err := s.repos.WithTransaction(ctx, func(tx *repository.Repositories) error {
+		if err := tx.AdvisoryLock(ctx, fmt.Sprintf("roster:%d", classroomID)); err != nil {
+			return err
+		}
+		var err error
+		claim, err = tx.Claims.GetByID(ctx, classroomID, claimID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound("Claim")
+		}
+		if err != nil {
+			return err
+		}
+		if claim.Status != model.ClaimStatusPending {
+			return &Error{
+				Code:    response.ErrResourceConflict,
+				Message: fmt.Sprintf("Claim %d is already %s", claimID, claim.Status),
+			}
+		}
+		before := *claim
+
+		now := time.Now().UTC()
+		if status == model.ClaimStatusApproved {
+			entry, err := tx.Roster.GetByID(ctx, classroomID, claim.RosterEntryID)
+			if err != nil {
+				return err
+			}
+			if entry.IsLinked() {
+				return &Error{
+					Code:    response.ErrResourceConflict,
+					Message: fmt.Sprintf("Student %s is already linked to %s", entry.StudentID, *entry.ForgejoUsername),
+				}
+			}
+			if err := checkForgejoUserAvailable(ctx, tx, classroomID, claim.ForgejoUserID, claim.ForgejoUsername, entry.ID); err != nil {
+				return err
+			}
+			if err := linkEntry(ctx, tx, entry, claim.ForgejoUserID, claim.ForgejoUsername, now); err != nil {
+				return err
+			}
+		}
+
+		claim.Status = status
+		claim.DecidedBy = &actor.Login
+		claim.DecidedAt = &now
+		if err := tx.Claims.Decide(ctx, claim); err != nil {
+			return err
+		}
+
+		action := model.AuditClaimApprove
+		if status == model.ClaimStatusRejected {
+			action = model.AuditClaimReject
+		}
+		return recordAudit(ctx, tx, auditEvent(classroomID, action, model.AuditTargetClaim, claim.ID), &before, claim)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Roster claim decided",
+		zap.Int64("classroom_id", classroomID),
+		zap.Int64("claim_id", claimID),
+		zap.String("status", status),
+		zap.String("decided_by", actor.Login),
+	)
+	return claim, nil
+}
+
+// openJoinLink returns the join link with token if it is enabled
+func (s *RosterService) openJoinLink(ctx context.Context, token string) (*model.JoinLink, error) {
+	link, err := s.repos.JoinLinks.GetByToken(ctx, token)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, notFound("Join link")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !link.Enabled {
+		return nil, &Error{
+			Code:    response.ErrBusinessInvitationClosed,
+			Message: response.GetErrorMessage(response.ErrBusinessInvitationClosed),
+			Details: map[string]interface{}{"reason": "disabled"},
+		}
+	}
+	return link, nil
+}
+
+// matchRosterEntry returns the student entry matching every identifier of
+// req. Staff entries cannot be claimed, and are reported as missing like
+// entries that match nothing.
+func matchRosterEntry(ctx context.Context, repos *repository.Repositories, classroomID int64, req *model.ClaimRosterRequest) (*model.RosterEntry, error) {
+	var entry *model.RosterEntry
+	var err error
+	if req.StudentID != "" {
+		entry, err = repos.Roster.GetByStudentID(ctx, classroomID, req.StudentID)
+	} else {
+		entry, err = repos.Roster.GetByEmail(ctx, classroomID, req.StudentEmail)
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+	case err != nil:
+		return nil, err
+	case req.StudentEmail != "" && !strings.EqualFold(entry.StudentEmail, req.StudentEmail):
+	case entry.Role != "student":
+	default:
+		return entry, nil
+	}
+	return nil, newError(response.ErrBusinessRosterNotFound, nil)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func TestRosterClaims(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Roster
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	prof := &Actor{ID: 1, Login: "prof"}
+	jdoe := server.AddUser("jdoe", "jdoe-token")
+	asmith := server.AddUser("asmith", "asmith-token")
+	student := &Actor{ID: jdoe.ID, Login: jdoe.Login}
+	other := &Actor{ID: asmith.ID, Login: asmith.Login}
+
+	classroom, err := services.Classrooms.Create(ctx, prof,
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	entries := map[string]*model.RosterEntry{}
+	for _, e := range []model.RosterEntry{
+		{StudentName: "John Doe", StudentEmail: "john@example.com", StudentID: "john123", Role: "student"},
+		{StudentName: "Alice Smith", StudentEmail: "alice@example.com", StudentID: "alice456", Role: "student"},
+		{StudentName: "Tom Assistant", StudentEmail: "tom@example.com", StudentID: "tom789", Role: "assistant"},
+	} {
+		e.ClassroomID = classroom.ID
+		require.NoError(t, repos.Roster.Create(ctx, &e))
+		entries[e.StudentID] = &e
+	}
+
+	t.Run("classrooms start without a join link", func(t *testing.T) {
+		_, err := svc.GetJoinLink(ctx, classroom.ID)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	t.Run("new join links require approval", func(t *testing.T) {
+		link, err := svc.RotateJoinLink(ctx, classroom.ID, &model.RotateJoinLinkRequest{})
+		require.NoError(t, err)
+		assert.True(t, link.Enabled)
+		assert.True(t, link.RequiresApproval)
+	})
+
+	noApproval := false
+	link, err := svc.RotateJoinLink(ctx, classroom.ID, &model.RotateJoinLinkRequest{RequiresApproval: &noApproval})
+	require.NoError(t, err)
+	assert.False(t, link.RequiresApproval)
+
+	t.Run("resolve shows the classroom", func(t *testing.T) {
+		details, err := svc.ResolveJoinLink(ctx, link.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "CS 101", details.ClassroomName)
+
+		_, err = svc.ResolveJoinLink(ctx, "unknown")
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	t.Run("claims must match a student entry", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  model.ClaimRosterRequest
+			code string
+		}{
+			{"no identifier", model.ClaimRosterRequest{}, response.ErrValidationInvalidInput},
+			{"unknown student ID", model.ClaimRosterRequest{StudentID: "nobody"}, response.ErrBusinessRosterNotFound},
+			{"mismatched email", model.ClaimRosterRequest{StudentID: "john123", StudentEmail: "alice@example.com"}, response.ErrBusinessRosterNotFound},
+			{"staff entry", model.ClaimRosterRequest{StudentID: "tom789"}, response.ErrBusinessRosterNotFound},
+		}
+		for _, tt := range tests {
+			_, err := svc.Claim(ctx, student, link.Token, &tt.req)
+			assert.Equal(t, tt.code, AsError(err).Code, tt.name)
+		}
+	})
+
+	t.Run("claiming links the entry right away", func(t *testing.T) {
+		claim, err := svc.Claim(ctx, student, link.Token, &model.ClaimRosterRequest{StudentEmail: "JOHN@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClaimStatusApproved, claim.Status)
+		assert.Equal(t, entries["john123"].ID, claim.RosterEntryID)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "john123")
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", *entry.ForgejoUsername)
+		assert.Equal(t, jdoe.ID, *entry.ForgejoUserID)
+		assert.NotNil(t, entry.LinkedAt)
+
+		// The cached roster shows the link
+		list, err := svc.List(ctx, classroom.ID, &model.RosterListRequest{LinkedOnly: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, list.Total)
+	})
+
+	t.Run("an account claims one entry", func(t *testing.T) {
+		_, err := svc.Claim(ctx, student, link.Token, &model.ClaimRosterRequest{StudentID: "alice456"})
+		assert.Equal(t, response.ErrResourceAlreadyExists, AsError(err).Code)
+
+		_, err = svc.Claim(ctx, other, link.Token, &model.ClaimRosterRequest{StudentID: "john123"})
+		assert.Equal(t, response.ErrResourceConflict, AsError(err).Code)
+	})
+
+	approval := true
+	_, err = svc.UpdateJoinLink(ctx, classroom.ID, &model.UpdateJoinLinkRequest{RequiresApproval: &approval})
+	require.NoError(t, err)
+
+	var pending *model.RosterClaim
+	t.Run("claims wait for approval", func(t *testing.T) {
+		pending, err = svc.Claim(ctx, other, link.Token, &model.ClaimRosterRequest{StudentID: "alice456"})
+		require.NoError(t, err)
+		assert.Equal(t, model.ClaimStatusPending, pending.Status)
+
+		again, err := svc.Claim(ctx, other, link.Token, &model.ClaimRosterRequest{StudentID: "alice456"})
+		require.NoError(t, err)
+		assert.Equal(t, pending.ID, again.ID)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "alice456")
+		require.NoError(t, err)
+		assert.False(t, entry.IsLinked())
+
+		list, err := svc.ListClaims(ctx, classroom.ID, &model.RosterClaimListRequest{Status: model.ClaimStatusPending})
+		require.NoError(t, err)
+		require.Len(t, list.Claims, 1)
+		assert.Equal(t, "Alice Smith", list.Claims[0].StudentName)
+	})
+
+	t.Run("approving links the entry", func(t *testing.T) {
+		claim, err := svc.ApproveClaim(ctx, prof, classroom.ID, pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ClaimStatusApproved, claim.Status)
+		assert.Equal(t, "prof", *claim.DecidedBy)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "alice456")
+		require.NoError(t, err)
+		assert.Equal(t, "asmith", *entry.ForgejoUsername)
+
+		_, err = svc.RejectClaim(ctx, prof, classroom.ID, pending.ID)
+		assert.Equal(t, response.ErrResourceConflict, AsError(err).Code)
+	})
+
+	t.Run("disabled links are closed", func(t *testing.T) {
+		disabled := false
+		_, err := svc.UpdateJoinLink(ctx, classroom.ID, &model.UpdateJoinLinkRequest{Enabled: &disabled})
+		require.NoError(t, err)
+
+		_, err = svc.Claim(ctx, student, link.Token, &model.ClaimRosterRequest{StudentID: "john123"})
+		assert.Equal(t, response.ErrBusinessInvitationClosed, AsError(err).Code)
+	})
+
+	t.Run("rotation keeps the approval setting", func(t *testing.T) {
+		rotated, err := svc.RotateJoinLink(ctx, classroom.ID, &model.RotateJoinLinkRequest{})
+		require.NoError(t, err)
+		assert.True(t, rotated.Enabled)
+		assert.True(t, rotated.RequiresApproval)
+	})
+}
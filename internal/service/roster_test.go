@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+func TestParseRosterCSV(t *testing.T) {
+	t.Run("positional columns", func(t *testing.T) {
+		rows, err := parseRosterCSV(strings.NewReader("Jane Doe, jane@example.com ,j1\n\nJohn Roe,john@example.com,j2,Assistant\n"), "")
+		require.NoError(t, err)
+		assert.Equal(t, []model.RosterImportRow{
+			{Row: 1, StudentName: "Jane Doe", StudentEmail: "jane@example.com", StudentID: "j1"},
+			{Row: 3, StudentName: "John Roe", StudentEmail: "john@example.com", StudentID: "j2", Role: "assistant"},
+		}, rows)
+	})
+
+	t.Run("header in any order", func(t *testing.T) {
+		rows, err := parseRosterCSV(strings.NewReader("\ufeffStudent ID\tEmail\tName\nj1\tjane@example.com\tJane\n"), `\t`)
+		require.NoError(t, err)
+		assert.Equal(t, []model.RosterImportRow{
+			{Row: 2, StudentName: "Jane", StudentEmail: "jane@example.com", StudentID: "j1"},
+		}, rows)
+	})
+
+	t.Run("custom delimiter", func(t *testing.T) {
+		rows, err := parseRosterCSV(strings.NewReader("Doe, Jane;jane@example.com;j1\n"), ";")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "Doe, Jane", rows[0].StudentName)
+	})
+
+	for name, tt := range map[string]struct {
+		input, delimiter, field string
+	}{
+		"missing header column": {"name,email\nJane,jane@example.com\n", "", "file"},
+		"no students":           {"name,email,student_id\n", "", "file"},
+		"unterminated quote":    {"\"Jane,jane@example.com,j1\n", "", "file"},
+		"invalid delimiter":     {"Jane,jane@example.com,j1\n", "::", "delimiter"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseRosterCSV(strings.NewReader(tt.input), tt.delimiter)
+			require.Error(t, err)
+			svcErr := AsError(err)
+			assert.Equal(t, response.ErrValidationInvalidInput, svcErr.Code)
+			assert.Contains(t, fmt.Sprint(svcErr.Details["fields"]), tt.field)
+		})
+	}
+}
+
+func TestRosterImport_Validate(t *testing.T) {
+	imp := &rosterImport{failed: make(map[int]bool), result: &model.RosterImportResult{}}
+	valid := imp.validate([]model.RosterImportRow{
+		{Row: 1, StudentName: "Jane", StudentEmail: "jane@example.com", StudentID: "j1"},
+		{Row: 2, StudentName: "John", StudentEmail: "not-an-email", StudentID: "j2"},
+		{Row: 3, StudentName: "Jim", StudentEmail: "JANE@example.com", StudentID: "j3"},
+		{Row: 4, StudentName: "Joe", StudentEmail: "joe@example.com", StudentID: "j1"},
+		{Row: 5, StudentName: "", StudentEmail: "x@example.com", StudentID: "j5", Role: "teacher"},
+		{Row: 6, StudentName: "Jo", StudentEmail: "jo@example.com", StudentID: "j6", Role: "assistant"},
+	})
+	imp.finish()
+
+	require.Len(t, valid, 2)
+	assert.Equal(t, 1, valid[0].Row)
+	assert.Equal(t, 6, valid[1].Row)
+	assert.Equal(t, 4, imp.result.Failed)
+
+	fields := make(map[int][]string)
+	for _, e := range imp.result.Errors {
+		fields[e.Row] = append(fields[e.Row], e.Field)
+	}
+	assert.Equal(t, map[int][]string{
+		2: {"student_email"},
+		3: {"student_email"},
+		4: {"student_id"},
+		5: {"student_name", "role"},
+	}, fields)
+}
+
+func TestRosterService_Import(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Roster
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	importCSV := func(t *testing.T, csv string, req model.ImportRosterRequest) *model.ImportRosterResponse {
+		t.Helper()
+		resp, err := svc.Import(ctx, classroom.ID, strings.NewReader(csv), &req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("small files are imported inline", func(t *testing.T) {
+		resp := importCSV(t, "name,email,student_id\nJane,jane@example.com,j1\nJohn,bad,j2\n", model.ImportRosterRequest{})
+		assert.Equal(t, queue.StatusSucceeded, resp.Status)
+		assert.Nil(t, resp.JobID)
+		require.NotNil(t, resp.Result)
+		assert.Equal(t, 1, resp.Result.Created)
+		assert.Equal(t, 1, resp.Result.Failed)
+		require.Len(t, resp.Result.Errors, 1)
+		assert.Equal(t, 3, resp.Result.Errors[0].Row)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "j1")
+		require.NoError(t, err)
+		assert.Equal(t, "student", entry.Role)
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		resp := importCSV(t, "Jane Doe,jane@example.com,j1\nJim,jim@example.com,j3\n", model.ImportRosterRequest{DryRun: true, Update: true})
+		assert.Equal(t, 1, resp.Result.Created)
+		assert.Equal(t, 1, resp.Result.Updated)
+
+		_, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "j3")
+		assert.Error(t, err)
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "j1")
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", entry.StudentName)
+	})
+
+	t.Run("existing students are skipped or updated", func(t *testing.T) {
+		resp := importCSV(t, "Jane Doe,jane@example.com,j1\n", model.ImportRosterRequest{})
+		assert.Equal(t, 1, resp.Result.Skipped)
+
+		resp = importCSV(t, "Jane Doe,jane@example.com,j1,assistant\n", model.ImportRosterRequest{Update: true})
+		assert.Equal(t, 1, resp.Result.Updated)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "j1")
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", entry.StudentName)
+		assert.Equal(t, "assistant", entry.Role)
+	})
+
+	t.Run("email of another student is rejected", func(t *testing.T) {
+		resp := importCSV(t, "Impostor,JANE@example.com,j9\n", model.ImportRosterRequest{})
+		assert.Equal(t, 1, resp.Result.Failed)
+		require.Len(t, resp.Result.Errors, 1)
+		assert.Equal(t, response.ErrResourceAlreadyExists, resp.Result.Errors[0].Code)
+	})
+
+	t.Run("large files are imported by a job", func(t *testing.T) {
+		var csv strings.Builder
+		csv.WriteString("name,email,student_id\n")
+		for i := 0; i < RosterImportJobRows-1; i++ {
+			fmt.Fprintf(&csv, "Student %d,s%d@example.com,s%d\n", i, i, i)
+		}
+		csv.WriteString("Broken,broken,s-broken\n")
+
+		resp := importCSV(t, csv.String(), model.ImportRosterRequest{})
+		assert.Equal(t, queue.StatusPending, resp.Status)
+		require.NotNil(t, resp.JobID)
+
+		job, err := svc.queue.Dequeue(ctx, []string{JobTypeImportRoster})
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, *resp.JobID, job.ID)
+
+		var payload ImportRosterPayload
+		require.NoError(t, job.Decode(&payload))
+		require.NoError(t, svc.importJob(ctx, job, payload))
+		require.NoError(t, svc.queue.Complete(ctx, job))
+
+		status, classroomID, err := services.Jobs.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, classroom.ID, classroomID)
+		assert.Equal(t, queue.StatusSucceeded, status.Status)
+		assert.Equal(t, 1, status.ErrorCount)
+
+		var result model.RosterImportResult
+		require.NoError(t, json.Unmarshal(status.Result, &result))
+		assert.Equal(t, RosterImportJobRows-1, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		assert.Empty(t, result.Errors)
+
+		errs, err := services.Jobs.ListErrors(ctx, job.ID, &model.JobErrorListRequest{})
+		require.NoError(t, err)
+		require.Len(t, errs.Errors, 1)
+		assert.Equal(t, RosterImportJobRows+1, errs.Errors[0].Row)
+		assert.Equal(t, "student_email", errs.Errors[0].Field)
+	})
+
+	t.Run("missing classroom", func(t *testing.T) {
+		_, err := svc.Import(ctx, 9999, strings.NewReader("Jane,jane@example.com,j1\n"), &model.ImportRosterRequest{})
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+}
+
+func TestJobService_GetMissing(t *testing.T) {
+	services, _ := setupTestServices(t)
+	_, _, err := services.Jobs.Get(context.Background(), 9999)
+	assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+}
+
+func TestRosterService_BulkValidation(t *testing.T) {
+	// Request-level validation runs before any database access
+	svc := NewRosterService(nil, nil, nil, nil, zap.NewNop())
+	ctx := context.Background()
+
+	tooMany := make([]model.RosterOperation, model.MaxBulkRosterOperations+1)
+	for name, req := range map[string]model.BulkRosterRequest{
+		"no operations": {},
+		"too many":      {Operations: tooMany},
+		"unknown mode":  {Operations: []model.RosterOperation{{Action: model.RosterActionRemove, StudentID: "s1"}}, Mode: "some"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Bulk(ctx, 1, &req)
+			assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+		})
+	}
+}
+
+func TestRosterOperation_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     model.RosterOperation
+		fields []string
+	}{
+		{"valid add", model.RosterOperation{Action: "add", StudentID: "s1", StudentName: "Jane", StudentEmail: "jane@example.com"}, nil},
+		{"add without email", model.RosterOperation{Action: "add", StudentID: "s1", StudentName: "Jane"}, []string{"student_email"}},
+		{"unknown action", model.RosterOperation{Action: "rename", StudentID: "s1"}, []string{"action"}},
+		{"missing student id", model.RosterOperation{Action: "remove"}, []string{"student_id"}},
+		{"empty update", model.RosterOperation{Action: "update", StudentID: "s1"}, []string{"operation"}},
+		{"update with bad role", model.RosterOperation{Action: "update", StudentID: "s1", Role: "dean"}, []string{"role"}},
+		{"link without username", model.RosterOperation{Action: "link", StudentID: "s1"}, []string{"forgejo_username"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op.Validate()
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var errs util.ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestRosterService_Bulk(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Roster
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	jane := server.AddUser("jane", "jane-token")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	add := func(id, email string) model.RosterOperation {
+		return model.RosterOperation{Action: model.RosterActionAdd, StudentID: id, StudentName: "Student " + id, StudentEmail: email}
+	}
+	statuses := func(resp *model.BulkRosterResponse) []string {
+		var s []string
+		for i, r := range resp.Results {
+			assert.Equal(t, i, r.Index)
+			s = append(s, r.Status)
+		}
+		return s
+	}
+
+	t.Run("best effort applies what it can", func(t *testing.T) {
+		resp, err := svc.Bulk(ctx, classroom.ID, &model.BulkRosterRequest{Operations: []model.RosterOperation{
+			add("s1", "s1@example.com"),
+			add("s1", "other@example.com"),
+			add("s2", "s2@example.com"),
+			{Action: model.RosterActionUpdate, StudentID: "s2", Role: "assistant"},
+			{Action: model.RosterActionLink, StudentID: "s1", ForgejoUsername: "jane"},
+			{Action: model.RosterActionLink, StudentID: "s2", ForgejoUsername: "nobody"},
+			{Action: model.RosterActionRemove, StudentID: "s9"},
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"success", "error", "success", "success", "success", "error", "error"}, statuses(resp))
+		assert.Equal(t, model.BulkRosterSummary{Total: 7, Succeeded: 4, Failed: 3}, resp.Summary)
+		require.NotNil(t, resp.Results[0].ID)
+		assert.Equal(t, response.ErrResourceAlreadyExists, resp.Results[1].Error.Code)
+		assert.Equal(t, response.ErrResourceNotFound, resp.Results[5].Error.Code)
+		assert.Equal(t, response.ErrResourceNotFound, resp.Results[6].Error.Code)
+
+		entry, err := repos.Roster.GetByStudentID(ctx, classroom.ID, "s1")
+		require.NoError(t, err)
+		require.NotNil(t, entry.ForgejoUserID)
+		assert.Equal(t, jane.ID, *entry.ForgejoUserID)
+	})
+
+	t.Run("a Forgejo account links to one student", func(t *testing.T) {
+		resp, err := svc.Bulk(ctx, classroom.ID, &model.BulkRosterRequest{Operations: []model.RosterOperation{
+			{Action: model.RosterActionLink, StudentID: "s2", ForgejoUsername: "jane"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, response.ErrResourceAlreadyExists, resp.Results[0].Error.Code)
+	})
+
+	t.Run("single add and link", func(t *testing.T) {
+		entry, err := svc.Add(ctx, classroom.ID, &model.AddStudentRequest{StudentID: "s6", StudentName: "Student s6", StudentEmail: "s6@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "student", entry.Role)
+		assert.False(t, entry.IsLinked())
+
+		_, err = svc.Add(ctx, classroom.ID, &model.AddStudentRequest{StudentID: "s6", StudentName: "Again", StudentEmail: "again@example.com"})
+		assert.Equal(t, response.ErrResourceAlreadyExists, AsError(err).Code)
+
+		server.AddUser("joe", "joe-token")
+		entry, err = svc.Link(ctx, classroom.ID, "s6", &model.LinkStudentRequest{ForgejoUsername: "joe"})
+		require.NoError(t, err)
+		require.NotNil(t, entry.ForgejoUsername)
+		assert.Equal(t, "joe", *entry.ForgejoUsername)
+
+		_, err = svc.Link(ctx, classroom.ID, "s6", &model.LinkStudentRequest{ForgejoUsername: "jane"})
+		assert.Equal(t, response.ErrResourceConflict, AsError(err).Code)
+		_, err = svc.Link(ctx, classroom.ID, "s9", &model.LinkStudentRequest{ForgejoUsername: "joe"})
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+		_, err = svc.Add(ctx, 9999, &model.AddStudentRequest{StudentID: "s7", StudentName: "Student s7", StudentEmail: "s7@example.com"})
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	t.Run("all or nothing rolls back on failure", func(t *testing.T) {
+		resp, err := svc.Bulk(ctx, classroom.ID, &model.BulkRosterRequest{Mode: model.BulkModeAllOrNothing, Operations: []model.RosterOperation{
+			add("s3", "s3@example.com"),
+			{Action: model.RosterActionRemove, StudentID: "s2"},
+			add("s4", "s1@example.com"),
+			add("s5", "s5@example.com"),
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"rolled_back", "rolled_back", "error", "skipped"}, statuses(resp))
+		assert.Equal(t, model.BulkRosterSummary{Total: 4, Succeeded: 0, Failed: 4, RolledBack: true}, resp.Summary)
+		assert.Nil(t, resp.Results[0].ID)
+
+		_, err = repos.Roster.GetByStudentID(ctx, classroom.ID, "s3")
+		assert.Error(t, err)
+		_, err = repos.Roster.GetByStudentID(ctx, classroom.ID, "s2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("all or nothing commits when everything succeeds", func(t *testing.T) {
+		resp, err := svc.Bulk(ctx, classroom.ID, &model.BulkRosterRequest{Mode: model.BulkModeAllOrNothing, Operations: []model.RosterOperation{
+			add("s3", "s3@example.com"),
+			{Action: model.RosterActionRemove, StudentID: "s2"},
+		}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"success", "success"}, statuses(resp))
+		assert.Nil(t, resp.Results[1].ID)
+		_, err = repos.Roster.GetByStudentID(ctx, classroom.ID, "s2")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+// Package service implements the classroom business logic on top of the
+// repository layer and the Forgejo client.
+package service
+
+import (
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/queue"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+)
+
+// Actor identifies the authenticated Forgejo user performing an operation
+type Actor struct {
+	ID    int64
+	Login string
+	Admin bool // Forgejo site administrator
+}
+
+// Services groups the services used by the API handlers
+type Services struct {
+	Classrooms  *ClassroomService
+	Assignments *AssignmentService
+	Invitations *InvitationService
+	Submissions *SubmissionService
+	Roster      *RosterService
+	Deadlines   *DeadlineService
+	Webhooks    *WebhookService
+	Jobs        *JobService
+	Audit       *AuditService
+	Permissions *auth.Checker
+}
+
+// New creates all services. Reads are cached in store, which may be nil to
+// disable caching.
+func New(repos *repository.Repositories, fj *forgejo.Client, q queue.Queue, store *cache.Store, logger *zap.Logger) *Services {
+	deadlines := NewDeadlineService(repos, fj, q, store, logger)
+	assignments := NewAssignmentService(repos, fj, store, logger)
+	return &Services{
+		Classrooms:  NewClassroomService(repos, fj, store, logger),
+		Assignments: assignments,
+		Invitations: NewInvitationService(repos, assignments, logger),
+		Submissions: NewSubmissionService(repos, fj, store, logger),
+		Roster:      NewRosterService(repos, fj, q, store, logger),
+		Deadlines:   deadlines,
+		Webhooks:    NewWebhookService(repos, fj, deadlines, store, logger),
+		Jobs:        NewJobService(repos, q),
+		Audit:       NewAuditService(repos),
+		Permissions: auth.NewChecker(repos),
+	}
+}
+
+// RegisterJobs registers the background job handlers of all services
+func (s *Services) RegisterJobs(pool *queue.Pool) {
+	s.Roster.RegisterJobs(pool)
+	s.Deadlines.RegisterJobs(pool)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// commitBuckets are the lower bounds of the buckets of the commit
+// distribution
+var commitBuckets = []int{0, 1, 2, 5, 10, 20, 50}
+
+// Stats returns the statistics of an assignment. They are computed in the
+// database and cached for the short stats TTL rather than invalidated, as
+// every push and acceptance changes them.
+func (s *AssignmentService) Stats(ctx context.Context, a *model.Assignment, req *model.AssignmentStatsRequest) (*model.AssignmentStats, error) {
+	key := cache.AssignmentStatsKey(a.ClassroomID, a.ID, req.Detailed)
+	var stats *model.AssignmentStats
+	if s.cache.Get(ctx, key, &stats) {
+		return stats, nil
+	}
+
+	stats, err := s.repos.Assignments.Stats(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	stats.AcceptanceRate = rate(stats.AcceptedCount, stats.TotalStudents)
+	stats.SubmissionRate = rate(stats.SubmissionCount, stats.TotalStudents)
+	stats.AverageCommits = math.Round(stats.AverageCommits*100) / 100
+
+	if req.Detailed {
+		details := &model.AssignmentStatsDetails{}
+		counts, err := s.repos.Assignments.CommitCounts(ctx, a.ID)
+		if err != nil {
+			return nil, err
+		}
+		details.CommitDistribution = bucketize(counts, commitBuckets)
+		if details.AcceptanceHistogram, err = s.repos.Assignments.AcceptanceHistogram(ctx, a.ID); err != nil {
+			return nil, err
+		}
+		if details.NotAccepted, err = s.repos.Assignments.ListNotAccepted(ctx, a.ID); err != nil {
+			return nil, err
+		}
+		stats.Details = details
+	}
+
+	s.cache.Set(ctx, key, stats, s.cache.Config().StatsTTL)
+	return stats, nil
+}
+
+// Stats returns the statistics of a classroom with the progress of each of
+// its assignments. Like assignment statistics, they are cached for the
+// stats TTL.
+func (s *ClassroomService) Stats(ctx context.Context, id int64) (*model.ClassroomStats, error) {
+	key := cache.ClassroomStatsKey(id)
+	var stats *model.ClassroomStats
+	if s.cache.Get(ctx, key, &stats) {
+		return stats, nil
+	}
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	stats, err := s.repos.Classrooms.Stats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Assignments, err = s.repos.Classrooms.ListAssignmentProgress(ctx, id); err != nil {
+		return nil, err
+	}
+	for i := range stats.Assignments {
+		a := &stats.Assignments[i]
+		a.AcceptanceRate = rate(a.AcceptedCount, stats.TotalStudents)
+		a.SubmissionRate = rate(a.SubmissionCount, stats.TotalStudents)
+	}
+
+	s.cache.Set(ctx, key, stats, s.cache.Config().StatsTTL)
+	return stats, nil
+}
+
+// Activity returns the work in progress across all classrooms for the
+// metrics endpoint. It is not cached, so that stalls show up on the next
+// scrape.
+func (s *ClassroomService) Activity(ctx context.Context) (*model.Activity, error) {
+	var err error
+	activity := &model.Activity{}
+	if activity.Assignments, err = s.repos.Assignments.ListActive(ctx); err != nil {
+		return nil, err
+	}
+	if activity.PendingClaims, err = s.repos.Claims.CountPending(ctx); err != nil {
+		return nil, err
+	}
+	activity.OverdueDeadlines, activity.OldestOverdueDeadline, err = s.repos.Assignments.OverdueDeadlines(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// rate returns part/total, or 0 when total is 0
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// bucketize sums counts, which map values to how often they occur, into
+// buckets starting at each of the ascending lower bounds
+func bucketize(counts map[int]int, bounds []int) []model.StatsBucket {
+	buckets := make([]model.StatsBucket, len(bounds))
+	for i, min := range bounds {
+		buckets[i].Min = min
+		if i+1 < len(bounds) {
+			max := bounds[i+1] - 1
+			buckets[i].Max = &max
+		}
+	}
+	for value, n := range counts {
+		for i := len(buckets) - 1; i >= 0; i-- {
+			if value >= buckets[i].Min {
+				buckets[i].Count += n
+				break
+			}
+		}
+	}
+	return buckets
+}
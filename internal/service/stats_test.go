@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func TestBucketize(t *testing.T) {
+	buckets := bucketize(map[int]int{0: 3, 1: 1, 4: 2, 5: 1, 120: 1}, []int{0, 1, 2, 5})
+
+	require.Len(t, buckets, 4)
+	assert.Equal(t, []int{0, 1, 2, 5}, []int{buckets[0].Min, buckets[1].Min, buckets[2].Min, buckets[3].Min})
+	assert.Equal(t, []int{3, 1, 2, 2}, []int{buckets[0].Count, buckets[1].Count, buckets[2].Count, buckets[3].Count})
+	assert.Equal(t, 0, *buckets[0].Max)
+	assert.Equal(t, 4, *buckets[2].Max)
+	assert.Nil(t, buckets[3].Max)
+
+	assert.Equal(t, 0, bucketize(nil, commitBuckets)[0].Count)
+}
+
+func TestStats(t *testing.T) {
+	services, server := setupTestServices(t)
+	repos := services.Assignments.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: "cs101/hw1-template",
+		TemplateRepositoryID: 1, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	roster := map[string]*model.RosterEntry{}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Tess"} {
+		role := "student"
+		if name == "Tess" {
+			role = "assistant"
+		}
+		entry := &model.RosterEntry{ClassroomID: classroom.ID, StudentName: name, StudentEmail: name + "@example.com", StudentID: name, Role: role}
+		require.NoError(t, repos.Roster.Create(ctx, entry))
+		roster[name] = entry
+	}
+
+	accepted := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	sha := "abc123"
+	for i, s := range []*model.Submission{
+		{StudentID: &roster["Alice"].ID, Status: SubmissionStatusAccepted, AcceptedAt: &accepted, LastCommitSHA: &sha, CommitCount: 4},
+		{StudentID: &roster["Bob"].ID, Status: SubmissionStatusAccepted, AcceptedAt: &accepted},
+	} {
+		s.AssignmentID = assignment.ID
+		s.RepositoryName = fmt.Sprintf("hw1-%d", i)
+		s.RepositoryID = int64(i + 1)
+		require.NoError(t, repos.Submissions.Create(ctx, s))
+	}
+
+	upcoming := time.Now().Add(24 * time.Hour)
+	other := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 2", Slug: "hw2", TemplateRepository: "cs101/hw2-template",
+		TemplateRepositoryID: 2, Deadline: &upcoming, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, other))
+
+	t.Run("assignment", func(t *testing.T) {
+		stats, err := services.Assignments.Stats(ctx, assignment, &model.AssignmentStatsRequest{Detailed: true})
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.TotalStudents)
+		assert.Equal(t, 2, stats.AcceptedCount)
+		assert.Equal(t, 1, stats.SubmissionCount)
+		assert.InDelta(t, 2.0/3, stats.AcceptanceRate, 0.001)
+		assert.InDelta(t, 1.0/3, stats.SubmissionRate, 0.001)
+		assert.Equal(t, 2.0, stats.AverageCommits)
+		assert.Equal(t, 1, stats.OnTimeSubmissions)
+
+		require.NotNil(t, stats.Details)
+		assert.Equal(t, 1, stats.Details.CommitDistribution[0].Count) // Bob, no commits
+		assert.Equal(t, 1, stats.Details.CommitDistribution[2].Count) // Alice, 2-4 commits
+		assert.Equal(t, []model.DateCount{{Date: "2026-03-02", Count: 2}}, stats.Details.AcceptanceHistogram)
+		require.Len(t, stats.Details.NotAccepted, 1)
+		assert.Equal(t, "Carol", stats.Details.NotAccepted[0].StudentName)
+
+		summary, err := services.Assignments.Stats(ctx, assignment, &model.AssignmentStatsRequest{})
+		require.NoError(t, err)
+		assert.Nil(t, summary.Details)
+	})
+
+	t.Run("classroom", func(t *testing.T) {
+		stats, err := services.Classrooms.Stats(ctx, classroom.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.TotalStudents)
+		assert.Equal(t, 0, stats.LinkedStudents)
+		assert.Equal(t, 2, stats.TotalAssignments)
+		assert.Equal(t, 2, stats.ActiveAssignments)
+		assert.Equal(t, 2, stats.TotalSubmissions)
+
+		// Homework 2 has a deadline, so it comes first
+		require.Len(t, stats.Assignments, 2)
+		assert.Equal(t, other.ID, stats.Assignments[0].AssignmentID)
+		assert.Equal(t, 0, stats.Assignments[0].AcceptedCount)
+		hw1 := stats.Assignments[1]
+		assert.Equal(t, "hw1", hw1.Slug)
+		assert.Equal(t, 2, hw1.AcceptedCount)
+		assert.Equal(t, 1, hw1.SubmissionCount)
+		assert.InDelta(t, 2.0/3, hw1.AcceptanceRate, 0.001)
+	})
+
+	t.Run("missing classroom", func(t *testing.T) {
+		_, err := services.Classrooms.Stats(ctx, 9999)
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+
+	t.Run("activity", func(t *testing.T) {
+		passed := time.Now().Add(-time.Hour)
+		require.NoError(t, repos.Assignments.Create(ctx, &model.Assignment{
+			ClassroomID: classroom.ID, Name: "Homework 0", Slug: "hw0", TemplateRepository: "cs101/hw0-template",
+			TemplateRepositoryID: 3, Deadline: &passed, MaxTeamSize: 1,
+		}))
+		require.NoError(t, repos.Claims.Create(ctx, &model.RosterClaim{
+			ClassroomID: classroom.ID, RosterEntryID: roster["Carol"].ID, ForgejoUserID: 7,
+			ForgejoUsername: "carol", Status: model.ClaimStatusPending,
+		}))
+
+		activity, err := services.Classrooms.Activity(ctx)
+		require.NoError(t, err)
+
+		// Homework 0 is past its deadline, which has not been enforced
+		require.Len(t, activity.Assignments, 2)
+		assert.Equal(t, model.ActiveAssignment{
+			ClassroomID: classroom.ID, AssignmentID: assignment.ID, TotalStudents: 3, PendingAcceptance: 1,
+		}, activity.Assignments[0])
+		assert.Equal(t, other.ID, activity.Assignments[1].AssignmentID)
+		assert.Equal(t, 3, activity.Assignments[1].PendingAcceptance)
+		require.NotNil(t, activity.Assignments[1].Deadline)
+		assert.Equal(t, map[int64]int{classroom.ID: 1}, activity.PendingClaims)
+		assert.Equal(t, 1, activity.OverdueDeadlines)
+		require.NotNil(t, activity.OldestOverdueDeadline)
+		assert.WithinDuration(t, passed, *activity.OldestOverdueDeadline, time.Second)
+	})
+
+	t.Run("team submissions count once per student", func(t *testing.T) {
+		group := &model.Assignment{
+			ClassroomID: classroom.ID, Name: "Project", Slug: "project", TemplateRepository: "cs101/project-template",
+			TemplateRepositoryID: 4, MaxTeamSize: 2,
+		}
+		require.NoError(t, repos.Assignments.Create(ctx, group))
+		team := &model.Team{AssignmentID: group.ID, Name: "Pair", Slug: "pair", LeaderID: roster["Alice"].ID}
+		require.NoError(t, repos.Teams.Create(ctx, team))
+		for _, name := range []string{"Alice", "Bob"} {
+			require.NoError(t, repos.Teams.AddMember(ctx, &model.TeamMember{TeamID: team.ID, StudentID: roster[name].ID, Role: "member"}))
+		}
+		require.NoError(t, repos.Submissions.Create(ctx, &model.Submission{
+			AssignmentID: group.ID, TeamID: &team.ID, RepositoryName: "project-pair", RepositoryID: 10,
+			Status: SubmissionStatusLate, AcceptedAt: &accepted, LastCommitSHA: &sha, CommitCount: 6,
+		}))
+
+		stats, err := services.Assignments.Stats(ctx, group, &model.AssignmentStatsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.AcceptedCount)
+		assert.Equal(t, 2, stats.SubmissionCount)
+		assert.Equal(t, 0, stats.OnTimeSubmissions)
+		assert.Equal(t, 2, stats.LateSubmissions)
+		assert.Equal(t, 6.0, stats.AverageCommits)
+
+		classroomStats, err := services.Classrooms.Stats(ctx, classroom.ID)
+		require.NoError(t, err)
+		for _, p := range classroomStats.Assignments {
+			if p.AssignmentID == group.ID {
+				assert.Equal(t, stats.LateSubmissions, p.LateCount)
+			}
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// SubmissionService reads and downloads submissions
+type SubmissionService struct {
+	repos   *repository.Repositories
+	forgejo *forgejo.Client
+	cache   *cache.Store
+	logger  *zap.Logger
+}
+
+// NewSubmissionService creates a new submission service
+func NewSubmissionService(repos *repository.Repositories, fj *forgejo.Client, store *cache.Store, logger *zap.Logger) *SubmissionService {
+	return &SubmissionService{
+		repos:   repos,
+		forgejo: fj,
+		cache:   store,
+		logger:  logger,
+	}
+}
+
+// Get returns a submission by ID
+func (s *SubmissionService) Get(ctx context.Context, id int64) (*model.Submission, error) {
+	key := cache.SubmissionKey(id)
+	var submission *model.Submission
+	if s.cache.Get(ctx, key, &submission) {
+		return submission, nil
+	}
+
+	submission, err := s.repos.Submissions.GetByID(ctx, id)
+	if err != nil {
+		return nil, submissionError(err)
+	}
+	s.cache.Set(ctx, key, submission, s.cache.Config().SubmissionTTL)
+	return submission, nil
+}
+
+// List returns the submissions matching req
+func (s *SubmissionService) List(ctx context.Context, req *model.SubmissionListRequest) (*model.SubmissionListResponse, error) {
+	if req.TeamOnly && req.IndividualOnly {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "individual_only",
+			Message: "team_only and individual_only are mutually exclusive",
+			Code:    response.ErrValidationInvalidInput,
+		}})
+	}
+
+	key := cache.SubmissionListKey(req)
+	var list *model.SubmissionListResponse
+	if s.cache.Get(ctx, key, &list) {
+		return list, nil
+	}
+
+	list, err := s.repos.Submissions.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, key, list, s.cache.Config().SubmissionTTL)
+	return list, nil
+}
+
+// BelongsTo reports whether the submission is the roster entry's own, or
+// that of a team it is a member of
+func (s *SubmissionService) BelongsTo(ctx context.Context, submission *model.Submission, rosterEntryID int64) (bool, error) {
+	if submission.StudentID != nil {
+		return *submission.StudentID == rosterEntryID, nil
+	}
+	if submission.TeamID == nil {
+		return false, nil
+	}
+
+	team, err := s.repos.Teams.GetByMember(ctx, submission.AssignmentID, rosterEntryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return team.ID == *submission.TeamID, nil
+}
+
+// submissionError reports a missing submission as RESOURCE_NOT_FOUND
+func submissionError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound("Submission")
+	}
+	return err
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// manifestName is the name of the CSV file listing the folders of a
+// submission archive
+const manifestName = "manifest.csv"
+
+// manifestHeader is the header row of the manifest
+var manifestHeader = []string{
+	"folder", "submission_id", "type", "name", "student_ids", "forgejo_usernames",
+	"repository", "ref", "commit_sha", "status", "note",
+}
+
+// unsafeFolderChars matches characters not allowed in archive folder names
+var unsafeFolderChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SubmissionArchive is a download of all submissions of an assignment as
+// one archive, with one folder per student or team
+type SubmissionArchive struct {
+	Filename    string
+	ContentType string
+
+	service    *SubmissionService
+	assignment *model.Assignment
+	classroom  *model.Classroom
+	format     string
+	atDeadline bool
+}
+
+// manifestRow is one submission listed in the manifest
+type manifestRow struct {
+	folder     string
+	submission *model.Submission
+	kind       string // individual or team
+	name       string
+	studentIDs []string
+	usernames  []string
+	ref        string
+	sha        string
+	note       string // why the folder is missing or incomplete
+}
+
+// Archive prepares the download of an assignment's submissions. The archive
+// is only built when it is written with Write.
+func (s *SubmissionService) Archive(ctx context.Context, assignmentID int64, req *model.SubmissionDownloadRequest) (*SubmissionArchive, error) {
+	format := req.Format
+	if format == "" {
+		format = model.SubmissionArchiveZip
+	}
+	v := util.NewValidator()
+	v.ValidateEnum("format", format, "Format",
+		[]string{model.SubmissionArchiveZip, model.SubmissionArchiveTar, model.SubmissionArchiveTarGz})
+	if v.HasErrors() {
+		return nil, validationError(v.Errors())
+	}
+
+	a, err := s.repos.Assignments.GetByID(ctx, assignmentID)
+	if err != nil {
+		return nil, assignmentError(err)
+	}
+	if req.AtDeadline && a.Deadline == nil {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "at_deadline",
+			Message: "Assignment has no deadline",
+			Code:    response.ErrValidationInvalidInput,
+		}})
+	}
+	classroom, err := s.repos.Classrooms.GetByID(ctx, a.ClassroomID)
+	if err != nil {
+		return nil, classroomError(err)
+	}
+
+	name := classroom.Slug + "-" + a.Slug
+	if req.AtDeadline {
+		name += "-deadline"
+	}
+	return &SubmissionArchive{
+		Filename:    name + "." + format,
+		ContentType: archiveContentType(format),
+		service:     s,
+		assignment:  a,
+		classroom:   classroom,
+		format:      format,
+		atDeadline:  req.AtDeadline,
+	}, nil
+}
+
+// Write streams the archive to w. Each repository is fetched from Forgejo
+// and copied into the archive as it arrives, so nothing is buffered.
+// Submissions that cannot be downloaded are listed in the manifest with the
+// reason rather than failing the download; an error is only returned if the
+// archive itself cannot be written, in which case it is left incomplete.
+func (a *SubmissionArchive) Write(ctx context.Context, w io.Writer) error {
+	s := a.service
+	roster, err := a.loadRoster(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := newArchiveWriter(a.format, w)
+	folders := make(map[string]bool)
+	var rows []*manifestRow
+
+	for page := 1; ; page++ {
+		list, err := s.repos.Submissions.List(ctx, &model.SubmissionListRequest{
+			AssignmentID: &a.assignment.ID,
+			Page:         page,
+			PerPage:      repository.MaxPerPage,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range list.Submissions {
+			row, err := a.describe(ctx, &list.Submissions[i], roster)
+			if err != nil {
+				return err
+			}
+			row.folder = uniqueFolder(folders, row.folder, row.submission.ID)
+			rows = append(rows, row)
+
+			if err := a.copySubmission(ctx, out, row); err != nil {
+				return err
+			}
+		}
+
+		if page >= list.TotalPages {
+			break
+		}
+	}
+
+	manifest, err := renderManifest(rows)
+	if err != nil {
+		return err
+	}
+	err = out.add(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     manifestName,
+		Mode:     0o644,
+		Size:     int64(len(manifest)),
+		ModTime:  time.Now(),
+	}, bytes.NewReader(manifest))
+	if err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	s.logger.Info("Submissions downloaded",
+		zap.Int64("assignment_id", a.assignment.ID),
+		zap.String("format", a.format),
+		zap.Bool("at_deadline", a.atDeadline),
+		zap.Int("submissions", len(rows)),
+	)
+	return nil
+}
+
+// loadRoster returns the classroom roster by entry ID
+func (a *SubmissionArchive) loadRoster(ctx context.Context) (map[int64]*model.RosterEntry, error) {
+	roster := make(map[int64]*model.RosterEntry)
+	for page := 1; ; page++ {
+		list, err := a.service.repos.Roster.List(ctx, a.classroom.ID, &model.RosterListRequest{
+			Page:    page,
+			PerPage: repository.MaxPerPage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Students {
+			roster[list.Students[i].ID] = &list.Students[i]
+		}
+		if page >= list.TotalPages {
+			return roster, nil
+		}
+	}
+}
+
+// describe fills in the manifest row of a submission: who it belongs to and
+// the folder it is stored in
+func (a *SubmissionArchive) describe(ctx context.Context, submission *model.Submission, roster map[int64]*model.RosterEntry) (*manifestRow, error) {
+	row := &manifestRow{submission: submission}
+
+	if submission.TeamID == nil {
+		row.kind = "individual"
+		row.folder = "submission-" + strconv.FormatInt(submission.ID, 10)
+		if submission.StudentID != nil {
+			if entry, ok := roster[*submission.StudentID]; ok {
+				row.name = entry.StudentName
+				row.studentIDs = []string{entry.StudentID}
+				row.folder = entry.StudentID
+				if entry.ForgejoUsername != nil {
+					row.usernames = []string{*entry.ForgejoUsername}
+					row.folder = *entry.ForgejoUsername
+				}
+			}
+		}
+		return row, nil
+	}
+
+	row.kind = "team"
+	team, err := a.service.repos.Teams.GetByID(ctx, *submission.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := a.service.repos.Teams.ListMembers(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	row.name = team.Name
+	row.folder = "team-" + team.Slug
+	for _, m := range members {
+		if entry, ok := roster[m.StudentID]; ok {
+			row.studentIDs = append(row.studentIDs, entry.StudentID)
+		}
+		if m.ForgejoUsername != "" {
+			row.usernames = append(row.usernames, m.ForgejoUsername)
+		}
+	}
+	return row, nil
+}
+
+// copySubmission copies the submission's repository into its folder at the
+// requested ref, noting in row why it could not be
+func (a *SubmissionArchive) copySubmission(ctx context.Context, out archiveWriter, row *manifestRow) error {
+	s := a.service
+	submission := row.submission
+	org := a.classroom.OrganizationName
+
+	if a.atDeadline {
+		if submission.DeadlineTag == nil || *submission.DeadlineTag != DeadlineTagName(*a.assignment.Deadline) {
+			row.note = "not tagged at the deadline"
+			return nil
+		}
+		if submission.DeadlineUnresolved {
+			row.note = "commit at the deadline is unknown"
+			return nil
+		}
+		if submission.DeadlineSHA == nil {
+			row.note = "no commits at the deadline"
+			return nil
+		}
+		row.ref, row.sha = *submission.DeadlineTag, *submission.DeadlineSHA
+	} else {
+		head, err := s.forgejo.LatestCommit(ctx, org, submission.RepositoryName, forgejo.CommitListOptions{})
+		if err != nil {
+			return a.skip(ctx, row, err)
+		}
+		if head == nil {
+			row.note = "no commits"
+			return nil
+		}
+		// Fetch the commit rather than the branch, so that the manifest
+		// matches the content even if the student pushes meanwhile
+		row.ref, row.sha = head.SHA, head.SHA
+	}
+
+	archive, err := s.forgejo.GetArchive(ctx, org, submission.RepositoryName, row.ref, forgejo.ArchiveTarGz)
+	if err != nil {
+		return a.skip(ctx, row, err)
+	}
+	defer archive.Close()
+
+	err = copyRepositoryArchive(out, row.folder, archive)
+	var writeErr *archiveWriteError
+	if errors.As(err, &writeErr) {
+		return err
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		row.note = "incomplete: " + err.Error()
+		s.logger.Warn("Failed to download submission",
+			zap.Int64("submission_id", submission.ID),
+			zap.String("repository", submission.RepositoryName),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// skip notes why a submission could not be fetched, or returns the error
+// if the download was cancelled
+func (a *SubmissionArchive) skip(ctx context.Context, row *manifestRow, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	row.note = "download failed: " + AsError(err).Message
+	a.service.logger.Warn("Failed to download submission",
+		zap.Int64("submission_id", row.submission.ID),
+		zap.String("repository", row.submission.RepositoryName),
+		zap.Error(err),
+	)
+	return nil
+}
+
+// uniqueFolder returns a safe folder name based on name that is not used
+// yet, and marks it used. Names are compared ignoring case, as graders may
+// extract the archive on a case-insensitive file system.
+func uniqueFolder(used map[string]bool, name string, submissionID int64) string {
+	folder := strings.Trim(unsafeFolderChars.ReplaceAllString(name, "_"), "._")
+	if folder == "" {
+		folder = "submission"
+	}
+	if used[strings.ToLower(folder)] {
+		folder = fmt.Sprintf("%s-%d", folder, submissionID)
+	}
+	used[strings.ToLower(folder)] = true
+	return folder
+}
+
+// renderManifest renders the manifest CSV
+func renderManifest(rows []*manifestRow) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(manifestHeader); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.folder,
+			strconv.FormatInt(row.submission.ID, 10),
+			row.kind,
+			row.name,
+			strings.Join(row.studentIDs, ";"),
+			strings.Join(row.usernames, ";"),
+			row.submission.RepositoryName,
+			row.ref,
+			row.sha,
+			row.submission.Status,
+			row.note,
+		}
+		for i, field := range record {
+			record[i] = csvSafe(field)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvSafe keeps spreadsheets from evaluating a field as a formula
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/auth"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo/forgejotest"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func TestSubmissionService_Visibility(t *testing.T) {
+	services, server := setupTestServices(t)
+	repos := services.Submissions.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	addRoster := func(userID int64, login, role string) *model.RosterEntry {
+		entry := &model.RosterEntry{
+			ClassroomID: classroom.ID, StudentName: login, StudentEmail: login + "@example.com",
+			StudentID: login, ForgejoUsername: &login, ForgejoUserID: &userID, Role: role,
+		}
+		require.NoError(t, repos.Roster.Create(ctx, entry))
+		return entry
+	}
+	alice := addRoster(10, "alice", "student")
+	bob := addRoster(11, "bob", "student")
+	addRoster(12, "ta", "assistant")
+
+	assignment := &model.Assignment{ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", MaxTeamSize: 1}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	var submissions []*model.Submission
+	for i, student := range []*model.RosterEntry{alice, bob} {
+		s := &model.Submission{
+			AssignmentID: assignment.ID, StudentID: &student.ID, RepositoryName: "hw1-" + student.StudentID,
+			RepositoryID: int64(100 + i), Status: SubmissionStatusAccepted,
+		}
+		require.NoError(t, repos.Submissions.Create(ctx, s))
+		submissions = append(submissions, s)
+	}
+
+	t.Run("roles", func(t *testing.T) {
+		checker := services.Permissions
+		tests := []struct {
+			name      string
+			userID    int64
+			siteAdmin bool
+			role      auth.Role
+		}{
+			{"owner", 1, false, auth.RoleOwner},
+			{"site admin", 99, true, auth.RoleAdmin},
+			{"assistant", 12, false, auth.RoleAssistant},
+			{"student", 10, false, auth.RoleStudent},
+		}
+		for _, tt := range tests {
+			m, err := checker.Membership(ctx, tt.userID, tt.siteAdmin, classroom.ID)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.role, m.Role, tt.name)
+		}
+
+		_, err := checker.Membership(ctx, 42, false, classroom.ID)
+		assert.Equal(t, response.ErrAuthzForbidden, AsError(err).Code)
+
+		_, err = checker.Authorize(ctx, 10, false, classroom.ID, auth.PermManageAssignments)
+		assert.Equal(t, response.ErrAuthzInsufficientPermissions, AsError(err).Code)
+	})
+
+	t.Run("students only list their own submissions", func(t *testing.T) {
+		list, err := services.Submissions.List(ctx, &model.SubmissionListRequest{AssignmentID: &assignment.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+
+		list, err = services.Submissions.List(ctx, &model.SubmissionListRequest{AssignmentID: &assignment.ID, MemberID: &alice.ID})
+		require.NoError(t, err)
+		require.Len(t, list.Submissions, 1)
+		assert.Equal(t, submissions[0].ID, list.Submissions[0].ID)
+	})
+
+	t.Run("belongs to", func(t *testing.T) {
+		own, err := services.Submissions.BelongsTo(ctx, submissions[0], alice.ID)
+		require.NoError(t, err)
+		assert.True(t, own)
+
+		own, err = services.Submissions.BelongsTo(ctx, submissions[1], alice.ID)
+		require.NoError(t, err)
+		assert.False(t, own)
+	})
+}
+
+// readZip returns the content of every file in a zip archive by name
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestCopyRepositoryArchive(t *testing.T) {
+	var src bytes.Buffer
+	gz := gzip.NewWriter(&src)
+	tw := tar.NewWriter(gz)
+	entries := []struct {
+		hdr     tar.Header
+		content string
+	}{
+		{tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "abc"}}, ""},
+		{tar.Header{Typeflag: tar.TypeDir, Name: "hw1/", Mode: 0o775}, ""},
+		{tar.Header{Typeflag: tar.TypeReg, Name: "hw1/main.go", Mode: 0o100664}, "package main\n"},
+		{tar.Header{Typeflag: tar.TypeReg, Name: "hw1/../../etc/passwd", Mode: 0o644}, "root"},
+		{tar.Header{Typeflag: tar.TypeSymlink, Name: "hw1/link", Linkname: "main.go", Mode: 0o777}, ""},
+		{tar.Header{Typeflag: tar.TypeSymlink, Name: "hw1/escape", Linkname: "../../secret", Mode: 0o777}, ""},
+	}
+	for _, e := range entries {
+		hdr := e.hdr
+		hdr.Size = int64(len(e.content))
+		require.NoError(t, tw.WriteHeader(&hdr))
+		_, err := io.WriteString(tw, e.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	t.Run("tar", func(t *testing.T) {
+		var out bytes.Buffer
+		w := newArchiveWriter(model.SubmissionArchiveTar, &out)
+		require.NoError(t, copyRepositoryArchive(w, "alice", bytes.NewReader(src.Bytes())))
+		require.NoError(t, w.Close())
+
+		tr := tar.NewReader(&out)
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, hdr.Name)
+			switch hdr.Name {
+			case "alice/main.go":
+				assert.EqualValues(t, 0o664, hdr.Mode)
+				content, _ := io.ReadAll(tr)
+				assert.Equal(t, "package main\n", string(content))
+			case "alice/link":
+				assert.Equal(t, "main.go", hdr.Linkname)
+			}
+		}
+		assert.Equal(t, []string{"alice/", "alice/main.go", "alice/link"}, names)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		var out bytes.Buffer
+		w := newArchiveWriter(model.SubmissionArchiveZip, &out)
+		require.NoError(t, copyRepositoryArchive(w, "alice", bytes.NewReader(src.Bytes())))
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, map[string]string{
+			"alice/":        "",
+			"alice/main.go": "package main\n",
+			"alice/link":    "main.go",
+		}, readZip(t, out.Bytes()))
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		w := newArchiveWriter(model.SubmissionArchiveZip, io.Discard)
+		err := copyRepositoryArchive(w, "alice", strings.NewReader("not a tarball"))
+		require.Error(t, err)
+		var writeErr *archiveWriteError
+		assert.False(t, errors.As(err, &writeErr))
+	})
+
+	t.Run("truncated archive", func(t *testing.T) {
+		// A file that does not compress, cut off halfway through
+		content := make([]byte, 64*1024)
+		rand.New(rand.NewSource(1)).Read(content)
+		var src bytes.Buffer
+		gz := gzip.NewWriter(&src)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "hw1/data.bin", Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		truncated := src.Bytes()[:src.Len()/2]
+
+		// readTar returns the content of every file in a tar archive by name
+		readTar := func(t *testing.T, r io.Reader) map[string]string {
+			t.Helper()
+			tr := tar.NewReader(r)
+			files := make(map[string]string)
+			for {
+				hdr, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return files
+				}
+				require.NoError(t, err)
+				data, err := io.ReadAll(tr)
+				require.NoError(t, err)
+				files[hdr.Name] = string(data)
+			}
+		}
+
+		for _, format := range []string{model.SubmissionArchiveZip, model.SubmissionArchiveTar, model.SubmissionArchiveTarGz} {
+			t.Run(format, func(t *testing.T) {
+				var out bytes.Buffer
+				w := newArchiveWriter(format, &out)
+				err := copyRepositoryArchive(w, "alice", bytes.NewReader(truncated))
+				require.Error(t, err)
+				var writeErr *archiveWriteError
+				assert.False(t, errors.As(err, &writeErr))
+
+				// The archive can still be written after the short entry
+				require.NoError(t, w.add(&tar.Header{Typeflag: tar.TypeReg, Name: "bob/README.md", Mode: 0o644, Size: 3}, strings.NewReader("bob")))
+				require.NoError(t, w.Close())
+
+				var files map[string]string
+				switch format {
+				case model.SubmissionArchiveZip:
+					files = readZip(t, out.Bytes())
+				case model.SubmissionArchiveTar:
+					files = readTar(t, &out)
+				case model.SubmissionArchiveTarGz:
+					gr, err := gzip.NewReader(&out)
+					require.NoError(t, err)
+					files = readTar(t, gr)
+				}
+				assert.Equal(t, "bob", files["bob/README.md"])
+				require.Contains(t, files, "alice/data.bin")
+				data := files["alice/data.bin"]
+				if format != model.SubmissionArchiveZip {
+					assert.Len(t, data, len(content))
+				}
+				assert.True(t, strings.HasPrefix(string(content), strings.TrimRight(data, "\x00")))
+			})
+		}
+	})
+}
+
+func TestUniqueFolder(t *testing.T) {
+	used := make(map[string]bool)
+	assert.Equal(t, "alice", uniqueFolder(used, "alice", 1))
+	assert.Equal(t, "Alice-2", uniqueFolder(used, "Alice", 2))
+	assert.Equal(t, "a_.._b", uniqueFolder(used, "a/../b", 3))
+	assert.Equal(t, "submission", uniqueFolder(used, "..", 4))
+	assert.Equal(t, "'=1+1", csvSafe("=1+1"))
+	assert.Equal(t, "alice", csvSafe("alice"))
+}
+
+func TestSubmissionService_Archive(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Submissions
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	assignment := &model.Assignment{ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", Deadline: &deadline, MaxTeamSize: 1}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	heads := make(map[string]string)
+	submissions := make(map[string]int64)
+	for i, login := range []string{"alice", "bob", "carol"} {
+		login := login
+		entry := &model.RosterEntry{
+			ClassroomID: classroom.ID, StudentName: login, StudentEmail: login + "@example.com",
+			StudentID: "s" + login, ForgejoUsername: &login, Role: "student",
+		}
+		require.NoError(t, repos.Roster.Create(ctx, entry))
+
+		name := "hw1-" + login
+		submission := &model.Submission{
+			AssignmentID: assignment.ID, StudentID: &entry.ID, RepositoryName: name,
+			RepositoryID: int64(100 + i), Status: SubmissionStatusAccepted,
+		}
+		require.NoError(t, repos.Submissions.Create(ctx, submission))
+		submissions[login] = submission.ID
+		if login != "carol" {
+			// carol's repository was deleted from Forgejo
+			server.AddRepository("cs101", name, false)
+			head := server.PushAt("cs101", name, forgejotest.DefaultBranch, "Solution", deadline.Add(-time.Minute)).SHA
+			pushedAt := deadline.Add(-time.Minute)
+			heads[login] = head
+			submission.LastCommitSHA, submission.LastCommitAt = &head, &pushedAt
+			require.NoError(t, repos.Submissions.UpdateLastCommit(ctx, submission, true))
+		}
+	}
+
+	// bob pushes after the deadline, which records his head at the deadline
+	bob, err := repos.Submissions.GetByID(ctx, submissions["bob"])
+	require.NoError(t, err)
+	lateFix := server.PushAt("cs101", "hw1-bob", forgejotest.DefaultBranch, "Late fix", deadline.Add(time.Minute)).SHA
+	require.NoError(t, services.Deadlines.RecordPush(ctx, bob, &forgejo.PushPayload{
+		Ref: "refs/heads/" + forgejotest.DefaultBranch, Before: heads["bob"], After: lateFix,
+	}, deadline.Add(time.Minute)))
+
+	download := func(t *testing.T, req *model.SubmissionDownloadRequest) (*SubmissionArchive, map[string]string, [][]string) {
+		t.Helper()
+		archive, err := svc.Archive(ctx, assignment.ID, req)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, archive.Write(ctx, &buf))
+
+		files := readZip(t, buf.Bytes())
+		manifest, err := csv.NewReader(strings.NewReader(files[manifestName])).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, manifestHeader, manifest[0])
+		return archive, files, manifest[1:]
+	}
+
+	t.Run("latest commits", func(t *testing.T) {
+		archive, files, manifest := download(t, &model.SubmissionDownloadRequest{})
+		assert.Equal(t, "cs-101-hw1.zip", archive.Filename)
+		assert.Equal(t, "application/zip", archive.ContentType)
+
+		assert.Equal(t, server.HeadSHA("cs101", "hw1-alice", forgejotest.DefaultBranch)+"\n", files["alice/COMMIT"])
+		assert.Equal(t, server.HeadSHA("cs101", "hw1-bob", forgejotest.DefaultBranch)+"\n", files["bob/COMMIT"])
+		assert.Contains(t, files, "alice/README.md")
+
+		require.Len(t, manifest, 3)
+		assert.Equal(t, []string{"alice", "individual", "salice", "alice"},
+			[]string{manifest[0][0], manifest[0][2], manifest[0][4], manifest[0][5]})
+		assert.Equal(t, server.HeadSHA("cs101", "hw1-bob", forgejotest.DefaultBranch), manifest[1][8])
+		assert.Empty(t, manifest[1][10])
+		assert.Equal(t, "carol", manifest[2][0])
+		assert.Contains(t, manifest[2][10], "download failed")
+	})
+
+	t.Run("failed archive downloads are noted", func(t *testing.T) {
+		server.FailNext(http.MethodGet, "/repos/cs101/hw1-alice/archive/"+heads["alice"]+".tar.gz", http.StatusNotFound, 1)
+		_, files, manifest := download(t, &model.SubmissionDownloadRequest{})
+		assert.NotContains(t, files, "alice/COMMIT")
+		assert.Contains(t, manifest[0][10], "download failed")
+		assert.Contains(t, files, "bob/COMMIT")
+	})
+
+	t.Run("at the deadline", func(t *testing.T) {
+		_, files, manifest := download(t, &model.SubmissionDownloadRequest{AtDeadline: true})
+		assert.Len(t, files, 1)
+		assert.Equal(t, "not tagged at the deadline", manifest[0][10])
+
+		// carol's repository cannot be tagged, the others are
+		require.Error(t, services.Deadlines.Enforce(ctx, assignment.ID, deadline))
+
+		// Tags point at the heads at the deadline, not at enforcement
+		server.PushAt("cs101", "hw1-alice", forgejotest.DefaultBranch, "After enforcement", deadline.Add(time.Hour))
+		archive, files, manifest := download(t, &model.SubmissionDownloadRequest{AtDeadline: true})
+		assert.Equal(t, "cs-101-hw1-deadline.zip", archive.Filename)
+		assert.Equal(t, heads["alice"]+"\n", files["alice/COMMIT"])
+		assert.Equal(t, heads["bob"]+"\n", files["bob/COMMIT"])
+		assert.Equal(t, DeadlineTagName(deadline), manifest[1][7])
+		assert.Equal(t, heads["bob"], manifest[1][8])
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		archive, err := svc.Archive(ctx, assignment.ID, &model.SubmissionDownloadRequest{Format: model.SubmissionArchiveTarGz})
+		require.NoError(t, err)
+		assert.Equal(t, "cs-101-hw1.tar.gz", archive.Filename)
+
+		var buf bytes.Buffer
+		require.NoError(t, archive.Write(ctx, &buf))
+		gz, err := gzip.NewReader(&buf)
+		require.NoError(t, err)
+		tr := tar.NewReader(gz)
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, hdr.Name)
+		}
+		assert.Contains(t, names, "bob/COMMIT")
+		assert.Equal(t, manifestName, names[len(names)-1])
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := svc.Archive(ctx, assignment.ID, &model.SubmissionDownloadRequest{Format: "rar"})
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+
+		undated := &model.Assignment{ClassroomID: classroom.ID, Name: "Homework 2", Slug: "hw2", MaxTeamSize: 1}
+		require.NoError(t, repos.Assignments.Create(ctx, undated))
+		_, err = svc.Archive(ctx, undated.ID, &model.SubmissionDownloadRequest{AtDeadline: true})
+		assert.Equal(t, response.ErrValidationInvalidInput, AsError(err).Code)
+
+		_, err = svc.Archive(ctx, 99999, &model.SubmissionDownloadRequest{})
+		assert.Equal(t, response.ErrResourceNotFound, AsError(err).Code)
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"code.forgejo.org/forgejo/classroom/internal/cache"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/repository"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+	"code.forgejo.org/forgejo/classroom/internal/util"
+)
+
+// Reasons for ignoring a push
+const (
+	pushIgnoredNotSubmission = "repository is not a submission"
+	pushIgnoredOtherBranch   = "push is not to the default branch"
+	pushIgnoredDeleted       = "branch was deleted"
+)
+
+// WebhookService keeps submissions up to date with the Forgejo webhooks of
+// their repositories
+type WebhookService struct {
+	repos     *repository.Repositories
+	forgejo   *forgejo.Client
+	deadlines *DeadlineService
+	cache     *cache.Store
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repos *repository.Repositories, fj *forgejo.Client, deadlines *DeadlineService, store *cache.Store, logger *zap.Logger) *WebhookService {
+	return &WebhookService{
+		repos:     repos,
+		forgejo:   fj,
+		deadlines: deadlines,
+		cache:     store,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// HandlePush records a push to the default branch of a submission
+// repository: the latest commit and the commit count are updated, and the
+// submission is marked late if the push came after the deadline. Pushes to
+// other branches and repositories are ignored.
+func (s *WebhookService) HandlePush(ctx context.Context, p *forgejo.PushPayload) (*model.PushResult, error) {
+	pushedAt := s.now()
+	if p.Repository == nil || p.Repository.ID == 0 {
+		return nil, validationError(util.ValidationErrors{{
+			Field:   "repository",
+			Message: "repository is required",
+			Code:    response.ErrValidationMissingField,
+		}})
+	}
+
+	submission, err := s.repos.Submissions.GetByRepositoryID(ctx, p.Repository.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &model.PushResult{Ignored: pushIgnoredNotSubmission}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.PushResult{SubmissionID: &submission.ID}
+	switch {
+	case p.Branch() != p.Repository.DefaultBranch:
+		result.Ignored = pushIgnoredOtherBranch
+		return result, nil
+	case p.Deleted():
+		result.Ignored = pushIgnoredDeleted
+		return result, nil
+	}
+
+	a, err := s.repos.Assignments.GetByID(ctx, submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *submission
+	sha := p.After
+	submission.LastCommitSHA = &sha
+	submission.LastCommitMessage = pushMessage(p)
+	submission.LastCommitAt = &pushedAt
+	count, counted := s.countCommits(ctx, p, submission)
+	submission.CommitCount = count
+	if err := s.repos.Submissions.UpdateLastCommit(ctx, submission, counted); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, cache.SubmissionKey(submission.ID), cache.SubmissionListPattern)
+	logAudit(ctx, s.repos, s.logger,
+		auditEvent(a.ClassroomID, model.AuditSubmissionPush, model.AuditTargetSubmission, submission.ID), &before, submission)
+
+	if err := s.deadlines.RecordPush(ctx, &before, p, pushedAt); err != nil {
+		return nil, err
+	}
+	updated, err := s.repos.Submissions.GetByID(ctx, submission.ID)
+	if err != nil {
+		return nil, err
+	}
+	if updated.IsLate() {
+		s.logger.Info("Late push",
+			zap.Int64("submission_id", submission.ID),
+			zap.String("repository", p.Repository.FullName),
+			zap.String("sha", sha),
+			zap.Time("pushed_at", pushedAt),
+		)
+	}
+
+	result.LastCommitSHA = sha
+	result.CommitCount = updated.CommitCount
+	result.Status = updated.Status
+	return result, nil
+}
+
+// countCommits returns the number of commits on the pushed branch and
+// true. Forgejo is asked first, since the push alone cannot tell a force
+// push from new commits; if it cannot be reached the number of pushed
+// commits is returned with false, to be added to the stored count.
+func (s *WebhookService) countCommits(ctx context.Context, p *forgejo.PushPayload, submission *model.Submission) (int, bool) {
+	owner := ""
+	if p.Repository.Owner != nil {
+		owner = p.Repository.Owner.Login
+	}
+	count, err := s.forgejo.CountCommits(ctx, owner, p.Repository.Name, forgejo.CommitListOptions{SHA: p.After})
+	if err == nil {
+		return count, true
+	}
+
+	s.logger.Warn("Failed to count commits, adding the pushed ones",
+		zap.Int64("submission_id", submission.ID),
+		zap.String("repository", p.Repository.FullName),
+		zap.Error(err),
+	)
+	pushed := p.TotalCommits
+	if pushed == 0 {
+		pushed = len(p.Commits)
+	}
+	return pushed, false
+}
+
+// pushMessage returns the message of the pushed head commit, if the
+// payload contains it
+func pushMessage(p *forgejo.PushPayload) *string {
+	head := p.HeadCommit
+	if head == nil {
+		for _, c := range p.Commits {
+			if c.ID == p.After {
+				head = c
+				break
+			}
+		}
+	}
+	if head == nil {
+		return nil
+	}
+	message := strings.TrimSpace(head.Message)
+	return &message
+}
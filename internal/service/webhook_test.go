@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/forgejo"
+	"code.forgejo.org/forgejo/classroom/internal/forgejo/forgejotest"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+func TestWebhookService_HandlePush(t *testing.T) {
+	services, server := setupTestServices(t)
+	svc := services.Webhooks
+	repos := svc.repos
+	ctx := context.Background()
+
+	server.AddOrganization("cs101")
+	server.AddOrganizationOwner("cs101", "prof")
+	classroom, err := services.Classrooms.Create(ctx, &Actor{ID: 1, Login: "prof"},
+		&model.CreateClassroomRequest{Name: "CS 101", OrganizationName: "cs101"})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	assignment := &model.Assignment{
+		ClassroomID: classroom.ID, Name: "Homework 1", Slug: "hw1", TemplateRepository: "cs101/hw1-template",
+		TemplateRepositoryID: 1, Deadline: &deadline, MaxTeamSize: 1,
+	}
+	require.NoError(t, repos.Assignments.Create(ctx, assignment))
+
+	repo := server.AddRepository("cs101", "hw1-alice", false)
+	submission := &model.Submission{
+		AssignmentID: assignment.ID, RepositoryName: repo.Name, RepositoryID: repo.ID,
+		RepositoryURL: repo.HTMLURL, Status: SubmissionStatusAccepted, CommitCount: 1,
+	}
+	require.NoError(t, repos.Submissions.Create(ctx, submission))
+
+	// push simulates pushing a commit and returns its webhook payload
+	push := func(message string) *forgejo.PushPayload {
+		before := server.HeadSHA("cs101", repo.Name, forgejotest.DefaultBranch)
+		commit := server.PushAt("cs101", repo.Name, forgejotest.DefaultBranch, message, time.Now())
+		head := &forgejo.PayloadCommit{ID: commit.SHA, Message: message + "\n"}
+		return &forgejo.PushPayload{
+			Ref: "refs/heads/" + forgejotest.DefaultBranch, Before: before, After: commit.SHA,
+			Commits: []*forgejo.PayloadCommit{head}, TotalCommits: 1, HeadCommit: head, Repository: &repo,
+		}
+	}
+
+	t.Run("push updates the latest commit", func(t *testing.T) {
+		svc.now = func() time.Time { return deadline.Add(-time.Minute) }
+		p := push("Solve part 1")
+
+		result, err := svc.HandlePush(ctx, p)
+		require.NoError(t, err)
+		assert.Empty(t, result.Ignored)
+		assert.Equal(t, 2, result.CommitCount)
+
+		got, err := services.Submissions.Get(ctx, submission.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.After, *got.LastCommitSHA)
+		assert.Equal(t, "Solve part 1", *got.LastCommitMessage)
+		assert.Equal(t, 2, got.CommitCount)
+		assert.WithinDuration(t, deadline.Add(-time.Minute), *got.LastCommitAt, time.Second)
+		assert.Equal(t, SubmissionStatusAccepted, got.Status)
+	})
+
+	t.Run("pushed commits are added when Forgejo cannot count", func(t *testing.T) {
+		p := push("Solve part 2")
+		server.FailNext(http.MethodGet, "/repos/cs101/"+repo.Name+"/commits", http.StatusNotFound, 1)
+
+		result, err := svc.HandlePush(ctx, p)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.CommitCount)
+	})
+
+	t.Run("other branches and repositories are ignored", func(t *testing.T) {
+		p := push("Experiment")
+		p.Ref = "refs/heads/experiment"
+		result, err := svc.HandlePush(ctx, p)
+		require.NoError(t, err)
+		assert.Equal(t, pushIgnoredOtherBranch, result.Ignored)
+
+		other := server.AddRepository("cs101", "scratch", false)
+		p.Ref, p.Repository = "refs/heads/"+forgejotest.DefaultBranch, &other
+		result, err = svc.HandlePush(ctx, p)
+		require.NoError(t, err)
+		assert.Equal(t, pushIgnoredNotSubmission, result.Ignored)
+		assert.Nil(t, result.SubmissionID)
+	})
+
+	t.Run("push after the deadline is late", func(t *testing.T) {
+		svc.now = func() time.Time { return deadline.Add(time.Minute) }
+
+		result, err := svc.HandlePush(ctx, push("Too late"))
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusLate, result.Status)
+
+		got, err := services.Submissions.Get(ctx, submission.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusLate, got.Status)
+		assert.Equal(t, "Too late", *got.LastCommitMessage)
+	})
+
+	t.Run("recording a commit leaves the status alone", func(t *testing.T) {
+		stale := *submission // still accepted
+		sha := "0123456789abcdef"
+		stale.LastCommitSHA, stale.CommitCount = &sha, 7
+		require.NoError(t, repos.Submissions.UpdateLastCommit(ctx, &stale, true))
+
+		got, err := repos.Submissions.GetByID(ctx, submission.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SubmissionStatusLate, got.Status)
+		assert.Equal(t, 7, got.CommitCount)
+	})
+}
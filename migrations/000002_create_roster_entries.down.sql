@@ -0,0 +1,2 @@
+-- Drop roster_entries table
+DROP TABLE IF EXISTS roster_entries;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- Create roster_entries table
+CREATE TABLE roster_entries (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    student_name VARCHAR(255) NOT NULL,
+    student_email VARCHAR(255) NOT NULL,
+    student_id VARCHAR(255) NOT NULL,
+    forgejo_username VARCHAR(255),
+    forgejo_user_id BIGINT,
+    role VARCHAR(32) NOT NULL DEFAULT 'student',
+    linked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_roster_entries_classroom_student_id ON roster_entries (classroom_id, student_id);
+CREATE UNIQUE INDEX idx_roster_entries_classroom_email ON roster_entries (classroom_id, lower(student_email));
+CREATE UNIQUE INDEX idx_roster_entries_classroom_forgejo_user_id ON roster_entries (classroom_id, forgejo_user_id)
+    WHERE forgejo_user_id IS NOT NULL;
+CREATE INDEX idx_roster_entries_classroom_linked ON roster_entries (classroom_id, (forgejo_user_id IS NOT NULL));
+CREATE INDEX idx_roster_entries_forgejo_user_id ON roster_entries (forgejo_user_id) WHERE forgejo_user_id IS NOT NULL;
+
+-- Add constraints
+ALTER TABLE roster_entries ADD CONSTRAINT chk_roster_entries_role
+    CHECK (role IN ('student', 'assistant', 'instructor'));
+ALTER TABLE roster_entries ADD CONSTRAINT chk_roster_entries_link
+    CHECK ((forgejo_username IS NULL) = (forgejo_user_id IS NULL));
+ALTER TABLE roster_entries ADD CONSTRAINT chk_roster_entries_student_name_length
+    CHECK (char_length(student_name) >= 1 AND char_length(student_name) <= 255);
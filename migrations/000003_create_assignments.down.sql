@@ -0,0 +1,2 @@
+-- Drop assignments table
+DROP TABLE IF EXISTS assignments;
\ No newline at end of file
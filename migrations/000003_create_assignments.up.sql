@@ -0,0 +1,29 @@
+-- Create assignments table
+CREATE TABLE assignments (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    description TEXT,
+    template_repository VARCHAR(512) NOT NULL,
+    template_repository_id BIGINT NOT NULL,
+    deadline TIMESTAMP WITH TIME ZONE,
+    max_team_size INTEGER NOT NULL DEFAULT 1,
+    auto_accept BOOLEAN NOT NULL DEFAULT false,
+    public BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_assignments_classroom_slug ON assignments (classroom_id, slug);
+CREATE INDEX idx_assignments_deadline ON assignments (deadline) WHERE deadline IS NOT NULL;
+CREATE INDEX idx_assignments_created_at ON assignments (created_at);
+
+-- Add constraints
+ALTER TABLE assignments ADD CONSTRAINT chk_assignments_slug_format
+    CHECK (slug ~ '^[a-z0-9]+(?:-[a-z0-9]+)*$');
+ALTER TABLE assignments ADD CONSTRAINT chk_assignments_name_length
+    CHECK (char_length(name) >= 1 AND char_length(name) <= 255);
+ALTER TABLE assignments ADD CONSTRAINT chk_assignments_max_team_size
+    CHECK (max_team_size >= 1);
@@ -0,0 +1,2 @@
+-- Drop teams table
+DROP TABLE IF EXISTS teams;
\ No newline at end of file
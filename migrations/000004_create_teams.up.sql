@@ -0,0 +1,24 @@
+-- Create teams table
+CREATE TABLE teams (
+    id BIGSERIAL PRIMARY KEY,
+    assignment_id BIGINT NOT NULL REFERENCES assignments (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    description TEXT,
+    leader_id BIGINT NOT NULL REFERENCES roster_entries (id) ON DELETE RESTRICT,
+    member_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_teams_assignment_slug ON teams (assignment_id, slug);
+CREATE INDEX idx_teams_leader_id ON teams (leader_id);
+
+-- Add constraints
+ALTER TABLE teams ADD CONSTRAINT chk_teams_slug_format
+    CHECK (slug ~ '^[a-z0-9]+(?:-[a-z0-9]+)*$');
+ALTER TABLE teams ADD CONSTRAINT chk_teams_name_length
+    CHECK (char_length(name) >= 1 AND char_length(name) <= 255);
+ALTER TABLE teams ADD CONSTRAINT chk_teams_member_count
+    CHECK (member_count >= 0);
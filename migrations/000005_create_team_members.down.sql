@@ -0,0 +1,2 @@
+-- Drop team_members table
+DROP TABLE IF EXISTS team_members;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- Create team_members table
+CREATE TABLE team_members (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    student_id BIGINT NOT NULL REFERENCES roster_entries (id) ON DELETE CASCADE,
+    role VARCHAR(32) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_team_members_team_student ON team_members (team_id, student_id);
+CREATE UNIQUE INDEX idx_team_members_team_leader ON team_members (team_id) WHERE role = 'leader';
+CREATE INDEX idx_team_members_student_id ON team_members (student_id);
+
+-- Add constraints
+ALTER TABLE team_members ADD CONSTRAINT chk_team_members_role
+    CHECK (role IN ('leader', 'member'));
@@ -0,0 +1,2 @@
+-- Drop submissions table
+DROP TABLE IF EXISTS submissions;
\ No newline at end of file
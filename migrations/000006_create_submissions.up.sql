@@ -0,0 +1,35 @@
+-- Create submissions table
+CREATE TABLE submissions (
+    id BIGSERIAL PRIMARY KEY,
+    assignment_id BIGINT NOT NULL REFERENCES assignments (id) ON DELETE CASCADE,
+    student_id BIGINT REFERENCES roster_entries (id) ON DELETE CASCADE,
+    team_id BIGINT REFERENCES teams (id) ON DELETE CASCADE,
+    repository_name VARCHAR(255) NOT NULL,
+    repository_id BIGINT NOT NULL,
+    repository_url VARCHAR(512) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    last_commit_sha VARCHAR(64),
+    last_commit_message TEXT,
+    commit_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_submissions_assignment_student ON submissions (assignment_id, student_id)
+    WHERE student_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_submissions_assignment_team ON submissions (assignment_id, team_id)
+    WHERE team_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_submissions_repository_id ON submissions (repository_id);
+CREATE INDEX idx_submissions_assignment_status ON submissions (assignment_id, status);
+CREATE INDEX idx_submissions_student_id ON submissions (student_id) WHERE student_id IS NOT NULL;
+CREATE INDEX idx_submissions_team_id ON submissions (team_id) WHERE team_id IS NOT NULL;
+
+-- Add constraints
+ALTER TABLE submissions ADD CONSTRAINT chk_submissions_status
+    CHECK (status IN ('pending', 'accepted', 'late'));
+ALTER TABLE submissions ADD CONSTRAINT chk_submissions_owner
+    CHECK ((student_id IS NULL) <> (team_id IS NULL));
+ALTER TABLE submissions ADD CONSTRAINT chk_submissions_commit_count
+    CHECK (commit_count >= 0);
@@ -0,0 +1,2 @@
+-- Drop jobs table
+DROP TABLE IF EXISTS jobs;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- Create jobs table
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    priority INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 4,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_jobs_ready ON jobs (priority DESC, run_at, id) WHERE status = 'pending';
+CREATE INDEX idx_jobs_running ON jobs (locked_at) WHERE status = 'running';
+CREATE INDEX idx_jobs_dead ON jobs (type, finished_at) WHERE status = 'dead';
+
+-- Add constraints
+ALTER TABLE jobs ADD CONSTRAINT chk_jobs_status
+    CHECK (status IN ('pending', 'running', 'succeeded', 'dead'));
+ALTER TABLE jobs ADD CONSTRAINT chk_jobs_attempts
+    CHECK (attempts >= 0 AND max_attempts >= 1);
@@ -0,0 +1,7 @@
+-- Drop deadline enforcement columns
+DROP INDEX IF EXISTS idx_submissions_repository_id_status;
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_tagged_at;
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_sha;
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_tag;
+ALTER TABLE assignments DROP COLUMN IF EXISTS deadline_enforced_for;
+ALTER TABLE assignments DROP COLUMN IF EXISTS deadline_scheduled_for;
\ No newline at end of file
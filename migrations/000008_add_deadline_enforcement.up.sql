@@ -0,0 +1,11 @@
+-- Track deadline enforcement on assignments
+ALTER TABLE assignments ADD COLUMN deadline_scheduled_for TIMESTAMP WITH TIME ZONE;
+ALTER TABLE assignments ADD COLUMN deadline_enforced_for TIMESTAMP WITH TIME ZONE;
+
+-- Record the deadline tag created in each submission repository
+ALTER TABLE submissions ADD COLUMN deadline_tag VARCHAR(255);
+ALTER TABLE submissions ADD COLUMN deadline_sha VARCHAR(64);
+ALTER TABLE submissions ADD COLUMN deadline_tagged_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes
+CREATE INDEX idx_submissions_repository_id_status ON submissions (repository_id, status);
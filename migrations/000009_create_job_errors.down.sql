@@ -0,0 +1,3 @@
+-- Drop job_errors table
+DROP TABLE IF EXISTS job_errors;
+ALTER TABLE jobs DROP COLUMN IF EXISTS result;
\ No newline at end of file
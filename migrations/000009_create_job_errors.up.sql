@@ -0,0 +1,20 @@
+-- Store the outcome of finished jobs
+ALTER TABLE jobs ADD COLUMN result JSONB;
+
+-- Create job_errors table
+CREATE TABLE job_errors (
+    id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    field VARCHAR(255) NOT NULL DEFAULT '',
+    code VARCHAR(64) NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_job_errors_job_id_row_number ON job_errors (job_id, row_number, id);
+
+-- Add constraints
+ALTER TABLE job_errors ADD CONSTRAINT chk_job_errors_row_number
+    CHECK (row_number >= 0);
@@ -0,0 +1,2 @@
+-- Drop assignment_invitations table
+DROP TABLE IF EXISTS assignment_invitations;
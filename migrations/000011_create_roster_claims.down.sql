@@ -0,0 +1,3 @@
+-- Drop roster_claims and classroom_join_links tables
+DROP TABLE IF EXISTS roster_claims;
+DROP TABLE IF EXISTS classroom_join_links;
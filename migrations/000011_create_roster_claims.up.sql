@@ -0,0 +1,36 @@
+-- Create classroom_join_links table
+CREATE TABLE classroom_join_links (
+    classroom_id BIGINT PRIMARY KEY REFERENCES classrooms (id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    requires_approval BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create roster_claims table
+CREATE TABLE roster_claims (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    roster_entry_id BIGINT NOT NULL REFERENCES roster_entries (id) ON DELETE CASCADE,
+    forgejo_user_id BIGINT NOT NULL,
+    forgejo_username VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    decided_by VARCHAR(255),
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_classroom_join_links_token ON classroom_join_links (token);
+CREATE INDEX idx_roster_claims_classroom_status ON roster_claims (classroom_id, status, created_at);
+-- A roster entry and a Forgejo account each have at most one pending claim
+CREATE UNIQUE INDEX idx_roster_claims_pending_entry ON roster_claims (roster_entry_id)
+    WHERE status = 'pending';
+CREATE UNIQUE INDEX idx_roster_claims_pending_user ON roster_claims (classroom_id, forgejo_user_id)
+    WHERE status = 'pending';
+
+-- Add constraints
+ALTER TABLE roster_claims ADD CONSTRAINT chk_roster_claims_status
+    CHECK (status IN ('pending', 'approved', 'rejected'));
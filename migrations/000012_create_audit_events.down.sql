@@ -0,0 +1,3 @@
+-- Drop audit_events table
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
@@ -0,0 +1,30 @@
+-- Create audit_events table. Events outlive their classroom, so there is no
+-- foreign key to classrooms.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    classroom_id BIGINT NOT NULL,
+    actor_id BIGINT,
+    actor_login VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id BIGINT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_audit_events_classroom_created ON audit_events (classroom_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id);
+
+-- The audit log is append-only
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,4 @@
+-- Restore the immediate check on team leaders
+ALTER TABLE teams DROP CONSTRAINT teams_leader_id_fkey;
+ALTER TABLE teams ADD CONSTRAINT teams_leader_id_fkey
+    FOREIGN KEY (leader_id) REFERENCES roster_entries (id) ON DELETE RESTRICT;
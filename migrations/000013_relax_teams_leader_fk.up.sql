@@ -0,0 +1,7 @@
+-- Check team leaders at the end of the statement instead of immediately, so
+-- that deleting a classroom can cascade to its roster entries and, through
+-- its assignments, to their teams. Removing a team leader on its own is
+-- still refused.
+ALTER TABLE teams DROP CONSTRAINT teams_leader_id_fkey;
+ALTER TABLE teams ADD CONSTRAINT teams_leader_id_fkey
+    FOREIGN KEY (leader_id) REFERENCES roster_entries (id) ON DELETE NO ACTION;
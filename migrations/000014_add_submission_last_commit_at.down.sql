@@ -0,0 +1,2 @@
+-- Drop the push time of the latest commit
+ALTER TABLE submissions DROP COLUMN IF EXISTS last_commit_at;
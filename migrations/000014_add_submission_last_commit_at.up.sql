@@ -0,0 +1,4 @@
+-- Record when the latest commit was pushed to the default branch of each
+-- submission repository. Commit dates are set by students, so the push
+-- time is taken from the server clock.
+ALTER TABLE submissions ADD COLUMN last_commit_at TIMESTAMP WITH TIME ZONE;
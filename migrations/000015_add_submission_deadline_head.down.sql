@@ -0,0 +1,4 @@
+-- Drop the recorded deadline heads
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_unresolved;
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_head_for;
+ALTER TABLE submissions DROP COLUMN IF EXISTS deadline_head_sha;
@@ -0,0 +1,7 @@
+-- Record the head of the default branch at the deadline, taken from the
+-- first push after it, so that enforcement tags the deadline snapshot even
+-- when it runs late. Submissions whose commit at the deadline cannot be
+-- determined are flagged instead of tagged.
+ALTER TABLE submissions ADD COLUMN deadline_head_sha VARCHAR(64);
+ALTER TABLE submissions ADD COLUMN deadline_head_for TIMESTAMP WITH TIME ZONE;
+ALTER TABLE submissions ADD COLUMN deadline_unresolved BOOLEAN NOT NULL DEFAULT FALSE;
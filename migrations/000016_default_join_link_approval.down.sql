@@ -0,0 +1,2 @@
+-- Restore claims without approval as the default for join links
+ALTER TABLE classroom_join_links ALTER COLUMN requires_approval SET DEFAULT FALSE;
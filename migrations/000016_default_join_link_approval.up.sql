@@ -0,0 +1,3 @@
+-- Claims through new join links wait for staff approval unless it is
+-- turned off. Existing links keep their setting.
+ALTER TABLE classroom_join_links ALTER COLUMN requires_approval SET DEFAULT TRUE;
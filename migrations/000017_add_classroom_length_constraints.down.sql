@@ -0,0 +1,3 @@
+-- Drop the description and organization name length constraints
+ALTER TABLE classrooms DROP CONSTRAINT IF EXISTS chk_classrooms_organization_name_length;
+ALTER TABLE classrooms DROP CONSTRAINT IF EXISTS chk_classrooms_description_length;
@@ -0,0 +1,8 @@
+-- Enforce the description and organization name limits that the API
+-- validates, like chk_classrooms_name_length does for names. Forgejo
+-- organization names are at most 40 characters. Rows written before the
+-- API limited descriptions are not checked.
+ALTER TABLE classrooms ADD CONSTRAINT chk_classrooms_description_length
+    CHECK (description IS NULL OR char_length(description) <= 1000) NOT VALID;
+ALTER TABLE classrooms ADD CONSTRAINT chk_classrooms_organization_name_length
+    CHECK (char_length(organization_name) >= 1 AND char_length(organization_name) <= 40) NOT VALID;
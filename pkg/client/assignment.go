@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// CreateAssignment creates an assignment from a template repository
+func (c *Client) CreateAssignment(ctx context.Context, req *model.CreateAssignmentRequest) (*model.Assignment, error) {
+	var assignment model.Assignment
+	if _, err := c.do(ctx, http.MethodPost, "/assignments", nil, req, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ListAssignments returns one page of assignments
+func (c *Client) ListAssignments(ctx context.Context, req *model.AssignmentListRequest) (*model.AssignmentListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.ClassroomID > 0 {
+			query.Set("classroom_id", strconv.FormatInt(req.ClassroomID, 10))
+		}
+		if req.Status != "" {
+			query.Set("status", req.Status)
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var assignments []model.Assignment
+	meta, err := c.do(ctx, http.MethodGet, "/assignments", query, nil, &assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(assignments))
+	return &model.AssignmentListResponse{
+		Assignments: assignments,
+		Total:       p.TotalCount,
+		Page:        p.Page,
+		PerPage:     p.PerPage,
+		TotalPages:  p.TotalPages,
+	}, nil
+}
+
+// GetAssignment returns an assignment
+func (c *Client) GetAssignment(ctx context.Context, id int64) (*model.Assignment, error) {
+	var assignment model.Assignment
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d", id), nil, nil, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// UpdateAssignment changes the fields of an assignment set in req
+func (c *Client) UpdateAssignment(ctx context.Context, id int64, req *model.UpdateAssignmentRequest) (*model.Assignment, error) {
+	var assignment model.Assignment
+	if _, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/assignments/%d", id), nil, req, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// DeleteAssignment deletes an assignment and its submissions
+func (c *Client) DeleteAssignment(ctx context.Context, id int64) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/assignments/%d", id), nil, nil, nil)
+	return err
+}
+
+// GetAssignmentStats returns the acceptance and submission statistics of an
+// assignment, with their distributions if req.Detailed is set
+func (c *Client) GetAssignmentStats(ctx context.Context, id int64, req *model.AssignmentStatsRequest) (*model.AssignmentStats, error) {
+	query := url.Values{}
+	if req != nil && req.Detailed {
+		query.Set("detailed", "true")
+	}
+
+	var stats model.AssignmentStats
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d/stats", id), query, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// AcceptAssignment accepts an assignment as the token user and returns the
+// resulting submission. teamName is only used for team assignments.
+func (c *Client) AcceptAssignment(ctx context.Context, assignmentID int64, teamName string) (*model.Submission, error) {
+	var submission model.Submission
+	req := &model.AcceptAssignmentRequest{TeamName: teamName}
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/assignments/%d/accept", assignmentID), nil, req, &submission); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// ListAuditEvents returns one page of the audit log of a classroom, newest
+// first
+func (c *Client) ListAuditEvents(ctx context.Context, classroomID int64, req *model.AuditListRequest) (*model.AuditListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Action != "" {
+			query.Set("action", req.Action)
+		}
+		if req.TargetType != "" {
+			query.Set("target_type", req.TargetType)
+		}
+		if req.TargetID != 0 {
+			query.Set("target_id", strconv.FormatInt(req.TargetID, 10))
+		}
+		if req.Actor != "" {
+			query.Set("actor", req.Actor)
+		}
+		if req.Since != "" {
+			query.Set("since", req.Since)
+		}
+		if req.Until != "" {
+			query.Set("until", req.Until)
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var events []model.AuditEvent
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d/audit", classroomID), query, nil, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(events))
+	return &model.AuditListResponse{
+		Events:     events,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
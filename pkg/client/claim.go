@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// GetJoinLink returns the join link of a classroom
+func (c *Client) GetJoinLink(ctx context.Context, classroomID int64) (*model.JoinLink, error) {
+	var link model.JoinLink
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d/roster/join-link", classroomID), nil, nil, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// RotateJoinLink issues a new join link token for a classroom, replacing
+// the previous one
+func (c *Client) RotateJoinLink(ctx context.Context, classroomID int64, req *model.RotateJoinLinkRequest) (*model.JoinLink, error) {
+	var link model.JoinLink
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/classrooms/%d/roster/join-link/rotate", classroomID), nil, req, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UpdateJoinLink enables or disables the join link of a classroom or
+// changes whether its claims need approval
+func (c *Client) UpdateJoinLink(ctx context.Context, classroomID int64, req *model.UpdateJoinLinkRequest) (*model.JoinLink, error) {
+	var link model.JoinLink
+	if _, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/classrooms/%d/roster/join-link", classroomID), nil, req, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ResolveJoinLink returns the classroom a join link token leads to
+func (c *Client) ResolveJoinLink(ctx context.Context, token string) (*model.JoinLinkDetails, error) {
+	var details model.JoinLinkDetails
+	if _, err := c.do(ctx, http.MethodGet, "/join/"+url.PathEscape(token), nil, nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// ClaimRosterEntry claims the roster entry matching req for the token user
+// through a join link token
+func (c *Client) ClaimRosterEntry(ctx context.Context, token string, req *model.ClaimRosterRequest) (*model.RosterClaim, error) {
+	var claim model.RosterClaim
+	if _, err := c.do(ctx, http.MethodPost, "/join/"+url.PathEscape(token)+"/claim", nil, req, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// ListClaims returns one page of the roster claims of a classroom
+func (c *Client) ListClaims(ctx context.Context, classroomID int64, req *model.RosterClaimListRequest) (*model.RosterClaimListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Status != "" {
+			query.Set("status", req.Status)
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var claims []model.RosterClaim
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d/roster/claims", classroomID), query, nil, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(claims))
+	return &model.RosterClaimListResponse{
+		Claims:     claims,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
+
+// ApproveClaim approves a pending roster claim, linking its entry
+func (c *Client) ApproveClaim(ctx context.Context, classroomID, claimID int64) (*model.RosterClaim, error) {
+	var claim model.RosterClaim
+	path := fmt.Sprintf("/classrooms/%d/roster/claims/%d/approve", classroomID, claimID)
+	if _, err := c.do(ctx, http.MethodPost, path, nil, nil, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// RejectClaim rejects a pending roster claim
+func (c *Client) RejectClaim(ctx context.Context, classroomID, claimID int64) (*model.RosterClaim, error) {
+	var claim model.RosterClaim
+	path := fmt.Sprintf("/classrooms/%d/roster/claims/%d/reject", classroomID, claimID)
+	if _, err := c.do(ctx, http.MethodPost, path, nil, nil, &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
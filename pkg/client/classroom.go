@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// CreateClassroom creates a classroom in an existing Forgejo organization
+func (c *Client) CreateClassroom(ctx context.Context, req *model.CreateClassroomRequest) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if _, err := c.do(ctx, http.MethodPost, "/classrooms", nil, req, &classroom); err != nil {
+		return nil, err
+	}
+	return &classroom, nil
+}
+
+// ListClassrooms returns one page of the classrooms visible to the token user
+func (c *Client) ListClassrooms(ctx context.Context, req *model.ClassroomListRequest) (*model.ClassroomListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.OrganizationName != "" {
+			query.Set("organization", req.OrganizationName)
+		}
+		if req.IncludeArchived {
+			query.Set("archived", "true")
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var classrooms []model.Classroom
+	meta, err := c.do(ctx, http.MethodGet, "/classrooms", query, nil, &classrooms)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(classrooms))
+	return &model.ClassroomListResponse{
+		Classrooms: classrooms,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
+
+// GetClassroom returns a classroom
+func (c *Client) GetClassroom(ctx context.Context, id int64) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d", id), nil, nil, &classroom); err != nil {
+		return nil, err
+	}
+	return &classroom, nil
+}
+
+// GetClassroomStats returns the statistics of a classroom with the progress
+// of each assignment
+func (c *Client) GetClassroomStats(ctx context.Context, id int64) (*model.ClassroomStats, error) {
+	var stats model.ClassroomStats
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d/stats", id), nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// UpdateClassroom changes the fields of a classroom set in req
+func (c *Client) UpdateClassroom(ctx context.Context, id int64, req *model.UpdateClassroomRequest) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if _, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/classrooms/%d", id), nil, req, &classroom); err != nil {
+		return nil, err
+	}
+	return &classroom, nil
+}
+
+// DeleteClassroom deletes a classroom with its roster and assignments
+func (c *Client) DeleteClassroom(ctx context.Context, id int64) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/classrooms/%d", id), nil, nil, nil)
+	return err
+}
+
+// ArchiveClassroom makes a classroom read-only
+func (c *Client) ArchiveClassroom(ctx context.Context, id int64) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/classrooms/%d/archive", id), nil, nil, &classroom); err != nil {
+		return nil, err
+	}
+	return &classroom, nil
+}
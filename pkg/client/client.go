@@ -0,0 +1,215 @@
+// Package client is a Go client for the Forgejo Classroom /api/v1 endpoints.
+//
+// Requests are authenticated with a Forgejo personal access token, which
+// fgc-server validates against Forgejo. Successful responses are decoded
+// from the {"data": ..., "meta": ...} envelope and failures are returned as
+// *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is used when no HTTP client is supplied
+const DefaultTimeout = 60 * time.Second
+
+// Client talks to a fgc-server instance
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a client for the server at serverURL authenticated with token
+func New(serverURL, token string) (*Client, error) {
+	if serverURL == "" {
+		return nil, fmt.Errorf("server URL cannot be empty")
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(serverURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("server URL must use http or https, got %q", serverURL)
+	}
+
+	return &Client{
+		baseURL:    u,
+		token:      token,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}, nil
+}
+
+// ErrNotSupported matches, with errors.Is, the *Error of endpoints the
+// server does not support, such as the team endpoints: teams are formed by
+// accepting team assignments instead
+var ErrNotSupported = errors.New("not supported by server")
+
+// Error is returned when the server responds with an error envelope
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+	Details    map[string]interface{}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s: %s (request_id: %s)", e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is ErrNotSupported and the server does not
+// support the endpoint
+func (e *Error) Is(target error) bool {
+	return target == ErrNotSupported && e.StatusCode == http.StatusNotImplemented
+}
+
+// envelope is the success response envelope, its data decoded later into
+// the type the caller expects
+type envelope struct {
+	Data json.RawMessage `json:"data"`
+	Meta *metaInfo       `json:"meta,omitempty"`
+}
+
+// metaInfo is the pagination metadata of list responses
+type metaInfo struct {
+	Page       int `json:"page,omitempty"`
+	PerPage    int `json:"per_page,omitempty"`
+	TotalPages int `json:"total_pages,omitempty"`
+	TotalCount int `json:"total_count,omitempty"`
+}
+
+// errorEnvelope is the body of error responses
+type errorEnvelope struct {
+	Error struct {
+		Code      string                 `json:"code"`
+		Message   string                 `json:"message"`
+		Details   map[string]interface{} `json:"details,omitempty"`
+		RequestID string                 `json:"request_id,omitempty"`
+	} `json:"error"`
+}
+
+// do sends a request with a JSON body and decodes the data of the response
+// envelope into out. It returns the pagination metadata, if any.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (*metaInfo, error) {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	return c.send(ctx, method, path, query, contentType, reader, out)
+}
+
+// send sends a request with a body of the given content type and decodes
+// the data of the response envelope into out
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader, out interface{}) (*metaInfo, error) {
+	req, err := c.newRequest(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return env.Meta, nil
+}
+
+// newRequest builds an authenticated request for an /api/v1 path. The
+// path is escaped already, so that segments may contain slashes.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) (*http.Request, error) {
+	u := *c.baseURL
+	u.RawPath = c.baseURL.EscapedPath() + "/api/v1" + path
+	unescaped, err := url.PathUnescape(u.RawPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request path %q: %w", path, err)
+	}
+	u.Path = unescaped
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	return req, nil
+}
+
+// pageQuery adds the pagination parameters of a list request to query
+func pageQuery(query url.Values, page, perPage int) {
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		query.Set("per_page", strconv.Itoa(perPage))
+	}
+}
+
+// pagination returns the pagination of a list response with n items,
+// treating a response without metadata as a single page
+func pagination(meta *metaInfo, n int) metaInfo {
+	if meta == nil {
+		return metaInfo{Page: 1, PerPage: n, TotalPages: 1, TotalCount: n}
+	}
+	return *meta
+}
+
+// decodeError converts an error response into an *Error
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var body errorEnvelope
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err == nil && body.Error.Code != "" {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+		apiErr.RequestID = body.Error.RequestID
+		apiErr.Details = body.Error.Details
+		return apiErr
+	}
+
+	apiErr.Code = fmt.Sprintf("HTTP_%d", resp.StatusCode)
+	apiErr.Message = http.StatusText(resp.StatusCode)
+	return apiErr
+}
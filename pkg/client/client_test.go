@@ -0,0 +1,335 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"code.forgejo.org/forgejo/classroom/internal/api/middleware"
+	"code.forgejo.org/forgejo/classroom/internal/audit"
+	"code.forgejo.org/forgejo/classroom/internal/model"
+	"code.forgejo.org/forgejo/classroom/internal/response"
+)
+
+func newTestServer(t *testing.T, handler gin.HandlerFunc) *Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Any("/api/v1/*path", handler)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, "secret")
+	require.NoError(t, err)
+	return c
+}
+
+func TestClient_AcceptAssignment(t *testing.T) {
+	t.Run("decodes the success envelope", func(t *testing.T) {
+		c := newTestServer(t, func(ctx *gin.Context) {
+			assert.Equal(t, "/api/v1/assignments/7/accept", ctx.Request.URL.Path)
+			assert.Equal(t, "token secret", ctx.GetHeader("Authorization"))
+			response.RespondWithData(ctx, http.StatusCreated, model.Submission{ID: 3, RepositoryName: "cs101-hw1-jdoe"})
+		})
+
+		submission, err := c.AcceptAssignment(context.Background(), 7, "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), submission.ID)
+		assert.Equal(t, "cs101-hw1-jdoe", submission.RepositoryName)
+	})
+
+	t.Run("decodes the error envelope", func(t *testing.T) {
+		c := newTestServer(t, func(ctx *gin.Context) {
+			response.RespondWithError(ctx, http.StatusUnprocessableEntity, response.ErrBusinessAlreadyAccepted,
+				"Assignment has already been accepted", map[string]interface{}{"repository_url": "https://forgejo/x"})
+		})
+
+		_, err := c.AcceptAssignment(context.Background(), 7, "")
+		var apiErr *Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+		assert.Equal(t, response.ErrBusinessAlreadyAccepted, apiErr.Code)
+		assert.Equal(t, "https://forgejo/x", apiErr.Details["repository_url"])
+		assert.NotEmpty(t, apiErr.RequestID)
+	})
+}
+
+func TestClient_ListAssignments(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/assignments", ctx.Request.URL.Path)
+		assert.Equal(t, "4", ctx.Query("classroom_id"))
+		response.RespondWithSuccess(ctx, http.StatusOK, []model.Assignment{{ID: 1, Slug: "hw1"}, {ID: 2, Slug: "hw2"}},
+			&response.MetaInfo{Page: 1, PerPage: 2, TotalPages: 3, TotalCount: 5})
+	})
+
+	list, err := c.ListAssignments(context.Background(), &model.AssignmentListRequest{ClassroomID: 4})
+	require.NoError(t, err)
+	require.Len(t, list.Assignments, 2)
+	assert.Equal(t, "hw2", list.Assignments[1].Slug)
+	assert.Equal(t, 5, list.Total)
+	assert.Equal(t, 3, list.TotalPages)
+}
+
+func TestClient_NotSupported(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		response.NotSupported(ctx, "Team management is not supported by this server")
+	})
+
+	_, err := c.CreateTeam(context.Background(), &model.CreateTeamRequest{AssignmentID: 7, Name: "Red"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, response.ErrSystemUnsupported, apiErr.Code)
+	assert.NotErrorIs(t, &Error{StatusCode: http.StatusNotFound}, ErrNotSupported)
+}
+
+func TestClient_GetAssignmentStats(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/assignments/7/stats", ctx.Request.URL.Path)
+		assert.Equal(t, "true", ctx.Query("detailed"))
+		response.RespondWithData(ctx, http.StatusOK, model.AssignmentStats{
+			AssignmentID: 7, TotalStudents: 2,
+			Details: &model.AssignmentStatsDetails{AcceptanceHistogram: []model.DateCount{{Date: "2026-03-02", Count: 1}}},
+		})
+	})
+
+	stats, err := c.GetAssignmentStats(context.Background(), 7, &model.AssignmentStatsRequest{Detailed: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalStudents)
+	require.NotNil(t, stats.Details)
+	assert.Equal(t, []model.DateCount{{Date: "2026-03-02", Count: 1}}, stats.Details.AcceptanceHistogram)
+}
+
+func TestClient_AcceptInvitation(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, http.MethodPost, ctx.Request.Method)
+		assert.Equal(t, "/api/v1/invitations/a-b_c/accept", ctx.Request.URL.Path)
+		var req model.AcceptAssignmentRequest
+		require.NoError(t, ctx.ShouldBindJSON(&req))
+		assert.Equal(t, "owls", req.TeamName)
+		response.RespondWithData(ctx, http.StatusCreated, model.Submission{ID: 3, AssignmentID: 7})
+	})
+
+	submission, err := c.AcceptInvitation(context.Background(), "a-b_c", "owls")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), submission.AssignmentID)
+}
+
+func TestClient_ListClaims(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/roster/claims", ctx.Request.URL.Path)
+		assert.Equal(t, "pending", ctx.Query("status"))
+		assert.Equal(t, "2", ctx.Query("page"))
+		response.RespondWithSuccess(ctx, http.StatusOK, []model.RosterClaim{{ID: 9, StudentID: "john123"}},
+			&response.MetaInfo{Page: 2, PerPage: 1, TotalPages: 3, TotalCount: 3})
+	})
+
+	list, err := c.ListClaims(context.Background(), 4, &model.RosterClaimListRequest{Status: "pending", Page: 2, PerPage: 1})
+	require.NoError(t, err)
+	require.Len(t, list.Claims, 1)
+	assert.Equal(t, "john123", list.Claims[0].StudentID)
+	assert.Equal(t, 3, list.Total)
+	assert.Equal(t, 3, list.TotalPages)
+}
+
+func TestClient_ListAuditEvents(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/audit", ctx.Request.URL.Path)
+		assert.Equal(t, "roster.link", ctx.Query("action"))
+		assert.Equal(t, "12", ctx.Query("target_id"))
+		assert.Equal(t, "2026-10-01T00:00:00Z", ctx.Query("since"))
+		assert.Empty(t, ctx.Query("actor"))
+		response.RespondWithSuccess(ctx, http.StatusOK, []model.AuditEvent{{
+			ID:      5,
+			Action:  "roster.link",
+			Changes: map[string]audit.Change{"forgejo_username": {After: "jdoe"}},
+		}}, &response.MetaInfo{Page: 1, PerPage: 50, TotalPages: 1, TotalCount: 1})
+	})
+
+	list, err := c.ListAuditEvents(context.Background(), 4, &model.AuditListRequest{
+		Action:   "roster.link",
+		TargetID: 12,
+		Since:    "2026-10-01T00:00:00Z",
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Events, 1)
+	assert.Equal(t, "jdoe", list.Events[0].Changes["forgejo_username"].After)
+	assert.Equal(t, 1, list.Total)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("", "token")
+	assert.Error(t, err)
+
+	_, err = New("ftp://example.com", "token")
+	assert.Error(t, err)
+}
+
+func TestClient_ImportRoster(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/roster/import", ctx.Request.URL.Path)
+		assert.Equal(t, "true", ctx.PostForm("update"))
+		assert.Equal(t, ";", ctx.PostForm("delimiter"))
+
+		header, err := ctx.FormFile("file")
+		require.NoError(t, err)
+		assert.Equal(t, "roster.csv", header.Filename)
+
+		jobID := int64(9)
+		response.RespondWithData(ctx, http.StatusAccepted, model.ImportRosterResponse{Status: "pending", JobID: &jobID})
+	})
+
+	resp, err := c.ImportRoster(context.Background(), 4, "/tmp/roster.csv", strings.NewReader("Jane;jane@example.com;j1\n"),
+		&model.ImportRosterRequest{Update: true, Delimiter: ";"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JobID)
+	assert.Equal(t, int64(9), *resp.JobID)
+	assert.Nil(t, resp.Result)
+}
+
+func TestClient_BulkRoster(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/roster/bulk", ctx.Request.URL.Path)
+		var req model.BulkRosterRequest
+		require.NoError(t, ctx.ShouldBindJSON(&req))
+		assert.Equal(t, model.BulkModeAllOrNothing, req.Mode)
+		require.Len(t, req.Operations, 1)
+
+		response.RespondWithData(ctx, http.StatusOK, model.BulkRosterResponse{
+			Results: []model.RosterOperationResult{{Index: 0, Status: model.BulkStatusError,
+				Error: &model.OperationError{Code: response.ErrResourceNotFound, Message: "Student s1 not found"}}},
+			Summary: model.BulkRosterSummary{Total: 1, Failed: 1, RolledBack: true},
+		})
+	})
+
+	resp, err := c.BulkRoster(context.Background(), 4, &model.BulkRosterRequest{
+		Mode:       model.BulkModeAllOrNothing,
+		Operations: []model.RosterOperation{{Action: model.RosterActionRemove, StudentID: "s1"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, response.ErrResourceNotFound, resp.Results[0].Error.Code)
+	assert.True(t, resp.Summary.RolledBack)
+}
+
+func TestClient_ListJobErrors(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/jobs/9/errors", ctx.Request.URL.Path)
+		assert.Equal(t, "2", ctx.Query("page"))
+		response.RespondWithSuccess(ctx, http.StatusOK,
+			[]model.JobError{{Row: 3, Field: "student_email", Code: response.ErrValidationInvalidFormat, Message: "bad"}},
+			&response.MetaInfo{Page: 2, PerPage: 1, TotalPages: 2, TotalCount: 2})
+	})
+
+	result, err := c.ListJobErrors(context.Background(), 9, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 3, result.Errors[0].Row)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 2, result.Page)
+}
+
+func TestClient_DownloadSubmissions(t *testing.T) {
+	t.Run("streams the archive", func(t *testing.T) {
+		c := newTestServer(t, func(ctx *gin.Context) {
+			assert.Equal(t, "/api/v1/assignments/7/submissions/download", ctx.Request.URL.Path)
+			assert.Equal(t, "tar.gz", ctx.Query("format"))
+			assert.Equal(t, "true", ctx.Query("at_deadline"))
+			ctx.Header("Content-Disposition", `attachment; filename="../cs101-hw1-deadline.tar.gz"`)
+			ctx.Data(http.StatusOK, "application/gzip", []byte("archive"))
+		})
+
+		var buf strings.Builder
+		filename, err := c.DownloadSubmissions(context.Background(), 7,
+			&model.SubmissionDownloadRequest{Format: "tar.gz", AtDeadline: true}, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "cs101-hw1-deadline.tar.gz", filename)
+		assert.Equal(t, "archive", buf.String())
+	})
+
+	t.Run("decodes the error envelope", func(t *testing.T) {
+		c := newTestServer(t, func(ctx *gin.Context) {
+			response.RespondWithError(ctx, http.StatusForbidden, response.ErrAuthzInsufficientPermissions, "Insufficient permissions", nil)
+		})
+
+		var buf strings.Builder
+		_, err := c.DownloadSubmissions(context.Background(), 7, nil, &buf)
+		var apiErr *Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, response.ErrAuthzInsufficientPermissions, apiErr.Code)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestClient_ListClassrooms(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms", ctx.Request.URL.Path)
+		assert.Equal(t, "cs101", ctx.Query("organization"))
+		assert.Equal(t, "true", ctx.Query("archived"))
+		assert.Equal(t, "2", ctx.Query("page"))
+		assert.Empty(t, ctx.Query("per_page"))
+		response.RespondWithSuccess(ctx, http.StatusOK, []model.Classroom{{ID: 4, Name: "CS 101"}},
+			&response.MetaInfo{Page: 2, PerPage: 1, TotalPages: 3, TotalCount: 3})
+	})
+
+	list, err := c.ListClassrooms(context.Background(), &model.ClassroomListRequest{
+		OrganizationName: "cs101",
+		IncludeArchived:  true,
+		Page:             2,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Classrooms, 1)
+	assert.Equal(t, int64(4), list.Classrooms[0].ID)
+	assert.Equal(t, 3, list.Total)
+	assert.Equal(t, 2, list.Page)
+	assert.Equal(t, 3, list.TotalPages)
+}
+
+func TestClient_GetClassroomStats(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/stats", ctx.Request.URL.Path)
+		response.RespondWithData(ctx, http.StatusOK, model.ClassroomStats{
+			ClassroomID: 4, TotalStudents: 30,
+			Assignments: []model.AssignmentProgress{{AssignmentID: 7, Slug: "hw1", AcceptedCount: 12, AcceptanceRate: 0.4}},
+		})
+	})
+
+	stats, err := c.GetClassroomStats(context.Background(), 4)
+	require.NoError(t, err)
+	assert.Equal(t, 30, stats.TotalStudents)
+	require.Len(t, stats.Assignments, 1)
+	assert.Equal(t, 0.4, stats.Assignments[0].AcceptanceRate)
+}
+
+func TestClient_DeleteClassroom(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, http.MethodDelete, ctx.Request.Method)
+		assert.Equal(t, "/api/v1/classrooms/4", ctx.Request.URL.Path)
+		ctx.Status(http.StatusNoContent)
+	})
+
+	require.NoError(t, c.DeleteClassroom(context.Background(), 4))
+}
+
+func TestClient_LinkStudent(t *testing.T) {
+	c := newTestServer(t, func(ctx *gin.Context) {
+		assert.Equal(t, "/api/v1/classrooms/4/roster/students/a%2Fb/link", ctx.Request.URL.EscapedPath())
+		var req model.LinkStudentRequest
+		require.NoError(t, ctx.ShouldBindJSON(&req))
+		response.RespondWithData(ctx, http.StatusOK, model.RosterEntry{ID: 9, StudentID: "a/b", ForgejoUsername: &req.ForgejoUsername})
+	})
+
+	entry, err := c.LinkStudent(context.Background(), 4, "a/b", &model.LinkStudentRequest{ForgejoUsername: "jdoe"})
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", *entry.ForgejoUsername)
+}
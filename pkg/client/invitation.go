@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// GetInvitation returns the invitation of an assignment
+func (c *Client) GetInvitation(ctx context.Context, assignmentID int64) (*model.Invitation, error) {
+	var inv model.Invitation
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d/invitation", assignmentID), nil, nil, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// RotateInvitation issues a new invitation token for an assignment,
+// replacing the previous one
+func (c *Client) RotateInvitation(ctx context.Context, assignmentID int64, req *model.RotateInvitationRequest) (*model.Invitation, error) {
+	var inv model.Invitation
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/assignments/%d/invitation/rotate", assignmentID), nil, req, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// UpdateInvitation enables or disables the invitation of an assignment or
+// changes its expiry
+func (c *Client) UpdateInvitation(ctx context.Context, assignmentID int64, req *model.UpdateInvitationRequest) (*model.Invitation, error) {
+	var inv model.Invitation
+	if _, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/assignments/%d/invitation", assignmentID), nil, req, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ResolveInvitation returns the assignment an invitation token leads to
+func (c *Client) ResolveInvitation(ctx context.Context, token string) (*model.InvitationDetails, error) {
+	var details model.InvitationDetails
+	if _, err := c.do(ctx, http.MethodGet, "/invitations/"+url.PathEscape(token), nil, nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// AcceptInvitation accepts the assignment of an invitation token as the
+// token user. teamName is only used for team assignments.
+func (c *Client) AcceptInvitation(ctx context.Context, token, teamName string) (*model.Submission, error) {
+	var submission model.Submission
+	req := &model.AcceptAssignmentRequest{TeamName: teamName}
+	if _, err := c.do(ctx, http.MethodPost, "/invitations/"+url.PathEscape(token)+"/accept", nil, req, &submission); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
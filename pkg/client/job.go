@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// GetJob returns the status of a background job
+func (c *Client) GetJob(ctx context.Context, id int64) (*model.Job, error) {
+	var job model.Job
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/jobs/%d", id), nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobErrors returns one page of the per-row failures of a job
+func (c *Client) ListJobErrors(ctx context.Context, id int64, page, perPage int) (*model.JobErrorListResponse, error) {
+	query := url.Values{}
+	pageQuery(query, page, perPage)
+
+	var errs []model.JobError
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/jobs/%d/errors", id), query, nil, &errs)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(errs))
+	return &model.JobErrorListResponse{
+		Errors:     errs,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// AddStudent adds a student to a classroom roster
+func (c *Client) AddStudent(ctx context.Context, classroomID int64, req *model.AddStudentRequest) (*model.RosterEntry, error) {
+	var entry model.RosterEntry
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/classrooms/%d/roster/students", classroomID), nil, req, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListStudents returns one page of a classroom roster
+func (c *Client) ListStudents(ctx context.Context, classroomID int64, req *model.RosterListRequest) (*model.RosterListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.LinkedOnly {
+			query.Set("linked_only", "true")
+		}
+		if req.UnlinkedOnly {
+			query.Set("unlinked_only", "true")
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var students []model.RosterEntry
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/classrooms/%d/roster/students", classroomID), query, nil, &students)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(students))
+	return &model.RosterListResponse{
+		Students:   students,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
+
+// LinkStudent links the roster entry with the given student ID to a Forgejo
+// account
+func (c *Client) LinkStudent(ctx context.Context, classroomID int64, studentID string, req *model.LinkStudentRequest) (*model.RosterEntry, error) {
+	var entry model.RosterEntry
+	path := fmt.Sprintf("/classrooms/%d/roster/students/%s/link", classroomID, url.PathEscape(studentID))
+	if _, err := c.do(ctx, http.MethodPost, path, nil, req, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ImportRoster uploads a roster CSV file to a classroom. Small files are
+// imported right away and the response carries the result; for larger
+// ones it carries the ID of the job to poll with GetJob.
+func (c *Client) ImportRoster(ctx context.Context, classroomID int64, filename string, file io.Reader, opts *model.ImportRosterRequest) (*model.ImportRosterResponse, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	if opts != nil {
+		fields := map[string]string{
+			"dry_run":   strconv.FormatBool(opts.DryRun),
+			"update":    strconv.FormatBool(opts.Update),
+			"delimiter": opts.Delimiter,
+		}
+		for name, value := range fields {
+			if err := w.WriteField(name, value); err != nil {
+				return nil, fmt.Errorf("failed to encode request: %w", err)
+			}
+		}
+	}
+
+	part, err := w.CreateFormFile("file", filepath.Base(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	var result model.ImportRosterResponse
+	path := fmt.Sprintf("/classrooms/%d/roster/import", classroomID)
+	if _, err := c.send(ctx, http.MethodPost, path, nil, w.FormDataContentType(), &body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BulkRoster applies add, update, remove and link operations to a
+// classroom roster. The results are aligned with req.Operations.
+func (c *Client) BulkRoster(ctx context.Context, classroomID int64, req *model.BulkRosterRequest) (*model.BulkRosterResponse, error) {
+	var result model.BulkRosterResponse
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/classrooms/%d/roster/bulk", classroomID), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
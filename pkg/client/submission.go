@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// ListSubmissions returns one page of the submissions of an assignment.
+// Students only see their own and their team's.
+func (c *Client) ListSubmissions(ctx context.Context, assignmentID int64, req *model.SubmissionListRequest) (*model.SubmissionListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.Status != "" {
+			query.Set("status", req.Status)
+		}
+		if req.TeamOnly {
+			query.Set("team_only", "true")
+		}
+		if req.IndividualOnly {
+			query.Set("individual_only", "true")
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var submissions []model.Submission
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d/submissions", assignmentID), query, nil, &submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(submissions))
+	return &model.SubmissionListResponse{
+		Submissions: submissions,
+		Total:       p.TotalCount,
+		Page:        p.Page,
+		PerPage:     p.PerPage,
+		TotalPages:  p.TotalPages,
+	}, nil
+}
+
+// GetSubmission returns a submission
+func (c *Client) GetSubmission(ctx context.Context, id int64) (*model.Submission, error) {
+	var submission model.Submission
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/submissions/%d", id), nil, nil, &submission); err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// DownloadSubmissions streams an archive of all submissions of an
+// assignment to w and returns the file name suggested by the server. The
+// download is not bound by the client timeout; cancel ctx to stop it.
+func (c *Client) DownloadSubmissions(ctx context.Context, assignmentID int64, opts *model.SubmissionDownloadRequest, w io.Writer) (string, error) {
+	query := url.Values{}
+	if opts != nil {
+		if opts.Format != "" {
+			query.Set("format", opts.Format)
+		}
+		if opts.AtDeadline {
+			query.Set("at_deadline", "true")
+		}
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d/submissions/download", assignmentID), query, "", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "*/*")
+
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", decodeError(resp)
+	}
+
+	filename := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		// Never let the server pick a directory
+		if name := path.Base(params["filename"]); name != "." && name != "/" && name != ".." {
+			filename = name
+		}
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", fmt.Errorf("download interrupted: %w", err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"code.forgejo.org/forgejo/classroom/internal/model"
+)
+
+// CreateTeam creates a team for a team assignment
+func (c *Client) CreateTeam(ctx context.Context, req *model.CreateTeamRequest) (*model.Team, error) {
+	var team model.Team
+	if _, err := c.do(ctx, http.MethodPost, "/teams", nil, req, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// ListTeams returns one page of the teams of an assignment
+func (c *Client) ListTeams(ctx context.Context, assignmentID int64, req *model.TeamListRequest) (*model.TeamListResponse, error) {
+	query := url.Values{}
+	if req != nil {
+		if req.ShowMembers {
+			query.Set("show_members", "true")
+		}
+		pageQuery(query, req.Page, req.PerPage)
+	}
+
+	var teams []model.TeamWithMembers
+	meta, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/assignments/%d/teams", assignmentID), query, nil, &teams)
+	if err != nil {
+		return nil, err
+	}
+
+	p := pagination(meta, len(teams))
+	return &model.TeamListResponse{
+		Teams:      teams,
+		Total:      p.TotalCount,
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		TotalPages: p.TotalPages,
+	}, nil
+}
+
+// GetTeam returns a team with its members
+func (c *Client) GetTeam(ctx context.Context, id int64) (*model.TeamWithMembers, error) {
+	var team model.TeamWithMembers
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/teams/%d", id), nil, nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// JoinTeam adds the token user to a team
+func (c *Client) JoinTeam(ctx context.Context, id int64) (*model.Team, error) {
+	var team model.Team
+	if _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/teams/%d/join", id), nil, &model.JoinTeamRequest{}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// LeaveTeam removes the token user from a team. Team leaders must set force.
+func (c *Client) LeaveTeam(ctx context.Context, id int64, force bool) error {
+	query := url.Values{}
+	if force {
+		query.Set("force", "true")
+	}
+	_, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/teams/%d/leave", id), query, nil, nil)
+	return err
+}